@@ -27,6 +27,7 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/cmd/agent/common/signals"
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -58,6 +59,9 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/status", getStatus).Methods("GET")
 	r.HandleFunc("/stream-logs", streamLogs).Methods("POST")
 	r.HandleFunc("/dogstatsd-stats", getDogstatsdStats).Methods("GET")
+	r.HandleFunc("/dogstatsd-contexts", getDogstatsdContexts).Methods("GET")
+	r.HandleFunc("/dogstatsd-capture", startDogstatsdCapture).Methods("POST")
+	r.HandleFunc("/dogstatsd-replay", startDogstatsdReplay).Methods("POST")
 	r.HandleFunc("/status/formatted", getFormattedStatus).Methods("GET")
 	r.HandleFunc("/status/health", getHealth).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusGetterHandler).Methods("GET")
@@ -302,6 +306,102 @@ func getDogstatsdStats(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonStats)
 }
 
+func getDogstatsdContexts(w http.ResponseWriter, r *http.Request) {
+	log.Info("Got a request for the Dogstatsd top contexts.")
+
+	if !config.Datadog.GetBool("use_dogstatsd") {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := json.Marshal(map[string]string{
+			"error":      "Dogstatsd not enabled in the Agent configuration",
+			"error_type": "no server",
+		})
+		w.WriteHeader(400)
+		w.Write(body)
+		return
+	}
+
+	jsonContexts, err := json.Marshal(aggregator.GetDogstatsdTopContexts())
+	if err != nil {
+		log.Errorf("Error marshalling the Dogstatsd top contexts: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonContexts)
+}
+
+// DogstatsdCaptureRequest is the body of the dogstatsd-capture requests.
+type DogstatsdCaptureRequest struct {
+	Duration string `json:"duration"`
+}
+
+// DogstatsdReplayRequest is the body of the dogstatsd-replay requests.
+type DogstatsdReplayRequest struct {
+	Path  string `json:"path"`
+	Paced bool   `json:"paced"`
+}
+
+func startDogstatsdCapture(w http.ResponseWriter, r *http.Request) {
+	log.Info("Got a request to capture the Dogstatsd traffic.")
+	w.Header().Set("Content-Type", "application/json")
+
+	if common.DSD == nil {
+		body, _ := json.Marshal(map[string]string{"error": "Dogstatsd is not running"})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	var request DogstatsdCaptureRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("invalid request: %v", err)})
+		http.Error(w, string(body), 400)
+		return
+	}
+	duration, err := time.ParseDuration(request.Duration)
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("invalid duration: %v", err)})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	path, err := common.DSD.Capture(duration)
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	body, _ := json.Marshal(map[string]string{"path": path})
+	w.Write(body)
+}
+
+func startDogstatsdReplay(w http.ResponseWriter, r *http.Request) {
+	log.Info("Got a request to replay a Dogstatsd capture.")
+	w.Header().Set("Content-Type", "application/json")
+
+	if common.DSD == nil {
+		body, _ := json.Marshal(map[string]string{"error": "Dogstatsd is not running"})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	var request DogstatsdReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("invalid request: %v", err)})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	if err := common.DSD.Replay(request.Path, request.Paced); err != nil {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	body, _ := json.Marshal(map[string]string{"path": request.Path})
+	w.Write(body)
+}
+
 func getFormattedStatus(w http.ResponseWriter, r *http.Request) {
 	log.Info("Got a request for the formatted status. Making formatted status.")
 	s, err := status.GetAndFormatStatus()
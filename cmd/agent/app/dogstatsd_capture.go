@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/agent"
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dsdCaptureDuration time.Duration
+	dsdReplayFilePath  string
+	dsdReplayFast      bool
+)
+
+func init() {
+	AgentCmd.AddCommand(dogstatsdCaptureCmd)
+	dogstatsdCaptureCmd.Flags().DurationVarP(&dsdCaptureDuration, "duration", "d", time.Minute, "Duration of the capture, at most 10 minutes")
+
+	AgentCmd.AddCommand(dogstatsdReplayCmd)
+	dogstatsdReplayCmd.Flags().StringVarP(&dsdReplayFilePath, "file", "f", "", "Path of the capture to replay")
+	dogstatsdReplayCmd.Flags().BoolVarP(&dsdReplayFast, "fast", "", false, "Replay the packets as fast as possible instead of at the pace they were received")
+}
+
+var dogstatsdCaptureCmd = &cobra.Command{
+	Use:   "dogstatsd-capture",
+	Short: "Record the packets received by dogstatsd to a file",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupDogstatsdCaptureCommand(); err != nil {
+			return err
+		}
+
+		request, _ := json.Marshal(agent.DogstatsdCaptureRequest{Duration: dsdCaptureDuration.String()})
+		r, err := postDogstatsdCaptureRequest("dogstatsd-capture", request)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Capturing the dogstatsd traffic for %v to %s\n", dsdCaptureDuration, color.GreenString(r["path"]))
+		return nil
+	},
+}
+
+var dogstatsdReplayCmd = &cobra.Command{
+	Use:   "dogstatsd-replay",
+	Short: "Replay a capture of the packets received by dogstatsd",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if dsdReplayFilePath == "" {
+			return fmt.Errorf("the path of the capture to replay is required")
+		}
+		if err := setupDogstatsdCaptureCommand(); err != nil {
+			return err
+		}
+
+		// the capture is read by the agent
+		path, err := filepath.Abs(dsdReplayFilePath)
+		if err != nil {
+			return err
+		}
+		request, _ := json.Marshal(agent.DogstatsdReplayRequest{Path: path, Paced: !dsdReplayFast})
+		if _, err := postDogstatsdCaptureRequest("dogstatsd-replay", request); err != nil {
+			return err
+		}
+		fmt.Printf("Replaying %s, the agent logs when the replay is complete\n", color.GreenString(path))
+		return nil
+	},
+}
+
+func setupDogstatsdCaptureCommand() error {
+	if flagNoColor {
+		color.NoColor = true
+	}
+
+	err := common.SetupConfigWithoutSecrets(confFilePath, "")
+	if err != nil {
+		return fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+
+	err = config.SetupLogger(loggerName, config.GetEnvDefault("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+	if err != nil {
+		fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+		return err
+	}
+
+	return util.SetAuthToken()
+}
+
+// postDogstatsdCaptureRequest sends a request to the agent and returns the decoded response.
+func postDogstatsdCaptureRequest(endpoint string, request []byte) (map[string]string, error) {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return nil, err
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/%s", ipcAddress, config.Datadog.GetInt("cmd_port"), endpoint)
+
+	r, err := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer(request))
+	response := make(map[string]string)
+	json.Unmarshal(r, &response) //nolint:errcheck
+	if err != nil {
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := response["error"]; found {
+			return nil, fmt.Errorf(e)
+		}
+		return nil, fmt.Errorf("Could not reach agent: %v \nMake sure the agent is running and contact support if you continue having issues", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	AgentCmd.AddCommand(dogstatsdMapperCmd)
+	dogstatsdMapperCmd.AddCommand(dogstatsdMapperTestCmd)
+}
+
+var (
+	dogstatsdMapperCmd = &cobra.Command{
+		Use:   "dogstatsd-mapper",
+		Short: "Check the DogStatsD mapper configuration",
+		Long:  ``,
+	}
+	dogstatsdMapperTestCmd = &cobra.Command{
+		Use:   "test [metric]",
+		Short: "Print how the DogStatsD mapper maps a metric, e.g. 'test.job.duration.my_job:12|ms|#env:prod'",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		RunE:  testDogstatsdMapping,
+	}
+)
+
+func testDogstatsdMapping(cmd *cobra.Command, args []string) error {
+	if flagNoColor {
+		color.NoColor = true
+	}
+
+	err := common.SetupConfigWithoutSecrets(confFilePath, "")
+	if err != nil {
+		return fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+
+	err = config.SetupLogger(loggerName, config.GetEnvDefault("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+	if err != nil {
+		fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+		return err
+	}
+
+	mappings, err := config.GetDogstatsdMappingProfiles()
+	if err != nil {
+		return err
+	}
+	if len(mappings) == 0 {
+		return fmt.Errorf("no mapping profile is configured in dogstatsd_mapper_profiles")
+	}
+	metricMapper, err := mapper.NewMetricMapper(mappings, config.Datadog.GetInt("dogstatsd_mapper_cache_size"))
+	if err != nil {
+		return fmt.Errorf("invalid mapper configuration: %v", err)
+	}
+
+	result, err := dogstatsd.MapMetricMessage(metricMapper, []byte(args[0]))
+	if err != nil {
+		return fmt.Errorf("invalid metric %q: %v", args[0], err)
+	}
+
+	printMappedMetric("Metric", result.Original)
+	switch {
+	case !result.Matched:
+		fmt.Println(color.YellowString("No mapping matched the metric, it is sent unchanged"))
+	case result.Dropped:
+		fmt.Println(color.RedString("The metric is dropped"))
+	default:
+		printMappedMetric("Mapped to", result.Mapped)
+	}
+	return nil
+}
+
+func printMappedMetric(title string, metric dogstatsd.MappedMetric) {
+	fmt.Printf("%s:\n", color.BlueString(title))
+	fmt.Printf("  name: %s\n", metric.Name)
+	fmt.Printf("  type: %s\n", metric.Type)
+	fmt.Printf("  tags: %s\n", strings.Join(metric.Tags, ","))
+}
@@ -10,9 +10,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
@@ -86,6 +88,12 @@ func requestDogstatsdStats() error {
 
 		if len(errMap["error_type"]) > 0 {
 			fmt.Println(e)
+			// the top contexts are available even when the metrics stats are disabled
+			if !prettyPrintJSON && !jsonStatus {
+				if contexts, err := requestDogstatsdTopContexts(c, ipcAddress); err == nil {
+					fmt.Printf("\n%s", contexts)
+				}
+			}
 			return nil
 		}
 
@@ -107,6 +115,12 @@ func requestDogstatsdStats() error {
 			fmt.Printf("Could not format the statistics, the data must be inconsistent. You may want to try the JSON output. Contact the support if you continue having issues.\n")
 			return nil
 		}
+		contexts, err := requestDogstatsdTopContexts(c, ipcAddress)
+		if err != nil {
+			fmt.Printf("Could not get the top contexts: %v\n", err)
+		} else {
+			s += "\n" + contexts
+		}
 	}
 
 	if dsdStatsFilePath == "" {
@@ -130,3 +144,18 @@ func requestDogstatsdStats() error {
 
 	return nil
 }
+
+// requestDogstatsdTopContexts returns a printable version of the metrics and tag keys with the most contexts.
+func requestDogstatsdTopContexts(c *http.Client, ipcAddress string) (string, error) {
+	urlstr := fmt.Sprintf("https://%v:%v/agent/dogstatsd-contexts", ipcAddress, config.Datadog.GetInt("cmd_port"))
+	r, err := util.DoGet(c, urlstr)
+	if err != nil {
+		return "", err
+	}
+
+	var topContexts aggregator.TopContexts
+	if err := json.Unmarshal(r, &topContexts); err != nil {
+		return "", err
+	}
+	return aggregator.FormatTopContexts(topContexts), nil
+}
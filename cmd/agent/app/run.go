@@ -35,8 +35,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
+	"github.com/DataDog/datadog-agent/pkg/openmetrics"
 	orchcfg "github.com/DataDog/datadog-agent/pkg/orchestrator/config"
+	"github.com/DataDog/datadog-agent/pkg/otlp"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
+	"github.com/DataDog/datadog-agent/pkg/remotewrite"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -360,6 +363,27 @@ func StartAgent() error {
 	}
 	log.Debugf("statsd started")
 
+	// start the Prometheus remote-write receiver
+	if remotewrite.IsEnabled() {
+		if err := remotewrite.StartServer(); err != nil {
+			log.Errorf("Could not start the Prometheus remote-write receiver: %s", err)
+		}
+	}
+
+	// start the OTLP receiver
+	if otlp.IsEnabled() {
+		if err := otlp.StartReceiver(); err != nil {
+			log.Errorf("Could not start the OTLP receiver: %s", err)
+		}
+	}
+
+	// expose the aggregated metrics to the local Prometheus servers
+	if openmetrics.IsEnabled() {
+		if err := openmetrics.StartServer(agg); err != nil {
+			log.Errorf("Could not start the OpenMetrics endpoint: %s", err)
+		}
+	}
+
 	// Start SNMP trap server
 	if traps.IsEnabled() {
 		if config.Datadog.GetBool("logs_enabled") {
@@ -447,6 +471,9 @@ func StopAgent() {
 		common.MetadataScheduler.Stop()
 	}
 	traps.StopServer()
+	remotewrite.StopServer()
+	otlp.StopReceiver()
+	openmetrics.StopServer()
 	api.StopServer()
 	clcrunnerapi.StopCLCRunnerServer()
 	jmx.StopJmxfetch()
@@ -48,6 +48,11 @@ func InitRuntimeSettings() error {
 	if err := registerRuntimeSetting(profilingRuntimeSetting("profiling")); err != nil {
 		return err
 	}
+	for _, name := range []string{"metric_filter_allowlist", "metric_filter_blocklist", "metric_filter_match_type", "metric_filter_strip_tags"} {
+		if err := registerRuntimeSetting(metricFilterRuntimeSetting(name)); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var metricFilterDescriptions = map[string]string{
+	"metric_filter_allowlist":  "Set/get the patterns of the metrics to keep, separated by commas",
+	"metric_filter_blocklist":  "Set/get the patterns of the metrics to drop, separated by commas",
+	"metric_filter_match_type": "Set/get how the metric filter patterns are matched, valid values are: wildcard, regex",
+	"metric_filter_strip_tags": "Set/get the tag keys removed from all the metrics, separated by commas",
+}
+
+// metricFilterRuntimeSetting wraps operations to change the metric filter at runtime.
+type metricFilterRuntimeSetting string
+
+func (s metricFilterRuntimeSetting) Description() string {
+	return metricFilterDescriptions[string(s)]
+}
+
+func (s metricFilterRuntimeSetting) Hidden() bool {
+	return false
+}
+
+func (s metricFilterRuntimeSetting) Name() string {
+	return string(s)
+}
+
+func (s metricFilterRuntimeSetting) Get() (interface{}, error) {
+	if s == "metric_filter_match_type" {
+		return config.Datadog.GetString(string(s)), nil
+	}
+	return config.Datadog.GetStringSlice(string(s)), nil
+}
+
+func (s metricFilterRuntimeSetting) Set(v interface{}) error {
+	var newValue interface{}
+	var err error
+
+	if s == "metric_filter_match_type" {
+		matchType, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("metricFilterRuntimeSetting: bad parameter value provided")
+		}
+		newValue = matchType
+	} else if newValue, err = getStringSlice(v); err != nil {
+		return fmt.Errorf("metricFilterRuntimeSetting: %v", err)
+	}
+
+	previousValue := config.Datadog.Get(string(s))
+	config.Datadog.Set(string(s), newValue)
+	if err := aggregator.ReloadMetricFilter(); err != nil {
+		config.Datadog.Set(string(s), previousValue)
+		return fmt.Errorf("metricFilterRuntimeSetting: %v", err)
+	}
+	return nil
+}
+
+// getStringSlice returns the list of strings contained in value.
+// If value is a string, the elements are separated by commas.
+func getStringSlice(v interface{}) ([]string, error) {
+	switch value := v.(type) {
+	case string:
+		list := []string{}
+		for _, elem := range strings.Split(value, ",") {
+			if elem = strings.TrimSpace(elem); elem != "" {
+				list = append(list, elem)
+			}
+		}
+		return list, nil
+	case []string:
+		return value, nil
+	case []interface{}:
+		list := make([]string, 0, len(value))
+		for _, elem := range value {
+			str, ok := elem.(string)
+			if !ok {
+				return nil, fmt.Errorf("getStringSlice: bad parameter value provided: %v", elem)
+			}
+			list = append(list, str)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("getStringSlice: bad parameter value provided")
+	}
+}
@@ -184,3 +184,43 @@ func TestProfiling(t *testing.T) {
 	err = ll.Set("on")
 	assert.NotNil(t, err)
 }
+
+func TestMetricFilter(t *testing.T) {
+	cleanRuntimeSetting()
+	defer func() {
+		config.Datadog.Set("metric_filter_allowlist", []string{})
+		config.Datadog.Set("metric_filter_match_type", "wildcard")
+		aggregator.ReloadMetricFilter()
+	}()
+
+	s := metricFilterRuntimeSetting("metric_filter_allowlist")
+	assert.Equal(t, "metric_filter_allowlist", s.Name())
+
+	err := s.Set("custom.*, system.cpu.user")
+	assert.Nil(t, err)
+	v, err := s.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"custom.*", "system.cpu.user"}, v)
+
+	err = s.Set([]interface{}{"custom.*"})
+	assert.Nil(t, err)
+	v, err = s.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"custom.*"}, v)
+
+	// an invalid filter is not applied
+	mt := metricFilterRuntimeSetting("metric_filter_match_type")
+	err = mt.Set("regex")
+	assert.Nil(t, err)
+	err = s.Set("custom.(")
+	assert.NotNil(t, err)
+	v, err = s.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"custom.*"}, v)
+
+	err = mt.Set("invalid")
+	assert.NotNil(t, err)
+	v, err = mt.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "regex", v)
+}
@@ -9,6 +9,7 @@ import (
 	"expvar"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
@@ -108,6 +109,9 @@ var (
 	aggregatorOrchestratorMetadata             = expvar.Int{}
 	aggregatorOrchestratorMetadataErrors       = expvar.Int{}
 	aggregatorDogstatsdContexts                = expvar.Int{}
+	aggregatorDogstatsdContextsDropped         = expvar.Int{}
+	aggregatorDogstatsdContextsCollapsed       = expvar.Int{}
+	aggregatorMetricSamplesFiltered            = expvar.Int{}
 
 	tlmFlush = telemetry.NewCounter("aggregator", "flush",
 		[]string{"data_type", "state"}, "Number of metrics/service checks/events flushed")
@@ -155,6 +159,9 @@ func init() {
 	aggregatorExpvars.Set("OrchestratorMetadata", &aggregatorOrchestratorMetadata)
 	aggregatorExpvars.Set("OrchestratorMetadataErrors", &aggregatorOrchestratorMetadataErrors)
 	aggregatorExpvars.Set("DogstatsdContexts", &aggregatorDogstatsdContexts)
+	aggregatorExpvars.Set("DogstatsdContextsDropped", &aggregatorDogstatsdContextsDropped)
+	aggregatorExpvars.Set("DogstatsdContextsCollapsed", &aggregatorDogstatsdContextsCollapsed)
+	aggregatorExpvars.Set("MetricSamplesFiltered", &aggregatorMetricSamplesFiltered)
 }
 
 // InitAggregator returns the Singleton instance
@@ -225,6 +232,16 @@ type BufferedAggregator struct {
 
 	tlmContainerTagsEnabled bool                                              // Whether we should call the tagger to tag agent telemetry metrics
 	agentTags               func(collectors.TagCardinality) ([]string, error) // This function gets the agent tags from the tagger (defined as a struct field to ease testing)
+
+	keepLastFlush bool         // Whether the series and sketches of the last flush are kept for the OpenMetrics endpoint
+	lastFlush     atomic.Value // Holds the *FlushSnapshot of the last flush
+}
+
+// FlushSnapshot holds the series and the sketches flushed by the aggregator.
+type FlushSnapshot struct {
+	Series   metrics.Series
+	Sketches metrics.SketchSeriesList
+	Time     time.Time
 }
 
 // NewBufferedAggregator instantiates a BufferedAggregator
@@ -269,9 +286,14 @@ func NewBufferedAggregator(s serializer.MetricSerializer, hostname string, flush
 		health:                  health.RegisterLiveness("aggregator"),
 		agentName:               agentName,
 		tlmContainerTagsEnabled: config.Datadog.GetBool("basic_telemetry_add_container_tags"),
+		keepLastFlush:           config.Datadog.GetBool("openmetrics_endpoint.enabled"),
 		agentTags:               tagger.AgentTags,
 	}
 
+	if err := ReloadMetricFilter(); err != nil {
+		log.Errorf("Invalid metric filter configuration, the metrics are not filtered: %v", err)
+	}
+
 	return aggregator
 }
 
@@ -361,7 +383,7 @@ func (agg *BufferedAggregator) handleSenderSample(ss senderMetricSample) {
 	if checkSampler, ok := agg.checkSamplers[ss.id]; ok {
 		if ss.commit {
 			checkSampler.commit(timeNowNano())
-		} else {
+		} else if getMetricFilter().filterSample(ss.metricSample) {
 			ss.metricSample.Tags = util.SortUniqInPlace(ss.metricSample.Tags)
 			checkSampler.addSample(ss.metricSample)
 		}
@@ -375,6 +397,9 @@ func (agg *BufferedAggregator) handleSenderBucket(checkBucket senderHistogramBuc
 	defer agg.mu.Unlock()
 
 	if checkSampler, ok := agg.checkSamplers[checkBucket.id]; ok {
+		if !getMetricFilter().filterBucket(checkBucket.bucket) {
+			return
+		}
 		checkBucket.bucket.Tags = util.SortUniqInPlace(checkBucket.bucket.Tags)
 		checkSampler.addBucket(checkBucket.bucket)
 	} else {
@@ -408,9 +433,20 @@ func (agg *BufferedAggregator) addEvent(e metrics.Event) {
 
 // addSample adds the metric sample
 func (agg *BufferedAggregator) addSample(metricSample *metrics.MetricSample, timestamp float64) {
+	if !getMetricFilter().filterSample(metricSample) {
+		return
+	}
 	agg.statsdSampler.addSample(metricSample, timestamp)
 }
 
+// addSampleWithTs adds the metric sample stamped by the client, it isn't aggregated
+func (agg *BufferedAggregator) addSampleWithTs(metricSample *metrics.MetricSample, timestamp float64) {
+	if !getMetricFilter().filterSample(metricSample) {
+		return
+	}
+	agg.statsdSampler.addSampleWithTs(metricSample, timestamp)
+}
+
 // GetSeriesAndSketches grabs all the series & sketches from the queue and clears the queue
 // The parameter `before` is used as an end interval while retrieving series and sketches
 // from the time sampler. Metrics and sketches before this timestamp should be returned.
@@ -541,11 +577,21 @@ func (agg *BufferedAggregator) sendSketches(start time.Time, sketches metrics.Sk
 
 func (agg *BufferedAggregator) flushSeriesAndSketches(start time.Time, waitForSerializer bool) {
 	series, sketches := agg.GetSeriesAndSketches(start)
+	if agg.keepLastFlush {
+		agg.lastFlush.Store(&FlushSnapshot{Series: series, Sketches: sketches, Time: start})
+	}
 
 	agg.sendSketches(start, sketches, waitForSerializer)
 	agg.sendSeries(start, series, waitForSerializer)
 }
 
+// GetLastFlush returns the series and the sketches of the last flush, nil if they are not kept
+// or if the aggregator did not flush yet. They must not be modified.
+func (agg *BufferedAggregator) GetLastFlush() *FlushSnapshot {
+	snapshot, _ := agg.lastFlush.Load().(*FlushSnapshot)
+	return snapshot
+}
+
 // GetServiceChecks grabs all the service checks from the queue and clears the queue
 func (agg *BufferedAggregator) GetServiceChecks() metrics.ServiceChecks {
 	agg.mu.Lock()
@@ -719,7 +765,7 @@ func (agg *BufferedAggregator) run() {
 			aggregatorDogstatsdMetricSample.Add(int64(len(ms)))
 			tlmProcessed.Add(float64(len(ms)), "dogstatsd_metrics")
 			for i := 0; i < len(ms); i++ {
-				agg.addSample(&ms[i], ms[i].Timestamp/float64(time.Second))
+				agg.addSampleWithTs(&ms[i], timeNowNano())
 			}
 			agg.MetricSamplePool.PutBatch(ms)
 		case ms := <-agg.bufferedMetricIn:
@@ -16,6 +16,7 @@ import (
 
 	// 3p
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
@@ -267,6 +268,37 @@ func TestRecurentSeries(t *testing.T) {
 
 }
 
+func TestGetLastFlush(t *testing.T) {
+	resetAggregator()
+	s := &serializer.MockSerializer{}
+	s.On("SendServiceChecks", mock.Anything).Return(nil)
+	s.On("SendSeries", mock.Anything).Return(nil)
+	agg := NewBufferedAggregator(s, "hostname", DefaultFlushInterval)
+
+	// the flushes are not kept by default
+	agg.Flush(time.Now(), true)
+	assert.Nil(t, agg.GetLastFlush())
+
+	agg.keepLastFlush = true
+	start := time.Now()
+	agg.addSample(&metrics.MetricSample{
+		Name:       "my.gauge",
+		Value:      21,
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{"foo:bar"},
+		SampleRate: 1,
+	}, float64(start.Add(-time.Minute).Unix()))
+	agg.Flush(start, true)
+
+	snapshot := agg.GetLastFlush()
+	require.NotNil(t, snapshot)
+	assert.Equal(t, start, snapshot.Time)
+	require.Len(t, snapshot.Series, 1)
+	assert.Equal(t, "my.gauge", snapshot.Series[0].Name)
+	assert.Equal(t, []string{"foo:bar"}, snapshot.Series[0].Tags)
+	assert.Empty(t, snapshot.Sketches)
+}
+
 func TestTags(t *testing.T) {
 	tests := []struct {
 		name                    string
@@ -320,3 +352,61 @@ func TestTags(t *testing.T) {
 		})
 	}
 }
+
+func TestTimestampedSamplesBypassBuckets(t *testing.T) {
+	resetAggregator()
+	agg := NewBufferedAggregator(nil, "hostname", DefaultFlushInterval)
+
+	live := &metrics.MetricSample{
+		Name:       "my.counter",
+		Value:      1,
+		Mtype:      metrics.CounterType,
+		Tags:       []string{"foo:bar"},
+		SampleRate: 1,
+	}
+	agg.addSample(live, 1004)
+	series, _ := agg.GetSeriesAndSketches(time.Unix(1010, 0))
+	require.Len(t, series, 1)
+
+	// a point stamped by the client for the bucket flushed above
+	agg.addSampleWithTs(&metrics.MetricSample{
+		Name:       "my.counter",
+		Value:      5,
+		Mtype:      metrics.CounterType,
+		Tags:       []string{"foo:bar"},
+		SampleRate: 0.5,
+		Timestamp:  float64(1002 * time.Second),
+	}, 1012)
+	agg.addSample(live, 1013)
+	series, _ = agg.GetSeriesAndSketches(time.Unix(1020, 0))
+
+	expectedSeries := metrics.Series{
+		&metrics.Serie{
+			Name:       "my.counter",
+			Points:     []metrics.Point{{Ts: 1010, Value: .1}},
+			Tags:       []string{"foo:bar"},
+			MType:      metrics.APIRateType,
+			ContextKey: generateContextKey(live),
+			Interval:   10,
+		},
+		// the stamped point is sent as a count with its own timestamp
+		&metrics.Serie{
+			Name:       "my.counter",
+			Points:     []metrics.Point{{Ts: 1002, Value: 10}},
+			Tags:       []string{"foo:bar"},
+			MType:      metrics.APICountType,
+			ContextKey: generateContextKey(live),
+			Interval:   10,
+		},
+	}
+	assert.Equal(t, expectedSeries, series)
+
+	// the live counter keeps its last sampled timestamp
+	assert.Equal(t, 1013.0, agg.statsdSampler.counterLastSampledByContext[generateContextKey(live)])
+	assert.Empty(t, agg.statsdSampler.metricsByTimestamp)
+
+	// the next flush only sends the zero value of the live counter for the current bucket
+	series, _ = agg.GetSeriesAndSketches(time.Unix(1030, 0))
+	require.Len(t, series, 1)
+	assert.Equal(t, []metrics.Point{{Ts: 1020, Value: 0}}, series[0].Points)
+}
@@ -44,7 +44,8 @@ func newCheckSampler() *CheckSampler {
 }
 
 func (cs *CheckSampler) addSample(metricSample *metrics.MetricSample) {
-	contextKey := cs.contextResolver.trackContext(metricSample, metricSample.Timestamp)
+	// the contexts of the checks are not limited
+	contextKey, _ := cs.contextResolver.trackContext(metricSample, metricSample.Timestamp)
 
 	if err := cs.metrics.AddSample(contextKey, metricSample, metricSample.Timestamp, 1); err != nil {
 		log.Debug("Ignoring sample '%s' on host '%s' and tags '%s': %s", metricSample.Name, metricSample.Host, metricSample.Tags, err)
@@ -84,7 +85,7 @@ func (cs *CheckSampler) addBucket(bucket *metrics.HistogramBucket) {
 		return
 	}
 
-	contextKey := cs.contextResolver.trackContext(bucket, bucket.Timestamp)
+	contextKey, _ := cs.contextResolver.trackContext(bucket, bucket.Timestamp)
 
 	// if the bucket is monotonic and we have already seen the bucket we only send the delta
 	if bucket.Monotonic {
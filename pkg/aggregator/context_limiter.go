@@ -0,0 +1,305 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// overflowTagValue replaces the values of a tag key in excess when they are collapsed.
+	overflowTagValue = "overflow"
+	// overflowTag replaces the tags of the contexts in excess of a metric when they are collapsed.
+	overflowTag = "overflow:true"
+
+	contextLimitActionCollapse = "collapse"
+	contextLimitActionDrop     = "drop"
+
+	// topContextsCount is the number of metrics and tag keys reported by the top contexts.
+	topContextsCount = 10
+)
+
+var (
+	dogstatsdTopContextsMutex sync.RWMutex
+	dogstatsdTopContexts      TopContexts
+)
+
+// contextLimits are the limits enforced on the contexts of each metric name.
+type contextLimits struct {
+	// maxContextsPerMetric is the maximum number of contexts of a metric, 0 means no limit
+	maxContextsPerMetric int
+	// maxTagValuesPerKey is the maximum number of values of a tag key of a metric, 0 means no limit
+	maxTagValuesPerKey int
+	// collapse is true when the samples in excess are aggregated in overflow contexts,
+	// they are dropped otherwise
+	collapse bool
+}
+
+// enabled returns true when a limit is enforced on the contexts.
+func (l contextLimits) enabled() bool {
+	return l.maxContextsPerMetric > 0 || l.maxTagValuesPerKey > 0
+}
+
+// getDogstatsdContextLimits returns the limits of the DogStatsD contexts set in the configuration.
+func getDogstatsdContextLimits() contextLimits {
+	limits := contextLimits{
+		maxContextsPerMetric: config.Datadog.GetInt("dogstatsd_max_contexts_per_metric"),
+		maxTagValuesPerKey:   config.Datadog.GetInt("dogstatsd_max_values_per_tag_key"),
+		collapse:             true,
+	}
+	switch action := config.Datadog.GetString("dogstatsd_context_limit_action"); action {
+	case contextLimitActionCollapse:
+	case contextLimitActionDrop:
+		limits.collapse = false
+	default:
+		log.Warnf("Invalid dogstatsd_context_limit_action %q, the contexts in excess are collapsed", action)
+	}
+	return limits
+}
+
+// metricCardinality tracks the contexts of a metric name.
+type metricCardinality struct {
+	contexts int
+	// tagValues counts the contexts having each value of each tag key
+	tagValues map[string]map[string]int
+}
+
+// MetricContexts is the number of contexts of a metric.
+type MetricContexts struct {
+	Name     string `json:"name"`
+	Contexts int    `json:"contexts"`
+}
+
+// TagKeyCardinality is the number of values of a tag key of a metric.
+type TagKeyCardinality struct {
+	Name   string `json:"name"`
+	TagKey string `json:"tag_key"`
+	Values int    `json:"values"`
+}
+
+// TopContexts lists the DogStatsD metrics with the most contexts, and the tag keys with the most values.
+type TopContexts struct {
+	Metrics []MetricContexts    `json:"metrics"`
+	TagKeys []TagKeyCardinality `json:"tag_keys"`
+}
+
+// GetDogstatsdTopContexts returns the top contexts of DogStatsD as of the last flush.
+func GetDogstatsdTopContexts() TopContexts {
+	dogstatsdTopContextsMutex.RLock()
+	defer dogstatsdTopContextsMutex.RUnlock()
+	return dogstatsdTopContexts
+}
+
+func setDogstatsdTopContexts(topContexts TopContexts) {
+	dogstatsdTopContextsMutex.Lock()
+	defer dogstatsdTopContextsMutex.Unlock()
+	dogstatsdTopContexts = topContexts
+}
+
+// FormatTopContexts returns a printable version of the top contexts.
+func FormatTopContexts(topContexts TopContexts) string {
+	var b strings.Builder
+	b.WriteString("Metrics with the most contexts:\n")
+	fmt.Fprintf(&b, "%-60s | %-10s\n", "Metric Name", "Contexts")
+	for _, metric := range topContexts.Metrics {
+		fmt.Fprintf(&b, "%-60s | %-10d\n", metric.Name, metric.Contexts)
+	}
+	b.WriteString("\nTag keys with the most values:\n")
+	fmt.Fprintf(&b, "%-60s | %-30s | %-10s\n", "Metric Name", "Tag Key", "Values")
+	for _, tagKey := range topContexts.TagKeys {
+		fmt.Fprintf(&b, "%-60s | %-30s | %-10d\n", tagKey.Name, tagKey.TagKey, tagKey.Values)
+	}
+	return b.String()
+}
+
+// splitTag returns the key and the value of a tag, the value is empty when the tag has no value.
+func splitTag(tag string) (string, string) {
+	if sep := strings.IndexByte(tag, ':'); sep != -1 {
+		return tag[:sep], tag[sep+1:]
+	}
+	return tag, ""
+}
+
+// limitContext enforces the limits on a sample creating a new context of a metric. The tags of
+// the sample held in tagsBuffer are collapsed when the metric reaches a limit, it returns the key
+// of the context the sample is aggregated in, or false when the sample must be dropped.
+func (cr *ContextResolver) limitContext(metricSampleContext metrics.MetricSampleContext, contextKey ckey.ContextKey) (ckey.ContextKey, bool) {
+	cardinality, found := cr.cardinality[metricSampleContext.GetName()]
+	if !found {
+		return contextKey, true
+	}
+
+	if cr.limits.maxTagValuesPerKey > 0 {
+		tags := cr.tagsBuffer.Get()
+		collapsed := false
+		for i, tag := range tags {
+			key, value := splitTag(tag)
+			values := cardinality.tagValues[key]
+			if values[value] > 0 || len(values) < cr.limits.maxTagValuesPerKey {
+				continue
+			}
+			if !cr.limits.collapse {
+				aggregatorDogstatsdContextsDropped.Add(1)
+				return contextKey, false
+			}
+			tags[i] = key + ":" + overflowTagValue
+			collapsed = true
+		}
+		if collapsed {
+			aggregatorDogstatsdContextsCollapsed.Add(1)
+			cr.tagsBuffer.SortUniq()
+			contextKey = cr.generateContextKey(metricSampleContext, cr.tagsBuffer)
+			if _, ok := cr.contextsByKey[contextKey]; ok {
+				return contextKey, true
+			}
+		}
+	}
+
+	if cr.limits.maxContextsPerMetric > 0 && cardinality.contexts >= cr.limits.maxContextsPerMetric {
+		if !cr.limits.collapse {
+			aggregatorDogstatsdContextsDropped.Add(1)
+			return contextKey, false
+		}
+		aggregatorDogstatsdContextsCollapsed.Add(1)
+		cr.tagsBuffer.Reset()
+		cr.tagsBuffer.Append(overflowTag)
+		contextKey = cr.generateContextKey(metricSampleContext, cr.tagsBuffer)
+	}
+	return contextKey, true
+}
+
+// addCardinality tracks a new context.
+func (cr *ContextResolver) addCardinality(context *Context) {
+	cardinality, found := cr.cardinality[context.Name]
+	if !found {
+		cardinality = &metricCardinality{tagValues: make(map[string]map[string]int)}
+		cr.cardinality[context.Name] = cardinality
+	}
+	cardinality.contexts++
+	for _, tag := range context.Tags {
+		key, value := splitTag(tag)
+		values, found := cardinality.tagValues[key]
+		if !found {
+			values = make(map[string]int)
+			cardinality.tagValues[key] = values
+		}
+		values[value]++
+	}
+}
+
+// removeCardinality stops tracking an expired context.
+func (cr *ContextResolver) removeCardinality(context *Context) {
+	cardinality, found := cr.cardinality[context.Name]
+	if !found {
+		return
+	}
+	cardinality.contexts--
+	if cardinality.contexts <= 0 {
+		delete(cr.cardinality, context.Name)
+		return
+	}
+	for _, tag := range context.Tags {
+		key, value := splitTag(tag)
+		values := cardinality.tagValues[key]
+		values[value]--
+		if values[value] <= 0 {
+			delete(values, value)
+		}
+		if len(values) == 0 {
+			delete(cardinality.tagValues, key)
+		}
+	}
+}
+
+// topContexts returns the n metrics with the most contexts and the n tag keys with the most values.
+func (cr *ContextResolver) topContexts(n int) TopContexts {
+	var topContexts TopContexts
+	if n <= 0 {
+		return topContexts
+	}
+
+	// keep the n best entries in heaps whose root is the worst one
+	metricsHeap := &metricContextsHeap{}
+	tagKeysHeap := &tagKeyCardinalityHeap{}
+	for name, cardinality := range cr.cardinality {
+		heap.Push(metricsHeap, MetricContexts{Name: name, Contexts: cardinality.contexts})
+		if metricsHeap.Len() > n {
+			heap.Pop(metricsHeap)
+		}
+		for key, values := range cardinality.tagValues {
+			heap.Push(tagKeysHeap, TagKeyCardinality{Name: name, TagKey: key, Values: len(values)})
+			if tagKeysHeap.Len() > n {
+				heap.Pop(tagKeysHeap)
+			}
+		}
+	}
+
+	topContexts.Metrics = []MetricContexts(*metricsHeap)
+	sort.Slice(topContexts.Metrics, func(i, j int) bool {
+		return metricContextsRanksBefore(topContexts.Metrics[i], topContexts.Metrics[j])
+	})
+	topContexts.TagKeys = []TagKeyCardinality(*tagKeysHeap)
+	sort.Slice(topContexts.TagKeys, func(i, j int) bool {
+		return tagKeyCardinalityRanksBefore(topContexts.TagKeys[i], topContexts.TagKeys[j])
+	})
+	return topContexts
+}
+
+// metricContextsRanksBefore returns true if a has more contexts than b, the names break the ties.
+func metricContextsRanksBefore(a, b MetricContexts) bool {
+	if a.Contexts == b.Contexts {
+		return a.Name < b.Name
+	}
+	return a.Contexts > b.Contexts
+}
+
+// tagKeyCardinalityRanksBefore returns true if a has more values than b, the names break the ties.
+func tagKeyCardinalityRanksBefore(a, b TagKeyCardinality) bool {
+	if a.Values == b.Values {
+		if a.Name == b.Name {
+			return a.TagKey < b.TagKey
+		}
+		return a.Name < b.Name
+	}
+	return a.Values > b.Values
+}
+
+// metricContextsHeap is a heap of MetricContexts whose root ranks last.
+type metricContextsHeap []MetricContexts
+
+func (h metricContextsHeap) Len() int            { return len(h) }
+func (h metricContextsHeap) Less(i, j int) bool  { return metricContextsRanksBefore(h[j], h[i]) }
+func (h metricContextsHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *metricContextsHeap) Push(x interface{}) { *h = append(*h, x.(MetricContexts)) }
+func (h *metricContextsHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// tagKeyCardinalityHeap is a heap of TagKeyCardinality whose root ranks last.
+type tagKeyCardinalityHeap []TagKeyCardinality
+
+func (h tagKeyCardinalityHeap) Len() int            { return len(h) }
+func (h tagKeyCardinalityHeap) Less(i, j int) bool  { return tagKeyCardinalityRanksBefore(h[j], h[i]) }
+func (h tagKeyCardinalityHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *tagKeyCardinalityHeap) Push(x interface{}) { *h = append(*h, x.(TagKeyCardinality)) }
+func (h *tagKeyCardinalityHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
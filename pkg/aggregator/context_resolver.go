@@ -28,6 +28,10 @@ type ContextResolver struct {
 	// buffer slice allocated once per ContextResolver to combine and sort
 	// tags, origin detection tags and k8s tags.
 	tagsBuffer *util.TagsBuilder
+	// cardinality tracks the contexts of each metric name, it is nil when
+	// no limit is enforced on the contexts and their statistics are disabled
+	cardinality map[string]*metricCardinality
+	limits      contextLimits
 }
 
 // generateContextKey generates the contextKey associated with the context of the metricSample
@@ -44,25 +48,66 @@ func newContextResolver() *ContextResolver {
 	}
 }
 
-// trackContext returns the contextKey associated with the context of the metricSample and tracks that context
-func (cr *ContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp float64) ckey.ContextKey {
+// newContextResolverWithLimits returns a context resolver enforcing the given limits
+// on the contexts of each metric name, their cardinality is tracked when a limit is set.
+func newContextResolverWithLimits(limits contextLimits) *ContextResolver {
+	cr := newContextResolver()
+	cr.limits = limits
+	if limits.enabled() {
+		cr.cardinality = make(map[string]*metricCardinality)
+	}
+	return cr
+}
+
+// setCardinalityTracking enables or disables the tracking of the cardinality of the metrics,
+// it is always enabled when a limit is enforced on the contexts.
+func (cr *ContextResolver) setCardinalityTracking(enabled bool) {
+	if cr.limits.enabled() || enabled == (cr.cardinality != nil) {
+		return
+	}
+	if !enabled {
+		cr.cardinality = nil
+		return
+	}
+	cr.cardinality = make(map[string]*metricCardinality)
+	for _, context := range cr.contextsByKey {
+		cr.addCardinality(context)
+	}
+}
+
+// trackContext returns the contextKey associated with the context of the metricSample and tracks that context.
+// It returns false when the sample exceeds the limits of its metric and must be dropped.
+func (cr *ContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp float64) (ckey.ContextKey, bool) {
+	defer cr.tagsBuffer.Reset()
 	metricSampleContext.GetTags(cr.tagsBuffer)
+	getMetricFilter().strip(cr.tagsBuffer)
 	contextKey := cr.generateContextKey(metricSampleContext, cr.tagsBuffer)
 
 	if _, ok := cr.contextsByKey[contextKey]; !ok {
-		// making a copy of tags for the context since tagsBuffer
-		// will be reused later. This allow us to allocate one slice
-		// per context instead of one per sample.
-		cr.contextsByKey[contextKey] = &Context{
-			Name: metricSampleContext.GetName(),
-			Tags: cr.tagsBuffer.Copy(),
-			Host: metricSampleContext.GetHost(),
+		if cr.cardinality != nil {
+			var accepted bool
+			if contextKey, accepted = cr.limitContext(metricSampleContext, contextKey); !accepted {
+				return contextKey, false
+			}
+		}
+		if _, ok := cr.contextsByKey[contextKey]; !ok {
+			// making a copy of tags for the context since tagsBuffer
+			// will be reused later. This allow us to allocate one slice
+			// per context instead of one per sample.
+			context := &Context{
+				Name: metricSampleContext.GetName(),
+				Tags: cr.tagsBuffer.Copy(),
+				Host: metricSampleContext.GetHost(),
+			}
+			cr.contextsByKey[contextKey] = context
+			if cr.cardinality != nil {
+				cr.addCardinality(context)
+			}
 		}
 	}
 	cr.lastSeenByKey[contextKey] = currentTimestamp
 
-	cr.tagsBuffer.Reset()
-	return contextKey
+	return contextKey, true
 }
 
 func (cr *ContextResolver) get(key ckey.ContextKey) (*Context, bool) {
@@ -99,6 +144,9 @@ func (cr *ContextResolver) expireContexts(expireTimestamp float64) []ckey.Contex
 
 	// Delete expired context keys
 	for _, expiredContextKey := range expiredContextKeys {
+		if context, found := cr.contextsByKey[expiredContextKey]; found && cr.cardinality != nil {
+			cr.removeCardinality(context)
+		}
 		delete(cr.contextsByKey, expiredContextKey)
 		delete(cr.lastSeenByKey, expiredContextKey)
 	}
@@ -72,9 +72,9 @@ func TestTrackContext(t *testing.T) {
 	contextResolver := newContextResolver()
 
 	// Track the 2 contexts
-	contextKey1 := contextResolver.trackContext(&mSample1, 1)
-	contextKey2 := contextResolver.trackContext(&mSample2, 1)
-	contextKey3 := contextResolver.trackContext(&mSample3, 1)
+	contextKey1, _ := contextResolver.trackContext(&mSample1, 1)
+	contextKey2, _ := contextResolver.trackContext(&mSample2, 1)
+	contextKey3, _ := contextResolver.trackContext(&mSample3, 1)
 
 	// When we look up the 2 keys, they return the correct contexts
 	context1 := contextResolver.contextsByKey[contextKey1]
@@ -112,8 +112,8 @@ func TestExpireContexts(t *testing.T) {
 	contextResolver := newContextResolver()
 
 	// Track the 2 contexts
-	contextKey1 := contextResolver.trackContext(&mSample1, 4)
-	contextKey2 := contextResolver.trackContext(&mSample2, 6)
+	contextKey1, _ := contextResolver.trackContext(&mSample1, 4)
+	contextKey2, _ := contextResolver.trackContext(&mSample2, 6)
 
 	// With an expireTimestap of 3, both contexts are still valid
 	assert.Len(t, contextResolver.expireContexts(3), 0)
@@ -134,3 +134,122 @@ func TestExpireContexts(t *testing.T) {
 	_, ok = contextResolver.contextsByKey[contextKey2]
 	assert.True(t, ok)
 }
+
+func TestTrackContextMaxContextsPerMetric(t *testing.T) {
+	for _, collapse := range []bool{true, false} {
+		contextResolver := newContextResolverWithLimits(contextLimits{maxContextsPerMetric: 2, collapse: collapse})
+
+		for i, host := range []string{"host1", "host2", "host3", "host4"} {
+			_, accepted := contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"host:" + host}}, 1)
+			assert.Equal(t, collapse || i < 2, accepted)
+		}
+		_, accepted := contextResolver.trackContext(&metrics.MetricSample{Name: "my.other.metric", Tags: []string{"foo"}}, 1)
+		assert.True(t, accepted)
+
+		// the tracked contexts are still accepted
+		_, accepted = contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"host:host1"}}, 1)
+		assert.True(t, accepted)
+
+		if collapse {
+			assert.Equal(t, 4, contextResolver.length())
+			assert.Equal(t, 3, contextResolver.cardinality["my.metric.name"].contexts)
+			contextKey, _ := contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"host:host5"}}, 1)
+			context, _ := contextResolver.get(contextKey)
+			assert.Equal(t, []string{overflowTag}, context.Tags)
+		} else {
+			assert.Equal(t, 3, contextResolver.length())
+			assert.Equal(t, 2, contextResolver.cardinality["my.metric.name"].contexts)
+		}
+	}
+}
+
+func TestTrackContextMaxTagValuesPerKey(t *testing.T) {
+	contextResolver := newContextResolverWithLimits(contextLimits{maxTagValuesPerKey: 2, collapse: true})
+
+	for _, user := range []string{"a", "b", "c", "d"} {
+		_, accepted := contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"env:prod", "user:" + user}}, 1)
+		assert.True(t, accepted)
+	}
+	contextKey, _ := contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"env:prod", "user:e"}}, 1)
+	context, _ := contextResolver.get(contextKey)
+	assert.Equal(t, []string{"env:prod", "user:overflow"}, context.Tags)
+
+	// the users in excess are aggregated in a single context
+	assert.Equal(t, 3, contextResolver.length())
+	assert.Len(t, contextResolver.cardinality["my.metric.name"].tagValues["user"], 3)
+
+	contextResolver = newContextResolverWithLimits(contextLimits{maxTagValuesPerKey: 2})
+	for i, user := range []string{"a", "b", "c"} {
+		_, accepted := contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"user:" + user}}, 1)
+		assert.Equal(t, i < 2, accepted)
+	}
+	assert.Equal(t, 2, contextResolver.length())
+}
+
+func TestExpireContextsCardinality(t *testing.T) {
+	contextResolver := newContextResolverWithLimits(contextLimits{maxContextsPerMetric: 1})
+
+	_, accepted := contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"foo:a"}}, 4)
+	assert.True(t, accepted)
+	_, accepted = contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"foo:b"}}, 6)
+	assert.False(t, accepted)
+
+	// the expired context makes room for a new one
+	contextResolver.expireContexts(5)
+	assert.Empty(t, contextResolver.cardinality)
+	_, accepted = contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"foo:b"}}, 6)
+	assert.True(t, accepted)
+	assert.Equal(t, map[string]int{"b": 1}, contextResolver.cardinality["my.metric.name"].tagValues["foo"])
+}
+
+func TestCardinalityTracking(t *testing.T) {
+	contextResolver := newContextResolverWithLimits(contextLimits{})
+	contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"env:prod"}}, 1)
+
+	// the cardinality is not tracked by default when no limit is set
+	assert.Nil(t, contextResolver.cardinality)
+
+	// it is rebuilt from the tracked contexts once enabled
+	contextResolver.setCardinalityTracking(true)
+	assert.Equal(t, 1, contextResolver.cardinality["my.metric.name"].contexts)
+	contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"env:dev"}}, 1)
+	assert.Equal(t, 2, contextResolver.cardinality["my.metric.name"].contexts)
+	contextResolver.setCardinalityTracking(false)
+	assert.Nil(t, contextResolver.cardinality)
+
+	// it can not be disabled when a limit is set
+	contextResolver = newContextResolverWithLimits(contextLimits{maxContextsPerMetric: 10})
+	contextResolver.setCardinalityTracking(false)
+	assert.NotNil(t, contextResolver.cardinality)
+}
+
+func TestTopContexts(t *testing.T) {
+	contextResolver := newContextResolverWithLimits(contextLimits{})
+	contextResolver.setCardinalityTracking(true)
+
+	for _, user := range []string{"a", "b", "c"} {
+		contextResolver.trackContext(&metrics.MetricSample{Name: "my.metric.name", Tags: []string{"env:prod", "user:" + user}}, 1)
+	}
+	contextResolver.trackContext(&metrics.MetricSample{Name: "my.other.metric", Tags: []string{"env:prod"}}, 1)
+	contextResolver.trackContext(&metrics.MetricSample{Name: "my.other.metric", Tags: []string{"env:dev"}}, 1)
+
+	assert.Equal(t, TopContexts{
+		Metrics: []MetricContexts{
+			{Name: "my.metric.name", Contexts: 3},
+		},
+		TagKeys: []TagKeyCardinality{
+			{Name: "my.metric.name", TagKey: "user", Values: 3},
+		},
+	}, contextResolver.topContexts(1))
+
+	topContexts := contextResolver.topContexts(10)
+	assert.Equal(t, []MetricContexts{
+		{Name: "my.metric.name", Contexts: 3},
+		{Name: "my.other.metric", Contexts: 2},
+	}, topContexts.Metrics)
+	assert.Equal(t, []TagKeyCardinality{
+		{Name: "my.metric.name", TagKey: "user", Values: 3},
+		{Name: "my.other.metric", TagKey: "env", Values: 2},
+		{Name: "my.metric.name", TagKey: "env", Values: 1},
+	}, topContexts.TagKeys)
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+const (
+	metricFilterMatchTypeWildcard = "wildcard"
+	metricFilterMatchTypeRegex    = "regex"
+)
+
+// currentMetricFilter holds the *metricFilter applied to the samples, it is
+// replaced when the filter is reloaded at runtime.
+var currentMetricFilter atomic.Value
+
+// metricFilter allows or blocks metrics by name and removes tag keys from
+// the contexts of the samples before they are aggregated.
+type metricFilter struct {
+	// when not empty, only the metrics matching one of the allowlist patterns are kept
+	allowlist []*regexp.Regexp
+	// the metrics matching one of the blocklist patterns are dropped
+	blocklist []*regexp.Regexp
+	stripTags map[string]struct{}
+}
+
+// newMetricFilter returns a new metric filter, the patterns are either wildcards where `*`
+// matches any sequence of characters, or regular expressions matching the whole metric name.
+func newMetricFilter(allowlist, blocklist []string, matchType string, stripTags []string) (*metricFilter, error) {
+	var err error
+	f := &metricFilter{}
+	if f.allowlist, err = compileMetricFilterPatterns(allowlist, matchType); err != nil {
+		return nil, fmt.Errorf("invalid allowlist: %v", err)
+	}
+	if f.blocklist, err = compileMetricFilterPatterns(blocklist, matchType); err != nil {
+		return nil, fmt.Errorf("invalid blocklist: %v", err)
+	}
+	if len(stripTags) > 0 {
+		f.stripTags = make(map[string]struct{}, len(stripTags))
+		for _, key := range stripTags {
+			f.stripTags[key] = struct{}{}
+		}
+	}
+	return f, nil
+}
+
+func compileMetricFilterPatterns(patterns []string, matchType string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		var expr string
+		switch matchType {
+		case metricFilterMatchTypeWildcard, "":
+			expr = "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		case metricFilterMatchTypeRegex:
+			expr = "^(?:" + pattern + ")$"
+		default:
+			return nil, fmt.Errorf("invalid match type `%s`, must be `wildcard` or `regex`", matchType)
+		}
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compile `%s`: %v", pattern, err)
+		}
+		regexes = append(regexes, regex)
+	}
+	return regexes, nil
+}
+
+// ReloadMetricFilter builds the metric filter from the configuration and applies it to the
+// samples received from now on. The current filter is kept if the configuration is invalid.
+func ReloadMetricFilter() error {
+	f, err := newMetricFilter(
+		config.Datadog.GetStringSlice("metric_filter_allowlist"),
+		config.Datadog.GetStringSlice("metric_filter_blocklist"),
+		config.Datadog.GetString("metric_filter_match_type"),
+		config.Datadog.GetStringSlice("metric_filter_strip_tags"),
+	)
+	if err != nil {
+		return err
+	}
+	currentMetricFilter.Store(f)
+	return nil
+}
+
+// getMetricFilter returns the current metric filter, nil if none was loaded.
+func getMetricFilter() *metricFilter {
+	f, _ := currentMetricFilter.Load().(*metricFilter)
+	return f
+}
+
+// allowed returns true if the metric must be kept.
+func (f *metricFilter) allowed(name string) bool {
+	if len(f.allowlist) > 0 && !matchAny(f.allowlist, name) {
+		return false
+	}
+	return !matchAny(f.blocklist, name)
+}
+
+func matchAny(regexes []*regexp.Regexp, name string) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// strip removes the stripped tag keys from the tags of a context, including the tags
+// added by the origin detection, it does nothing when the filter is nil.
+func (f *metricFilter) strip(tb *util.TagsBuilder) {
+	if f == nil || len(f.stripTags) == 0 {
+		return
+	}
+	tags := tb.Get()
+	kept := tags[:0]
+	for _, tag := range tags {
+		key, _ := splitTag(tag)
+		if _, found := f.stripTags[key]; !found {
+			kept = append(kept, tag)
+		}
+	}
+	if len(kept) == len(tags) {
+		return
+	}
+	// the kept tags are a prefix of the internal buffer of the builder
+	tb.Reset()
+	tb.Append(kept...)
+}
+
+// filterSample returns false if the sample must be dropped.
+func (f *metricFilter) filterSample(sample *metrics.MetricSample) bool {
+	if f == nil {
+		return true
+	}
+	if !f.allowed(sample.Name) {
+		aggregatorMetricSamplesFiltered.Add(1)
+		return false
+	}
+	return true
+}
+
+// filterBucket returns false if the bucket must be dropped.
+func (f *metricFilter) filterBucket(bucket *metrics.HistogramBucket) bool {
+	if f == nil {
+		return true
+	}
+	if !f.allowed(bucket.Name) {
+		aggregatorMetricSamplesFiltered.Add(1)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build test
+
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+func TestMetricFilterAllowed(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		allowlist []string
+		blocklist []string
+		matchType string
+		allowed   []string
+		blocked   []string
+	}{
+		{
+			name:    "no filter",
+			allowed: []string{"custom.metric", "system.cpu.user"},
+		},
+		{
+			name:      "wildcard allowlist",
+			allowlist: []string{"custom.*", "system.cpu.user"},
+			matchType: metricFilterMatchTypeWildcard,
+			allowed:   []string{"custom.metric", "custom.a.b", "system.cpu.user"},
+			blocked:   []string{"system.cpu.system", "customer.metric", "my.custom.metric"},
+		},
+		{
+			name:      "wildcard blocklist",
+			blocklist: []string{"*.debug.*"},
+			allowed:   []string{"custom.metric", "debug.metric"},
+			blocked:   []string{"custom.debug.metric"},
+		},
+		{
+			name:      "blocklist takes precedence",
+			allowlist: []string{"custom.*"},
+			blocklist: []string{"custom.internal.*"},
+			allowed:   []string{"custom.metric"},
+			blocked:   []string{"custom.internal.metric", "other.metric"},
+		},
+		{
+			name:      "regex",
+			allowlist: []string{`custom\.(foo|bar)`},
+			blocklist: []string{`.*\.count`},
+			matchType: metricFilterMatchTypeRegex,
+			allowed:   []string{"custom.foo", "custom.bar"},
+			blocked:   []string{"custom.foo.count", "custom.foobar", "my.custom.foo"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := newMetricFilter(tc.allowlist, tc.blocklist, tc.matchType, nil)
+			require.NoError(t, err)
+			for _, name := range tc.allowed {
+				assert.True(t, f.allowed(name), name)
+			}
+			for _, name := range tc.blocked {
+				assert.False(t, f.allowed(name), name)
+			}
+		})
+	}
+}
+
+func TestMetricFilterInvalid(t *testing.T) {
+	_, err := newMetricFilter([]string{"custom.("}, nil, metricFilterMatchTypeRegex, nil)
+	assert.Error(t, err)
+	_, err = newMetricFilter(nil, []string{"custom.*"}, "glob", nil)
+	assert.Error(t, err)
+}
+
+func TestMetricFilterStripTags(t *testing.T) {
+	f, err := newMetricFilter(nil, nil, "", []string{"request_id", "user"})
+	require.NoError(t, err)
+
+	tb := util.NewTagsBuilderFromSlice([]string{"env:prod", "request_id:1234", "service:web", "user", "user:bob"})
+	f.strip(tb)
+	assert.Equal(t, []string{"env:prod", "service:web"}, tb.Get())
+
+	tb = util.NewTagsBuilderFromSlice([]string{"env:prod"})
+	f.strip(tb)
+	assert.Equal(t, []string{"env:prod"}, tb.Get())
+
+	// a nil filter does nothing
+	f = nil
+	tb = util.NewTagsBuilderFromSlice([]string{"user:bob"})
+	f.strip(tb)
+	assert.Equal(t, []string{"user:bob"}, tb.Get())
+}
+
+func TestMetricFilterSample(t *testing.T) {
+	var f *metricFilter
+	sample := &metrics.MetricSample{Name: "custom.metric", Tags: []string{"user:bob"}}
+	assert.True(t, f.filterSample(sample))
+
+	f, err := newMetricFilter([]string{"custom.*"}, nil, "", []string{"user"})
+	require.NoError(t, err)
+	assert.True(t, f.filterSample(sample))
+	assert.False(t, f.filterSample(&metrics.MetricSample{Name: "other.metric"}))
+
+	bucket := &metrics.HistogramBucket{Name: "custom.histogram", Tags: []string{"user:bob", "env:prod"}}
+	assert.True(t, f.filterBucket(bucket))
+	assert.False(t, f.filterBucket(&metrics.HistogramBucket{Name: "other.histogram"}))
+}
+
+// originTagger returns the tags of the containers for the origin detection.
+type originTagger struct {
+	tagger.Tagger
+	tags map[string][]string
+}
+
+func (o *originTagger) TagBuilder(entity string, cardinality collectors.TagCardinality, tb *util.TagsBuilder) error {
+	tb.Append(o.tags[entity]...)
+	return nil
+}
+
+func TestMetricFilterStripsOriginTags(t *testing.T) {
+	defaultTagger := tagger.GetDefaultTagger()
+	defer tagger.SetDefaultTagger(defaultTagger)
+	tagger.SetDefaultTagger(&originTagger{tags: map[string][]string{
+		"container_id://abc": {"pod_name:web-1", "kube_namespace:default"},
+	}})
+	defer currentMetricFilter.Store((*metricFilter)(nil))
+	f, err := newMetricFilter(nil, nil, "", []string{"pod_name"})
+	require.NoError(t, err)
+	currentMetricFilter.Store(f)
+
+	contextResolver := newContextResolver()
+	contextKey, _ := contextResolver.trackContext(&metrics.MetricSample{
+		Name:     "custom.metric",
+		Tags:     []string{"env:prod"},
+		OriginID: "container_id://abc",
+	}, 1)
+	context, _ := contextResolver.get(contextKey)
+	assert.Equal(t, []string{"env:prod", "kube_namespace:default"}, context.Tags)
+}
+
+func TestReloadMetricFilter(t *testing.T) {
+	defer func() {
+		config.Datadog.Set("metric_filter_blocklist", []string{})
+		config.Datadog.Set("metric_filter_match_type", metricFilterMatchTypeWildcard)
+		ReloadMetricFilter()
+	}()
+
+	config.Datadog.Set("metric_filter_blocklist", []string{"custom.*"})
+	require.NoError(t, ReloadMetricFilter())
+	assert.False(t, getMetricFilter().allowed("custom.metric"))
+
+	// an invalid configuration keeps the current filter
+	config.Datadog.Set("metric_filter_match_type", "glob")
+	assert.Error(t, ReloadMetricFilter())
+	assert.False(t, getMetricFilter().allowed("custom.metric"))
+	assert.True(t, getMetricFilter().allowed("other.metric"))
+}
@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build test
+
+package aggregator
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// TestCheckSampler feeds the histogram buckets submitted through its Sender to a CheckSampler,
+// so that the packages submitting buckets can test how the aggregator handles them.
+type TestCheckSampler struct {
+	Sender  Sender
+	sampler *CheckSampler
+	samples chan senderMetricSample
+	buckets chan senderHistogramBucket
+}
+
+// NewTestCheckSampler returns a TestCheckSampler, its Sender buffers up to bufferSize samples
+// and buckets between two flushes.
+func NewTestCheckSampler(bufferSize int) *TestCheckSampler {
+	samples := make(chan senderMetricSample, bufferSize)
+	buckets := make(chan senderHistogramBucket, bufferSize)
+	return &TestCheckSampler{
+		Sender:  newCheckSender(check.ID("test"), "", samples, make(chan metrics.ServiceCheck, bufferSize), make(chan metrics.Event, bufferSize), buckets, make(chan senderOrchestratorMetadata, bufferSize)),
+		sampler: newCheckSampler(),
+		samples: samples,
+		buckets: buckets,
+	}
+}
+
+// Flush adds the buckets submitted since the previous flush to the CheckSampler, like the aggregator
+// does, and returns the sketches it computed. The other metrics are discarded.
+func (s *TestCheckSampler) Flush() metrics.SketchSeriesList {
+	for {
+		select {
+		case <-s.samples:
+		case b := <-s.buckets:
+			b.bucket.Tags = util.SortUniqInPlace(b.bucket.Tags)
+			s.sampler.addBucket(b.bucket)
+		default:
+			// the buckets are stamped on submission, commit past their timestamp
+			s.sampler.commit(timeNowNano() + 1)
+			_, sketches := s.sampler.flush()
+			return sketches
+		}
+	}
+}
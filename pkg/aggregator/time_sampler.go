@@ -6,6 +6,8 @@
 package aggregator
 
 import (
+	"time"
+
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -28,6 +30,8 @@ type TimeSampler struct {
 	counterLastSampledByContext map[ckey.ContextKey]float64
 	lastCutOffTime              int64
 	sketchMap                   sketchMap
+	// timestampedSeries holds the points stamped by the clients, which are sent as is on the next flush
+	timestampedSeries metrics.Series
 }
 
 // NewTimeSampler returns a newly initialized TimeSampler
@@ -35,9 +39,11 @@ func NewTimeSampler(interval int64) *TimeSampler {
 	if interval == 0 {
 		interval = bucketSize
 	}
+	contextResolver := newContextResolverWithLimits(getDogstatsdContextLimits())
+	contextResolver.setCardinalityTracking(config.Datadog.GetBool("dogstatsd_metrics_stats_enable"))
 	return &TimeSampler{
 		interval:                    interval,
-		contextResolver:             newContextResolver(),
+		contextResolver:             contextResolver,
 		metricsByTimestamp:          map[int64]metrics.ContextMetrics{},
 		counterLastSampledByContext: map[ckey.ContextKey]float64{},
 		sketchMap:                   make(sketchMap),
@@ -55,7 +61,10 @@ func (s *TimeSampler) isBucketStillOpen(bucketStartTimestamp, timestamp int64) b
 // Add the metricSample to the correct bucket
 func (s *TimeSampler) addSample(metricSample *metrics.MetricSample, timestamp float64) {
 	// Keep track of the context
-	contextKey := s.contextResolver.trackContext(metricSample, timestamp)
+	contextKey, accepted := s.contextResolver.trackContext(metricSample, timestamp)
+	if !accepted {
+		return
+	}
 	bucketStart := s.calculateBucketStart(timestamp)
 
 	switch metricSample.Mtype {
@@ -80,6 +89,34 @@ func (s *TimeSampler) addSample(metricSample *metrics.MetricSample, timestamp fl
 	}
 }
 
+// addSampleWithTs adds a sample stamped by the client, which is already aggregated: it bypasses the
+// buckets and is sent with its own timestamp on the next flush. Only gauges and counts can be stamped.
+func (s *TimeSampler) addSampleWithTs(metricSample *metrics.MetricSample, timestamp float64) {
+	contextKey, accepted := s.contextResolver.trackContext(metricSample, timestamp)
+	if !accepted {
+		return
+	}
+	context, _ := s.contextResolver.get(contextKey)
+
+	value := metricSample.Value
+	mType := metrics.APIGaugeType
+	if metricSample.Mtype == metrics.CounterType {
+		if metricSample.SampleRate > 0 {
+			value /= metricSample.SampleRate
+		}
+		mType = metrics.APICountType
+	}
+	s.timestampedSeries = append(s.timestampedSeries, &metrics.Serie{
+		Name:       context.Name,
+		Tags:       context.Tags,
+		Host:       context.Host,
+		Points:     []metrics.Point{{Ts: metricSample.Timestamp / float64(time.Second), Value: value}},
+		MType:      mType,
+		Interval:   s.interval,
+		ContextKey: contextKey,
+	})
+}
+
 func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.SketchPoint) metrics.SketchSeries {
 	ctx, _ := s.contextResolver.get(ck)
 	ss := metrics.SketchSeries{
@@ -179,7 +216,8 @@ func (s *TimeSampler) flush(timestamp float64) (metrics.Series, metrics.SketchSe
 	// Compute a limit timestamp
 	cutoffTime := s.calculateBucketStart(timestamp)
 
-	series := s.flushSeries(cutoffTime)
+	series := append(s.flushSeries(cutoffTime), s.timestampedSeries...)
+	s.timestampedSeries = nil
 	sketches := s.flushSketches(cutoffTime)
 
 	// expiring contexts
@@ -188,6 +226,13 @@ func (s *TimeSampler) flush(timestamp float64) (metrics.Series, metrics.SketchSe
 
 	aggregatorDogstatsdContexts.Set(int64(s.contextResolver.length()))
 	tlmDogstatsdContexts.Set(float64(s.contextResolver.length()))
+	// the top contexts are only computed when the statistics are enabled or a limit is enforced
+	s.contextResolver.setCardinalityTracking(config.Datadog.GetBool("dogstatsd_metrics_stats_enable"))
+	if s.contextResolver.cardinality != nil {
+		setDogstatsdTopContexts(s.contextResolver.topContexts(topContextsCount))
+	} else {
+		setDogstatsdTopContexts(TopContexts{})
+	}
 	return series, sketches
 }
 
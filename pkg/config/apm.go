@@ -29,8 +29,19 @@ func setupAPM(config Config) {
 	config.SetKnown("apm_config.obfuscation.remove_stack_traces")
 	config.SetKnown("apm_config.obfuscation.redis.enabled")
 	config.SetKnown("apm_config.obfuscation.memcached.enabled")
+	config.SetKnown("apm_config.obfuscation.sensitive_data.enabled")
+	config.SetKnown("apm_config.obfuscation.sensitive_data.detectors")
+	config.SetKnown("apm_config.obfuscation.sensitive_data.rules")
+	config.SetKnown("apm_config.obfuscation.sensitive_data.keys")
+	config.SetKnown("apm_config.obfuscation.sensitive_data.resource")
+	config.SetKnown("apm_config.obfuscation.sensitive_data.action")
+	config.SetKnown("apm_config.obfuscation.sensitive_data.hash_key")
 	config.SetKnown("apm_config.filter_tags.require")
 	config.SetKnown("apm_config.filter_tags.reject")
+	config.SetKnown("apm_config.filter_tags_glob.require")
+	config.SetKnown("apm_config.filter_tags_glob.reject")
+	config.SetKnown("apm_config.filter_tags_regex.require")
+	config.SetKnown("apm_config.filter_tags_regex.reject")
 	config.SetKnown("apm_config.extra_sample_rate")
 	config.SetKnown("apm_config.dd_agent_bin")
 	config.SetKnown("apm_config.trace_writer.connection_limit")
@@ -81,6 +92,15 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.sync_flushing", "DD_APM_SYNC_FLUSHING")                                   //nolint:errcheck
 	config.BindEnv("apm_config.filter_tags.require", "DD_APM_FILTER_TAGS_REQUIRE")                       //nolint:errcheck
 	config.BindEnv("apm_config.filter_tags.reject", "DD_APM_FILTER_TAGS_REJECT")                         //nolint:errcheck
+	config.BindEnv("apm_config.filter_tags_glob.require", "DD_APM_FILTER_TAGS_GLOB_REQUIRE")             //nolint:errcheck
+	config.BindEnv("apm_config.filter_tags_glob.reject", "DD_APM_FILTER_TAGS_GLOB_REJECT")               //nolint:errcheck
+	config.BindEnv("apm_config.filter_tags_regex.require", "DD_APM_FILTER_TAGS_REGEX_REQUIRE")           //nolint:errcheck
+	config.BindEnv("apm_config.filter_tags_regex.reject", "DD_APM_FILTER_TAGS_REGEX_REJECT")             //nolint:errcheck
+
+	// Payload storage on disk
+	config.BindEnv("apm_config.payload_storage_path", "DD_APM_PAYLOAD_STORAGE_PATH")                             //nolint:errcheck
+	config.BindEnv("apm_config.payload_storage_max_size_in_bytes", "DD_APM_PAYLOAD_STORAGE_MAX_SIZE_IN_BYTES")   //nolint:errcheck
+	config.BindEnv("apm_config.payload_storage_max_age_in_seconds", "DD_APM_PAYLOAD_STORAGE_MAX_AGE_IN_SECONDS") //nolint:errcheck
 
 	config.SetEnvKeyTransformer("apm_config.ignore_resources", func(in string) interface{} {
 		r, err := splitCSVString(in, ',')
@@ -99,6 +119,22 @@ func setupAPM(config Config) {
 		return strings.Split(in, " ")
 	})
 
+	config.SetEnvKeyTransformer("apm_config.filter_tags_glob.require", func(in string) interface{} {
+		return strings.Split(in, " ")
+	})
+
+	config.SetEnvKeyTransformer("apm_config.filter_tags_glob.reject", func(in string) interface{} {
+		return strings.Split(in, " ")
+	})
+
+	config.SetEnvKeyTransformer("apm_config.filter_tags_regex.require", func(in string) interface{} {
+		return strings.Split(in, " ")
+	})
+
+	config.SetEnvKeyTransformer("apm_config.filter_tags_regex.reject", func(in string) interface{} {
+		return strings.Split(in, " ")
+	})
+
 	config.SetEnvKeyTransformer("apm_config.replace_tags", func(in string) interface{} {
 		var out []map[string]string
 		if err := json.Unmarshal([]byte(in), &out); err != nil {
@@ -128,10 +128,14 @@ type MappingProfile struct {
 
 // MetricMapping represent one mapping rule
 type MetricMapping struct {
-	Match     string            `mapstructure:"match" json:"match"`
-	MatchType string            `mapstructure:"match_type" json:"match_type"`
-	Name      string            `mapstructure:"name" json:"name"`
-	Tags      map[string]string `mapstructure:"tags" json:"tags"`
+	Match      string            `mapstructure:"match" json:"match"`
+	MatchType  string            `mapstructure:"match_type" json:"match_type"`
+	Name       string            `mapstructure:"name" json:"name"`
+	Tags       map[string]string `mapstructure:"tags" json:"tags"`
+	Action     string            `mapstructure:"action" json:"action"`
+	DropTags   []string          `mapstructure:"drop_tags" json:"drop_tags"`
+	RenameTags map[string]string `mapstructure:"rename_tags" json:"rename_tags"`
+	Type       string            `mapstructure:"type" json:"type"`
 }
 
 // Warnings represent the warnings in the config
@@ -363,7 +367,11 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("dogstatsd_queue_size", 1024)
 
 	config.BindEnvAndSetDefault("dogstatsd_non_local_traffic", false)
-	config.BindEnvAndSetDefault("dogstatsd_socket", "") // Notice: empty means feature disabled
+	config.BindEnvAndSetDefault("dogstatsd_socket", "")        // Notice: empty means feature disabled
+	config.BindEnvAndSetDefault("dogstatsd_stream_socket", "") // Notice: empty means feature disabled
+	config.BindEnvAndSetDefault("dogstatsd_tcp_port", 0)       // Notice: 0 means TCP port closed
+	// Framing of the messages received on the stream socket and the TCP port: "length_prefix" or "newline"
+	config.BindEnvAndSetDefault("dogstatsd_stream_framing", "length_prefix")
 	config.BindEnvAndSetDefault("dogstatsd_stats_port", 5000)
 	config.BindEnvAndSetDefault("dogstatsd_stats_enable", false)
 	config.BindEnvAndSetDefault("dogstatsd_stats_buffer", 10)
@@ -375,10 +383,25 @@ func InitConfig(config Config) {
 	// contexts will be deleted (see 'dogstatsd_expiry_seconds').
 	config.BindEnvAndSetDefault("dogstatsd_context_expiry_seconds", 300)
 	config.BindEnvAndSetDefault("dogstatsd_origin_detection", false) // Only supported for socket traffic
+	// Maximum age in seconds of the samples sent with a timestamp, the older samples are dropped
+	config.BindEnvAndSetDefault("dogstatsd_timestamp_max_age", 600)
 	config.BindEnvAndSetDefault("dogstatsd_so_rcvbuf", 0)
 	config.BindEnvAndSetDefault("dogstatsd_metrics_stats_enable", false)
+	// Limits of the contexts of each DogStatsD metric name, 0 means no limit
+	config.BindEnvAndSetDefault("dogstatsd_max_contexts_per_metric", 0)
+	config.BindEnvAndSetDefault("dogstatsd_max_values_per_tag_key", 0)
+	// Either "collapse" the contexts in excess into overflow contexts or "drop" them
+	config.BindEnvAndSetDefault("dogstatsd_context_limit_action", "collapse")
+
+	// Metric filter applied to the DogStatsD and checks samples before aggregation
+	config.BindEnvAndSetDefault("metric_filter_allowlist", []string{})
+	config.BindEnvAndSetDefault("metric_filter_blocklist", []string{})
+	config.BindEnvAndSetDefault("metric_filter_match_type", "wildcard")
+	config.BindEnvAndSetDefault("metric_filter_strip_tags", []string{})
 	config.BindEnvAndSetDefault("dogstatsd_tags", []string{})
 	config.BindEnvAndSetDefault("dogstatsd_mapper_cache_size", 1000)
+	// Directory of the DogStatsD traffic captures, defaults to run_path/dsd_capture
+	config.BindEnvAndSetDefault("dogstatsd_capture_path", "")
 	config.BindEnvAndSetDefault("dogstatsd_string_interner_size", 4096)
 	// Enable check for Entity-ID presence when enriching Dogstatsd metrics with tags
 	config.BindEnvAndSetDefault("dogstatsd_entity_id_precedence", false)
@@ -486,6 +509,30 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("snmp_traps_config.bind_host", "localhost")
 	config.BindEnvAndSetDefault("snmp_traps_config.stop_timeout", 5) // in seconds
 
+	// Prometheus remote-write receiver
+	config.BindEnvAndSetDefault("prometheus_remote_write.enabled", false)
+	config.BindEnvAndSetDefault("prometheus_remote_write.port", 9201)
+	config.BindEnvAndSetDefault("prometheus_remote_write.bind_host", "localhost")
+	config.BindEnvAndSetDefault("prometheus_remote_write.namespace", "")
+	config.BindEnvAndSetDefault("prometheus_remote_write.pod_tags_enabled", true)
+	config.BindEnvAndSetDefault("prometheus_remote_write.pod_name_label", "pod")
+	config.BindEnvAndSetDefault("prometheus_remote_write.pod_namespace_label", "namespace")
+	config.BindEnvAndSetDefault("prometheus_remote_write.tag_cardinality", "low")
+
+	// OpenTelemetry protocol (OTLP) receiver
+	config.BindEnvAndSetDefault("otlp_config.enabled", false)
+	config.BindEnvAndSetDefault("otlp_config.bind_host", "localhost")
+	config.BindEnvAndSetDefault("otlp_config.grpc_port", 4317)
+	config.BindEnvAndSetDefault("otlp_config.http_port", 4318)
+	config.BindEnvAndSetDefault("otlp_config.metrics_enabled", true)
+	config.BindEnvAndSetDefault("otlp_config.traces_enabled", true)
+
+	// OpenMetrics endpoint exposing the aggregated metrics
+	config.BindEnvAndSetDefault("openmetrics_endpoint.enabled", false)
+	config.BindEnvAndSetDefault("openmetrics_endpoint.port", 9203)
+	config.BindEnvAndSetDefault("openmetrics_endpoint.bind_host", "localhost")
+	config.BindEnvAndSetDefault("openmetrics_endpoint.percentiles", []string{"0.5", "0.95", "0.99"})
+
 	// Kube ApiServer
 	config.BindEnvAndSetDefault("kubernetes_kubeconfig_path", "")
 	config.BindEnvAndSetDefault("leader_lease_duration", "60")
@@ -610,6 +657,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("logs_config.open_files_limit", 100)
 	// add global processing rules that are applied on all logs
 	config.BindEnv("logs_config.processing_rules") //nolint:errcheck
+	// forward a copy of the logs to non-Datadog destinations
+	config.BindEnv("logs_config.forwarders") //nolint:errcheck
+	// reserve pipelines to some sources so that they are not delayed by the other sources
+	config.BindEnv("logs_config.dedicated_pipelines") //nolint:errcheck
 	// enforce the agent to use files to collect container logs on kubernetes environment
 	config.BindEnvAndSetDefault("logs_config.k8s_container_use_file", false)
 	// Enable the agent to use files to collect container logs on standalone docker environment, containers
@@ -634,6 +685,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("logs_config.use_tcp", false)
 	config.BindEnvAndSetDefault("logs_config.use_compression", true)
 	config.BindEnvAndSetDefault("logs_config.compression_level", 6) // Default level for the gzip/deflate algorithm
+	// Compression algorithm used when use_compression is enabled: gzip, deflate or zstd (only if built with zstd)
+	config.BindEnvAndSetDefault("logs_config.compression_kind", "gzip")
 	config.BindEnvAndSetDefault("logs_config.batch_wait", DefaultBatchWait)
 	config.BindEnvAndSetDefault("logs_config.connection_reset_interval", 0) // in seconds, 0 means disabled
 	config.BindEnvAndSetDefault("logs_config.dd_port", 10516)
@@ -648,6 +701,18 @@ func InitConfig(config Config) {
 	// could happen while serializing large objects on log lines.
 	config.BindEnvAndSetDefault("logs_config.aggregation_timeout", 1000)
 	config.BindEnv("logs_config.additional_endpoints") //nolint:errcheck
+	// Detect multi-line patterns automatically on sources that don't define a multi_line processing rule,
+	// by sampling the first lines of the source and testing them against well-known new content formats.
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_detection", false)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_extra_patterns", []string{})
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_default_sample_size", 500)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_default_match_threshold", 0.48)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_default_match_timeout", 30) // in seconds
+	// Spill the payloads that could not be sent to disk while the intake is unreachable and replay them
+	// once it recovers. When no path is set, payloads are stored in <logs_config.run_path>/disk_buffer.
+	config.BindEnvAndSetDefault("logs_config.use_disk_buffer", false)
+	config.BindEnvAndSetDefault("logs_config.disk_buffer_path", "")
+	config.BindEnvAndSetDefault("logs_config.disk_buffer_max_size", 100*1024*1024) // in bytes
 
 	// The cardinality of tags to send for checks and dogstatsd respectively.
 	// Choices are: low, orchestrator, high.
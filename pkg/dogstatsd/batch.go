@@ -11,11 +11,16 @@ type batcher struct {
 	samples      []metrics.MetricSample
 	samplesCount int
 
+	// samplesWithTs holds the samples stamped by the client
+	samplesWithTs      []metrics.MetricSample
+	samplesWithTsCount int
+
 	events        []*metrics.Event
 	serviceChecks []*metrics.ServiceCheck
 
 	// output channels
 	choutSamples       chan<- []metrics.MetricSample
+	choutSamplesWithTs chan<- []metrics.MetricSample
 	choutEvents        chan<- []*metrics.Event
 	choutServiceChecks chan<- []*metrics.ServiceCheck
 
@@ -26,14 +31,26 @@ func newBatcher(agg *aggregator.BufferedAggregator) *batcher {
 	s, e, sc := agg.GetBufferedChannels()
 	return &batcher{
 		samples:            agg.MetricSamplePool.GetBatch(),
+		samplesWithTs:      agg.MetricSamplePool.GetBatch(),
 		metricSamplePool:   agg.MetricSamplePool,
 		choutSamples:       s,
+		choutSamplesWithTs: agg.GetBufferedMetricsWithTsChannel(),
 		choutEvents:        e,
 		choutServiceChecks: sc,
 	}
 }
 
+// appendSample batches the sample, the samples stamped by the client are sent
+// to the aggregator with their timestamp instead of being stamped on arrival.
 func (b *batcher) appendSample(sample metrics.MetricSample) {
+	if sample.Timestamp > 0 {
+		if b.samplesWithTsCount == len(b.samplesWithTs) {
+			b.flushSamplesWithTs()
+		}
+		b.samplesWithTs[b.samplesWithTsCount] = sample
+		b.samplesWithTsCount++
+		return
+	}
 	if b.samplesCount == len(b.samples) {
 		b.flushSamples()
 	}
@@ -57,9 +74,18 @@ func (b *batcher) flushSamples() {
 	}
 }
 
+func (b *batcher) flushSamplesWithTs() {
+	if b.samplesWithTsCount > 0 {
+		b.choutSamplesWithTs <- b.samplesWithTs[:b.samplesWithTsCount]
+		b.samplesWithTsCount = 0
+		b.samplesWithTs = b.metricSamplePool.GetBatch()
+	}
+}
+
 // flush pushes all batched metrics to the aggregator.
 func (b *batcher) flush() {
 	b.flushSamples()
+	b.flushSamplesWithTs()
 	if len(b.events) > 0 {
 		b.choutEvents <- b.events
 		b.events = []*metrics.Event{}
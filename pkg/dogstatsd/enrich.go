@@ -2,8 +2,10 @@ package dogstatsd
 
 import (
 	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
 
@@ -54,6 +56,15 @@ func extractTagsMetadata(tags []string, defaultHostname string, originTags strin
 	return tags, host, origin, k8sOrigin, cardinality
 }
 
+// originFromContainerID returns the tagger entity of the container whose ID was sent in the
+// message when the listener could not detect the origin, e.g. for the UDP traffic.
+func originFromContainerID(origin string, containerID string) string {
+	if origin != "" || containerID == "" {
+		return origin
+	}
+	return containers.BuildTaggerEntityName(containerID)
+}
+
 func enrichMetricType(dogstatsdMetricType metricType) metrics.MetricType {
 	switch dogstatsdMetricType {
 	case gaugeType:
@@ -86,7 +97,7 @@ func isExcluded(metricName, namespace string, excludedNamespaces []string) bool
 func enrichMetricSample(metricSamples []metrics.MetricSample, ddSample dogstatsdMetricSample, namespace string, excludedNamespaces []string,
 	defaultHostname string, origin string, entityIDPrecedenceEnabled bool, serverlessMode bool) []metrics.MetricSample {
 	metricName := ddSample.name
-	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(ddSample.tags, defaultHostname, origin, entityIDPrecedenceEnabled)
+	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(ddSample.tags, defaultHostname, originFromContainerID(origin, ddSample.containerID), entityIDPrecedenceEnabled)
 
 	if !isExcluded(metricName, namespace, excludedNamespaces) {
 		metricName = namespace + metricName
@@ -98,6 +109,13 @@ func enrichMetricSample(metricSamples []metrics.MetricSample, ddSample dogstatsd
 
 	mtype := enrichMetricType(ddSample.metricType)
 
+	// the points supplied with a timestamp are already aggregated by the client,
+	// which is only supported for gauges and counts: other types are stamped on arrival
+	var timestamp float64
+	if ddSample.ts > 0 && (ddSample.metricType == gaugeType || ddSample.metricType == countType) {
+		timestamp = float64(ddSample.ts) * float64(time.Second)
+	}
+
 	// if 'ddSample.values' contains values we're enriching a multi-value
 	// dogstatsd message and will create a MetricSample per value. If not
 	// we will use 'ddSample.value'and return a single MetricSample
@@ -112,6 +130,7 @@ func enrichMetricSample(metricSamples []metrics.MetricSample, ddSample dogstatsd
 					Value:       ddSample.values[idx],
 					SampleRate:  ddSample.sampleRate,
 					RawValue:    ddSample.setValue,
+					Timestamp:   timestamp,
 					OriginID:    originID,
 					K8sOriginID: k8sOriginID,
 					Cardinality: cardinality,
@@ -129,6 +148,7 @@ func enrichMetricSample(metricSamples []metrics.MetricSample, ddSample dogstatsd
 		Value:       ddSample.value,
 		SampleRate:  ddSample.sampleRate,
 		RawValue:    ddSample.setValue,
+		Timestamp:   timestamp,
 		OriginID:    originID,
 		K8sOriginID: k8sOriginID,
 		Cardinality: cardinality,
@@ -160,7 +180,7 @@ func enrichEventAlertType(dogstatsdAlertType alertType) metrics.EventAlertType {
 }
 
 func enrichEvent(event dogstatsdEvent, defaultHostname string, origin string, entityIDPrecedenceEnabled bool) *metrics.Event {
-	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(event.tags, defaultHostname, origin, entityIDPrecedenceEnabled)
+	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(event.tags, defaultHostname, originFromContainerID(origin, event.containerID), entityIDPrecedenceEnabled)
 
 	enrichedEvent := &metrics.Event{
 		Title:          event.title,
@@ -199,7 +219,7 @@ func enrichServiceCheckStatus(status serviceCheckStatus) metrics.ServiceCheckSta
 }
 
 func enrichServiceCheck(serviceCheck dogstatsdServiceCheck, defaultHostname string, origin string, entityIDPrecedenceEnabled bool) *metrics.ServiceCheck {
-	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(serviceCheck.tags, defaultHostname, origin, entityIDPrecedenceEnabled)
+	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(serviceCheck.tags, defaultHostname, originFromContainerID(origin, serviceCheck.containerID), entityIDPrecedenceEnabled)
 
 	enrichedServiceCheck := &metrics.ServiceCheck{
 		CheckName:   serviceCheck.name,
@@ -3,6 +3,7 @@ package dogstatsd
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/stretchr/testify/assert"
@@ -113,6 +114,21 @@ func TestConvertParseSingle(t *testing.T) {
 	}
 }
 
+func TestConvertParseSingleWithTimestamp(t *testing.T) {
+	for metricSymbol, metricType := range symbolToType {
+		parsed, err := parseAndEnrichSingleMetricMessage([]byte("daemon:666|"+metricSymbol+"|T1657100430"), "", nil, "default-hostname")
+
+		assert.NoError(t, err)
+		assert.Equal(t, metricType, parsed.Mtype)
+		// only the gauges and the counts are sent with their timestamp
+		if metricType == metrics.GaugeType || metricType == metrics.CounterType {
+			assert.Equal(t, 1657100430*float64(time.Second), parsed.Timestamp)
+		} else {
+			assert.Zero(t, parsed.Timestamp)
+		}
+	}
+}
+
 func TestConvertParseSingleWithTags(t *testing.T) {
 	for metricSymbol, metricType := range symbolToType {
 
@@ -851,6 +867,29 @@ func TestConvertEntityOriginDetectionTagsError(t *testing.T) {
 	assert.InEpsilon(t, 1.0, parsed.SampleRate, epsilon)
 }
 
+func TestConvertContainerIDOriginDetection(t *testing.T) {
+	parsed, err := parseAndEnrichSingleMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1|c:container-id"), "", nil, "default-hostname")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sometag1:somevalue1"}, parsed.Tags)
+	assert.Equal(t, "container_id://container-id", parsed.OriginID)
+	assert.Equal(t, "", parsed.K8sOriginID)
+
+	sc, err := parseAndEnrichServiceCheckMessage([]byte("_sc|agent.up|0|c:container-id"), "default-hostname")
+	assert.NoError(t, err)
+	assert.Equal(t, "container_id://container-id", sc.OriginID)
+
+	e, err := parseAndEnrichEventMessage([]byte("_e{10,9}:test title|test text|c:container-id"), "default-hostname")
+	assert.NoError(t, err)
+	assert.Equal(t, "container_id://container-id", e.OriginID)
+
+	// the origin detected by the listener takes precedence over the container ID field
+	sample, err := newParser(newFloat64ListPool()).parseMetricSample([]byte("daemon:666|g|c:container-id"))
+	assert.NoError(t, err)
+	samples := enrichMetricSample(nil, sample, "", nil, "default-hostname", "container_id://uds-origin", true, false)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "container_id://uds-origin", samples[0].OriginID)
+}
+
 func TestEnrichTags(t *testing.T) {
 	type args struct {
 		tags                       []string
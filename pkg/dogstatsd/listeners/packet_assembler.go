@@ -17,6 +17,9 @@ const messageSeparator = byte('\n')
 type packetAssembler struct {
 	packet       *Packet
 	packetLength int
+	// origin is set on the assembled packets, when the messages of
+	// the assembler all come from the same client.
+	origin string
 	// assembled packets are pushed into this buffer
 	packetsBuffer    *packetsBuffer
 	sharedPacketPool *PacketPool
@@ -72,6 +75,7 @@ func (p *packetAssembler) flush() {
 		return
 	}
 	p.packet.Contents = p.packet.buffer[:p.packetLength]
+	p.packet.Origin = p.origin
 	p.packetsBuffer.append(p.packet)
 	// retrieve an available packet from the packet pool,
 	// which will be pushed back by the server when processed.
@@ -79,8 +83,17 @@ func (p *packetAssembler) flush() {
 	p.packetLength = 0
 }
 
+// flushAndClose sends the messages being assembled and stops the assembler.
+func (p *packetAssembler) flushAndClose() {
+	p.Lock()
+	p.flush()
+	p.Unlock()
+	p.close()
+}
+
 func (p *packetAssembler) close() {
 	p.Lock()
+	p.flushTimer.Stop()
 	close(p.closeChannel)
 	p.Unlock()
 }
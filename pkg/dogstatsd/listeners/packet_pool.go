@@ -64,11 +64,12 @@ func (p *PacketPool) Get() *Packet {
 	return p.pool.Get().(*Packet)
 }
 
-// Put resets the Packet origin and puts it back in the pool.
+// Put resets the Packet origin and ancillary data and puts it back in the pool.
 func (p *PacketPool) Put(packet *Packet) {
 	if packet.Origin != NoOrigin {
 		packet.Origin = NoOrigin
 	}
+	packet.Ancillary = packet.Ancillary[:0]
 	if p.tlmEnabled {
 		tlmPacketPoolPut.Inc()
 		tlmPacketPool.Dec()
@@ -0,0 +1,215 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listeners
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Framings of the stream listeners
+const (
+	// lengthPrefixFraming prefixes each frame with its length as a 4 bytes little endian
+	// unsigned integer, a frame may hold several messages separated by newlines.
+	lengthPrefixFraming = "length_prefix"
+	// newlineFraming terminates each message by a newline.
+	newlineFraming = "newline"
+)
+
+// frameLengthSize is the size of the length prefixing a frame.
+const frameLengthSize = 4
+
+// streamListener accepts connections on a stream socket and reads the messages of
+// each connection in its own goroutine. The messages of a connection are assembled
+// in packets holding the origin of the connection.
+type streamListener struct {
+	name             string
+	listener         net.Listener
+	framing          string
+	bufferSize       int
+	flushTimeout     time.Duration
+	packetsBuffer    *packetsBuffer
+	sharedPacketPool *PacketPool
+	telemetry        *listenerTelemetry
+	// getOrigin returns the origin of the messages of a connection,
+	// it is nil when origin detection is disabled.
+	getOrigin func(conn net.Conn) (string, error)
+
+	mu      sync.Mutex
+	conns   map[net.Conn]struct{}
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func newStreamListener(name string, listener net.Listener, telemetry *listenerTelemetry, packetOut chan Packets, sharedPacketPool *PacketPool) (*streamListener, error) {
+	framing := config.Datadog.GetString("dogstatsd_stream_framing")
+	if framing != lengthPrefixFraming && framing != newlineFraming {
+		return nil, fmt.Errorf("%s: invalid framing '%s', must be %s or %s", name, framing, lengthPrefixFraming, newlineFraming)
+	}
+	flushTimeout := config.Datadog.GetDuration("dogstatsd_packet_buffer_flush_timeout")
+	return &streamListener{
+		name:             name,
+		listener:         listener,
+		framing:          framing,
+		bufferSize:       config.Datadog.GetInt("dogstatsd_buffer_size"),
+		flushTimeout:     flushTimeout,
+		packetsBuffer:    newPacketsBuffer(uint(config.Datadog.GetInt("dogstatsd_packet_buffer_size")), flushTimeout, packetOut),
+		sharedPacketPool: sharedPacketPool,
+		telemetry:        telemetry,
+		conns:            make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Listen accepts the connections. Should be called in its own goroutine
+func (l *streamListener) Listen() {
+	log.Infof("%s: starting to listen on %s", l.name, l.listener.Addr())
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if isClosedConnError(err) {
+				return
+			}
+			log.Errorf("%s: error accepting connection: %v", l.name, err)
+			continue
+		}
+		l.mu.Lock()
+		if l.stopped {
+			l.mu.Unlock()
+			conn.Close()
+			return
+		}
+		l.conns[conn] = struct{}{}
+		l.wg.Add(1)
+		l.mu.Unlock()
+		go l.handleConnection(conn)
+	}
+}
+
+// handleConnection reads the messages of a connection until it is closed.
+func (l *streamListener) handleConnection(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		l.wg.Done()
+	}()
+
+	assembler := newPacketAssembler(l.flushTimeout, l.packetsBuffer, l.sharedPacketPool)
+	defer assembler.flushAndClose()
+	if l.getOrigin != nil {
+		origin, err := l.getOrigin(conn)
+		if err != nil {
+			log.Warnf("%s: error processing origin, data will not be tagged : %v", l.name, err)
+		} else {
+			assembler.origin = origin
+		}
+	}
+
+	reader := bufio.NewReaderSize(conn, l.bufferSize)
+	var err error
+	if l.framing == newlineFraming {
+		err = l.readLines(reader, assembler)
+	} else {
+		err = l.readFrames(reader, assembler)
+	}
+	if err != io.EOF && !isClosedConnError(err) {
+		log.Errorf("%s: error reading from %s: %v", l.name, conn.RemoteAddr(), err)
+		l.telemetry.onReadError()
+	}
+}
+
+// readFrames reads frames prefixed by their length, the frames that
+// do not fit in a packet are dropped.
+func (l *streamListener) readFrames(reader *bufio.Reader, assembler *packetAssembler) error {
+	buffer := make([]byte, l.bufferSize)
+	header := make([]byte, frameLengthSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return err
+		}
+		size := int64(binary.LittleEndian.Uint32(header))
+		if size > int64(len(buffer)) {
+			log.Debugf("%s: dropping a frame of %d bytes, larger than the buffer size", l.name, size)
+			l.telemetry.onReadError()
+			if _, err := io.CopyN(ioutil.Discard, reader, size); err != nil {
+				return err
+			}
+			continue
+		}
+		frame := buffer[:size]
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return err
+		}
+		l.telemetry.onReadSuccess(len(frame))
+		if len(frame) > 0 {
+			assembler.addMessage(frame)
+		}
+	}
+}
+
+// readLines reads messages terminated by a newline, the messages that
+// do not fit in a packet are dropped.
+func (l *streamListener) readLines(reader *bufio.Reader, assembler *packetAssembler) error {
+	dropping := false
+	for {
+		line, err := reader.ReadSlice(messageSeparator)
+		switch err {
+		case nil:
+			if dropping {
+				// end of a dropped message
+				dropping = false
+				continue
+			}
+			l.telemetry.onReadSuccess(len(line))
+			if len(line) > 1 {
+				assembler.addMessage(line[:len(line)-1])
+			}
+		case bufio.ErrBufferFull:
+			if !dropping {
+				log.Debugf("%s: dropping a message larger than the buffer size", l.name)
+				l.telemetry.onReadError()
+				dropping = true
+			}
+		default:
+			// the last message may not be terminated by a newline
+			if len(line) > 0 && !dropping {
+				l.telemetry.onReadSuccess(len(line))
+				assembler.addMessage(line)
+			}
+			return err
+		}
+	}
+}
+
+// Stop closes the connections and stops listening,
+// this call blocks until the connections are closed.
+func (l *streamListener) Stop() {
+	l.listener.Close()
+	l.mu.Lock()
+	l.stopped = true
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+	l.packetsBuffer.close()
+}
+
+// isClosedConnError returns true if the error is returned by an operation on a closed connection.
+func isClosedConnError(err error) bool {
+	return strings.HasSuffix(err.Error(), " use of closed network connection")
+}
@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listeners
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var tcpTelemetry = newListenerTelemetry("tcp", "TCP")
+
+// TCPListener implements the StatsdListener interface for TCP protocol.
+// It listens to a given TCP address and sends back packets ready to be
+// processed, the messages are framed according to `dogstatsd_stream_framing`.
+// Origin detection is not implemented for TCP.
+type TCPListener struct {
+	*streamListener
+}
+
+// NewTCPListener returns an idle TCP Statsd listener
+func NewTCPListener(packetOut chan Packets, sharedPacketPool *PacketPool) (*TCPListener, error) {
+	var url string
+	if config.Datadog.GetBool("dogstatsd_non_local_traffic") == true {
+		// Listen to all network interfaces
+		url = fmt.Sprintf(":%d", config.Datadog.GetInt("dogstatsd_tcp_port"))
+	} else {
+		url = net.JoinHostPort(config.GetBindHost(), config.Datadog.GetString("dogstatsd_tcp_port"))
+	}
+
+	listener, err := net.Listen("tcp", url)
+	if err != nil {
+		return nil, fmt.Errorf("can't listen: %s", err)
+	}
+	streamListener, err := newStreamListener("dogstatsd-tcp", listener, tcpTelemetry, packetOut, sharedPacketPool)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	log.Debugf("dogstatsd-tcp: %s successfully initialized", listener.Addr())
+	return &TCPListener{streamListener}, nil
+}
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+// +build !windows
+
+package listeners
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var packetPoolTCP = NewPacketPool(config.Datadog.GetInt("dogstatsd_buffer_size"))
+
+// lengthPrefixed returns the frame prefixed by its length.
+func lengthPrefixed(frame string) []byte {
+	header := make([]byte, frameLengthSize)
+	binary.LittleEndian.PutUint32(header, uint32(len(frame)))
+	return append(header, frame...)
+}
+
+func receivePacket(t *testing.T, packetsChannel chan Packets) *Packet {
+	select {
+	case packets := <-packetsChannel:
+		require.Equal(t, 1, len(packets))
+		return packets[0]
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "Timeout on receive channel")
+	}
+	return nil
+}
+
+func newTestTCPListener(t *testing.T, framing string, packetsChannel chan Packets) *TCPListener {
+	mockConfig := config.Mock()
+	mockConfig.Set("dogstatsd_tcp_port", 0)
+	mockConfig.Set("dogstatsd_stream_framing", framing)
+	s, err := NewTCPListener(packetsChannel, packetPoolTCP)
+	require.Nil(t, err)
+	require.NotNil(t, s)
+	return s
+}
+
+func TestNewTCPListenerInvalidFraming(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("dogstatsd_tcp_port", 0)
+	mockConfig.Set("dogstatsd_stream_framing", "crlf")
+	s, err := NewTCPListener(nil, packetPoolTCP)
+	assert.Nil(t, s)
+	assert.Error(t, err)
+}
+
+func TestStartStopTCPListener(t *testing.T) {
+	s := newTestTCPListener(t, lengthPrefixFraming, nil)
+	go s.Listen()
+	address := s.listener.Addr().String()
+
+	// an idle connection does not prevent the listener from stopping
+	conn, err := net.Dial("tcp", address)
+	require.Nil(t, err)
+	defer conn.Close()
+
+	s.Stop()
+	_, err = net.Dial("tcp", address)
+	assert.NotNil(t, err)
+}
+
+func TestTCPReceiveLengthPrefixedFrames(t *testing.T) {
+	packetsChannel := make(chan Packets)
+	s := newTestTCPListener(t, lengthPrefixFraming, packetsChannel)
+	go s.Listen()
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	require.Nil(t, err)
+	defer conn.Close()
+	// the frame larger than the buffer size is dropped
+	tooLarge := make([]byte, config.Datadog.GetInt("dogstatsd_buffer_size")+1)
+	conn.Write(lengthPrefixed(string(tooLarge)))
+	conn.Write(lengthPrefixed("daemon:666|g|#sometag1:somevalue1\ndaemon:667|g"))
+	conn.Write(lengthPrefixed("daemon:668|g"))
+
+	packet := receivePacket(t, packetsChannel)
+	assert.Equal(t, "daemon:666|g|#sometag1:somevalue1\ndaemon:667|g\ndaemon:668|g", string(packet.Contents))
+	assert.Equal(t, NoOrigin, packet.Origin)
+}
+
+func TestTCPReceiveNewlineTerminatedMessages(t *testing.T) {
+	packetsChannel := make(chan Packets)
+	s := newTestTCPListener(t, newlineFraming, packetsChannel)
+	go s.Listen()
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	require.Nil(t, err)
+	conn.Write([]byte("daemon:666|g\ndaemon:"))
+	conn.Write([]byte("667|g\n\ndaemon:668|g"))
+	// the last message is sent when the connection is closed
+	conn.Close()
+
+	packet := receivePacket(t, packetsChannel)
+	assert.Equal(t, "daemon:666|g\ndaemon:667|g\ndaemon:668|g", string(packet.Contents))
+}
@@ -64,17 +64,9 @@ func NewUDSListener(packetOut chan Packets, sharedPacketPool *PacketPool) (*UDSL
 	if addrErr != nil {
 		return nil, fmt.Errorf("dogstatsd-uds: can't ResolveUnixAddr: %v", addrErr)
 	}
-	fileInfo, err := os.Stat(socketPath)
-	// Socket file already exists
-	if err == nil {
-		// Make sure it's a UNIX socket
-		if fileInfo.Mode()&os.ModeSocket == 0 {
-			return nil, fmt.Errorf("dogstatsd-uds: cannot reuse %s socket path: path already exists and is not a UNIX socket", socketPath)
-		}
-		err = os.Remove(socketPath)
-		if err != nil {
-			return nil, fmt.Errorf("dogstatsd-usd: cannot remove stale UNIX socket: %v", err)
-		}
+	err := removeStaleSocket("dogstatsd-uds", socketPath)
+	if err != nil {
+		return nil, err
 	}
 
 	conn, err := net.ListenUnixgram("unixgram", address)
@@ -139,6 +131,9 @@ func (l *UDSListener) Listen() {
 			oob := l.oobPool.Get().([]byte)
 			var oobn int
 			n, oobn, _, _, err = l.conn.ReadMsgUnix(packet.buffer, oob)
+			// Keep the credentials for the traffic capture, the packet
+			// buffer is reused with the packet so this doesn't allocate
+			packet.Ancillary = append(packet.Ancillary[:0], oob[:oobn]...)
 			// Extract container id from credentials
 			container, taggingErr := processUDSOrigin(oob[:oobn])
 			if taggingErr != nil {
@@ -191,3 +186,21 @@ func (l *UDSListener) Stop() {
 		}
 	}
 }
+
+// removeStaleSocket removes the socket file left at socketPath by a previous run,
+// it returns an error if the path exists and is not a UNIX socket.
+func removeStaleSocket(name string, socketPath string) error {
+	fileInfo, err := os.Stat(socketPath)
+	// Socket file already exists
+	if err == nil {
+		// Make sure it's a UNIX socket
+		if fileInfo.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%s: cannot reuse %s socket path: path already exists and is not a UNIX socket", name, socketPath)
+		}
+		err = os.Remove(socketPath)
+		if err != nil {
+			return fmt.Errorf("%s: cannot remove stale UNIX socket: %v", name, err)
+		}
+	}
+	return nil
+}
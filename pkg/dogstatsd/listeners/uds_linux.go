@@ -63,7 +63,33 @@ func processUDSOrigin(ancillary []byte) (string, error) {
 	if err != nil {
 		return NoOrigin, err
 	}
+	return originForCredentials(cred)
+}
+
+// processUDSPeerOrigin returns the origin of the messages of a stream connection.
+// The credentials of the client when it connected are retrieved with SO_PEERCRED,
+// they are valid for the whole connection.
+func processUDSPeerOrigin(conn *net.UnixConn) (string, error) {
+	rawconn, err := conn.SyscallConn()
+	if err != nil {
+		return NoOrigin, err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	err = rawconn.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil {
+		return NoOrigin, err
+	}
+	if credErr != nil {
+		return NoOrigin, credErr
+	}
+	return originForCredentials(cred)
+}
 
+// originForCredentials returns the origin of the process identified by the credentials.
+func originForCredentials(cred *unix.Ucred) (string, error) {
 	if cred.Pid == 0 {
 		return NoOrigin, fmt.Errorf("matched PID for the process is 0, it belongs " +
 			"probably to another namespace. Is the agent in host PID mode?")
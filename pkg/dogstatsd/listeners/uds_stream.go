@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listeners
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var udsStreamTelemetry = newListenerTelemetry("uds_stream", "UDS stream")
+
+// UDSStreamListener implements the StatsdListener interface for Unix Domain
+// Socket stream protocol. It listens to a given socket path and sends back
+// packets ready to be processed, the messages are framed according to
+// `dogstatsd_stream_framing`.
+// Origin detection is done once per connection, from the credentials of
+// the client when it connected.
+type UDSStreamListener struct {
+	*streamListener
+	OriginDetection bool
+}
+
+// NewUDSStreamListener returns an idle UDS stream Statsd listener
+func NewUDSStreamListener(packetOut chan Packets, sharedPacketPool *PacketPool) (*UDSStreamListener, error) {
+	socketPath := config.Datadog.GetString("dogstatsd_stream_socket")
+	originDetection := config.Datadog.GetBool("dogstatsd_origin_detection")
+
+	address, addrErr := net.ResolveUnixAddr("unix", socketPath)
+	if addrErr != nil {
+		return nil, fmt.Errorf("dogstatsd-uds-stream: can't ResolveUnixAddr: %v", addrErr)
+	}
+	err := removeStaleSocket("dogstatsd-uds-stream", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.ListenUnix("unix", address)
+	if err != nil {
+		return nil, fmt.Errorf("can't listen: %s", err)
+	}
+	err = os.Chmod(socketPath, 0722)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("can't set the socket at write only: %s", err)
+	}
+
+	streamListener, err := newStreamListener("dogstatsd-uds-stream", listener, udsStreamTelemetry, packetOut, sharedPacketPool)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	if originDetection && runtime.GOOS != "linux" {
+		log.Errorf("dogstatsd-uds-stream: error enabling origin detection: only implemented on Linux hosts")
+		originDetection = false
+	}
+	if originDetection {
+		log.Debugf("dogstatsd-uds-stream: enabling origin detection on %s", listener.Addr())
+		streamListener.getOrigin = func(conn net.Conn) (string, error) {
+			origin, err := processUDSPeerOrigin(conn.(*net.UnixConn))
+			if err != nil {
+				udsOriginDetectionErrors.Add(1)
+				tlmUDSOriginDetectionError.Inc()
+			}
+			return origin, err
+		}
+	}
+
+	log.Debugf("dogstatsd-uds-stream: %s successfully initialized", listener.Addr())
+	return &UDSStreamListener{
+		streamListener:  streamListener,
+		OriginDetection: originDetection,
+	}, nil
+}
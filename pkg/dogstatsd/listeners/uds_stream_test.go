@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !windows
+// UDS won't work in windows
+
+package listeners
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestNewUDSStreamListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dd-test-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir) // clean up
+	socketPath := filepath.Join(dir, "dsd.socket")
+
+	mockConfig := config.Mock()
+	mockConfig.Set("dogstatsd_stream_socket", socketPath)
+	mockConfig.Set("dogstatsd_origin_detection", false)
+
+	// a file which is not a socket is not removed
+	_, err = os.Create(socketPath)
+	require.Nil(t, err)
+	_, err = NewUDSStreamListener(nil, packetPoolUDS)
+	assert.Error(t, err)
+	os.Remove(socketPath)
+
+	s, err := NewUDSStreamListener(nil, packetPoolUDS)
+	require.Nil(t, err)
+	require.NotNil(t, s)
+	fi, err := os.Stat(socketPath)
+	require.Nil(t, err)
+	assert.Equal(t, "Srwx-w--w-", fi.Mode().String())
+
+	go s.Listen()
+	s.Stop()
+	_, err = net.Dial("unix", socketPath)
+	assert.NotNil(t, err)
+}
+
+func TestUDSStreamReceive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dd-test-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir) // clean up
+	socketPath := filepath.Join(dir, "dsd.socket")
+
+	mockConfig := config.Mock()
+	mockConfig.Set("dogstatsd_stream_socket", socketPath)
+	mockConfig.Set("dogstatsd_origin_detection", false)
+	mockConfig.Set("dogstatsd_stream_framing", lengthPrefixFraming)
+
+	packetsChannel := make(chan Packets)
+	s, err := NewUDSStreamListener(packetsChannel, packetPoolUDS)
+	require.Nil(t, err)
+	go s.Listen()
+	defer s.Stop()
+
+	conn, err := net.Dial("unix", socketPath)
+	require.Nil(t, err)
+	defer conn.Close()
+	conn.Write(lengthPrefixed("daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2"))
+
+	packet := receivePacket(t, packetsChannel)
+	assert.Equal(t, "daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2", string(packet.Contents))
+	assert.Equal(t, NoOrigin, packet.Origin)
+}
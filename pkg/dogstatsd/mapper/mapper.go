@@ -21,8 +21,21 @@ var (
 const (
 	matchTypeWildcard = "wildcard"
 	matchTypeRegex    = "regex"
+
+	actionMap  = "map"
+	actionDrop = "drop"
 )
 
+// metricTypes are the types a mapping can override the type of a metric with,
+// the sets can not be converted to or from another type.
+var metricTypes = map[string]bool{
+	"gauge":        true,
+	"count":        true,
+	"histogram":    true,
+	"distribution": true,
+	"timing":       true,
+}
+
 // MetricMapper contains mappings and cache instance
 type MetricMapper struct {
 	Profiles []MappingProfile
@@ -38,15 +51,27 @@ type MappingProfile struct {
 
 // MetricMapping represent one mapping rule
 type MetricMapping struct {
-	name  string
-	tags  map[string]string
-	regex *regexp.Regexp
+	name       string
+	tags       map[string]string
+	regex      *regexp.Regexp
+	drop       bool
+	dropTags   []string
+	renameTags map[string]string
+	metricType string
 }
 
 // MapResult represent the outcome of the mapping
 type MapResult struct {
-	Name    string
-	Tags    []string
+	Name string
+	Tags []string
+	// Drop is true when the metric must be dropped
+	Drop bool
+	// DropTags are the keys of the tags removed from the metric
+	DropTags []string
+	// RenameTags maps the keys of the tags to rename to their new key
+	RenameTags map[string]string
+	// Type overrides the type of the metric when set
+	Type    string
 	matched bool
 }
 
@@ -69,17 +94,36 @@ func NewMetricMapper(configProfiles []config.MappingProfile, cacheSize int) (*Me
 			if matchType != matchTypeWildcard && matchType != matchTypeRegex {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: invalid match type, must be `wildcard` or `regex`", profile.Name, i)
 			}
-			if currentMapping.Name == "" {
+			action := currentMapping.Action
+			if action == "" {
+				action = actionMap
+			}
+			if action != actionMap && action != actionDrop {
+				return nil, fmt.Errorf("profile: %s, mapping num %d: invalid action, must be `map` or `drop`", profile.Name, i)
+			}
+			// the dropped metrics are not renamed
+			if currentMapping.Name == "" && action != actionDrop {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: name is required", profile.Name, i)
 			}
 			if currentMapping.Match == "" {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: match is required", profile.Name, i)
 			}
+			if currentMapping.Type != "" && !metricTypes[currentMapping.Type] {
+				return nil, fmt.Errorf("profile: %s, mapping num %d: invalid type `%s`, must be `gauge`, `count`, `histogram`, `distribution` or `timing`", profile.Name, i, currentMapping.Type)
+			}
 			regex, err := buildRegex(currentMapping.Match, matchType)
 			if err != nil {
 				return nil, err
 			}
-			profile.Mappings = append(profile.Mappings, &MetricMapping{name: currentMapping.Name, tags: currentMapping.Tags, regex: regex})
+			profile.Mappings = append(profile.Mappings, &MetricMapping{
+				name:       currentMapping.Name,
+				tags:       currentMapping.Tags,
+				regex:      regex,
+				drop:       action == actionDrop,
+				dropTags:   currentMapping.DropTags,
+				renameTags: currentMapping.RenameTags,
+				metricType: currentMapping.Type,
+			})
 		}
 		profiles = append(profiles, profile)
 	}
@@ -127,6 +171,12 @@ func (m *MetricMapper) Map(metricName string) *MapResult {
 				continue
 			}
 
+			if mapping.drop {
+				mapResult := &MapResult{Drop: true, matched: true}
+				m.cache.add(metricName, mapResult)
+				return mapResult
+			}
+
 			name := string(mapping.regex.ExpandString(
 				[]byte{},
 				mapping.name,
@@ -140,7 +190,14 @@ func (m *MetricMapper) Map(metricName string) *MapResult {
 				tags = append(tags, tagKey+":"+tagValue)
 			}
 
-			mapResult := &MapResult{Name: name, matched: true, Tags: tags}
+			mapResult := &MapResult{
+				Name:       name,
+				Tags:       tags,
+				DropTags:   mapping.dropTags,
+				RenameTags: mapping.renameTags,
+				Type:       mapping.metricType,
+				matched:    true,
+			}
 			m.cache.add(metricName, mapResult)
 			return mapResult
 		}
@@ -150,3 +207,37 @@ func (m *MetricMapper) Map(metricName string) *MapResult {
 	}
 	return nil
 }
+
+// ApplyTags returns the tags of a mapped metric: the tags to drop are removed
+// from the tags of the metric, the tags to rename are renamed and the tags
+// of the mapping are appended. The tags slice is modified in place.
+func (r *MapResult) ApplyTags(tags []string) []string {
+	if len(r.DropTags) > 0 || len(r.RenameTags) > 0 {
+		n := 0
+		for _, tag := range tags {
+			key, value := tag, ""
+			if sep := strings.IndexByte(tag, ':'); sep != -1 {
+				key, value = tag[:sep], tag[sep:]
+			}
+			if containsString(r.DropTags, key) {
+				continue
+			}
+			if newKey, found := r.RenameTags[key]; found {
+				tag = newKey + value
+			}
+			tags[n] = tag
+			n++
+		}
+		tags = tags[:n]
+	}
+	return append(tags, r.Tags...)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
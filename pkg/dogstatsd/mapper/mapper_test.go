@@ -324,6 +324,39 @@ dogstatsd_mapper_profiles:
 				{Name: "foo.bar1.duration", Tags: []string{"bar:bar", "foo:foo_name"}, matched: true},
 			},
 		},
+		{
+			name: "Drop action and type override",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.debug.*"
+        action: drop
+      - match: "test.job.duration.*"
+        name: "test.job.duration"
+        type: distribution
+        drop_tags:
+          - pid
+        rename_tags:
+          env: environment
+        tags:
+          job: "$1"
+      - match: "test.job.size.*"
+        name: "$0"
+        type: gauge
+`,
+			packets: []string{
+				"test.job.debug.my_job",
+				"test.job.duration.my_job",
+				"test.job.size.my_job",
+			},
+			expectedResults: []MapResult{
+				{Drop: true, matched: true},
+				{Name: "test.job.duration", Tags: []string{"job:my_job"}, DropTags: []string{"pid"}, RenameTags: map[string]string{"env": "environment"}, Type: "distribution", matched: true},
+				{Name: "test.job.size.my_job", Type: "gauge", matched: true},
+			},
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -499,6 +532,38 @@ dogstatsd_mapper_profiles:
 			},
 			expectedError: "missing prefix for profile",
 		},
+		{
+			name: "Invalid action",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration.*"
+        name: "test.job.duration"
+        action: invalid
+`,
+			packets: []string{
+				"test.job.duration.my_job_type",
+			},
+			expectedError: "invalid action",
+		},
+		{
+			name: "Invalid type",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration.*"
+        name: "test.job.duration"
+        type: set
+`,
+			packets: []string{
+				"test.job.duration.my_job_type",
+			},
+			expectedError: "invalid type `set`",
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -527,3 +592,15 @@ func getMapper(configString string) (*MetricMapper, error) {
 	}
 	return mapper, err
 }
+
+func TestApplyTags(t *testing.T) {
+	mapResult := &MapResult{
+		Tags:       []string{"job:my_job"},
+		DropTags:   []string{"pid", "debug"},
+		RenameTags: map[string]string{"env": "environment", "flag": "feature"},
+	}
+	tags := mapResult.ApplyTags([]string{"pid:123", "env:prod", "debug", "flag", "some:tag"})
+	assert.Equal(t, []string{"environment:prod", "feature", "some:tag", "job:my_job"}, tags)
+
+	assert.Equal(t, []string{"job:my_job"}, mapResult.ApplyTags(nil))
+}
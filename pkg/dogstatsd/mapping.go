@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// metricTypeNames maps the names of the metric types used in the mapper configuration to the metric types.
+var metricTypeNames = map[string]metricType{
+	"gauge":        gaugeType,
+	"count":        countType,
+	"histogram":    histogramType,
+	"distribution": distributionType,
+	"set":          setType,
+	"timing":       timingType,
+}
+
+// MappedMetric is a DogStatsD metric before or after being mapped.
+type MappedMetric struct {
+	Name string
+	Type string
+	Tags []string
+}
+
+// MappingResult describes how the mapper maps a DogStatsD metric.
+type MappingResult struct {
+	Original MappedMetric
+	Mapped   MappedMetric
+	// Matched is true when a mapping matched the name of the metric
+	Matched bool
+	// Dropped is true when the matching mapping drops the metric
+	Dropped bool
+}
+
+// mapMetricSample applies the mapping matching the name of the sample, if any.
+// It returns false when the sample must be dropped.
+func mapMetricSample(metricMapper *mapper.MetricMapper, sample *dogstatsdMetricSample) bool {
+	mapResult := metricMapper.Map(sample.name)
+	if mapResult == nil {
+		return true
+	}
+	if mapResult.Drop {
+		log.Tracef("Dogstatsd mapper: metric %q dropped", sample.name)
+		return false
+	}
+	log.Tracef("Dogstatsd mapper: metric mapped from %q to %q with tags %v", sample.name, mapResult.Name, mapResult.Tags)
+	sample.name = mapResult.Name
+	sample.tags = mapResult.ApplyTags(sample.tags)
+	// the value of a set can not be converted to another type
+	if mapResult.Type != "" && sample.metricType != setType {
+		sample.metricType = metricTypeNames[mapResult.Type]
+	}
+	return true
+}
+
+// MapMetricMessage parses a DogStatsD metric message and returns how it is mapped by the mapper,
+// it is used to check the mapper configuration.
+func MapMetricMessage(metricMapper *mapper.MetricMapper, message []byte) (MappingResult, error) {
+	parser := newParser(newFloat64ListPool())
+	sample, err := parser.parseMetricSample(message)
+	if err != nil {
+		return MappingResult{}, err
+	}
+	result := MappingResult{
+		Original: newMappedMetric(sample),
+		Matched:  metricMapper.Map(sample.name) != nil,
+	}
+	if !mapMetricSample(metricMapper, &sample) {
+		result.Dropped = true
+		return result, nil
+	}
+	result.Mapped = newMappedMetric(sample)
+	return result, nil
+}
+
+// newMappedMetric copies the tags of the sample since the mapping modifies them in place.
+func newMappedMetric(sample dogstatsdMetricSample) MappedMetric {
+	mapped := MappedMetric{
+		Name: sample.name,
+		Tags: append([]string(nil), sample.tags...),
+	}
+	for name, mtype := range metricTypeNames {
+		if mtype == sample.metricType {
+			mapped.Type = name
+		}
+	}
+	return mapped
+}
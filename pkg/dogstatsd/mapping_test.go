@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
+)
+
+func TestMapMetricMessage(t *testing.T) {
+	metricMapper, err := mapper.NewMetricMapper([]config.MappingProfile{{
+		Name:   "test",
+		Prefix: "test.",
+		Mappings: []config.MetricMapping{
+			{Match: "test.job.debug.*", Action: "drop"},
+			{Match: "test.job.duration.*", Name: "test.job.duration", Type: "distribution", DropTags: []string{"pid"}, Tags: map[string]string{"job": "$1"}},
+		},
+	}}, 10)
+	require.NoError(t, err)
+
+	result, err := MapMetricMessage(metricMapper, []byte("test.job.duration.my_job:12|ms|#pid:123,env:prod"))
+	require.NoError(t, err)
+	assert.True(t, result.Matched)
+	assert.False(t, result.Dropped)
+	assert.Equal(t, MappedMetric{Name: "test.job.duration.my_job", Type: "timing", Tags: []string{"pid:123", "env:prod"}}, result.Original)
+	assert.Equal(t, MappedMetric{Name: "test.job.duration", Type: "distribution", Tags: []string{"env:prod", "job:my_job"}}, result.Mapped)
+
+	result, err = MapMetricMessage(metricMapper, []byte("test.job.debug.my_job:12|g"))
+	require.NoError(t, err)
+	assert.True(t, result.Matched)
+	assert.True(t, result.Dropped)
+
+	result, err = MapMetricMessage(metricMapper, []byte("other.metric:12|c"))
+	require.NoError(t, err)
+	assert.False(t, result.Matched)
+	assert.Equal(t, result.Original, result.Mapped)
+
+	_, err = MapMetricMessage(metricMapper, []byte("invalid"))
+	assert.Error(t, err)
+}
@@ -33,6 +33,7 @@ type dogstatsdEvent struct {
 	sourceType     string
 	alertType      alertType
 	tags           []string
+	containerID    string
 }
 
 type eventHeader struct {
@@ -48,6 +49,7 @@ var (
 	eventSourceTypePrefix     = []byte("s:")
 	eventAlertTypePrefix      = []byte("t:")
 	eventTagsPrefix           = []byte("#")
+	eventContainerIDPrefix    = []byte("c:")
 
 	eventPriorityLow    = []byte("low")
 	eventPriorityNormal = []byte("normal")
@@ -156,6 +158,8 @@ func (p *parser) applyEventOptionalField(event dogstatsdEvent, optionalField []b
 		newEvent.alertType, err = parseEventAlertType(optionalField[len(eventAlertTypePrefix):])
 	case bytes.HasPrefix(optionalField, eventTagsPrefix):
 		newEvent.tags = p.parseTags(optionalField[len(eventTagsPrefix):])
+	case bytes.HasPrefix(optionalField, eventContainerIDPrefix):
+		newEvent.containerID = string(optionalField[len(eventContainerIDPrefix):])
 	}
 	if err != nil {
 		return event, err
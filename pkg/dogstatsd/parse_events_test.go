@@ -246,6 +246,15 @@ func TestEventMetadataMultiple(t *testing.T) {
 	assert.Equal(t, string("source test"), e.sourceType)
 }
 
+func TestEventMetadataContainerID(t *testing.T) {
+	e, err := parseEvent([]byte("_e{10,9}:test title|test text|#tag1,tag2:test|c:container-id"))
+
+	require.Nil(t, err)
+	assert.Equal(t, string("test title"), e.title)
+	assert.Equal(t, []string{string("tag1"), string("tag2:test")}, e.tags)
+	assert.Equal(t, string("container-id"), e.containerID)
+}
+
 func TestEventEmptyTitle(t *testing.T) {
 	_, err := parseEvent([]byte("_e{0,9}:|test text"))
 
@@ -24,8 +24,10 @@ var (
 	setSymbol          = []byte("s")
 	timingSymbol       = []byte("ms")
 
-	tagsFieldPrefix       = []byte("#")
-	sampleRateFieldPrefix = []byte("@")
+	tagsFieldPrefix        = []byte("#")
+	sampleRateFieldPrefix  = []byte("@")
+	timestampFieldPrefix   = []byte("T")
+	containerIDFieldPrefix = []byte("c:")
 )
 
 type dogstatsdMetricSample struct {
@@ -39,6 +41,12 @@ type dogstatsdMetricSample struct {
 	metricType metricType
 	sampleRate float64
 	tags       []string
+	// ts is the unix timestamp supplied by the client, 0 when the sample
+	// is stamped on arrival
+	ts int64
+	// containerID is the ID of the container sending the sample, used
+	// for origin detection when the listener can not detect it
+	containerID string
 }
 
 // sanity checks a given message against the metric sample format
@@ -47,7 +55,7 @@ func hasMetricSampleFormat(message []byte) bool {
 		return false
 	}
 	separatorCount := bytes.Count(message, fieldSeparator)
-	if separatorCount < 1 || separatorCount > 3 {
+	if separatorCount < 1 || separatorCount > 5 {
 		return false
 	}
 	return true
@@ -87,3 +95,14 @@ func parseMetricSampleMetricType(rawMetricType []byte) (metricType, error) {
 func parseMetricSampleSampleRate(rawSampleRate []byte) (float64, error) {
 	return parseFloat64(rawSampleRate)
 }
+
+func parseMetricSampleTimestamp(rawTimestamp []byte) (int64, error) {
+	ts, err := parseInt64(rawTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	if ts <= 0 {
+		return 0, fmt.Errorf("invalid timestamp: %d", ts)
+	}
+	return ts, nil
+}
@@ -18,12 +18,13 @@ const (
 )
 
 type dogstatsdServiceCheck struct {
-	name      string
-	status    serviceCheckStatus
-	timestamp int64
-	hostname  string
-	message   string
-	tags      []string
+	name        string
+	status      serviceCheckStatus
+	timestamp   int64
+	hostname    string
+	message     string
+	tags        []string
+	containerID string
 }
 
 var (
@@ -32,10 +33,11 @@ var (
 	rawServiceCheckStatusCritical = []byte("2")
 	rawServiceCheckStatusUnknown  = []byte("3")
 
-	serviceCheckTimestampPrefix = []byte("d:")
-	serviceCheckHostnamePrefix  = []byte("h:")
-	serviceCheckMessagePrefix   = []byte("m:")
-	serviceCheckTagsPrefix      = []byte("#")
+	serviceCheckTimestampPrefix   = []byte("d:")
+	serviceCheckHostnamePrefix    = []byte("h:")
+	serviceCheckMessagePrefix     = []byte("m:")
+	serviceCheckTagsPrefix        = []byte("#")
+	serviceCheckContainerIDPrefix = []byte("c:")
 )
 
 // sanity checks a given message against the metric sample format
@@ -90,6 +92,8 @@ func (p *parser) applyServiceCheckOptionalField(serviceCheck dogstatsdServiceChe
 		newServiceCheck.tags = p.parseTags(optionalField[len(serviceCheckTagsPrefix):])
 	case bytes.HasPrefix(optionalField, serviceCheckMessagePrefix):
 		newServiceCheck.message = string(optionalField[len(serviceCheckMessagePrefix):])
+	case bytes.HasPrefix(optionalField, serviceCheckContainerIDPrefix):
+		newServiceCheck.containerID = string(optionalField[len(serviceCheckContainerIDPrefix):])
 	}
 	if err != nil {
 		return serviceCheck, err
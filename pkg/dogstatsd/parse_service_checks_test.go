@@ -98,6 +98,14 @@ func TestServiceCheckMetadataMessage(t *testing.T) {
 	assert.Equal(t, []string(nil), sc.tags)
 }
 
+func TestServiceCheckMetadataContainerID(t *testing.T) {
+	sc, err := parseServiceCheck([]byte("_sc|agent.up|0|#tag1:test|c:container-id"))
+	require.Nil(t, err)
+	assert.Equal(t, "agent.up", sc.name)
+	assert.Equal(t, []string{"tag1:test"}, sc.tags)
+	assert.Equal(t, "container-id", sc.containerID)
+}
+
 func TestServiceCheckMetadataMultiple(t *testing.T) {
 	// all type
 	sc, err := parseServiceCheck([]byte("_sc|agent.up|0|d:21|h:localhost|#tag1:test,tag2|m:this is fine"))
@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// MaxCaptureDuration is the maximum duration of a capture.
+	MaxCaptureDuration = 10 * time.Minute
+
+	// captureQueueSize is the number of packets waiting to be written,
+	// the packets received when the queue is full are not captured.
+	captureQueueSize = 4096
+)
+
+// TrafficCapture records the packets received by DogStatsD to a file for a bounded duration.
+type TrafficCapture struct {
+	sync.RWMutex
+	location string
+	// ongoing is protected by the lock, active mirrors it
+	// to check for a capture without locking
+	ongoing bool
+	active  uint32
+	records chan *Record
+	stop    chan struct{}
+	done    chan struct{}
+	dropped uint64
+}
+
+// NewTrafficCapture returns a new traffic capture writing the capture files in location.
+func NewTrafficCapture(location string) *TrafficCapture {
+	return &TrafficCapture{location: location}
+}
+
+// Start starts capturing the packets for the given duration, it returns the path of the capture file.
+func (tc *TrafficCapture) Start(duration time.Duration) (string, error) {
+	if duration <= 0 || duration > MaxCaptureDuration {
+		return "", fmt.Errorf("invalid capture duration %v, it must be positive and at most %v", duration, MaxCaptureDuration)
+	}
+
+	tc.Lock()
+	defer tc.Unlock()
+	if tc.ongoing {
+		return "", fmt.Errorf("a capture is already in progress")
+	}
+
+	if err := os.MkdirAll(tc.location, 0700); err != nil {
+		return "", fmt.Errorf("could not create the capture directory: %v", err)
+	}
+	path := filepath.Join(tc.location, fmt.Sprintf("datadog-capture-%d", time.Now().Unix()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("could not create the capture file: %v", err)
+	}
+	writer, err := NewWriter(file)
+	if err != nil {
+		file.Close()
+		return "", fmt.Errorf("could not write the capture file: %v", err)
+	}
+
+	tc.ongoing = true
+	atomic.StoreUint32(&tc.active, 1)
+	atomic.StoreUint64(&tc.dropped, 0)
+	tc.records = make(chan *Record, captureQueueSize)
+	tc.stop = make(chan struct{})
+	tc.done = make(chan struct{})
+	go tc.run(file, writer, duration, tc.records, tc.stop, tc.done)
+
+	log.Infof("Capturing the DogStatsD traffic to %s for %v", path, duration)
+	return path, nil
+}
+
+// Stop stops the ongoing capture, if any, and waits until the capture file is written.
+func (tc *TrafficCapture) Stop() {
+	tc.RLock()
+	ongoing, stop, done := tc.ongoing, tc.stop, tc.done
+	tc.RUnlock()
+	if !ongoing {
+		return
+	}
+	select {
+	case stop <- struct{}{}:
+	case <-done:
+	}
+	<-done
+}
+
+// IsOngoing returns true if a capture is in progress.
+func (tc *TrafficCapture) IsOngoing() bool {
+	return atomic.LoadUint32(&tc.active) == 1
+}
+
+// Enqueue copies the packets to the capture. It never blocks: the packets
+// are not captured when the capture file can not be written fast enough.
+func (tc *TrafficCapture) Enqueue(packets listeners.Packets) {
+	tc.RLock()
+	defer tc.RUnlock()
+	if !tc.ongoing {
+		return
+	}
+	now := time.Now().UnixNano()
+	for _, packet := range packets {
+		// the packets are reused once processed, their data is copied
+		record := &Record{
+			Timestamp: now,
+			Origin:    packet.Origin,
+			Contents:  append([]byte(nil), packet.Contents...),
+		}
+		if len(packet.Ancillary) > 0 {
+			record.Ancillary = append([]byte(nil), packet.Ancillary...)
+		}
+		select {
+		case tc.records <- record:
+		default:
+			atomic.AddUint64(&tc.dropped, 1)
+		}
+	}
+}
+
+// run writes the records until the end of the capture, it uses the channels of its own
+// capture since they are replaced when a new capture starts.
+func (tc *TrafficCapture) run(file *os.File, writer *Writer, duration time.Duration, records chan *Record, stop, done chan struct{}) {
+	defer close(done)
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	var err error
+	write := func(record *Record) {
+		if err == nil {
+			err = writer.Write(record)
+		}
+	}
+
+	for running := true; running; {
+		select {
+		case record := <-records:
+			write(record)
+		case <-timer.C:
+			running = false
+		case <-stop:
+			running = false
+		}
+	}
+
+	// no packet can be enqueued anymore, write the remaining ones
+	tc.Lock()
+	tc.ongoing = false
+	atomic.StoreUint32(&tc.active, 0)
+	tc.Unlock()
+	close(records)
+	for record := range records {
+		write(record)
+	}
+
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Errorf("Could not write the DogStatsD capture %s: %v", file.Name(), err)
+		return
+	}
+	if dropped := atomic.LoadUint64(&tc.dropped); dropped > 0 {
+		log.Warnf("%d packets were not written to the DogStatsD capture %s, the capture could not keep up with the traffic", dropped, file.Name())
+	}
+	log.Infof("The DogStatsD capture %s is complete", file.Name())
+}
@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
+)
+
+func TestCaptureAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dsd_capture")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	capture := NewTrafficCapture(dir)
+	assert.False(t, capture.IsOngoing())
+	_, err = capture.Start(time.Hour)
+	assert.Error(t, err)
+
+	path, err := capture.Start(time.Minute)
+	require.NoError(t, err)
+	assert.True(t, capture.IsOngoing())
+	_, err = capture.Start(time.Minute)
+	assert.Error(t, err, "only one capture can run at a time")
+
+	capture.Enqueue(listeners.Packets{
+		{Contents: []byte("daemon:666|g"), Origin: "container_id://foo", Ancillary: []byte{1, 2}},
+		{Contents: []byte("daemon:667|g")},
+	})
+	capture.Stop()
+	assert.False(t, capture.IsOngoing())
+	// the packets received once the capture is stopped are ignored
+	capture.Enqueue(listeners.Packets{{Contents: []byte("daemon:668|g")}})
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var records []*Record
+	count, err := Replay(file, true, nil, func(record *Record) {
+		records = append(records, record)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, records, 2)
+	assert.Equal(t, "daemon:666|g", string(records[0].Contents))
+	assert.Equal(t, "container_id://foo", records[0].Origin)
+	assert.Equal(t, []byte{1, 2}, records[0].Ancillary)
+	assert.Equal(t, "daemon:667|g", string(records[1].Contents))
+	assert.Equal(t, "", records[1].Origin)
+}
+
+func TestCaptureStopsAfterDuration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dsd_capture")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	capture := NewTrafficCapture(dir)
+	_, err = capture.Start(10 * time.Millisecond)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return !capture.IsOngoing() }, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// The capture files start with a header made of captureMagic and the version of the format,
+// followed by the records. Each record is prefixed by its length as a 4 bytes little endian
+// unsigned integer and holds, in little endian:
+//   - the reception time of the packet in nanoseconds since epoch, on 8 bytes
+//   - the length of the origin on 4 bytes, then the origin
+//   - the length of the ancillary data on 4 bytes, then the ancillary data
+//   - the contents of the packet
+var captureMagic = []byte("DSDCAP")
+
+const (
+	captureVersion = byte(1)
+
+	// recordFixedSize is the size of the fixed length fields of a record.
+	recordFixedSize = 8 + 4 + 4
+	// maxRecordSize protects the reader against corrupted files.
+	maxRecordSize = 64 * 1024 * 1024
+)
+
+// Record is a packet received by DogStatsD.
+type Record struct {
+	// Timestamp is the reception time of the packet in nanoseconds since epoch
+	Timestamp int64
+	// Origin is the container the packet was received from, if identified
+	Origin string
+	// Ancillary holds the credentials of the sender when read on the Unix socket
+	Ancillary []byte
+	// Contents might contain several messages
+	Contents []byte
+}
+
+// Writer writes records to a capture file.
+type Writer struct {
+	writer *bufio.Writer
+	// buf is used to encode the integers
+	buf [16]byte
+}
+
+// NewWriter returns a new writer, it writes the header of the capture.
+func NewWriter(w io.Writer) (*Writer, error) {
+	writer := &Writer{writer: bufio.NewWriter(w)}
+	if _, err := writer.writer.Write(captureMagic); err != nil {
+		return nil, err
+	}
+	if err := writer.writer.WriteByte(captureVersion); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write writes a record.
+func (w *Writer) Write(record *Record) error {
+	size := recordFixedSize + len(record.Origin) + len(record.Ancillary) + len(record.Contents)
+	binary.LittleEndian.PutUint32(w.buf[0:4], uint32(size))
+	binary.LittleEndian.PutUint64(w.buf[4:12], uint64(record.Timestamp))
+	binary.LittleEndian.PutUint32(w.buf[12:16], uint32(len(record.Origin)))
+	if _, err := w.writer.Write(w.buf[:16]); err != nil {
+		return err
+	}
+	if _, err := w.writer.WriteString(record.Origin); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(w.buf[0:4], uint32(len(record.Ancillary)))
+	if _, err := w.writer.Write(w.buf[:4]); err != nil {
+		return err
+	}
+	if _, err := w.writer.Write(record.Ancillary); err != nil {
+		return err
+	}
+	_, err := w.writer.Write(record.Contents)
+	return err
+}
+
+// Flush writes the buffered records to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.writer.Flush()
+}
+
+// Reader reads the records of a capture file.
+type Reader struct {
+	reader *bufio.Reader
+}
+
+// NewReader returns a new reader, it returns an error if the header of the capture is invalid.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{reader: bufio.NewReader(r)}
+	header := make([]byte, len(captureMagic)+1)
+	if _, err := io.ReadFull(reader.reader, header); err != nil {
+		return nil, fmt.Errorf("could not read the capture header: %v", err)
+	}
+	if !bytes.Equal(header[:len(captureMagic)], captureMagic) {
+		return nil, fmt.Errorf("not a DogStatsD capture")
+	}
+	if version := header[len(captureMagic)]; version != captureVersion {
+		return nil, fmt.Errorf("unsupported capture version %d", version)
+	}
+	return reader, nil
+}
+
+// Read returns the next record, or io.EOF when the whole capture was read.
+func (r *Reader) Read() (*Record, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r.reader, prefix[:]); err != nil {
+		return nil, err
+	}
+	size := binary.LittleEndian.Uint32(prefix[:])
+	if size < recordFixedSize || size > maxRecordSize {
+		return nil, fmt.Errorf("invalid record size %d", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, data); err != nil {
+		return nil, fmt.Errorf("truncated record: %v", err)
+	}
+
+	record := &Record{Timestamp: int64(binary.LittleEndian.Uint64(data[0:8]))}
+	data = data[8:]
+	origin, data, err := readField(data)
+	if err != nil {
+		return nil, err
+	}
+	record.Origin = string(origin)
+	if record.Ancillary, data, err = readField(data); err != nil {
+		return nil, err
+	}
+	record.Contents = data
+	return record, nil
+}
+
+// readField reads a field prefixed by its length and returns the remaining data.
+func readField(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("truncated record")
+	}
+	length := binary.LittleEndian.Uint32(data[0:4])
+	data = data[4:]
+	if uint32(len(data)) < length {
+		return nil, nil, fmt.Errorf("truncated record")
+	}
+	return data[:length], data[length:], nil
+}
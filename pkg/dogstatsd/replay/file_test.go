@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAndReadRecords(t *testing.T) {
+	records := []*Record{
+		{Timestamp: 1, Contents: []byte("daemon:666|g")},
+		{Timestamp: 2, Origin: "container_id://foo", Ancillary: []byte{1, 2, 3}, Contents: []byte("daemon:667|g\ndaemon:668|g")},
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf)
+	require.NoError(t, err)
+	for _, record := range records {
+		require.NoError(t, writer.Write(record))
+	}
+	require.NoError(t, writer.Flush())
+
+	reader, err := NewReader(&buf)
+	require.NoError(t, err)
+	for _, expected := range records {
+		record, err := reader.Read()
+		require.NoError(t, err)
+		assert.Equal(t, expected.Timestamp, record.Timestamp)
+		assert.Equal(t, expected.Origin, record.Origin)
+		assert.Equal(t, string(expected.Ancillary), string(record.Ancillary))
+		assert.Equal(t, expected.Contents, record.Contents)
+	}
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadInvalidCapture(t *testing.T) {
+	_, err := NewReader(bytes.NewBufferString("not a capture"))
+	assert.Error(t, err)
+
+	_, err = NewReader(bytes.NewBufferString("DSDCAP\x02"))
+	assert.Error(t, err)
+
+	// the record is truncated
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(&Record{Timestamp: 1, Contents: []byte("daemon:666|g")}))
+	require.NoError(t, writer.Flush())
+	reader, err := NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-2]))
+	require.NoError(t, err)
+	_, err = reader.Read()
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"io"
+	"time"
+)
+
+// Replay reads the records of a capture and calls handle for each of them, it returns the
+// number of records replayed. When paced is true, it waits between two records for the time
+// elapsed between their reception. The replay is interrupted when stop is closed.
+func Replay(r io.Reader, paced bool, stop <-chan struct{}, handle func(*Record)) (int, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var start time.Time
+	var firstTimestamp int64
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+
+		if paced {
+			if count == 0 {
+				start = time.Now()
+				firstTimestamp = record.Timestamp
+			}
+			// the delay is computed from the start of the replay so that it doesn't drift
+			delay := time.Until(start.Add(time.Duration(record.Timestamp - firstTimestamp)))
+			if delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-stop:
+					timer.Stop()
+					return count, nil
+				}
+			}
+		}
+
+		select {
+		case <-stop:
+			return count, nil
+		default:
+		}
+		handle(record)
+		count++
+	}
+}
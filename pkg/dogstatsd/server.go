@@ -11,6 +11,7 @@ import (
 	"expvar"
 	"fmt"
 	"net"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
@@ -23,6 +24,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/replay"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
@@ -39,6 +41,8 @@ var (
 	dogstatsdEventPackets             = expvar.Int{}
 	dogstatsdMetricParseErrors        = expvar.Int{}
 	dogstatsdMetricPackets            = expvar.Int{}
+	dogstatsdMetricTimestampErrors    = expvar.Int{}
+	dogstatsdMetricMapperDrops        = expvar.Int{}
 	dogstatsdPacketsLastSec           = expvar.Int{}
 	dogstatsdUnterminatedMetricErrors = expvar.Int{}
 
@@ -48,6 +52,10 @@ var (
 	tlmProcessedOkTags    = map[string]string{"message_type": "metrics", "state": "ok"}
 )
 
+// maxTimestampSkew is the tolerated clock skew between the clients and the agent
+// for the samples stamped by the client.
+const maxTimestampSkew = 10 * time.Second
+
 func init() {
 	dogstatsdExpvars.Set("ServiceCheckParseErrors", &dogstatsdServiceCheckParseErrors)
 	dogstatsdExpvars.Set("ServiceCheckPackets", &dogstatsdServiceCheckPackets)
@@ -55,6 +63,8 @@ func init() {
 	dogstatsdExpvars.Set("EventPackets", &dogstatsdEventPackets)
 	dogstatsdExpvars.Set("MetricParseErrors", &dogstatsdMetricParseErrors)
 	dogstatsdExpvars.Set("MetricPackets", &dogstatsdMetricPackets)
+	dogstatsdExpvars.Set("MetricTimestampErrors", &dogstatsdMetricTimestampErrors)
+	dogstatsdExpvars.Set("MetricMapperDrops", &dogstatsdMetricMapperDrops)
 	dogstatsdExpvars.Set("UnterminatedMetricErrors", &dogstatsdUnterminatedMetricErrors)
 }
 
@@ -87,6 +97,12 @@ type Server struct {
 	eolTerminationEnabled     bool
 	telemetryEnabled          bool
 	entityIDPrecedenceEnabled bool
+	// timestampMaxAge is the maximum age of the samples stamped by the client,
+	// the older samples are dropped.
+	timestampMaxAge time.Duration
+	// capture records the packets received to a file when a capture is ongoing
+	capture   *replay.TrafficCapture
+	replaying uint32
 	// disableVerboseLogs is a feature flag to disable the logs capable
 	// of flooding the logger output (e.g. parsing messages error).
 	// NOTE(remy): this should probably be dropped and use a throttler logger, see
@@ -167,6 +183,15 @@ func NewServer(aggregator *aggregator.BufferedAggregator, extraTags []string) (*
 			udsListenerRunning = true
 		}
 	}
+	streamSocketPath := config.Datadog.GetString("dogstatsd_stream_socket")
+	if len(streamSocketPath) > 0 {
+		unixStreamListener, err := listeners.NewUDSStreamListener(packetsChannel, sharedPacketPool)
+		if err != nil {
+			log.Errorf(err.Error())
+		} else {
+			tmpListeners = append(tmpListeners, unixStreamListener)
+		}
+	}
 	if config.Datadog.GetInt("dogstatsd_port") > 0 {
 		udpListener, err := listeners.NewUDPListener(packetsChannel, sharedPacketPool)
 		if err != nil {
@@ -176,6 +201,15 @@ func NewServer(aggregator *aggregator.BufferedAggregator, extraTags []string) (*
 		}
 	}
 
+	if config.Datadog.GetInt("dogstatsd_tcp_port") > 0 {
+		tcpListener, err := listeners.NewTCPListener(packetsChannel, sharedPacketPool)
+		if err != nil {
+			log.Errorf(err.Error())
+		} else {
+			tmpListeners = append(tmpListeners, tcpListener)
+		}
+	}
+
 	pipeName := config.Datadog.GetString("dogstatsd_pipe_name")
 	if len(pipeName) > 0 {
 		namedPipeListener, err := listeners.NewNamedPipeListener(pipeName, packetsChannel, sharedPacketPool)
@@ -211,6 +245,11 @@ func NewServer(aggregator *aggregator.BufferedAggregator, extraTags []string) (*
 
 	entityIDPrecedenceEnabled := config.Datadog.GetBool("dogstatsd_entity_id_precedence")
 
+	captureLocation := config.Datadog.GetString("dogstatsd_capture_path")
+	if captureLocation == "" {
+		captureLocation = filepath.Join(config.Datadog.GetString("run_path"), "dsd_capture")
+	}
+
 	s := &Server{
 		Started:                   true,
 		Statistics:                stats,
@@ -227,10 +266,12 @@ func NewServer(aggregator *aggregator.BufferedAggregator, extraTags []string) (*
 		histToDist:                histToDist,
 		histToDistPrefix:          histToDistPrefix,
 		extraTags:                 extraTags,
+		capture:                   replay.NewTrafficCapture(captureLocation),
 		eolTerminationEnabled:     config.Datadog.GetBool("dogstatsd_eol_required"),
 		telemetryEnabled:          telemetry_utils.IsEnabled(),
 		entityIDPrecedenceEnabled: entityIDPrecedenceEnabled,
 		disableVerboseLogs:        config.Datadog.GetBool("dogstatsd_disable_verbose_logs"),
+		timestampMaxAge:           time.Duration(config.Datadog.GetInt("dogstatsd_timestamp_max_age")) * time.Second,
 		Debug: &dsdServerDebug{
 			Stats: make(map[ckey.ContextKey]metricStat),
 			metricsCounts: metricsCountBuckets{
@@ -464,13 +505,17 @@ func (s *Server) parseMetricMessage(metricSamples []metrics.MetricSample, parser
 		tlmProcessed.IncWithTags(tlmProcessedErrorTags)
 		return metricSamples, err
 	}
-	if s.mapper != nil {
-		mapResult := s.mapper.Map(sample.name)
-		if mapResult != nil {
-			log.Tracef("Dogstatsd mapper: metric mapped from %q to %q with tags %v", sample.name, mapResult.Name, mapResult.Tags)
-			sample.name = mapResult.Name
-			sample.tags = append(sample.tags, mapResult.Tags...)
+	if sample.ts > 0 && !s.isTimestampAccepted(sample.ts) {
+		dogstatsdMetricTimestampErrors.Add(1)
+		tlmProcessed.IncWithTags(tlmProcessedErrorTags)
+		return metricSamples, fmt.Errorf("timestamp %d is outside of the acceptance window", sample.ts)
+	}
+	if s.mapper != nil && !mapMetricSample(s.mapper, &sample) {
+		if len(sample.values) > 0 {
+			s.sharedFloat64List.put(sample.values)
 		}
+		dogstatsdMetricMapperDrops.Add(1)
+		return metricSamples, nil
 	}
 	metricSamples = enrichMetricSample(metricSamples, sample, s.metricPrefix, s.metricPrefixBlacklist, s.defaultHostname, origin, s.entityIDPrecedenceEnabled, s.ServerlessMode)
 
@@ -492,6 +537,14 @@ func (s *Server) parseMetricMessage(metricSamples []metrics.MetricSample, parser
 	return metricSamples, nil
 }
 
+// isTimestampAccepted returns true if a sample stamped by the client at ts is not older than
+// timestampMaxAge, nor in the future beyond the tolerated clock skew.
+func (s *Server) isTimestampAccepted(ts int64) bool {
+	now := time.Now()
+	sampleTime := time.Unix(ts, 0)
+	return !sampleTime.Before(now.Add(-s.timestampMaxAge)) && !sampleTime.After(now.Add(maxTimestampSkew))
+}
+
 func (s *Server) parseEventMessage(parser *parser, message []byte, origin string) (*metrics.Event, error) {
 	sample, err := parser.parseEvent(message)
 	if err != nil {
@@ -526,6 +579,7 @@ func (s *Server) Stop() {
 	for _, l := range s.listeners {
 		l.Stop()
 	}
+	s.capture.Stop()
 	if s.Statistics != nil {
 		s.Statistics.Stop()
 	}
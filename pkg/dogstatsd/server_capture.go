@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/replay"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Capture records the packets received by the server during the given duration,
+// it returns the path of the capture file written in the background.
+func (s *Server) Capture(duration time.Duration) (string, error) {
+	return s.capture.Start(duration)
+}
+
+// Replay feeds the packets of a capture file to the server as if they were received by
+// its listeners, with their original origin. When paced is true, the packets are replayed
+// at the pace they were received. The replay runs in the background, only one capture
+// can be replayed at a time.
+func (s *Server) Replay(path string, paced bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open the capture: %v", err)
+	}
+	if !atomic.CompareAndSwapUint32(&s.replaying, 0, 1) {
+		file.Close()
+		return fmt.Errorf("a capture is already being replayed")
+	}
+
+	go func() {
+		defer atomic.StoreUint32(&s.replaying, 0)
+		defer file.Close()
+
+		// interrupt the replay when the server stops
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-s.stopChan:
+				close(stop)
+			case <-done:
+			}
+		}()
+
+		log.Infof("Replaying the DogStatsD capture %s", path)
+		count, err := replay.Replay(file, paced, stop, func(record *replay.Record) {
+			packet := s.sharedPacketPool.Get()
+			packet.Contents = record.Contents
+			packet.Origin = record.Origin
+			packet.Ancillary = append(packet.Ancillary[:0], record.Ancillary...)
+			select {
+			case s.packetsIn <- listeners.Packets{packet}:
+			case <-s.stopChan:
+			}
+		})
+		if err != nil {
+			log.Errorf("Could not replay the DogStatsD capture %s after %d packets: %v", path, count, err)
+			return
+		}
+		log.Infof("Replayed %d packets of the DogStatsD capture %s", count, path)
+	}()
+	return nil
+}
@@ -8,8 +8,10 @@ package dogstatsd
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -516,6 +518,94 @@ func TestE2EParsing(t *testing.T) {
 	}
 }
 
+func TestTimestampedMetrics(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+
+	agg := mockAggregator()
+	metricOut, _, _ := agg.GetBufferedChannels()
+	metricWithTsOut := agg.GetBufferedMetricsWithTsChannel()
+	s, err := NewServer(agg, nil)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	url := fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port"))
+	conn, err := net.Dial("udp", url)
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	// the samples outside of the acceptance window are dropped
+	ts := time.Now().Add(-time.Minute).Unix()
+	tooOld := time.Now().Add(-time.Hour).Unix()
+	inFuture := time.Now().Add(time.Hour).Unix()
+	conn.Write([]byte(fmt.Sprintf("daemon:1|g|T%d\ndaemon:2|g|T%d\ndaemon:666|g|#foo:bar|T%d\ndaemon:667|g", tooOld, inFuture, ts)))
+	select {
+	case res := <-metricWithTsOut:
+		require.Equal(t, 1, len(res))
+		assert.EqualValues(t, 666.0, res[0].Value)
+		assert.Equal(t, []string{"foo:bar"}, res[0].Tags)
+		assert.Equal(t, float64(ts)*float64(time.Second), res[0].Timestamp)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+	select {
+	case res := <-metricOut:
+		require.Equal(t, 1, len(res))
+		assert.EqualValues(t, 667.0, res[0].Value)
+		assert.Zero(t, res[0].Timestamp)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+}
+
+func TestCaptureAndReplay(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+	dir, err := ioutil.TempDir("", "dsd_capture")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	config.Datadog.Set("dogstatsd_capture_path", dir)
+	defer config.Datadog.Set("dogstatsd_capture_path", "")
+
+	agg := mockAggregator()
+	metricOut, _, _ := agg.GetBufferedChannels()
+	s, err := NewServer(agg, nil)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	path, err := s.Capture(time.Minute)
+	require.NoError(t, err)
+
+	conn, err := net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+	conn.Write([]byte("daemon:666|g|#sometag1:somevalue1"))
+	select {
+	case res := <-metricOut:
+		require.Equal(t, 1, len(res))
+		assert.Equal(t, "daemon", res[0].Name)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+	s.capture.Stop()
+
+	// the captured packet is processed again
+	require.NoError(t, s.Replay(path, false))
+	select {
+	case res := <-metricOut:
+		require.Equal(t, 1, len(res))
+		assert.Equal(t, "daemon", res[0].Name)
+		assert.EqualValues(t, 666.0, res[0].Value)
+		assert.Equal(t, []string{"sometag1:somevalue1"}, res[0].Tags)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+
+	assert.Error(t, s.Replay(filepath.Join(dir, "missing"), false))
+}
+
 func TestExtraTags(t *testing.T) {
 	port, err := getAvailableUDPPort()
 	require.NoError(t, err)
@@ -757,6 +847,37 @@ dogstatsd_mapper_profiles:
 			},
 			expectedCacheSize: 1000,
 		},
+		{
+			name: "Drop, tags removal and type override",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.debug.*"
+        action: drop
+      - match: 'test\.job\.duration\.(?P<job>\w+)'
+        match_type: regex
+        name: "test.job.duration"
+        type: distribution
+        drop_tags:
+          - pid
+        rename_tags:
+          env: environment
+        tags:
+          job: "${job}"
+`,
+			packets: []string{
+				"test.job.debug.my_job:666|g",
+				"test.job.duration.my_job:666|ms|#pid:123,env:prod,some:tag",
+				"test.job.duration.other_job:666|ms|#pid:456",
+			},
+			expectedSamples: []MetricSample{
+				{Name: "test.job.duration", Tags: []string{"environment:prod", "some:tag", "job:my_job"}, Mtype: metrics.DistributionType, Value: 666.0},
+				{Name: "test.job.duration", Tags: []string{"job:other_job"}, Mtype: metrics.DistributionType, Value: 666.0},
+			},
+			expectedCacheSize: 1000,
+		},
 		{
 			name: "Cache size",
 			config: `
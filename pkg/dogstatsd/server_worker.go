@@ -44,6 +44,9 @@ func (w *worker) run() {
 			return
 		case <-w.server.health.C:
 		case packets := <-w.server.packetsIn:
+			if w.server.capture.IsOngoing() {
+				w.server.capture.Enqueue(packets)
+			}
 			w.samples = w.samples[0:0]
 			// we return the samples in case the slice was extended
 			// when parsing the packets
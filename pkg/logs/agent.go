@@ -18,11 +18,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/logs/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/channel"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/container"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/file"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/journald"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/listener"
+	"github.com/DataDog/datadog-agent/pkg/logs/input/syslog"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/traps"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/windowsevent"
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
@@ -41,13 +43,14 @@ type Agent struct {
 	auditor                   auditor.Auditor
 	destinationsCtx           *client.DestinationsContext
 	pipelineProvider          pipeline.Provider
+	forwarders                *forwarder.Forwarders
 	inputs                    []restart.Restartable
 	health                    *health.Handle
 	diagnosticMessageReceiver *diagnostic.BufferedMessageReceiver
 }
 
 // NewAgent returns a new Logs Agent
-func NewAgent(sources *config.LogSources, services *service.Services, processingRules []*config.ProcessingRule, endpoints *config.Endpoints) *Agent {
+func NewAgent(sources *config.LogSources, services *service.Services, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, forwarderConfigs []*config.ForwarderConfig, dedicatedPipelineConfigs []*config.DedicatedPipelineConfig) *Agent {
 	health := health.RegisterLiveness("logs-agent")
 
 	// setup the auditor
@@ -58,8 +61,21 @@ func NewAgent(sources *config.LogSources, services *service.Services, processing
 	destinationsCtx := client.NewDestinationsContext()
 	diagnosticMessageReceiver := diagnostic.NewBufferedMessageReceiver()
 
+	// setup the forwarders sending a copy of the logs to non-Datadog destinations
+	forwarders := forwarder.NewForwarders(forwarderConfigs, destinationsCtx)
+
 	// setup the pipeline provider that provides pairs of processor and sender
-	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, diagnosticMessageReceiver, processingRules, endpoints, destinationsCtx)
+	options := pipeline.ProviderOptions{
+		DedicatedPipelineConfigs: dedicatedPipelineConfigs,
+	}
+	if config.UseDiskBuffer() {
+		options.DiskBufferPath = config.DiskBufferPath()
+		options.DiskBufferMaxSize = config.DiskBufferMaxSize()
+	}
+	if len(forwarderConfigs) > 0 {
+		options.Forwarder = forwarders
+	}
+	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, diagnosticMessageReceiver, processingRules, endpoints, destinationsCtx, options)
 
 	// setup the inputs
 	inputs := []restart.Restartable{
@@ -75,12 +91,14 @@ func NewAgent(sources *config.LogSources, services *service.Services, processing
 		journald.NewLauncher(sources, pipelineProvider, auditor),
 		windowsevent.NewLauncher(sources, pipelineProvider),
 		traps.NewLauncher(sources, pipelineProvider),
+		syslog.NewLauncher(sources, pipelineProvider),
 	}
 
 	return &Agent{
 		auditor:                   auditor,
 		destinationsCtx:           destinationsCtx,
 		pipelineProvider:          pipelineProvider,
+		forwarders:                forwarders,
 		inputs:                    inputs,
 		health:                    health,
 		diagnosticMessageReceiver: diagnosticMessageReceiver,
@@ -111,6 +129,7 @@ func NewServerless(sources *config.LogSources, services *service.Services, proce
 		auditor:                   auditor,
 		destinationsCtx:           destinationsCtx,
 		pipelineProvider:          pipelineProvider,
+		forwarders:                forwarder.NewForwarders(nil, destinationsCtx),
 		inputs:                    inputs,
 		health:                    health,
 		diagnosticMessageReceiver: diagnosticMessageReceiver,
@@ -120,7 +139,7 @@ func NewServerless(sources *config.LogSources, services *service.Services, proce
 // Start starts all the elements of the data pipeline
 // in the right order to prevent data loss
 func (a *Agent) Start() {
-	starter := restart.NewStarter(a.destinationsCtx, a.auditor, a.pipelineProvider, a.diagnosticMessageReceiver)
+	starter := restart.NewStarter(a.destinationsCtx, a.auditor, a.forwarders, a.pipelineProvider, a.diagnosticMessageReceiver)
 	for _, input := range a.inputs {
 		starter.Add(input)
 	}
@@ -142,6 +161,7 @@ func (a *Agent) Stop() {
 	stopper := restart.NewSerialStopper(
 		inputs,
 		a.pipelineProvider,
+		a.forwarders,
 		a.auditor,
 		a.destinationsCtx,
 		a.diagnosticMessageReceiver,
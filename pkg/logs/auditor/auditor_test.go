@@ -103,6 +103,18 @@ func (suite *AuditorTestSuite) TestAuditorRecoversRegistryForOffset() {
 	suite.Equal("", offset)
 }
 
+func (suite *AuditorTestSuite) TestAuditorReturnsLastUpdated() {
+	lastUpdated := time.Date(2006, time.January, 12, 1, 1, 1, 1, time.UTC)
+	suite.a.registry = make(map[string]*RegistryEntry)
+	suite.a.registry[suite.source.Config.Path] = &RegistryEntry{
+		LastUpdated: lastUpdated,
+		Offset:      "42",
+	}
+
+	suite.Equal(lastUpdated, suite.a.GetLastUpdated(suite.source.Config.Path))
+	suite.True(suite.a.GetLastUpdated("anotherpath").IsZero())
+}
+
 func (suite *AuditorTestSuite) TestAuditorCleansupRegistry() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.a.registry[suite.source.Config.Path] = &RegistryEntry{
@@ -5,10 +5,13 @@
 
 package mock
 
+import "time"
+
 // Registry does nothing
 type Registry struct {
 	offset      string
 	tailingMode string
+	lastUpdated time.Time
 }
 
 // NewRegistry returns a new registry.
@@ -35,3 +38,13 @@ func (r *Registry) GetTailingMode(identifier string) string {
 func (r *Registry) SetTailingMode(tailingMode string) {
 	r.tailingMode = tailingMode
 }
+
+// GetLastUpdated returns the time of the last commit.
+func (r *Registry) GetLastUpdated(identifier string) time.Time {
+	return r.lastUpdated
+}
+
+// SetLastUpdated sets the time of the last commit.
+func (r *Registry) SetLastUpdated(lastUpdated time.Time) {
+	r.lastUpdated = lastUpdated
+}
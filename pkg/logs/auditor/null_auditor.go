@@ -1,6 +1,8 @@
 package auditor
 
 import (
+	"time"
+
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
@@ -27,6 +29,9 @@ func (a *NullAuditor) GetOffset(identifier string) string { return "" }
 // GetTailingMode returns an empty string.
 func (a *NullAuditor) GetTailingMode(identifier string) string { return "" }
 
+// GetLastUpdated returns the zero time.
+func (a *NullAuditor) GetLastUpdated(identifier string) time.Time { return time.Time{} }
+
 // Start starts the NullAuditor main loop.
 func (a *NullAuditor) Start() {
 	go a.run()
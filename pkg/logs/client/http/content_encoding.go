@@ -8,6 +8,7 @@ package http
 import (
 	"bytes"
 	"compress/gzip"
+	"compress/zlib"
 )
 
 // ContentEncoding encodes the payload
@@ -71,3 +72,43 @@ func (c *GzipContentEncoding) encode(payload []byte) ([]byte, error) {
 	}
 	return compressedPayload.Bytes(), nil
 }
+
+// DeflateContentEncoding encodes the payload using the deflate algorithm,
+// wrapped in the zlib format as expected by the deflate HTTP content coding.
+type DeflateContentEncoding struct {
+	level int
+}
+
+// NewDeflateContentEncoding creates a new Deflate content type
+func NewDeflateContentEncoding(level int) *DeflateContentEncoding {
+	if level < zlib.NoCompression {
+		level = zlib.NoCompression
+	} else if level > zlib.BestCompression {
+		level = zlib.BestCompression
+	}
+
+	return &DeflateContentEncoding{
+		level,
+	}
+}
+
+func (c *DeflateContentEncoding) name() string {
+	return "deflate"
+}
+
+func (c *DeflateContentEncoding) encode(payload []byte) ([]byte, error) {
+	var compressedPayload bytes.Buffer
+	zlibWriter, err := zlib.NewWriterLevel(&compressedPayload, c.level)
+	if err != nil {
+		return nil, err
+	}
+	_, err = zlibWriter.Write(payload)
+	if err != nil {
+		return nil, err
+	}
+	err = zlibWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return compressedPayload.Bytes(), nil
+}
@@ -0,0 +1,17 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !zstd
+
+package http
+
+import (
+	"errors"
+)
+
+// NewZstdContentEncoding returns an error as the agent is built without zstd support
+func NewZstdContentEncoding(level int) (ContentEncoding, error) {
+	return nil, errors.New("zstd compression is not supported by this build of the agent")
+}
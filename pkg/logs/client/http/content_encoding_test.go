@@ -8,6 +8,7 @@ package http
 import (
 	"bytes"
 	"compress/gzip"
+	"compress/zlib"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -42,6 +43,25 @@ func TestGzipContentEncodingName(t *testing.T) {
 	assert.Equal(t, NewGzipContentEncoding(gzip.BestCompression).name(), "gzip")
 }
 
+func TestDeflateContentEncoding(t *testing.T) {
+	payload := []byte("my payload")
+
+	encodedPayload, err := NewDeflateContentEncoding(zlib.BestCompression).encode(payload)
+	assert.Nil(t, err)
+
+	reader, err := zlib.NewReader(bytes.NewReader(encodedPayload))
+	assert.Nil(t, err)
+	var buffer bytes.Buffer
+	_, err = buffer.ReadFrom(reader)
+	assert.Nil(t, err)
+
+	assert.Equal(t, payload, buffer.Bytes())
+}
+
+func TestDeflateContentEncodingName(t *testing.T) {
+	assert.Equal(t, NewDeflateContentEncoding(zlib.BestCompression).name(), "deflate")
+}
+
 func decompress(payload []byte) ([]byte, error) {
 	reader, err := gzip.NewReader(bytes.NewReader(payload))
 	if err != nil {
@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build zstd
+
+package http
+
+import (
+	"testing"
+
+	zstd "github.com/DataDog/zstd"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestZstdContentEncoding(t *testing.T) {
+	payload := []byte("my payload")
+
+	contentEncoding, err := NewZstdContentEncoding(zstd.BestCompression)
+	assert.Nil(t, err)
+	encodedPayload, err := contentEncoding.encode(payload)
+	assert.Nil(t, err)
+
+	decompressedPayload, err := zstd.Decompress(nil, encodedPayload)
+	assert.Nil(t, err)
+
+	assert.Equal(t, payload, decompressedPayload)
+}
+
+func TestBuildZstdContentEncoding(t *testing.T) {
+	assert.Equal(t, "zstd", buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: config.ZstdCompressionKind}).name())
+}
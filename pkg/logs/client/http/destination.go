@@ -22,6 +22,8 @@ import (
 const (
 	TextContentType = "text/plain"
 	JSONContentType = "application/json"
+	// NDJSONContentType is used to send newline delimited JSON objects.
+	NDJSONContentType = "application/x-ndjson"
 )
 
 // HTTP errors.
@@ -50,6 +52,18 @@ func NewDestination(endpoint config.Endpoint, contentType string, destinationsCo
 	return newDestination(endpoint, contentType, destinationsContext, time.Second*10)
 }
 
+// NewDestinationWithURL returns a new Destination sending the uncompressed payloads to url,
+// it is used to forward the logs to endpoints that are not a Datadog intake.
+func NewDestinationWithURL(url string, contentType string, destinationsContext *client.DestinationsContext) *Destination {
+	return &Destination{
+		url:                 url,
+		contentType:         contentType,
+		contentEncoding:     IdentityContentType,
+		client:              httputils.NewResetClient(0, httpClientFactory(time.Second*10)),
+		destinationsContext: destinationsContext,
+	}
+}
+
 func newDestination(endpoint config.Endpoint, contentType string, destinationsContext *client.DestinationsContext, timeout time.Duration) *Destination {
 	return &Destination{
 		url:                 buildURL(endpoint),
@@ -165,10 +179,24 @@ func buildURL(endpoint config.Endpoint) string {
 }
 
 func buildContentEncoding(endpoint config.Endpoint) ContentEncoding {
-	if endpoint.UseCompression {
+	if !endpoint.UseCompression {
+		return IdentityContentType
+	}
+	switch endpoint.CompressionKind {
+	case config.GzipCompressionKind, "":
 		return NewGzipContentEncoding(endpoint.CompressionLevel)
+	case config.DeflateCompressionKind:
+		return NewDeflateContentEncoding(endpoint.CompressionLevel)
+	case config.ZstdCompressionKind:
+		contentEncoding, err := NewZstdContentEncoding(endpoint.CompressionLevel)
+		if err == nil {
+			return contentEncoding
+		}
+		log.Warnf("Could not use zstd compression for %s, falling back to gzip: %v", endpoint.Host, err)
+	default:
+		log.Warnf("Unknown compression kind %s for %s, falling back to gzip", endpoint.CompressionKind, endpoint.Host)
 	}
-	return IdentityContentType
+	return NewGzipContentEncoding(endpoint.CompressionLevel)
 }
 
 // CheckConnectivity check if sending logs through HTTP works
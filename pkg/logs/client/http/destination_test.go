@@ -6,6 +6,7 @@
 package http
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -81,6 +82,14 @@ func TestBuildURLShouldReturnAddressWithPortWhenDefined(t *testing.T) {
 	assert.Equal(t, "http://foo:1234/v1/input/bar", url)
 }
 
+func TestBuildContentEncoding(t *testing.T) {
+	assert.Equal(t, IdentityContentType, buildContentEncoding(config.Endpoint{CompressionKind: config.DeflateCompressionKind}))
+	assert.Equal(t, "gzip", buildContentEncoding(config.Endpoint{UseCompression: true}).name())
+	assert.Equal(t, "gzip", buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: config.GzipCompressionKind}).name())
+	assert.Equal(t, "deflate", buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: config.DeflateCompressionKind}).name())
+	assert.Equal(t, "gzip", buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: "lz4"}).name())
+}
+
 func TestDestinationSend200(t *testing.T) {
 	server := NewHTTPServerTest(200)
 	err := server.destination.Send([]byte("yo"))
@@ -108,6 +117,26 @@ func TestDestinationSend400(t *testing.T) {
 	server.stop()
 }
 
+func TestDestinationWithURL(t *testing.T) {
+	var contentType, path string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		path = r.URL.Path
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+	destCtx := client.NewDestinationsContext()
+	destCtx.Start()
+	defer destCtx.Stop()
+
+	destination := NewDestinationWithURL(ts.URL+"/logs", NDJSONContentType, destCtx)
+	assert.Nil(t, destination.Send([]byte("{\"message\":\"yo\"}")))
+	assert.Equal(t, NDJSONContentType, contentType)
+	assert.Equal(t, "/logs", path)
+	assert.Equal(t, "{\"message\":\"yo\"}", string(body))
+}
+
 func TestConnectivityCheck(t *testing.T) {
 	// Connectivity is ok when server return 200
 	server := NewHTTPServerTest(200)
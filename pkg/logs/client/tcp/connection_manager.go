@@ -56,20 +56,10 @@ func (cm *ConnectionManager) NewConnection(ctx context.Context) (net.Conn, error
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
 
-	cm.firstConn.Do(func() {
-		if cm.endpoint.ProxyAddress != "" {
-			log.Infof("Connecting to the backend: %v, via socks5: %v, with SSL: %v", cm.address(), cm.endpoint.ProxyAddress, cm.endpoint.UseSSL)
-		} else {
-			log.Infof("Connecting to the backend: %v, with SSL: %v", cm.address(), cm.endpoint.UseSSL)
-		}
-	})
+	cm.logFirstConnection()
 
 	var retries uint
-	var err error
 	for {
-		if err != nil {
-			status.AddGlobalWarning(statusConnectionError, fmt.Sprintf("Connection to the log intake cannot be established: %v", err))
-		}
 		if retries > 0 {
 			log.Debugf("Connect attempt #%d", retries)
 			cm.backoff(ctx, retries)
@@ -85,45 +75,91 @@ func (cm *ConnectionManager) NewConnection(ctx context.Context) (net.Conn, error
 			// Continue.
 		}
 
-		var conn net.Conn
+		conn, err := cm.connect(ctx)
+		if err != nil {
+			cm.handleConnectionError(err)
+			continue
+		}
+		return conn, nil
+	}
+}
+
+// TryNewConnection returns an initialized connection to the intake,
+// unlike NewConnection it makes a single attempt and returns an error when it fails.
+func (cm *ConnectionManager) TryNewConnection(ctx context.Context) (net.Conn, error) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.logFirstConnection()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	conn, err := cm.connect(ctx)
+	if err != nil {
+		cm.handleConnectionError(err)
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (cm *ConnectionManager) logFirstConnection() {
+	cm.firstConn.Do(func() {
 		if cm.endpoint.ProxyAddress != "" {
-			var dialer proxy.Dialer
-			dialer, err = proxy.SOCKS5("tcp", cm.endpoint.ProxyAddress, nil, proxy.Direct)
-			if err != nil {
-				log.Warn(err)
-				continue
-			}
-			// TODO: handle timeouts with ctx.
-			conn, err = dialer.Dial("tcp", cm.address())
+			log.Infof("Connecting to the backend: %v, via socks5: %v, with SSL: %v", cm.address(), cm.endpoint.ProxyAddress, cm.endpoint.UseSSL)
 		} else {
-			var dialer net.Dialer
-			dctx, cancel := context.WithTimeout(ctx, connectionTimeout)
-			defer cancel()
-			conn, err = dialer.DialContext(dctx, "tcp", cm.address())
+			log.Infof("Connecting to the backend: %v, with SSL: %v", cm.address(), cm.endpoint.UseSSL)
 		}
+	})
+}
+
+func (cm *ConnectionManager) handleConnectionError(err error) {
+	log.Warn(err)
+	status.AddGlobalWarning(statusConnectionError, fmt.Sprintf("Connection to the log intake cannot be established: %v", err))
+}
+
+// connect makes one attempt to open a connection to the intake.
+func (cm *ConnectionManager) connect(ctx context.Context) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if cm.endpoint.ProxyAddress != "" {
+		var dialer proxy.Dialer
+		dialer, err = proxy.SOCKS5("tcp", cm.endpoint.ProxyAddress, nil, proxy.Direct)
 		if err != nil {
-			log.Warn(err)
-			continue
-		}
-		log.Debugf("connected to %v", cm.address())
-
-		if cm.endpoint.UseSSL {
-			sslConn := tls.Client(conn, &tls.Config{
-				ServerName: cm.endpoint.Host,
-			})
-			err = cm.handshakeWithTimeout(sslConn, connectionTimeout)
-			if err != nil {
-				log.Warn(err)
-				continue
-			}
-			log.Debug("SSL handshake successful")
-			conn = sslConn
+			return nil, err
 		}
+		// TODO: handle timeouts with ctx.
+		conn, err = dialer.Dial("tcp", cm.address())
+	} else {
+		var dialer net.Dialer
+		dctx, cancel := context.WithTimeout(ctx, connectionTimeout)
+		defer cancel()
+		conn, err = dialer.DialContext(dctx, "tcp", cm.address())
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("connected to %v", cm.address())
 
-		go cm.handleServerClose(conn)
-		status.RemoveGlobalWarning(statusConnectionError)
-		return conn, nil
+	if cm.endpoint.UseSSL {
+		sslConn := tls.Client(conn, &tls.Config{
+			ServerName: cm.endpoint.Host,
+		})
+		err = cm.handshakeWithTimeout(sslConn, connectionTimeout)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		log.Debug("SSL handshake successful")
+		conn = sslConn
 	}
+
+	go cm.handleServerClose(conn)
+	status.RemoveGlobalWarning(statusConnectionError)
+	return conn, nil
 }
 
 func (cm *ConnectionManager) handshakeWithTimeout(conn *tls.Conn, timeout time.Duration) error {
@@ -86,3 +86,34 @@ func TestShouldResetDisabled(t *testing.T) {
 	assert.False(t, connManager.ShouldReset(time.Now().Add(-time.Duration(5)*time.Second)))
 	assert.False(t, connManager.ShouldReset(time.Now().Add(-time.Duration(20)*time.Second)))
 }
+
+func TestTryNewConnectionFailsFast(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := l.Addr()
+	l.Close()
+
+	destinationsCtx := client.NewDestinationsContext()
+	destinationsCtx.Start()
+	defer destinationsCtx.Stop()
+
+	connManager := newConnectionManagerForAddr(addr)
+	conn, err := connManager.TryNewConnection(destinationsCtx.Context())
+	assert.Nil(t, conn)
+	assert.Error(t, err)
+}
+
+func TestFailFastDestinationReturnsRetryableError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := l.Addr()
+	l.Close()
+
+	destinationsCtx := client.NewDestinationsContext()
+	destinationsCtx.Start()
+	defer destinationsCtx.Stop()
+
+	destination := NewFailFastDestination(AddrToEndPoint(addr), true, destinationsCtx)
+	err = destination.Send([]byte("foo"))
+	assert.IsType(t, &client.RetryableError{}, err)
+}
@@ -8,6 +8,7 @@ package tcp
 import (
 	"bytes"
 	"encoding/binary"
+	"strconv"
 )
 
 // Delimiter is responsible for adding delimiters to the frames being sent.
@@ -67,3 +68,17 @@ type lineBreakDelimiter struct {
 func (l *lineBreakDelimiter) delimit(content []byte) ([]byte, error) {
 	return append(content, '\n'), nil
 }
+
+var octetCounting octetCountingDelimiter
+
+// octetCountingDelimiter frames the content with the `MSG-LEN SP` prefix of RFC 6587,
+// it is used to send syslog messages that may contain line breaks.
+type octetCountingDelimiter struct {
+	Delimiter
+}
+
+func (o *octetCountingDelimiter) delimit(content []byte) ([]byte, error) {
+	frame := strconv.AppendInt(make([]byte, 0, len(content)+11), int64(len(content)), 10)
+	frame = append(frame, ' ')
+	return append(frame, content...), nil
+}
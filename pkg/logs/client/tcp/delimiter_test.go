@@ -42,3 +42,13 @@ func TestLineBreakDelimiter(t *testing.T) {
 	assert.Equal(t, "foo\n", string(bytes))
 
 }
+
+func TestOctetCountingDelimiter(t *testing.T) {
+	bytes, err := octetCounting.delimit([]byte{})
+	assert.Nil(t, err)
+	assert.Equal(t, "0 ", string(bytes))
+
+	bytes, err = octetCounting.delimit([]byte("foo\nbar"))
+	assert.Nil(t, err)
+	assert.Equal(t, "7 foo\nbar", string(bytes))
+}
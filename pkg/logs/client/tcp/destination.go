@@ -31,6 +31,10 @@ type Destination struct {
 	connCreationTime    time.Time
 	inputChan           chan []byte
 	once                sync.Once
+
+	// failFast makes Send return a retryable error instead of blocking
+	// when no connection can be established.
+	failFast bool
 }
 
 // NewDestination returns a new destination.
@@ -44,6 +48,26 @@ func NewDestination(endpoint config.Endpoint, useProto bool, destinationsContext
 	}
 }
 
+// NewFailFastDestination returns a new destination that does not wait for the intake to be reachable,
+// Send returns a retryable error when the connection can not be established so that the caller can
+// buffer the payload instead.
+func NewFailFastDestination(endpoint config.Endpoint, useProto bool, destinationsContext *client.DestinationsContext) *Destination {
+	destination := NewDestination(endpoint, useProto, destinationsContext)
+	destination.failFast = true
+	return destination
+}
+
+// NewSyslogDestination returns a new destination sending syslog messages to a collector,
+// the messages are framed using octet counting as defined in RFC 6587.
+func NewSyslogDestination(endpoint config.Endpoint, destinationsContext *client.DestinationsContext) *Destination {
+	return &Destination{
+		prefixer:            newPrefixer(""),
+		delimiter:           &octetCounting,
+		connManager:         NewConnectionManager(endpoint),
+		destinationsContext: destinationsContext,
+	}
+}
+
 // Send transforms a message into a frame and sends it to a remote server,
 // returns an error if the operation failed.
 func (d *Destination) Send(payload []byte) error {
@@ -52,7 +76,14 @@ func (d *Destination) Send(payload []byte) error {
 
 		// We work only if we have a started destination context
 		ctx := d.destinationsContext.Context()
-		if d.conn, err = d.connManager.NewConnection(ctx); err != nil {
+		if d.failFast {
+			if d.conn, err = d.connManager.TryNewConnection(ctx); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return client.NewRetryableError(err)
+			}
+		} else if d.conn, err = d.connManager.NewConnection(ctx); err != nil {
 			// the connection manager is not meant to fail,
 			// this can happen only when the context is cancelled.
 			return err
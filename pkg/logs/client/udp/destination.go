@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package udp
+
+import (
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+// Destination sends a payload per datagram to a remote server,
+// it is used to forward syslog messages to a collector.
+type Destination struct {
+	address             string
+	destinationsContext *client.DestinationsContext
+	conn                net.Conn
+	mu                  sync.Mutex
+}
+
+// NewDestination returns a new Destination.
+func NewDestination(host string, port int, destinationsContext *client.DestinationsContext) *Destination {
+	return &Destination{
+		address:             net.JoinHostPort(host, strconv.Itoa(port)),
+		destinationsContext: destinationsContext,
+	}
+}
+
+// Send sends a payload in a single datagram. As UDP does not guarantee the delivery,
+// the errors are never retryable and the payload is dropped.
+func (d *Destination) Send(payload []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if ctx := d.destinationsContext.Context(); ctx != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if d.conn == nil {
+		conn, err := net.Dial("udp", d.address)
+		if err != nil {
+			return err
+		}
+		d.conn = conn
+	}
+
+	metrics.BytesSent.Add(int64(len(payload)))
+	metrics.TlmBytesSent.Add(float64(len(payload)))
+	metrics.EncodedBytesSent.Add(int64(len(payload)))
+	metrics.TlmEncodedBytesSent.Add(float64(len(payload)))
+
+	if _, err := d.conn.Write(payload); err != nil {
+		// the address may have changed, resolve it again on the next payload
+		d.conn.Close()
+		d.conn = nil
+		return err
+	}
+	return nil
+}
+
+// SendAsync sends a payload without waiting for the result, writing a datagram never blocks.
+func (d *Destination) SendAsync(payload []byte) {
+	d.Send(payload) //nolint:errcheck
+}
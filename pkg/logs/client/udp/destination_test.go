@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package udp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+)
+
+func TestDestinationSend(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	destCtx := client.NewDestinationsContext()
+	destCtx.Start()
+	defer destCtx.Stop()
+
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	destination := NewDestination("127.0.0.1", port, destCtx)
+	assert.Nil(t, destination.Send([]byte("foo")))
+	assert.Nil(t, destination.Send([]byte("bar")))
+
+	buf := make([]byte, 100)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(buf[:n]))
+	n, _, err = conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", string(buf[:n]))
+}
+
+func TestDestinationSendWithStoppedContext(t *testing.T) {
+	destCtx := client.NewDestinationsContext()
+	destCtx.Start()
+	destCtx.Stop()
+
+	destination := NewDestination("127.0.0.1", 514, destCtx)
+	assert.Equal(t, context.Canceled, destination.Send([]byte("foo")))
+}
@@ -9,6 +9,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"time"
 
@@ -100,6 +102,52 @@ func GlobalProcessingRules() ([]*ProcessingRule, error) {
 	return rules, nil
 }
 
+// Forwarders returns the destinations to which a copy of the logs is forwarded.
+func Forwarders() ([]*ForwarderConfig, error) {
+	var forwarders []*ForwarderConfig
+	var err error
+	raw := coreConfig.Datadog.Get("logs_config.forwarders")
+	if raw == nil {
+		return forwarders, nil
+	}
+	if s, ok := raw.(string); ok && s != "" {
+		err = json.Unmarshal([]byte(s), &forwarders)
+	} else {
+		err = coreConfig.Datadog.UnmarshalKey("logs_config.forwarders", &forwarders)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = ValidateForwarders(forwarders)
+	if err != nil {
+		return nil, err
+	}
+	return forwarders, nil
+}
+
+// DedicatedPipelines returns the pipelines reserved to some sources.
+func DedicatedPipelines() ([]*DedicatedPipelineConfig, error) {
+	var pipelines []*DedicatedPipelineConfig
+	var err error
+	raw := coreConfig.Datadog.Get("logs_config.dedicated_pipelines")
+	if raw == nil {
+		return pipelines, nil
+	}
+	if s, ok := raw.(string); ok && s != "" {
+		err = json.Unmarshal([]byte(s), &pipelines)
+	} else {
+		err = coreConfig.Datadog.UnmarshalKey("logs_config.dedicated_pipelines", &pipelines)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = ValidateDedicatedPipelines(pipelines)
+	if err != nil {
+		return nil, err
+	}
+	return pipelines, nil
+}
+
 // BuildEndpoints returns the endpoints to send logs.
 func BuildEndpoints(httpConnectivity HTTPConnectivity) (*Endpoints, error) {
 	coreConfig.SanitizeAPIKeyConfig(coreConfig.Datadog, "logs_config.api_key")
@@ -190,6 +238,7 @@ func buildTCPEndpoints() (*Endpoints, error) {
 type LogsConfigKeys struct {
 	UseCompression          string
 	CompressionLevel        string
+	CompressionKind         string
 	ConnectionResetInterval string
 	LogsDDURL               string
 	LogsNoSSL               string
@@ -203,6 +252,7 @@ type LogsConfigKeys struct {
 var logsConfigDefaultKeys = LogsConfigKeys{
 	UseCompression:          "logs_config.use_compression",
 	CompressionLevel:        "logs_config.compression_level",
+	CompressionKind:         "logs_config.compression_kind",
 	ConnectionResetInterval: "logs_config.connection_reset_interval",
 	LogsDDURL:               "logs_config.logs_dd_url",
 	LogsNoSSL:               "logs_config.logs_no_ssl",
@@ -230,10 +280,16 @@ func BuildHTTPEndpointsWithConfig(logsConfig LogsConfigKeys, endpointPrefix stri
 		defaultUseCompression = coreConfig.Datadog.GetBool(logsConfig.UseCompression)
 	}
 
+	defaultCompressionKind := GzipCompressionKind
+	if isSetAndNotEmpty(coreConfig.Datadog, logsConfig.CompressionKind) {
+		defaultCompressionKind = coreConfig.Datadog.GetString(logsConfig.CompressionKind)
+	}
+
 	main := Endpoint{
 		APIKey:                  getLogsAPIKey(coreConfig.Datadog),
 		UseCompression:          defaultUseCompression,
 		CompressionLevel:        coreConfig.Datadog.GetInt(logsConfig.CompressionLevel),
+		CompressionKind:         defaultCompressionKind,
 		ConnectionResetInterval: time.Duration(coreConfig.Datadog.GetInt(logsConfig.ConnectionResetInterval)) * time.Second,
 	}
 
@@ -255,6 +311,9 @@ func BuildHTTPEndpointsWithConfig(logsConfig LogsConfigKeys, endpointPrefix stri
 	for i := 0; i < len(additionals); i++ {
 		additionals[i].UseSSL = main.UseSSL
 		additionals[i].APIKey = coreConfig.SanitizeAPIKey(additionals[i].APIKey)
+		if additionals[i].CompressionKind == "" {
+			additionals[i].CompressionKind = main.CompressionKind
+		}
 	}
 
 	batchWait := batchWaitFromKey(coreConfig.Datadog, logsConfig.BatchWait)
@@ -327,3 +386,56 @@ func TaggerWarmupDuration() time.Duration {
 func AggregationTimeout() time.Duration {
 	return coreConfig.Datadog.GetDuration("logs_config.aggregation_timeout") * time.Millisecond
 }
+
+// AutoMultiLineDetection returns whether the agent should try to detect multi-line patterns by default.
+func AutoMultiLineDetection() bool {
+	return coreConfig.Datadog.GetBool("logs_config.auto_multi_line_detection")
+}
+
+// AutoMultiLineSampleSize returns the default number of lines sampled to detect a multi-line pattern.
+func AutoMultiLineSampleSize() int {
+	return coreConfig.Datadog.GetInt("logs_config.auto_multi_line_default_sample_size")
+}
+
+// AutoMultiLineMatchThreshold returns the default ratio of sampled lines a pattern must match to be used.
+func AutoMultiLineMatchThreshold() float64 {
+	return coreConfig.Datadog.GetFloat64("logs_config.auto_multi_line_default_match_threshold")
+}
+
+// AutoMultiLineMatchTimeout returns the maximum duration spent sampling lines to detect a multi-line pattern.
+func AutoMultiLineMatchTimeout() time.Duration {
+	return coreConfig.Datadog.GetDuration("logs_config.auto_multi_line_default_match_timeout") * time.Second
+}
+
+// AutoMultiLineExtraPatterns returns the user defined patterns to test in addition to the built-in ones,
+// invalid patterns are ignored.
+func AutoMultiLineExtraPatterns() []*regexp.Regexp {
+	var patterns []*regexp.Regexp
+	for _, pattern := range coreConfig.Datadog.GetStringSlice("logs_config.auto_multi_line_extra_patterns") {
+		re, err := regexp.Compile("^" + pattern)
+		if err != nil {
+			log.Warnf("Invalid auto multi-line pattern %s: %v", pattern, err)
+			continue
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns
+}
+
+// UseDiskBuffer returns whether the payloads that could not be sent should be buffered on disk.
+func UseDiskBuffer() bool {
+	return coreConfig.Datadog.GetBool("logs_config.use_disk_buffer")
+}
+
+// DiskBufferPath returns the directory where the payloads that could not be sent are buffered.
+func DiskBufferPath() string {
+	if path := coreConfig.Datadog.GetString("logs_config.disk_buffer_path"); path != "" {
+		return path
+	}
+	return filepath.Join(coreConfig.Datadog.GetString("logs_config.run_path"), "disk_buffer")
+}
+
+// DiskBufferMaxSize returns the maximum size in bytes of the payloads buffered on disk.
+func DiskBufferMaxSize() int64 {
+	return coreConfig.Datadog.GetInt64("logs_config.disk_buffer_max_size")
+}
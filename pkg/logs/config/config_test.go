@@ -128,6 +128,42 @@ func (suite *ConfigTestSuite) TestGlobalProcessingRulesShouldReturnRulesWithVali
 	suite.NotNil(rule.Regex)
 }
 
+func (suite *ConfigTestSuite) TestForwarders() {
+	forwarders, err := Forwarders()
+	suite.Nil(err)
+	suite.Equal(0, len(forwarders))
+
+	suite.config.Set("logs_config.forwarders", []map[string]interface{}{
+		{
+			"name":    "compliance",
+			"type":    "syslog",
+			"host":    "collector.example.com",
+			"port":    6514,
+			"use_ssl": true,
+		},
+	})
+	forwarders, err = Forwarders()
+	suite.Nil(err)
+	suite.Equal(1, len(forwarders))
+	suite.Equal("compliance", forwarders[0].Name)
+	suite.Equal(SyslogForwarder, forwarders[0].Type)
+	suite.Equal("collector.example.com", forwarders[0].Host)
+	suite.Equal(6514, forwarders[0].Port)
+	suite.True(forwarders[0].UseSSL)
+
+	suite.config.Set("logs_config.forwarders", `[{"name":"archive","type":"http","url":"https://collector.example.com/logs","global":true}]`)
+	forwarders, err = Forwarders()
+	suite.Nil(err)
+	suite.Equal(1, len(forwarders))
+	suite.Equal(HTTPForwarder, forwarders[0].Type)
+	suite.Equal("https://collector.example.com/logs", forwarders[0].URL)
+	suite.True(forwarders[0].Global)
+
+	suite.config.Set("logs_config.forwarders", `[{"name":"archive","type":"kafka"}]`)
+	_, err = Forwarders()
+	suite.NotNil(err)
+}
+
 func (suite *ConfigTestSuite) TestTaggerWarmupDuration() {
 	// assert TaggerWarmupDuration is disabled by default
 	taggerWarmupDuration := TaggerWarmupDuration()
@@ -162,21 +198,24 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsEnvVar() {
 		Port:             443,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 6}
+		CompressionLevel: 6,
+		CompressionKind:  "gzip"}
 	expectedAdditionalEndpoint1 := Endpoint{
 		APIKey:           "456",
 		Host:             "additional.endpoint.1",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  "gzip"}
 	expectedAdditionalEndpoint2 := Endpoint{
 		APIKey:           "789",
 		Host:             "additional.endpoint.2",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  "gzip"}
 
 	expectedEndpoints := NewEndpoints(expectedMainEndpoint, []Endpoint{expectedAdditionalEndpoint1, expectedAdditionalEndpoint2}, false, true, time.Second)
 	endpoints, err := BuildHTTPEndpoints()
@@ -248,21 +287,24 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 		Port:             443,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 6}
+		CompressionLevel: 6,
+		CompressionKind:  "gzip"}
 	expectedAdditionalEndpoint1 := Endpoint{
 		APIKey:           "456",
 		Host:             "additional.endpoint.1",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  "gzip"}
 	expectedAdditionalEndpoint2 := Endpoint{
 		APIKey:           "789",
 		Host:             "additional.endpoint.2",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  "gzip"}
 
 	expectedEndpoints := NewEndpoints(expectedMainEndpoint, []Endpoint{expectedAdditionalEndpoint1, expectedAdditionalEndpoint2}, false, true, time.Second)
 	endpoints, err := BuildHTTPEndpoints()
@@ -271,6 +313,31 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 	suite.Equal(expectedEndpoints, endpoints)
 }
 
+func (suite *ConfigTestSuite) TestHttpEndpointsCompressionKind() {
+	suite.config.Set("api_key", "123")
+	suite.config.Set("logs_config.logs_dd_url", "agent-http-intake.logs.datadoghq.com:443")
+	suite.config.Set("logs_config.compression_kind", "zstd")
+	endpointsInConfig := []map[string]interface{}{
+		{
+			"api_key": "456",
+			"host":    "additional.endpoint.1",
+			"port":    1234},
+		{
+			"api_key":          "789",
+			"host":             "additional.endpoint.2",
+			"port":             1234,
+			"compression_kind": "deflate"},
+	}
+	suite.config.Set("logs_config.additional_endpoints", endpointsInConfig)
+
+	endpoints, err := BuildHTTPEndpoints()
+
+	suite.Nil(err)
+	suite.Equal(ZstdCompressionKind, endpoints.Main.CompressionKind)
+	suite.Equal(ZstdCompressionKind, endpoints.Additionals[0].CompressionKind)
+	suite.Equal(DeflateCompressionKind, endpoints.Additionals[1].CompressionKind)
+}
+
 func (suite *ConfigTestSuite) TestMultipleTCPEndpointsInConf() {
 	suite.config.Set("api_key", "123")
 	suite.config.Set("logs_config.logs_dd_url", "agent-http-intake.logs.datadoghq.com:443")
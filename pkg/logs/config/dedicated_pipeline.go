@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Pipeline priorities
+const (
+	LowPriority    = "low"
+	NormalPriority = "normal"
+	HighPriority   = "high"
+)
+
+var validPipelineName = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// DedicatedPipelineConfig represents a pipeline reserved to the sources it matches,
+// so that they are not delayed by the sources sharing the other pipelines.
+type DedicatedPipelineConfig struct {
+	Name string `mapstructure:"name" json:"name"`
+	// Priority is one of low, normal or high, the shared pipelines have a normal priority.
+	Priority string `mapstructure:"priority" json:"priority"`
+
+	// A source is matched when its name, its service or one of its tags is listed.
+	SourceNames []string `mapstructure:"source_names" json:"source_names"`
+	Services    []string `mapstructure:"services" json:"services"`
+	Tags        []string `mapstructure:"tags" json:"tags"`
+
+	// BatchWait and BatchMaxSize override the batch settings of the HTTP transport when set.
+	BatchWait    int `mapstructure:"batch_wait" json:"batch_wait"`
+	BatchMaxSize int `mapstructure:"batch_max_size" json:"batch_max_size"`
+
+	// LogsDDURL and APIKey override the main endpoint when set,
+	// the additional endpoints are only used when the main endpoint is not overridden.
+	LogsDDURL string `mapstructure:"logs_dd_url" json:"logs_dd_url"`
+	APIKey    string `mapstructure:"api_key" json:"api_key"`
+}
+
+// Validate returns an error if the dedicated pipeline is misconfigured.
+func (c *DedicatedPipelineConfig) Validate() error {
+	if !validPipelineName.MatchString(c.Name) {
+		return fmt.Errorf("a dedicated pipeline must have a name made of letters, digits, '_', '.' or '-'")
+	}
+	switch c.Priority {
+	case "", LowPriority, NormalPriority, HighPriority:
+	default:
+		return fmt.Errorf("invalid priority '%v' for dedicated pipeline %s, must be low, normal or high", c.Priority, c.Name)
+	}
+	if len(c.SourceNames) == 0 && len(c.Services) == 0 && len(c.Tags) == 0 {
+		return fmt.Errorf("dedicated pipeline %s must match at least one source name, service or tag", c.Name)
+	}
+	if c.BatchWait < 0 || c.BatchWait > 10 {
+		return fmt.Errorf("invalid batch_wait %d for dedicated pipeline %s, must be between 0 and 10 seconds", c.BatchWait, c.Name)
+	}
+	if c.BatchMaxSize < 0 {
+		return fmt.Errorf("invalid batch_max_size %d for dedicated pipeline %s", c.BatchMaxSize, c.Name)
+	}
+	if c.LogsDDURL != "" {
+		if _, _, err := parseAddress(c.LogsDDURL); err != nil {
+			return fmt.Errorf("invalid logs_dd_url for dedicated pipeline %s: %v", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the logs of the source must be sent to the dedicated pipeline.
+func (c *DedicatedPipelineConfig) Matches(source *LogSource) bool {
+	for _, name := range c.SourceNames {
+		if name == source.Name {
+			return true
+		}
+	}
+	for _, service := range c.Services {
+		if service == source.Config.Service {
+			return true
+		}
+	}
+	for _, tag := range c.Tags {
+		for _, sourceTag := range source.Config.Tags {
+			if tag == sourceTag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetPriority returns the priority of the dedicated pipeline, normal when not set.
+func (c *DedicatedPipelineConfig) GetPriority() string {
+	if c.Priority == "" {
+		return NormalPriority
+	}
+	return c.Priority
+}
+
+// BuildEndpoints returns the endpoints of the dedicated pipeline, which are
+// the endpoints shared by all the pipelines with the overrides of the dedicated pipeline.
+func (c *DedicatedPipelineConfig) BuildEndpoints(endpoints *Endpoints) *Endpoints {
+	main := endpoints.Main
+	additionals := endpoints.Additionals
+	if c.LogsDDURL != "" {
+		if host, port, err := parseAddress(c.LogsDDURL); err == nil {
+			main.Host = host
+			main.Port = port
+			additionals = nil
+		}
+	}
+	if c.APIKey != "" {
+		main.APIKey = c.APIKey
+	}
+	batchWait := endpoints.BatchWait
+	if c.BatchWait > 0 {
+		batchWait = time.Duration(c.BatchWait) * time.Second
+	}
+	dedicatedEndpoints := NewEndpoints(main, additionals, endpoints.UseProto, endpoints.UseHTTP, batchWait)
+	dedicatedEndpoints.BatchMaxSize = endpoints.BatchMaxSize
+	if c.BatchMaxSize > 0 {
+		dedicatedEndpoints.BatchMaxSize = c.BatchMaxSize
+	}
+	return dedicatedEndpoints
+}
+
+// ValidateDedicatedPipelines returns an error if one of the dedicated pipelines
+// is misconfigured or if two dedicated pipelines have the same name.
+func ValidateDedicatedPipelines(pipelines []*DedicatedPipelineConfig) error {
+	names := make(map[string]bool)
+	for _, pipeline := range pipelines {
+		if err := pipeline.Validate(); err != nil {
+			return err
+		}
+		if names[pipeline.Name] {
+			return fmt.Errorf("dedicated pipeline %s is defined more than once", pipeline.Name)
+		}
+		names[pipeline.Name] = true
+	}
+	return nil
+}
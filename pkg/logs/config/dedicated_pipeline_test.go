@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDedicatedPipelines(t *testing.T) {
+	validPipelines := [][]*DedicatedPipelineConfig{
+		nil,
+		{{Name: "critical", Services: []string{"payments"}}},
+		{{Name: "critical", Priority: HighPriority, SourceNames: []string{"payments"}, BatchWait: 1, BatchMaxSize: 10}},
+		{{Name: "audit", Priority: LowPriority, Tags: []string{"team:security"}, LogsDDURL: "audit.example.com:443"}},
+		{
+			{Name: "critical", Services: []string{"payments"}},
+			{Name: "audit", Tags: []string{"team:security"}},
+		},
+	}
+	for _, pipelines := range validPipelines {
+		assert.Nil(t, ValidateDedicatedPipelines(pipelines))
+	}
+
+	invalidPipelines := [][]*DedicatedPipelineConfig{
+		{{Services: []string{"payments"}}},
+		{{Name: "../critical", Services: []string{"payments"}}},
+		{{Name: "critical"}},
+		{{Name: "critical", Priority: "urgent", Services: []string{"payments"}}},
+		{{Name: "critical", Services: []string{"payments"}, BatchWait: 11}},
+		{{Name: "critical", Services: []string{"payments"}, BatchMaxSize: -1}},
+		{{Name: "critical", Services: []string{"payments"}, LogsDDURL: "example.com"}},
+		{
+			{Name: "critical", Services: []string{"payments"}},
+			{Name: "critical", Tags: []string{"team:security"}},
+		},
+	}
+	for _, pipelines := range invalidPipelines {
+		assert.NotNil(t, ValidateDedicatedPipelines(pipelines))
+	}
+}
+
+func TestDedicatedPipelineMatches(t *testing.T) {
+	pipeline := &DedicatedPipelineConfig{
+		Name:        "critical",
+		SourceNames: []string{"billing"},
+		Services:    []string{"payments"},
+		Tags:        []string{"team:security"},
+	}
+	assert.True(t, pipeline.Matches(NewLogSource("billing", &LogsConfig{})))
+	assert.True(t, pipeline.Matches(NewLogSource("", &LogsConfig{Service: "payments"})))
+	assert.True(t, pipeline.Matches(NewLogSource("", &LogsConfig{Tags: []string{"env:prod", "team:security"}})))
+	assert.False(t, pipeline.Matches(NewLogSource("web", &LogsConfig{Service: "web", Tags: []string{"env:prod"}})))
+}
+
+func TestDedicatedPipelineBuildEndpoints(t *testing.T) {
+	main := Endpoint{APIKey: "123", Host: "agent-http-intake.logs.datadoghq.com", Port: 443, UseSSL: true}
+	additionals := []Endpoint{{APIKey: "456", Host: "additional.intake.com", Port: 443}}
+	endpoints := NewEndpoints(main, additionals, false, true, 5*time.Second)
+
+	pipeline := &DedicatedPipelineConfig{Name: "critical", Services: []string{"payments"}}
+	assert.Equal(t, endpoints, pipeline.BuildEndpoints(endpoints))
+
+	pipeline = &DedicatedPipelineConfig{
+		Name:         "critical",
+		Services:     []string{"payments"},
+		BatchWait:    1,
+		BatchMaxSize: 10,
+		LogsDDURL:    "critical.intake.com:8443",
+		APIKey:       "789",
+	}
+	dedicatedEndpoints := pipeline.BuildEndpoints(endpoints)
+	assert.Equal(t, "critical.intake.com", dedicatedEndpoints.Main.Host)
+	assert.Equal(t, 8443, dedicatedEndpoints.Main.Port)
+	assert.Equal(t, "789", dedicatedEndpoints.Main.APIKey)
+	assert.True(t, dedicatedEndpoints.Main.UseSSL)
+	assert.Len(t, dedicatedEndpoints.Additionals, 0)
+	assert.True(t, dedicatedEndpoints.UseHTTP)
+	assert.Equal(t, time.Second, dedicatedEndpoints.BatchWait)
+	assert.Equal(t, 10, dedicatedEndpoints.BatchMaxSize)
+	// the shared endpoints are not modified
+	assert.Equal(t, "123", endpoints.Main.APIKey)
+	assert.Equal(t, 0, endpoints.BatchMaxSize)
+}
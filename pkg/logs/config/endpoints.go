@@ -9,14 +9,22 @@ import (
 	"time"
 )
 
+// Compression kinds supported by the HTTP endpoints.
+const (
+	GzipCompressionKind    = "gzip"
+	DeflateCompressionKind = "deflate"
+	ZstdCompressionKind    = "zstd"
+)
+
 // Endpoint holds all the organization and network parameters to send logs to Datadog.
 type Endpoint struct {
 	APIKey                  string `mapstructure:"api_key" json:"api_key"`
 	Host                    string
 	Port                    int
 	UseSSL                  bool
-	UseCompression          bool `mapstructure:"use_compression" json:"use_compression"`
-	CompressionLevel        int  `mapstructure:"compression_level" json:"compression_level"`
+	UseCompression          bool   `mapstructure:"use_compression" json:"use_compression"`
+	CompressionLevel        int    `mapstructure:"compression_level" json:"compression_level"`
+	CompressionKind         string `mapstructure:"compression_kind" json:"compression_kind"`
 	ProxyAddress            string
 	ConnectionResetInterval time.Duration
 }
@@ -28,6 +36,8 @@ type Endpoints struct {
 	UseProto    bool
 	UseHTTP     bool
 	BatchWait   time.Duration
+	// BatchMaxSize is the maximum number of messages in a batch, the default size is used when zero.
+	BatchMaxSize int
 }
 
 // NewEndpoints returns a new endpoints composite.
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Forwarder types
+const (
+	// SyslogForwarder sends the logs formatted according to RFC 5424 over TCP or UDP.
+	SyslogForwarder = "syslog"
+	// HTTPForwarder sends the logs as newline delimited JSON objects over HTTP.
+	HTTPForwarder = "http"
+)
+
+// ForwarderConfig represents a destination to which a copy of the logs is forwarded,
+// in a format that does not depend on the Datadog intake.
+type ForwarderConfig struct {
+	Name string `mapstructure:"name" json:"name"`
+	Type string `mapstructure:"type" json:"type"`
+
+	Host     string `mapstructure:"host" json:"host"`         // Syslog
+	Port     int    `mapstructure:"port" json:"port"`         // Syslog
+	Protocol string `mapstructure:"protocol" json:"protocol"` // Syslog
+	UseSSL   bool   `mapstructure:"use_ssl" json:"use_ssl"`   // Syslog
+
+	URL string `mapstructure:"url" json:"url"` // HTTP
+
+	// Global forwards the logs of all sources, otherwise only the sources
+	// listing the forwarder name in their `forwarders` are forwarded.
+	Global bool `mapstructure:"global" json:"global"`
+}
+
+// Validate returns an error if the forwarder is misconfigured.
+func (c *ForwarderConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("a forwarder must have a name")
+	}
+	switch c.Type {
+	case SyslogForwarder:
+		if c.Host == "" || c.Port == 0 {
+			return fmt.Errorf("syslog forwarder %s must have a host and a port", c.Name)
+		}
+		switch c.Protocol {
+		case "", TCPType:
+		case UDPType:
+			if c.UseSSL {
+				return fmt.Errorf("syslog forwarder %s must use the tcp protocol to enable SSL", c.Name)
+			}
+		default:
+			return fmt.Errorf("invalid protocol '%v' for syslog forwarder %s, must be tcp or udp", c.Protocol, c.Name)
+		}
+	case HTTPForwarder:
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("http forwarder %s must have a valid http or https url", c.Name)
+		}
+	default:
+		return fmt.Errorf("invalid type '%v' for forwarder %s, must be syslog or http", c.Type, c.Name)
+	}
+	return nil
+}
+
+// ShouldForward returns true if the logs of the source must be sent to the forwarder.
+func (c *ForwarderConfig) ShouldForward(source *LogSource) bool {
+	if c.Global {
+		return true
+	}
+	for _, name := range source.Config.Forwarders {
+		if name == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateForwarders returns an error if one of the forwarders is misconfigured
+// or if two forwarders have the same name.
+func ValidateForwarders(forwarders []*ForwarderConfig) error {
+	names := make(map[string]bool)
+	for _, forwarder := range forwarders {
+		if err := forwarder.Validate(); err != nil {
+			return err
+		}
+		if names[forwarder.Name] {
+			return fmt.Errorf("forwarder %s is defined more than once", forwarder.Name)
+		}
+		names[forwarder.Name] = true
+	}
+	return nil
+}
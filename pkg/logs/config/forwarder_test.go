@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateForwarders(t *testing.T) {
+	validForwarders := [][]*ForwarderConfig{
+		nil,
+		{{Name: "syslog", Type: SyslogForwarder, Host: "localhost", Port: 514}},
+		{{Name: "syslog", Type: SyslogForwarder, Host: "localhost", Port: 514, Protocol: UDPType}},
+		{{Name: "syslog", Type: SyslogForwarder, Host: "localhost", Port: 6514, Protocol: TCPType, UseSSL: true}},
+		{{Name: "http", Type: HTTPForwarder, URL: "https://localhost/logs"}},
+		{
+			{Name: "syslog", Type: SyslogForwarder, Host: "localhost", Port: 514},
+			{Name: "http", Type: HTTPForwarder, URL: "http://localhost:8080"},
+		},
+	}
+	for _, forwarders := range validForwarders {
+		assert.Nil(t, ValidateForwarders(forwarders))
+	}
+
+	invalidForwarders := [][]*ForwarderConfig{
+		{{Type: SyslogForwarder, Host: "localhost", Port: 514}},
+		{{Name: "syslog", Type: SyslogForwarder, Host: "localhost"}},
+		{{Name: "syslog", Type: SyslogForwarder, Port: 514}},
+		{{Name: "syslog", Type: SyslogForwarder, Host: "localhost", Port: 514, Protocol: "sctp"}},
+		{{Name: "syslog", Type: SyslogForwarder, Host: "localhost", Port: 514, Protocol: UDPType, UseSSL: true}},
+		{{Name: "http", Type: HTTPForwarder}},
+		{{Name: "http", Type: HTTPForwarder, URL: "localhost:8080"}},
+		{{Name: "kafka", Type: "kafka"}},
+		{
+			{Name: "syslog", Type: SyslogForwarder, Host: "localhost", Port: 514},
+			{Name: "syslog", Type: HTTPForwarder, URL: "http://localhost:8080"},
+		},
+	}
+	for _, forwarders := range invalidForwarders {
+		assert.NotNil(t, ValidateForwarders(forwarders))
+	}
+}
+
+func TestShouldForward(t *testing.T) {
+	source := NewLogSource("", &LogsConfig{Type: FileType, Path: "/var/log/app.log", Forwarders: []string{"compliance"}})
+	other := NewLogSource("", &LogsConfig{Type: FileType, Path: "/var/log/other.log"})
+
+	forwarder := &ForwarderConfig{Name: "compliance"}
+	assert.True(t, forwarder.ShouldForward(source))
+	assert.False(t, forwarder.ShouldForward(other))
+
+	forwarder = &ForwarderConfig{Name: "archive", Global: true}
+	assert.True(t, forwarder.ShouldForward(source))
+	assert.True(t, forwarder.ShouldForward(other))
+}
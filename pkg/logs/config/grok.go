@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// maxGrokExpansionDepth prevents infinite recursion on patterns referencing each others.
+const maxGrokExpansionDepth = 10
+
+// grokReference matches the `%{PATTERN}` and `%{PATTERN:capture}` references.
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::(\w+))?\}`)
+
+// grokPatterns is a small library of the most common grok patterns.
+var grokPatterns = map[string]string{
+	"INT":               `[+-]?\d+`,
+	"NUMBER":            `[+-]?(?:\d+(?:\.\d*)?|\.\d+)`,
+	"WORD":              `\w+`,
+	"NOTSPACE":          `\S+`,
+	"SPACE":             `\s*`,
+	"DATA":              `.*?`,
+	"GREEDYDATA":        `.*`,
+	"QUOTEDSTRING":      `"(?:[^"\\]|\\.)*"`,
+	"UUID":              `[A-Fa-f0-9]{8}-(?:[A-Fa-f0-9]{4}-){3}[A-Fa-f0-9]{12}`,
+	"IPV4":              `(?:\d{1,3}\.){3}\d{1,3}`,
+	"IPV6":              `[0-9A-Fa-f:]*:[0-9A-Fa-f:.]+`,
+	"IP":                `(?:%{IPV6}|%{IPV4})`,
+	"HOSTNAME":          `[0-9A-Za-z][0-9A-Za-z\-.]*`,
+	"IPORHOST":          `(?:%{IP}|%{HOSTNAME})`,
+	"USER":              `[\w.@-]+`,
+	"PATH":              `(?:/[^\s?#]*)+`,
+	"URIPARAM":          `\?\S*`,
+	"URIPATHPARAM":      `%{PATH}(?:%{URIPARAM})?`,
+	"HTTPMETHOD":        `(?:GET|HEAD|POST|PUT|DELETE|CONNECT|OPTIONS|TRACE|PATCH)`,
+	"HTTPSTATUS":        `[1-5]\d{2}`,
+	"LOGLEVEL":          `(?i:trace|debug|info|notice|warn(?:ing)?|err(?:or)?|crit(?:ical)?|fatal|severe|emerg(?:ency)?|alert)`,
+	"TIMESTAMP_ISO8601": `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(?::\d{2}(?:[.,]\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?`,
+	"HTTPDATE":          `\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`,
+}
+
+// expandGrokPattern replaces the grok references of a pattern with their regular expression,
+// references with a capture name are turned into named capture groups.
+func expandGrokPattern(pattern string) (string, error) {
+	for depth := 0; grokReference.MatchString(pattern); depth++ {
+		if depth == maxGrokExpansionDepth {
+			return "", fmt.Errorf("too many nested grok references in pattern %s", pattern)
+		}
+		var err error
+		pattern = grokReference.ReplaceAllStringFunc(pattern, func(reference string) string {
+			submatches := grokReference.FindStringSubmatch(reference)
+			expansion, found := grokPatterns[submatches[1]]
+			if !found {
+				err = fmt.Errorf("unknown grok pattern %s", submatches[1])
+				return reference
+			}
+			if submatches[2] != "" {
+				return "(?P<" + submatches[2] + ">" + expansion + ")"
+			}
+			return "(?:" + expansion + ")"
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return pattern, nil
+}
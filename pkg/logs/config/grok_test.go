@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandGrokPattern(t *testing.T) {
+	pattern, err := expandGrokPattern(`%{IPORHOST:client} - %{USER} \[%{HTTPDATE}\] "%{HTTPMETHOD:method} %{URIPATHPARAM:path} HTTP/%{NUMBER}" %{HTTPSTATUS:status_code} %{INT}`)
+	assert.Nil(t, err)
+
+	re := regexp.MustCompile(pattern)
+	submatches := re.FindStringSubmatch(`10.0.0.1 - frank [17/Oct/2020:13:55:36 -0700] "GET /index.html?lang=en HTTP/1.1" 503 2326`)
+	assert.NotNil(t, submatches)
+	captures := make(map[string]string)
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			captures[name] = submatches[i]
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"client":      "10.0.0.1",
+		"method":      "GET",
+		"path":        "/index.html?lang=en",
+		"status_code": "503",
+	}, captures)
+}
+
+func TestExpandGrokPatternWithoutReference(t *testing.T) {
+	pattern, err := expandGrokPattern(`request_id=(?P<request_id>\w+)`)
+	assert.Nil(t, err)
+	assert.Equal(t, `request_id=(?P<request_id>\w+)`, pattern)
+}
+
+func TestExpandGrokPatternWithUnknownReference(t *testing.T) {
+	_, err := expandGrokPattern(`%{UNKNOWN:foo}`)
+	assert.NotNil(t, err)
+}
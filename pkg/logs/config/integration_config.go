@@ -7,6 +7,7 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/serverless/aws"
@@ -22,6 +23,7 @@ const (
 	WindowsEventType  = "windows_event"
 	SnmpTrapsType     = "snmp_traps"
 	StringChannelType = "string_channel"
+	SyslogType        = "syslog"
 
 	// UTF16BE for UTF-16 Big endian encoding
 	UTF16BE string = "utf-16-be"
@@ -34,12 +36,17 @@ const (
 type LogsConfig struct {
 	Type string
 
-	Port int    // Network
+	Port int    // Network, Syslog
 	Path string // File, Journald
 
-	Encoding     string   `mapstructure:"encoding" json:"encoding"`             // File
-	ExcludePaths []string `mapstructure:"exclude_paths" json:"exclude_paths"`   // File
-	TailingMode  string   `mapstructure:"start_position" json:"start_position"` // File
+	Protocol    string `mapstructure:"protocol" json:"protocol"`           // Syslog
+	TLSCertFile string `mapstructure:"tls_cert_file" json:"tls_cert_file"` // Syslog
+	TLSKeyFile  string `mapstructure:"tls_key_file" json:"tls_key_file"`   // Syslog
+
+	Encoding        string   `mapstructure:"encoding" json:"encoding"`                 // File
+	ExcludePaths    []string `mapstructure:"exclude_paths" json:"exclude_paths"`       // File
+	TailingMode     string   `mapstructure:"start_position" json:"start_position"`     // File
+	RotatedArchives string   `mapstructure:"rotated_archives" json:"rotated_archives"` // File
 
 	IncludeUnits  []string `mapstructure:"include_units" json:"include_units"`   // Journald
 	ExcludeUnits  []string `mapstructure:"exclude_units" json:"exclude_units"`   // Journald
@@ -66,6 +73,12 @@ type LogsConfig struct {
 	SourceCategory  string
 	Tags            []string
 	ProcessingRules []*ProcessingRule `mapstructure:"log_processing_rules" json:"log_processing_rules"`
+	// Forwarders contains the names of the forwarders to which a copy of the logs is sent.
+	Forwarders []string `mapstructure:"forwarders" json:"forwarders"`
+
+	AutoMultiLine               *bool   `mapstructure:"auto_multi_line_detection" json:"auto_multi_line_detection"`
+	AutoMultiLineSampleSize     int     `mapstructure:"auto_multi_line_sample_size" json:"auto_multi_line_sample_size"`
+	AutoMultiLineMatchThreshold float64 `mapstructure:"auto_multi_line_match_threshold" json:"auto_multi_line_match_threshold"`
 }
 
 // TailingMode type
@@ -125,10 +138,24 @@ func (c *LogsConfig) Validate() error {
 		if err != nil {
 			return err
 		}
+		if _, err := filepath.Match(c.RotatedArchives, ""); err != nil {
+			return fmt.Errorf("invalid rotated_archives pattern '%v' for %v", c.RotatedArchives, c.Path)
+		}
 	case c.Type == TCPType && c.Port == 0:
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case c.Type == SyslogType:
+		err := c.validateSyslog()
+		if err != nil {
+			return err
+		}
+	}
+	if c.AutoMultiLineSampleSize < 0 {
+		return fmt.Errorf("auto_multi_line_sample_size must be positive")
+	}
+	if c.AutoMultiLineMatchThreshold < 0 || c.AutoMultiLineMatchThreshold > 1 {
+		return fmt.Errorf("auto_multi_line_match_threshold must be between 0 and 1")
 	}
 	err := ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
@@ -137,6 +164,34 @@ func (c *LogsConfig) Validate() error {
 	return CompileProcessingRules(c.ProcessingRules)
 }
 
+// AutoMultiLineEnabled returns whether multi-line patterns should be detected automatically for this config,
+// the source setting takes precedence over the global one.
+func (c *LogsConfig) AutoMultiLineEnabled() bool {
+	if c.AutoMultiLine != nil {
+		return *c.AutoMultiLine
+	}
+	return AutoMultiLineDetection()
+}
+
+func (c *LogsConfig) validateSyslog() error {
+	if c.Port == 0 {
+		return fmt.Errorf("syslog source must have a port")
+	}
+	switch c.Protocol {
+	case "", UDPType:
+		if c.TLSCertFile != "" || c.TLSKeyFile != "" {
+			return fmt.Errorf("syslog source must use the tcp protocol to enable TLS")
+		}
+	case TCPType:
+		if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+			return fmt.Errorf("syslog source must have both a tls_cert_file and a tls_key_file to enable TLS")
+		}
+	default:
+		return fmt.Errorf("invalid syslog protocol '%v', must be tcp or udp", c.Protocol)
+	}
+	return nil
+}
+
 func (c *LogsConfig) validateTailingMode() error {
 	mode, found := TailingModeFromString(c.TailingMode)
 	if !found && c.TailingMode != "" {
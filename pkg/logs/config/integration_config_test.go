@@ -16,11 +16,15 @@ import (
 func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 	validConfigs := []*LogsConfig{
 		{Type: FileType, Path: "/var/log/foo.log"},
+		{Type: FileType, Path: "/var/log/foo.log", RotatedArchives: "/var/log/foo.log.*.gz"},
 		{Type: TCPType, Port: 1234},
 		{Type: UDPType, Port: 5678},
 		{Type: DockerType},
 		{Type: JournaldType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: SnmpTrapsType},
+		{Type: SyslogType, Port: 514},
+		{Type: SyslogType, Port: 514, Protocol: UDPType},
+		{Type: SyslogType, Port: 6514, Protocol: TCPType, TLSCertFile: "/etc/cert.pem", TLSKeyFile: "/etc/key.pem"},
 	}
 
 	for _, config := range validConfigs {
@@ -33,6 +37,7 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 	invalidConfigs := []*LogsConfig{
 		{},
 		{Type: FileType},
+		{Type: FileType, Path: "/var/log/foo.log", RotatedArchives: "/var/log/foo.log.[.gz"},
 		{Type: TCPType},
 		{Type: UDPType},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo"}}},
@@ -42,6 +47,10 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Pattern: ".*"}}},
+		{Type: SyslogType},
+		{Type: SyslogType, Port: 514, Protocol: "sctp"},
+		{Type: SyslogType, Port: 514, TLSCertFile: "/etc/cert.pem", TLSKeyFile: "/etc/key.pem"},
+		{Type: SyslogType, Port: 6514, Protocol: TCPType, TLSCertFile: "/etc/cert.pem"},
 	}
 
 	for _, config := range invalidConfigs {
@@ -49,3 +58,15 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		assert.NotNil(t, err)
 	}
 }
+
+func TestAutoMultiLineConfig(t *testing.T) {
+	enabled := true
+	disabled := false
+	assert.False(t, (&LogsConfig{}).AutoMultiLineEnabled())
+	assert.True(t, (&LogsConfig{AutoMultiLine: &enabled}).AutoMultiLineEnabled())
+	assert.False(t, (&LogsConfig{AutoMultiLine: &disabled}).AutoMultiLineEnabled())
+
+	assert.Nil(t, (&LogsConfig{Type: TCPType, Port: 1234, AutoMultiLineSampleSize: 10, AutoMultiLineMatchThreshold: 0.3}).Validate())
+	assert.NotNil(t, (&LogsConfig{Type: TCPType, Port: 1234, AutoMultiLineSampleSize: -1}).Validate())
+	assert.NotNil(t, (&LogsConfig{Type: TCPType, Port: 1234, AutoMultiLineMatchThreshold: 1.5}).Validate())
+}
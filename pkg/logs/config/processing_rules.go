@@ -16,6 +16,15 @@ const (
 	IncludeAtMatch = "include_at_match"
 	MaskSequences  = "mask_sequences"
 	MultiLine      = "multi_line"
+	RemapJSON      = "remap_json"
+	Extract        = "extract"
+	Sample         = "sample"
+)
+
+// Extraction targets
+const (
+	ExtractToTags       = "tags"
+	ExtractToAttributes = "attributes"
 )
 
 // ProcessingRule defines an exclusion or a masking rule to
@@ -25,6 +34,20 @@ type ProcessingRule struct {
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
 	Pattern            string
+	// JSON attributes remapping, only used by the remap_json rule type.
+	StatusAttribute    string            `mapstructure:"status_attribute" json:"status_attribute"`
+	ServiceAttribute   string            `mapstructure:"service_attribute" json:"service_attribute"`
+	TimestampAttribute string            `mapstructure:"timestamp_attribute" json:"timestamp_attribute"`
+	TagAttributes      []string          `mapstructure:"tag_attributes" json:"tag_attributes"`
+	RenameAttributes   map[string]string `mapstructure:"rename_attributes" json:"rename_attributes"`
+	DropAttributes     []string          `mapstructure:"drop_attributes" json:"drop_attributes"`
+	// ExtractTo defines where the named captures of an extract rule are stored, tags by default.
+	ExtractTo string `mapstructure:"extract_to" json:"extract_to"`
+	// Sampling, only used by the sample rule type: keep one line out of SampleRate
+	// and at most RateLimit lines per second with bursts of Burst lines.
+	SampleRate int     `mapstructure:"sample_rate" json:"sample_rate"`
+	RateLimit  float64 `mapstructure:"rate_limit" json:"rate_limit"`
+	Burst      int     `mapstructure:"burst" json:"burst"`
 	// TODO: should be moved out
 	Regex       *regexp.Regexp
 	Placeholder []byte
@@ -34,7 +57,10 @@ type ProcessingRule struct {
 // Each processing rule must have:
 // - a valid name
 // - a valid type
-// - a valid pattern that compiles
+// - a valid pattern that compiles, the pattern is optional for remap_json and sample rules
+// Sample rules must also have a valid sample rate or rate limit.
+// Extract rules must also have a pattern with at least one named capture group
+// and a valid extraction target.
 func ValidateProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
 		if rule.Name == "" {
@@ -44,6 +70,22 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		switch rule.Type {
 		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine:
 			break
+		case RemapJSON:
+			if rule.Pattern == "" {
+				continue
+			}
+		case Sample:
+			if err := validateSampleRule(rule); err != nil {
+				return err
+			}
+			if rule.Pattern == "" {
+				continue
+			}
+		case Extract:
+			if err := validateExtractRule(rule); err != nil {
+				return err
+			}
+			continue
 		case "":
 			return fmt.Errorf("type must be set for processing rule `%s`", rule.Name)
 		default:
@@ -64,12 +106,23 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 // CompileProcessingRules compiles all processing rule regular expressions.
 func CompileProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
-		re, err := regexp.Compile(rule.Pattern)
+		if (rule.Type == RemapJSON || rule.Type == Sample) && rule.Pattern == "" {
+			// remap_json and sample rules without pattern apply to all lines
+			continue
+		}
+		pattern := rule.Pattern
+		if rule.Type == Extract {
+			var err error
+			if pattern, err = expandGrokPattern(pattern); err != nil {
+				return err
+			}
+		}
+		re, err := regexp.Compile(pattern)
 		if err != nil {
 			return err
 		}
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch:
+		case ExcludeAtMatch, IncludeAtMatch, RemapJSON, Extract, Sample:
 			rule.Regex = re
 		case MaskSequences:
 			rule.Regex = re
@@ -83,3 +136,42 @@ func CompileProcessingRules(rules []*ProcessingRule) error {
 	}
 	return nil
 }
+
+// validateExtractRule makes sure the pattern of an extract rule expands and compiles
+// to a regular expression with named capture groups.
+func validateExtractRule(rule *ProcessingRule) error {
+	switch rule.ExtractTo {
+	case "", ExtractToTags, ExtractToAttributes:
+		break
+	default:
+		return fmt.Errorf("invalid extract_to %s for processing rule: %s, must be %s or %s", rule.ExtractTo, rule.Name, ExtractToTags, ExtractToAttributes)
+	}
+	if rule.Pattern == "" {
+		return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
+	}
+	pattern, err := expandGrokPattern(rule.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %s for processing rule: %s: %v", rule.Pattern, rule.Name, err)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %s for processing rule: %s", rule.Pattern, rule.Name)
+	}
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("pattern %s for processing rule: %s has no named capture group", rule.Pattern, rule.Name)
+}
+
+// validateSampleRule makes sure a sample rule defines how lines are sampled.
+func validateSampleRule(rule *ProcessingRule) error {
+	if rule.SampleRate < 0 || rule.RateLimit < 0 || rule.Burst < 0 {
+		return fmt.Errorf("sample_rate, rate_limit and burst must be positive for processing rule: %s", rule.Name)
+	}
+	if rule.SampleRate == 0 && rule.RateLimit == 0 {
+		return fmt.Errorf("sample_rate or rate_limit must be set for processing rule: %s", rule.Name)
+	}
+	return nil
+}
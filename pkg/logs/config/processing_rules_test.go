@@ -33,3 +33,57 @@ func TestCompileShouldFailWithInvalidRules(t *testing.T) {
 		assert.Nil(t, rule.Regex)
 	}
 }
+
+func TestRemapJSONRulePatternIsOptional(t *testing.T) {
+	rules := []*ProcessingRule{
+		{Type: RemapJSON, Name: "all_lines", StatusAttribute: "level"},
+		{Type: RemapJSON, Name: "some_lines", Pattern: "billing", StatusAttribute: "level"},
+	}
+	assert.Nil(t, ValidateProcessingRules(rules))
+	assert.Nil(t, CompileProcessingRules(rules))
+	assert.Nil(t, rules[0].Regex)
+	assert.True(t, rules[1].Regex.MatchString(`{"service":"billing"}`))
+
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Type: RemapJSON, Name: "invalid", Pattern: "(?=abf)"}}))
+}
+
+func TestValidateExtractRules(t *testing.T) {
+	validRules := []*ProcessingRule{
+		{Type: Extract, Name: "regex", Pattern: `request_id=(?P<request_id>\w+)`},
+		{Type: Extract, Name: "grok", Pattern: `%{HTTPSTATUS:status_code}`, ExtractTo: ExtractToAttributes},
+	}
+	assert.Nil(t, ValidateProcessingRules(validRules))
+	assert.Nil(t, CompileProcessingRules(validRules))
+	assert.Equal(t, []string{"", "status_code"}, validRules[1].Regex.SubexpNames())
+
+	invalidRules := []*ProcessingRule{
+		{Type: Extract, Name: "no_pattern"},
+		{Type: Extract, Name: "no_capture", Pattern: `request_id=\w+`},
+		{Type: Extract, Name: "unknown_grok", Pattern: `%{FOO:foo}`},
+		{Type: Extract, Name: "invalid_target", Pattern: `(?P<foo>\w+)`, ExtractTo: "message"},
+	}
+	for _, rule := range invalidRules {
+		assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{rule}), rule.Name)
+	}
+}
+
+func TestValidateSampleRules(t *testing.T) {
+	validRules := []*ProcessingRule{
+		{Type: Sample, Name: "one_in_ten", SampleRate: 10},
+		{Type: Sample, Name: "rate_limited", Pattern: "healthz", RateLimit: 0.5, Burst: 5},
+	}
+	assert.Nil(t, ValidateProcessingRules(validRules))
+	assert.Nil(t, CompileProcessingRules(validRules))
+	assert.Nil(t, validRules[0].Regex)
+	assert.NotNil(t, validRules[1].Regex)
+
+	invalidRules := []*ProcessingRule{
+		{Type: Sample, Name: "nothing"},
+		{Type: Sample, Name: "negative_rate", SampleRate: -1},
+		{Type: Sample, Name: "negative_limit", RateLimit: -1},
+		{Type: Sample, Name: "invalid_pattern", SampleRate: 2, Pattern: "(?=abf)"},
+	}
+	for _, rule := range invalidRules {
+		assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{rule}), rule.Name)
+	}
+}
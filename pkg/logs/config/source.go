@@ -118,6 +118,19 @@ func (s *LogSource) GetInfo(key string) InfoProvider {
 	return s.info[key]
 }
 
+// GetOrRegisterInfo returns the info registered with the given key, if there is none
+// the info created by newInfo is registered and returned.
+func (s *LogSource) GetOrRegisterInfo(key string, newInfo func() InfoProvider) InfoProvider {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if i, found := s.info[key]; found {
+		return i
+	}
+	i := newInfo()
+	s.info[key] = i
+	return i
+}
+
 // GetInfoStatus returns a primitive representation of the info for the status page
 func (s *LogSource) GetInfoStatus() map[string][]string {
 	s.lock.Lock()
@@ -28,6 +28,13 @@ func (s *LogSourceSuite) TestInputs() {
 
 }
 
+func (s *LogSourceSuite) TestGetOrRegisterInfo() {
+	s.source = NewLogSource("", nil)
+	info := s.source.GetOrRegisterInfo("foo", func() InfoProvider { return NewCountInfo("foo") })
+	s.Equal(info, s.source.GetInfo("foo"))
+	s.Equal(info, s.source.GetOrRegisterInfo("foo", func() InfoProvider { return NewCountInfo("foo") }))
+}
+
 func TestTrackerSuite(t *testing.T) {
 	suite.Run(t, new(LogSourceSuite))
 }
@@ -0,0 +1,197 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package decoder
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// autoMultiLineInfoKey is the key used to surface the auto multi-line detection state on the status page.
+const autoMultiLineInfoKey = "Auto multi-line detection"
+
+// newContentFormat is a well-known line prefix that marks the beginning of a new log message.
+type newContentFormat struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// newContentFormats is the catalogue of prefixes tested against the sampled lines,
+// the order matters as the first matching format gets the point for a given line.
+var newContentFormats = []newContentFormat{
+	{"RFC3339", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)},
+	{"date time with optional millis", regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}([.,]\d+)?`)},
+	{"bracketed date time", regexp.MustCompile(`^\[\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}`)},
+	{"slashed date time", regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`)},
+	{"ANSIC/UnixDate", regexp.MustCompile(`^[A-Z][a-z]{2} [A-Z][a-z]{2} +\d{1,2} \d{2}:\d{2}:\d{2}`)},
+	{"RFC1123", regexp.MustCompile(`^[A-Z][a-z]{2}, \d{2} [A-Z][a-z]{2} \d{4} \d{2}:\d{2}:\d{2}`)},
+	{"syslog timestamp", regexp.MustCompile(`^[A-Z][a-z]{2} +\d{1,2} \d{2}:\d{2}:\d{2}`)},
+	{"java SimpleFormatter", regexp.MustCompile(`^[A-Z][a-z]{2} \d{1,2}, \d{4} \d{1,2}:\d{2}:\d{2} (AM|PM)`)},
+	{"common log format", regexp.MustCompile(`^\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2}`)},
+	{"time of day", regexp.MustCompile(`^\d{2}:\d{2}:\d{2}([.,]\d+)?\s`)},
+	{"level prefix", regexp.MustCompile(`^\[?(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERROR|SEVERE|FATAL|CRITICAL)\b`)},
+	{"glog prefix", regexp.MustCompile(`^[IWEF]\d{4} \d{2}:\d{2}:\d{2}`)},
+}
+
+// scoredFormat tracks how many sampled lines a format matched.
+type scoredFormat struct {
+	format newContentFormat
+	score  int
+}
+
+// AutoMultiLineHandler samples the first lines of a source to detect a new content
+// pattern. While detecting, lines are forwarded as single lines; once a pattern
+// has been found with enough confidence, lines are aggregated with a MultiLineHandler,
+// otherwise the handler keeps on behaving like a SingleLineHandler.
+type AutoMultiLineHandler struct {
+	inputChan         chan *Message
+	outputChan        chan *Message
+	singleLineHandler *SingleLineHandler
+	multiLineHandler  *MultiLineHandler
+	scoredFormats     []*scoredFormat
+	linesToAssess     int
+	linesTested       int
+	matchThreshold    float64
+	detectionTimeout  time.Duration
+	detectionStart    time.Time
+	detecting         bool
+	flushTimeout      time.Duration
+	lineLimit         int
+	source            *config.LogSource
+	status            *config.MappedInfo
+}
+
+// NewAutoMultiLineHandler returns a new AutoMultiLineHandler.
+func NewAutoMultiLineHandler(outputChan chan *Message, source *config.LogSource, linesToAssess int, matchThreshold float64, detectionTimeout time.Duration, flushTimeout time.Duration, lineLimit int) *AutoMultiLineHandler {
+	scoredFormats := make([]*scoredFormat, 0, len(newContentFormats))
+	for _, format := range newContentFormats {
+		scoredFormats = append(scoredFormats, &scoredFormat{format: format})
+	}
+	for _, pattern := range config.AutoMultiLineExtraPatterns() {
+		scoredFormats = append(scoredFormats, &scoredFormat{format: newContentFormat{name: "user defined", re: pattern}})
+	}
+
+	h := &AutoMultiLineHandler{
+		inputChan:         make(chan *Message),
+		outputChan:        outputChan,
+		singleLineHandler: NewSingleLineHandler(outputChan, lineLimit),
+		scoredFormats:     scoredFormats,
+		linesToAssess:     linesToAssess,
+		matchThreshold:    matchThreshold,
+		detectionTimeout:  detectionTimeout,
+		detecting:         true,
+		flushTimeout:      flushTimeout,
+		lineLimit:         lineLimit,
+		source:            source,
+	}
+
+	// Since a single source can have multiple tailers - each with their own decoder instance,
+	// make sure the detection state is shared on the status page.
+	if existingInfo, ok := source.GetInfo(autoMultiLineInfoKey).(*config.MappedInfo); ok {
+		h.status = existingInfo
+	} else {
+		h.status = config.NewMappedInfo(autoMultiLineInfoKey)
+		source.RegisterInfo(h.status)
+	}
+	h.status.SetMessage("state", fmt.Sprintf("Sampling the first %d lines", linesToAssess))
+	return h
+}
+
+// Handle forward lines to inputChan to process them.
+func (h *AutoMultiLineHandler) Handle(input *Message) {
+	h.inputChan <- input
+}
+
+// Stop stops the handler.
+func (h *AutoMultiLineHandler) Stop() {
+	close(h.inputChan)
+}
+
+// Start starts the handler.
+func (h *AutoMultiLineHandler) Start() {
+	go h.run()
+}
+
+// run consumes new lines and processes them, the output channel is closed
+// by the delegated multiline handler when a pattern has been detected.
+func (h *AutoMultiLineHandler) run() {
+	for message := range h.inputChan {
+		h.process(message)
+	}
+	if h.multiLineHandler != nil {
+		h.multiLineHandler.Stop()
+		return
+	}
+	close(h.outputChan)
+}
+
+// process scores the line against the catalogue while detecting and
+// forwards it to the handler in charge.
+func (h *AutoMultiLineHandler) process(message *Message) {
+	if !h.detecting {
+		if h.multiLineHandler != nil {
+			h.multiLineHandler.Handle(message)
+		} else {
+			h.singleLineHandler.process(message)
+		}
+		return
+	}
+
+	if h.linesTested == 0 {
+		h.detectionStart = time.Now()
+	}
+	h.score(message.Content)
+	h.singleLineHandler.process(message)
+
+	if h.linesTested >= h.linesToAssess || time.Since(h.detectionStart) > h.detectionTimeout {
+		h.detectPattern()
+	}
+}
+
+// score gives a point to the first format matching the line.
+func (h *AutoMultiLineHandler) score(content []byte) {
+	h.linesTested++
+	for i, scored := range h.scoredFormats {
+		if scored.format.re.Match(content) {
+			scored.score++
+			// keep the formats sorted by score to test the most likely ones first
+			for ; i > 0 && h.scoredFormats[i-1].score < scored.score; i-- {
+				h.scoredFormats[i-1], h.scoredFormats[i] = h.scoredFormats[i], h.scoredFormats[i-1]
+			}
+			return
+		}
+	}
+}
+
+// detectPattern ends the detection phase and switches to multiline aggregation
+// when the best format matched enough of the sampled lines.
+func (h *AutoMultiLineHandler) detectPattern() {
+	h.detecting = false
+	best := h.scoredFormats[0]
+	confidence := float64(best.score) / float64(h.linesTested)
+
+	if best.score == 0 || confidence < h.matchThreshold {
+		log.Debugf("No multi-line pattern detected for source %s: best match %.2f is below threshold %.2f", h.source.Name, confidence, h.matchThreshold)
+		h.status.SetMessage("state", fmt.Sprintf("No pattern detected after %d lines (best confidence %.0f%% below %.0f%%), using single line handling", h.linesTested, confidence*100, h.matchThreshold*100))
+		return
+	}
+
+	log.Debugf("Multi-line pattern %s (%s) detected for source %s with confidence %.2f", best.format.re.String(), best.format.name, h.source.Name, confidence)
+	h.status.SetMessage("state", fmt.Sprintf("Detected pattern %s (%s) after %d lines with %.0f%% confidence", best.format.re.String(), best.format.name, h.linesTested, confidence*100))
+
+	h.multiLineHandler = NewMultiLineHandler(h.outputChan, best.format.re, h.flushTimeout, h.lineLimit)
+	if existingInfo, ok := h.source.GetInfo(h.multiLineHandler.countInfo.InfoKey()).(*config.CountInfo); ok {
+		h.multiLineHandler.countInfo = existingInfo
+	} else {
+		h.source.RegisterInfo(h.multiLineHandler.countInfo)
+	}
+	h.multiLineHandler.Start()
+	h.scoredFormats = nil
+}
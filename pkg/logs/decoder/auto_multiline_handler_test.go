@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package decoder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func newTestAutoMultiLineHandler(outputChan chan *Message, linesToAssess int) (*AutoMultiLineHandler, *config.LogSource) {
+	source := config.NewLogSource("config", &config.LogsConfig{})
+	h := NewAutoMultiLineHandler(outputChan, source, linesToAssess, 0.5, time.Minute, 10*time.Millisecond, 100)
+	return h, source
+}
+
+func TestAutoMultiLineHandlerDetectsPattern(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	h, source := newTestAutoMultiLineHandler(outputChan, 2)
+	h.Start()
+
+	// sampled lines are sent as single lines
+	h.Handle(getDummyMessageWithLF("2020-10-17 12:00:00,123 first"))
+	h.Handle(getDummyMessageWithLF("2020-10-17 12:00:01,456 second"))
+	assert.Equal(t, "2020-10-17 12:00:00,123 first", string((<-outputChan).Content))
+	assert.Equal(t, "2020-10-17 12:00:01,456 second", string((<-outputChan).Content))
+
+	// following lines are aggregated with the detected pattern
+	h.Handle(getDummyMessageWithLF("2020-10-17 12:00:02,789 exception"))
+	h.Handle(getDummyMessageWithLF("  at com.example.Foo.bar(Foo.java:42)"))
+	h.Handle(getDummyMessageWithLF("2020-10-17 12:00:03,000 recovered"))
+
+	output := <-outputChan
+	assert.Equal(t, "2020-10-17 12:00:02,789 exception\\n  at com.example.Foo.bar(Foo.java:42)", string(output.Content))
+	output = <-outputChan
+	assert.Equal(t, "2020-10-17 12:00:03,000 recovered", string(output.Content))
+
+	info := source.GetInfoStatus()[autoMultiLineInfoKey]
+	assert.Len(t, info, 1)
+	assert.True(t, strings.HasPrefix(info[0], "Detected pattern"))
+
+	h.Stop()
+	_, isOpen := <-outputChan
+	assert.False(t, isOpen)
+}
+
+func TestAutoMultiLineHandlerFallsBackToSingleLine(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	h, source := newTestAutoMultiLineHandler(outputChan, 3)
+	h.Start()
+
+	h.Handle(getDummyMessageWithLF("2020-10-17 12:00:00 first"))
+	h.Handle(getDummyMessageWithLF("no timestamp"))
+	h.Handle(getDummyMessageWithLF("still no timestamp"))
+	h.Handle(getDummyMessageWithLF("a single line"))
+
+	assert.Equal(t, "2020-10-17 12:00:00 first", string((<-outputChan).Content))
+	assert.Equal(t, "no timestamp", string((<-outputChan).Content))
+	assert.Equal(t, "still no timestamp", string((<-outputChan).Content))
+	assert.Equal(t, "a single line", string((<-outputChan).Content))
+
+	info := source.GetInfoStatus()[autoMultiLineInfoKey]
+	assert.Len(t, info, 1)
+	assert.True(t, strings.HasPrefix(info[0], "No pattern detected"))
+
+	h.Stop()
+	_, isOpen := <-outputChan
+	assert.False(t, isOpen)
+}
+
+func TestAutoMultiLineHandlerScoresMostLikelyFormatFirst(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	h, _ := newTestAutoMultiLineHandler(outputChan, 10)
+
+	h.score([]byte("ERROR something went wrong"))
+	h.score([]byte("ERROR something else went wrong"))
+	h.score([]byte("2020-10-17T12:00:00Z started"))
+
+	assert.Equal(t, "level prefix", h.scoredFormats[0].format.name)
+	assert.Equal(t, 2, h.scoredFormats[0].score)
+	assert.Equal(t, 3, h.linesTested)
+}
+
+func TestNewContentFormats(t *testing.T) {
+	lines := map[string]string{
+		"2020-10-17T12:00:00.123Z foo":         "RFC3339",
+		"2020-10-17T12:00:00+02:00 foo":        "RFC3339",
+		"2020-10-17 12:00:00,123 INFO foo":     "date time with optional millis",
+		"[2020-10-17 12:00:00] foo":            "bracketed date time",
+		"2020/10/17 12:00:00 foo":              "slashed date time",
+		"Sat Oct 17 12:00:00 2020 foo":         "ANSIC/UnixDate",
+		"Sat, 17 Oct 2020 12:00:00 UTC foo":    "RFC1123",
+		"Oct 17 12:00:00 host foo":             "syslog timestamp",
+		"Oct 17, 2020 1:00:00 PM foo":          "java SimpleFormatter",
+		"17/Oct/2020:12:00:00 +0000 foo":       "common log format",
+		"12:00:00.123 foo":                     "time of day",
+		"[WARN] foo":                           "level prefix",
+		"I1017 12:00:00.123456 1 main.go] foo": "glog prefix",
+	}
+	for line, expected := range lines {
+		var matched string
+		for _, format := range newContentFormats {
+			if format.re.MatchString(line) {
+				matched = format.name
+				break
+			}
+		}
+		assert.Equal(t, expected, matched, line)
+	}
+	for _, line := range []string{"  at com.example.Foo.bar(Foo.java:42)", "Traceback (most recent call last):", "\tat foo"} {
+		for _, format := range newContentFormats {
+			assert.False(t, format.re.MatchString(line), line)
+		}
+	}
+}
@@ -106,6 +106,17 @@ func NewDecoderWithEndLineMatcher(source *config.LogSource, parser parser.Parser
 			lineHandler = lh
 		}
 	}
+	if lineHandler == nil && source.Config.AutoMultiLineEnabled() {
+		linesToAssess := source.Config.AutoMultiLineSampleSize
+		if linesToAssess <= 0 {
+			linesToAssess = config.AutoMultiLineSampleSize()
+		}
+		matchThreshold := source.Config.AutoMultiLineMatchThreshold
+		if matchThreshold <= 0 {
+			matchThreshold = config.AutoMultiLineMatchThreshold()
+		}
+		lineHandler = NewAutoMultiLineHandler(outputChan, source, linesToAssess, matchThreshold, config.AutoMultiLineMatchTimeout(), config.AggregationTimeout(), lineLimit)
+	}
 	if lineHandler == nil {
 		lineHandler = NewSingleLineHandler(outputChan, lineLimit)
 	}
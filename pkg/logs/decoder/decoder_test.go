@@ -303,3 +303,23 @@ func TestDecoderWithDecodingParser(t *testing.T) {
 
 	d.Stop()
 }
+
+func TestDecoderWithAutoMultiLine(t *testing.T) {
+	enabled := true
+	source := config.NewLogSource("config", &config.LogsConfig{AutoMultiLine: &enabled, AutoMultiLineSampleSize: 10})
+	InitializeDecoder(source, parser.NoopParser)
+	assert.NotNil(t, source.GetInfo(autoMultiLineInfoKey))
+
+	// an explicit multi_line rule takes precedence over the detection
+	source = config.NewLogSource("config", &config.LogsConfig{
+		AutoMultiLine:   &enabled,
+		ProcessingRules: []*config.ProcessingRule{{Type: config.MultiLine, Name: "numbers", Pattern: "[0-9]"}},
+	})
+	assert.Nil(t, config.CompileProcessingRules(source.Config.ProcessingRules))
+	InitializeDecoder(source, parser.NoopParser)
+	assert.Nil(t, source.GetInfo(autoMultiLineInfoKey))
+
+	source = config.NewLogSource("config", &config.LogsConfig{})
+	InitializeDecoder(source, parser.NoopParser)
+	assert.Nil(t, source.GetInfo(autoMultiLineInfoKey))
+}
@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/tcp"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/udp"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/processor"
+	"github.com/DataDog/datadog-agent/pkg/logs/sender"
+)
+
+const (
+	// warningPeriod is the number of dropped messages between two warnings.
+	warningPeriod = 1000
+
+	maxBatchSize   = 200
+	maxContentSize = 1000000
+
+	minRetryBackoff = 100 * time.Millisecond
+	maxRetryBackoff = 10 * time.Second
+)
+
+// Forwarder sends a copy of the logs to a destination that is not a Datadog intake,
+// with its own format. The copies are queued without blocking the pipelines: when the
+// destination can not keep up, the messages that do not fit in the queue are dropped.
+type Forwarder struct {
+	config              *config.ForwarderConfig
+	encoder             processor.Encoder
+	serializer          sender.Serializer
+	destination         client.Destination
+	destinationsContext *client.DestinationsContext
+	batchWait           time.Duration
+	inputChan           chan *message.Message
+	done                chan struct{}
+}
+
+// NewForwarder returns a new forwarder: syslog forwarders send a message per frame formatted
+// according to RFC 5424, HTTP forwarders send batches of newline delimited JSON objects.
+func NewForwarder(forwarderConfig *config.ForwarderConfig, destinationsContext *client.DestinationsContext) *Forwarder {
+	forwarder := &Forwarder{
+		config:              forwarderConfig,
+		destinationsContext: destinationsContext,
+		batchWait:           coreConfig.DefaultBatchWait * time.Second,
+	}
+	switch forwarderConfig.Type {
+	case config.SyslogForwarder:
+		forwarder.encoder = processor.SyslogEncoder
+		if forwarderConfig.Protocol == config.UDPType {
+			forwarder.destination = udp.NewDestination(forwarderConfig.Host, forwarderConfig.Port, destinationsContext)
+		} else {
+			forwarder.destination = tcp.NewSyslogDestination(config.Endpoint{
+				Host:   forwarderConfig.Host,
+				Port:   forwarderConfig.Port,
+				UseSSL: forwarderConfig.UseSSL,
+			}, destinationsContext)
+		}
+	case config.HTTPForwarder:
+		forwarder.encoder = processor.JSONEncoder
+		forwarder.serializer = sender.LineSerializer
+		forwarder.destination = http.NewDestinationWithURL(forwarderConfig.URL, http.NDJSONContentType, destinationsContext)
+	}
+	return forwarder
+}
+
+// Start starts the forwarder.
+func (f *Forwarder) Start() {
+	f.inputChan = make(chan *message.Message, config.ChanSize)
+	f.done = make(chan struct{})
+	go f.run()
+}
+
+// Stop stops the forwarder,
+// this call blocks until the queued messages are sent or the destinations context is cancelled.
+func (f *Forwarder) Stop() {
+	close(f.inputChan)
+	<-f.done
+}
+
+// forward encodes a copy of the message and queues it.
+func (f *Forwarder) forward(msg *message.Message, redactedMsg []byte) {
+	content, err := f.encoder.Encode(msg, redactedMsg)
+	if err != nil {
+		log.Errorf("Unable to encode message for forwarder %s: %v", f.config.Name, err)
+		return
+	}
+	forwarded := message.NewMessage(content, msg.Origin, msg.GetStatus(), msg.IngestionTimestamp)
+	forwarded.Timestamp = msg.Timestamp
+	select {
+	case f.inputChan <- forwarded:
+	default:
+		// TODO: Display the warning in the status
+		if dropped, _ := metrics.DestinationLogsDropped.Get(f.config.Name).(*expvar.Int); dropped == nil || dropped.Value()%warningPeriod == 0 {
+			log.Warnf("Some logs forwarded to %v were dropped", f.config.Name)
+		}
+		metrics.DestinationLogsDropped.Add(f.config.Name, 1)
+		metrics.TlmLogsDropped.Inc(f.config.Name)
+	}
+}
+
+func (f *Forwarder) run() {
+	defer close(f.done)
+	if f.serializer == nil {
+		for msg := range f.inputChan {
+			f.send(msg.Content)
+		}
+		return
+	}
+
+	buffer := sender.NewMessageBuffer(maxBatchSize, maxContentSize)
+	flush := func() {
+		if buffer.IsEmpty() {
+			return
+		}
+		f.send(f.serializer.Serialize(buffer.GetMessages()))
+		buffer.Clear()
+	}
+	ticker := time.NewTicker(f.batchWait)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, isOpen := <-f.inputChan:
+			if !isOpen {
+				flush()
+				return
+			}
+			if !buffer.AddMessage(msg) {
+				flush()
+				buffer.AddMessage(msg)
+			}
+			if buffer.IsFull() {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send sends a payload to the destination, retrying with an exponential backoff
+// as long as the error is retryable and the destinations context is not cancelled.
+func (f *Forwarder) send(payload []byte) {
+	backoff := minRetryBackoff
+	for {
+		err := f.destination.Send(payload)
+		if err == nil {
+			return
+		}
+		metrics.DestinationErrors.Add(1)
+		metrics.TlmDestinationErrors.Inc()
+		if _, ok := err.(*client.RetryableError); !ok {
+			if err != context.Canceled {
+				log.Warnf("Could not forward payload to %s: %v", f.config.Name, err)
+			}
+			return
+		}
+		ctx := f.destinationsContext.Context()
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
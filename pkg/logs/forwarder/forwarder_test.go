@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+func newTestMessage(content string, source *config.LogSource) *message.Message {
+	msg := message.NewMessageWithSource([]byte(content), message.StatusInfo, source, 0)
+	msg.Origin.SetHostname("test-host")
+	return msg
+}
+
+func TestSyslogForwarder(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	destinationsCtx := client.NewDestinationsContext()
+	destinationsCtx.Start()
+	defer destinationsCtx.Stop()
+
+	forwarders := NewForwarders([]*config.ForwarderConfig{{
+		Name: "compliance",
+		Type: config.SyslogForwarder,
+		Host: "127.0.0.1",
+		Port: listener.Addr().(*net.TCPAddr).Port,
+	}}, destinationsCtx)
+	forwarders.Start()
+	defer forwarders.Stop()
+
+	source := config.NewLogSource("", &config.LogsConfig{Service: "app", Forwarders: []string{"compliance"}})
+	other := config.NewLogSource("", &config.LogsConfig{Service: "other"})
+	forwarders.Forward(newTestMessage("ignored", other), []byte("ignored"))
+	forwarders.Forward(newTestMessage("hello world", source), []byte("hello world"))
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	// the frame is prefixed by its length
+	prefix, err := reader.ReadString(' ')
+	require.NoError(t, err)
+	length, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+	require.NoError(t, err)
+	frame := make([]byte, length)
+	_, err = io.ReadFull(reader, frame)
+	require.NoError(t, err)
+
+	syslogMessage := string(frame)
+	assert.True(t, strings.HasPrefix(syslogMessage, "<46>1 "), syslogMessage)
+	assert.True(t, strings.HasSuffix(syslogMessage, " test-host app - - - hello world"), syslogMessage)
+}
+
+func TestHTTPForwarder(t *testing.T) {
+	var mu sync.Mutex
+	var contentType string
+	var lines []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		contentType = r.Header.Get("Content-Type")
+		lines = append(lines, strings.Split(string(body), "\n")...)
+	}))
+	defer server.Close()
+
+	destinationsCtx := client.NewDestinationsContext()
+	destinationsCtx.Start()
+	defer destinationsCtx.Stop()
+
+	forwarders := NewForwarders([]*config.ForwarderConfig{{
+		Name:   "archive",
+		Type:   config.HTTPForwarder,
+		URL:    server.URL + "/logs",
+		Global: true,
+	}}, destinationsCtx)
+	forwarders.Start()
+
+	source := config.NewLogSource("", &config.LogsConfig{Service: "app"})
+	forwarders.Forward(newTestMessage("first", source), []byte("first"))
+	forwarders.Forward(newTestMessage("second", source), []byte("second"))
+	// stopping the forwarders flushes the current batch
+	forwarders.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "application/x-ndjson", contentType)
+	require.Len(t, lines, 2)
+	for i, expected := range []string{"first", "second"} {
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &payload))
+		assert.Equal(t, expected, payload["message"])
+		assert.Equal(t, "app", payload["service"])
+		assert.Equal(t, "test-host", payload["hostname"])
+	}
+}
+
+func TestForwarderDropsMessagesWhenFull(t *testing.T) {
+	destinationsCtx := client.NewDestinationsContext()
+	destinationsCtx.Start()
+	defer destinationsCtx.Stop()
+
+	forwarder := NewForwarder(&config.ForwarderConfig{
+		Name:     "full",
+		Type:     config.SyslogForwarder,
+		Host:     "127.0.0.1",
+		Port:     514,
+		Protocol: config.UDPType,
+	}, destinationsCtx)
+	// the forwarder is not running, nothing consumes the queue
+	forwarder.inputChan = make(chan *message.Message, 1)
+
+	source := config.NewLogSource("", &config.LogsConfig{})
+	forwarder.forward(newTestMessage("first", source), []byte("first"))
+	forwarder.forward(newTestMessage("second", source), []byte("second"))
+	forwarder.forward(newTestMessage("third", source), []byte("third"))
+
+	assert.Len(t, forwarder.inputChan, 1)
+	assert.Equal(t, "2", metrics.DestinationLogsDropped.Get("full").String())
+}
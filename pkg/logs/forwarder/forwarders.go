@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/restart"
+)
+
+// Forwarders hands the processed messages to the forwarders configured for their source,
+// it must be started before and stopped after the pipelines.
+type Forwarders struct {
+	forwarders []*Forwarder
+}
+
+// NewForwarders returns the forwarders matching the configs.
+func NewForwarders(configs []*config.ForwarderConfig, destinationsContext *client.DestinationsContext) *Forwarders {
+	forwarders := &Forwarders{}
+	for _, forwarderConfig := range configs {
+		forwarders.forwarders = append(forwarders.forwarders, NewForwarder(forwarderConfig, destinationsContext))
+	}
+	return forwarders
+}
+
+// Start starts all the forwarders.
+func (f *Forwarders) Start() {
+	for _, forwarder := range f.forwarders {
+		forwarder.Start()
+	}
+}
+
+// Stop stops all the forwarders in parallel.
+func (f *Forwarders) Stop() {
+	stopper := restart.NewParallelStopper()
+	for _, forwarder := range f.forwarders {
+		stopper.Add(forwarder)
+	}
+	stopper.Stop()
+}
+
+// Forward sends a copy of the message to the forwarders of its source.
+func (f *Forwarders) Forward(msg *message.Message, redactedMsg []byte) {
+	for _, forwarder := range f.forwarders {
+		if forwarder.config.ShouldForward(msg.Origin.LogSource) {
+			forwarder.forward(msg, redactedMsg)
+		}
+	}
+}
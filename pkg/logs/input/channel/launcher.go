@@ -33,7 +33,7 @@ func (l *Launcher) Start() {
 }
 
 func (l *Launcher) startNewTailer(source *config.LogSource) {
-	outputChan := l.pipelineProvider.NextPipelineChan()
+	outputChan := l.pipelineProvider.NextPipelineChanForSource(source)
 	tailer := NewTailer(source, source.Config.Channel, outputChan)
 	l.tailers = append(l.tailers, tailer)
 	tailer.Start()
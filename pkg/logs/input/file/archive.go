@@ -0,0 +1,320 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const (
+	gzipArchiveExtension = ".gz"
+	zstdArchiveExtension = ".zst"
+
+	// archiveCompletedOffset is the offset committed in the registry once an archive has been fully sent.
+	archiveCompletedOffset = "eof"
+)
+
+// ArchiveReader reads once the compressed rotated files of a source, e.g. `/var/log/app.log.1.gz`,
+// that may have been missed while the agent was down.
+// The position in each archive is tracked in the registry: the offset is the number of decompressed bytes
+// sent, then archiveCompletedOffset once the archive has been fully sent so that it is never read again.
+type ArchiveReader struct {
+	source     *config.LogSource
+	outputChan chan *message.Message
+	registry   auditor.Registry
+	maxAge     time.Duration
+
+	// archives are the archives left to read, collected on the first call to HasArchives or on start.
+	archives  []string
+	collected bool
+	// startOffsets are the offsets to start reading from for the archives without registry entry.
+	startOffsets map[string]int64
+	// ignored are the archives already handed to another reader.
+	ignored map[string]bool
+
+	shouldStop int32
+	done       chan struct{}
+}
+
+// NewArchiveReader returns a new ArchiveReader reading the archives matching the rotated_archives pattern of the source.
+func NewArchiveReader(source *config.LogSource, outputChan chan *message.Message, registry auditor.Registry) *ArchiveReader {
+	return &ArchiveReader{
+		source:     source,
+		outputChan: outputChan,
+		registry:   registry,
+		// the registry entries of the completed archives expire after the auditor TTL,
+		// older archives are ignored to not send them twice.
+		maxAge:       time.Duration(coreConfig.Datadog.GetInt("logs_config.auditor_ttl")) * time.Hour,
+		startOffsets: make(map[string]int64),
+		done:         make(chan struct{}),
+	}
+}
+
+// Identifier returns the identifier used in the registry for an archive.
+func (r *ArchiveReader) Identifier(path string) string {
+	return fmt.Sprintf("archive:%s", path)
+}
+
+// HasArchives returns true if some archives of the source have not been sent yet.
+func (r *ArchiveReader) HasArchives() bool {
+	if err := r.collect(); err != nil {
+		log.Warnf("Could not collect the rotated archives of %s: %v", r.source.Config.Path, err)
+		return false
+	}
+	return len(r.archives) > 0
+}
+
+// Start starts reading the archives, from the oldest to the most recent one.
+func (r *ArchiveReader) Start() {
+	go r.run()
+}
+
+// Stop stops the reader and returns once the last messages have been forwarded,
+// the archive being read is resumed from its last committed offset on the next start.
+func (r *ArchiveReader) Stop() {
+	atomic.StoreInt32(&r.shouldStop, 1)
+	<-r.done
+}
+
+// IsDone returns true once all the archives have been read or the reader has been stopped.
+func (r *ArchiveReader) IsDone() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *ArchiveReader) run() {
+	defer close(r.done)
+	if err := r.collect(); err != nil {
+		log.Warnf("Could not collect the rotated archives of %s: %v", r.source.Config.Path, err)
+		return
+	}
+	for _, path := range r.archives {
+		if atomic.LoadInt32(&r.shouldStop) != 0 {
+			return
+		}
+		if err := r.readArchive(path); err != nil {
+			log.Warnf("Could not read the rotated archive %s: %v", path, err)
+		}
+	}
+}
+
+// collect collects once the archives to read.
+func (r *ArchiveReader) collect() error {
+	if r.collected {
+		return nil
+	}
+	archives, err := r.collectArchives()
+	if err != nil {
+		return err
+	}
+	r.archives = archives
+	r.collected = true
+	return nil
+}
+
+// collectArchives returns the archives to read sorted by modification time,
+// the ones that are too old or whose content has already been sent are filtered out.
+// The content of the archives modified before the last commit of the live file has been
+// tailed, only the more recent ones are read, starting from the committed offset of the
+// live file for the oldest one as it is the file that was being tailed.
+func (r *ArchiveReader) collectArchives() ([]string, error) {
+	paths, err := filepath.Glob(r.source.Config.RotatedArchives)
+	if err != nil {
+		return nil, err
+	}
+	liveLastUpdated, liveOffset := r.lastLiveCommit()
+	modTimes := make(map[string]time.Time)
+	var archives []string
+	for _, path := range paths {
+		switch filepath.Ext(path) {
+		case gzipArchiveExtension, zstdArchiveExtension:
+		default:
+			log.Debugf("Ignoring %s, only %s and %s archives are supported", path, gzipArchiveExtension, zstdArchiveExtension)
+			continue
+		}
+		if r.ignored[path] || r.registry.GetOffset(r.Identifier(path)) == archiveCompletedOffset {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if r.maxAge > 0 && time.Since(info.ModTime()) > r.maxAge {
+			log.Debugf("Ignoring %s, it was last modified more than %v ago", path, r.maxAge)
+			continue
+		}
+		if !liveLastUpdated.IsZero() && !info.ModTime().After(liveLastUpdated) {
+			log.Debugf("Ignoring %s, it was rotated before the last commit of %s", path, r.source.Config.Path)
+			continue
+		}
+		modTimes[path] = info.ModTime()
+		archives = append(archives, path)
+	}
+	sort.SliceStable(archives, func(i, j int) bool {
+		return modTimes[archives[i]].Before(modTimes[archives[j]])
+	})
+	if !liveLastUpdated.IsZero() && len(archives) > 0 && r.registry.GetOffset(r.Identifier(archives[0])) == "" {
+		r.startOffsets[archives[0]] = liveOffset
+	}
+	return archives, nil
+}
+
+// lastLiveCommit returns the time and the offset of the most recent commit of the files of the source,
+// or the zero time when none of them has been tailed.
+func (r *ArchiveReader) lastLiveCommit() (time.Time, int64) {
+	paths, err := filepath.Glob(r.source.Config.Path)
+	if err != nil || len(paths) == 0 {
+		paths = []string{r.source.Config.Path}
+	}
+	var lastUpdated time.Time
+	var offset int64
+	for _, path := range paths {
+		identifier := fmt.Sprintf("file:%s", path)
+		updated := r.registry.GetLastUpdated(identifier)
+		if !updated.After(lastUpdated) {
+			continue
+		}
+		lastUpdated = updated
+		offset, _ = strconv.ParseInt(r.registry.GetOffset(identifier), 10, 64)
+	}
+	return lastUpdated, offset
+}
+
+// readArchive decompresses an archive and forwards its lines, starting from the last committed offset.
+func (r *ArchiveReader) readArchive(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader, err := newArchiveReader(path, f)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	offset := r.startOffsets[path]
+	if value := r.registry.GetOffset(r.Identifier(path)); value != "" {
+		offset, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid offset %q: %v", value, err)
+		}
+	}
+	if offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, reader, offset); err == io.EOF {
+			log.Debugf("Ignoring %s, its content has already been sent", path)
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Reading the rotated archive %s from offset %d", path, offset)
+	r.source.AddInput(path)
+	defer r.source.RemoveInput(path)
+
+	d := newDecoder(r.source)
+	var last *decoder.Message
+	var lastOffset int64
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		last, lastOffset = r.forwardMessages(d, path, offset)
+	}()
+	d.Start()
+
+	completed := false
+	for atomic.LoadInt32(&r.shouldStop) == 0 {
+		inBuf := make([]byte, 4096)
+		n, readErr := reader.Read(inBuf)
+		if n > 0 {
+			d.InputChan <- decoder.NewInput(inBuf[:n])
+			r.source.BytesRead.Add(int64(n))
+		}
+		if readErr == io.EOF {
+			completed = true
+			break
+		}
+		if readErr != nil {
+			err = readErr
+			break
+		}
+	}
+	d.Stop()
+	<-forwarded
+
+	if last != nil {
+		// the last line commits the completion of the archive only if it has been fully read
+		committedOffset := strconv.FormatInt(lastOffset, 10)
+		if completed {
+			committedOffset = archiveCompletedOffset
+		}
+		r.send(path, last, committedOffset)
+	}
+	return err
+}
+
+// forwardMessages sends the decoded lines to the output channel, except the last one which is
+// returned with its offset once the decoder is flushed so that it can commit the completion of the archive.
+func (r *ArchiveReader) forwardMessages(d *decoder.Decoder, path string, offset int64) (*decoder.Message, int64) {
+	var last *decoder.Message
+	var lastOffset int64
+	for output := range d.OutputChan {
+		offset += int64(output.RawDataLen)
+		// Ignore empty lines
+		if len(output.Content) == 0 {
+			continue
+		}
+		if last != nil {
+			r.send(path, last, strconv.FormatInt(lastOffset, 10))
+		}
+		last, lastOffset = output, offset
+	}
+	return last, lastOffset
+}
+
+// send sends a line of an archive to the output channel.
+func (r *ArchiveReader) send(path string, output *decoder.Message, offset string) {
+	origin := message.NewOrigin(r.source)
+	origin.Identifier = r.Identifier(path)
+	origin.Offset = offset
+	origin.SetTags([]string{fmt.Sprintf("filename:%s", filepath.Base(path))})
+	r.outputChan <- message.NewMessage(output.Content, origin, output.Status, output.IngestionTimestamp)
+}
+
+// newArchiveReader returns a reader decompressing the content of an archive depending on its extension.
+func newArchiveReader(path string, f io.Reader) (io.ReadCloser, error) {
+	switch filepath.Ext(path) {
+	case gzipArchiveExtension:
+		return gzip.NewReader(f)
+	case zstdArchiveExtension:
+		return newZstdReader(f)
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", path)
+	}
+}
@@ -0,0 +1,18 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !zstd
+
+package file
+
+import (
+	"fmt"
+	"io"
+)
+
+// newZstdReader returns an error as the agent has been built without zstd support.
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("zstd archives are not supported, the agent has been built without zstd")
+}
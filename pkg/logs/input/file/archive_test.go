@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func writeGzipArchive(t *testing.T, path string, content string) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	w := gzip.NewWriter(f)
+	_, err = w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func readArchives(t *testing.T, source *config.LogSource, registry *mock.Registry) []*message.Message {
+	outputChan := make(chan *message.Message, 10)
+	reader := NewArchiveReader(source, outputChan, registry)
+	reader.Start()
+	select {
+	case <-reader.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the archives have not been read")
+	}
+	close(outputChan)
+	var messages []*message.Message
+	for msg := range outputChan {
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestArchiveReaderReadsGzipArchive(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-archive-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	path := filepath.Join(testDir, "app.log.1.gz")
+	writeGzipArchive(t, path, "first\n\nsecond\nthird\n")
+	source := config.NewLogSource("", &config.LogsConfig{
+		Type:            config.FileType,
+		Path:            filepath.Join(testDir, "app.log"),
+		RotatedArchives: filepath.Join(testDir, "app.log.*.gz"),
+	})
+
+	messages := readArchives(t, source, mock.NewRegistry())
+	require.Len(t, messages, 3)
+	assert.Equal(t, "first", string(messages[0].Content))
+	assert.Equal(t, "6", messages[0].Origin.Offset)
+	assert.Equal(t, "second", string(messages[1].Content))
+	assert.Equal(t, "14", messages[1].Origin.Offset)
+	assert.Equal(t, "third", string(messages[2].Content))
+	assert.Equal(t, archiveCompletedOffset, messages[2].Origin.Offset)
+	for _, msg := range messages {
+		assert.Equal(t, "archive:"+path, msg.Origin.Identifier)
+		assert.Equal(t, []string{"filename:app.log.1.gz"}, msg.Origin.Tags())
+	}
+	assert.Equal(t, int64(20), source.BytesRead.Value())
+}
+
+func TestArchiveReaderResumesFromOffset(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-archive-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	writeGzipArchive(t, filepath.Join(testDir, "app.log.1.gz"), "first\nsecond\n")
+	source := config.NewLogSource("", &config.LogsConfig{
+		Type:            config.FileType,
+		Path:            filepath.Join(testDir, "app.log"),
+		RotatedArchives: filepath.Join(testDir, "app.log.*.gz"),
+	})
+	registry := mock.NewRegistry()
+	registry.SetOffset("6")
+
+	messages := readArchives(t, source, registry)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "second", string(messages[0].Content))
+	assert.Equal(t, archiveCompletedOffset, messages[0].Origin.Offset)
+}
+
+func TestArchiveReaderSkipsCompletedArchives(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-archive-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	writeGzipArchive(t, filepath.Join(testDir, "app.log.1.gz"), "first\n")
+	source := config.NewLogSource("", &config.LogsConfig{
+		Type:            config.FileType,
+		Path:            filepath.Join(testDir, "app.log"),
+		RotatedArchives: filepath.Join(testDir, "app.log.*.gz"),
+	})
+	registry := mock.NewRegistry()
+	registry.SetOffset(archiveCompletedOffset)
+
+	assert.Len(t, readArchives(t, source, registry), 0)
+}
+
+func TestArchiveReaderCollectsArchivesByModificationTime(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-archive-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	now := time.Now()
+	archives := map[string]time.Time{
+		"app.log.1.gz":  now.Add(-time.Hour),
+		"app.log.2.gz":  now.Add(-2 * time.Hour),
+		"app.log.3.gz":  now.Add(-48 * time.Hour),
+		"app.log.4.bz2": now.Add(-3 * time.Hour),
+	}
+	for name, modTime := range archives {
+		path := filepath.Join(testDir, name)
+		writeGzipArchive(t, path, "line\n")
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	source := config.NewLogSource("", &config.LogsConfig{
+		Type:            config.FileType,
+		Path:            filepath.Join(testDir, "app.log"),
+		RotatedArchives: filepath.Join(testDir, "app.log.*"),
+	})
+	reader := NewArchiveReader(source, nil, mock.NewRegistry())
+	reader.maxAge = 24 * time.Hour
+
+	paths, err := reader.collectArchives()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(testDir, "app.log.2.gz"), filepath.Join(testDir, "app.log.1.gz")}, paths)
+}
+
+func TestArchiveReaderSkipsArchivesRotatedBeforeLastLiveCommit(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-archive-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	now := time.Now()
+	archives := map[string]time.Time{
+		"app.log.1.gz": now.Add(-time.Hour),
+		"app.log.2.gz": now.Add(-2 * time.Hour),
+		"app.log.3.gz": now.Add(-3 * time.Hour),
+	}
+	for name, modTime := range archives {
+		path := filepath.Join(testDir, name)
+		writeGzipArchive(t, path, "first\nsecond\n")
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	source := config.NewLogSource("", &config.LogsConfig{
+		Type:            config.FileType,
+		Path:            filepath.Join(testDir, "app.log"),
+		RotatedArchives: filepath.Join(testDir, "app.log.*.gz"),
+	})
+	registry := mock.NewRegistry()
+	registry.SetLastUpdated(now.Add(-150 * time.Minute))
+	reader := NewArchiveReader(source, nil, registry)
+	reader.maxAge = 24 * time.Hour
+
+	// the content of app.log.3.gz has been tailed, app.log.2.gz is the file that was being tailed
+	paths, err := reader.collectArchives()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(testDir, "app.log.2.gz"), filepath.Join(testDir, "app.log.1.gz")}, paths)
+}
+
+func TestArchiveReaderResumesFromLiveOffset(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-archive-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	path := filepath.Join(testDir, "app.log.1.gz")
+	writeGzipArchive(t, path, "first\nsecond\n")
+	source := config.NewLogSource("", &config.LogsConfig{
+		Type:            config.FileType,
+		Path:            filepath.Join(testDir, "app.log"),
+		RotatedArchives: filepath.Join(testDir, "app.log.*.gz"),
+	})
+	registry := mock.NewRegistry()
+	registry.SetLastUpdated(time.Now().Add(-time.Hour))
+	reader := NewArchiveReader(source, nil, registry)
+
+	// the live file was committed at offset 6 before being rotated
+	reader.startOffsets[path] = 6
+	outputChan := make(chan *message.Message, 10)
+	reader.outputChan = outputChan
+	require.NoError(t, reader.readArchive(path))
+	close(outputChan)
+	var messages []*message.Message
+	for msg := range outputChan {
+		messages = append(messages, msg)
+	}
+	require.Len(t, messages, 1)
+	assert.Equal(t, "second", string(messages[0].Content))
+
+	// the whole archive has already been tailed
+	reader.startOffsets[path] = 100
+	reader.outputChan = make(chan *message.Message, 10)
+	require.NoError(t, reader.readArchive(path))
+	assert.Len(t, reader.outputChan, 0)
+}
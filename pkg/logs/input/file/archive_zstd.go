@@ -0,0 +1,19 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build zstd
+
+package file
+
+import (
+	"io"
+
+	"github.com/DataDog/zstd"
+)
+
+// newZstdReader returns a reader decompressing a zstd stream.
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	return zstd.NewReader(r), nil
+}
@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build zstd
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestArchiveReaderReadsZstdArchive(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-archive-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	content, err := zstd.Compress(nil, []byte("first\nsecond\n"))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(testDir, "app.log.1.zst"), content, 0644))
+	source := config.NewLogSource("", &config.LogsConfig{
+		Type:            config.FileType,
+		Path:            filepath.Join(testDir, "app.log"),
+		RotatedArchives: filepath.Join(testDir, "app.log.*.zst"),
+	})
+
+	messages := readArchives(t, source, mock.NewRegistry())
+	require.Len(t, messages, 2)
+	assert.Equal(t, "first", string(messages[0].Content))
+	assert.Equal(t, "second", string(messages[1].Content))
+	assert.Equal(t, archiveCompletedOffset, messages[1].Origin.Offset)
+}
@@ -31,6 +31,8 @@ type Scanner struct {
 	tailingLimit        int
 	fileProvider        *Provider
 	tailers             map[string]*Tailer
+	archiveReaders      map[*config.LogSource]*ArchiveReader
+	knownArchives       map[*config.LogSource]map[string]bool
 	registry            auditor.Registry
 	tailerSleepDuration time.Duration
 	stop                chan struct{}
@@ -45,6 +47,8 @@ func NewScanner(sources *config.LogSources, tailingLimit int, pipelineProvider p
 		removedSources:      sources.GetRemovedForType(config.FileType),
 		fileProvider:        NewProvider(tailingLimit),
 		tailers:             make(map[string]*Tailer),
+		archiveReaders:      make(map[*config.LogSource]*ArchiveReader),
+		knownArchives:       make(map[*config.LogSource]map[string]bool),
 		registry:            registry,
 		tailerSleepDuration: tailerSleepDuration,
 		stop:                make(chan struct{}),
@@ -90,6 +94,10 @@ func (s *Scanner) cleanup() {
 		stopper.Add(tailer)
 		delete(s.tailers, tailer.file.GetScanKey())
 	}
+	for source, reader := range s.archiveReaders {
+		stopper.Add(reader)
+		delete(s.archiveReaders, source)
+	}
 	stopper.Stop()
 }
 
@@ -101,7 +109,9 @@ func (s *Scanner) cleanup() {
 // The Scanner needs to stop that previous tailer,
 // and start a new one for the new file.
 func (s *Scanner) scan() {
-	files := s.fileProvider.FilesToTail(s.activeSources)
+	s.checkArchiveReaders()
+	s.checkNewArchives()
+	files := s.fileProvider.FilesToTail(s.tailableSources())
 	filesTailed := make(map[string]bool)
 	tailersLen := len(s.tailers)
 
@@ -166,6 +176,11 @@ func (s *Scanner) scan() {
 // addSource keeps track of the new source and launch new tailers for this source.
 func (s *Scanner) addSource(source *config.LogSource) {
 	s.activeSources = append(s.activeSources, source)
+	if source.Config.RotatedArchives != "" {
+		// the rotated archives are read first, the files are tailed once they have all been sent.
+		s.startArchiveReader(source)
+		return
+	}
 	s.launchTailers(source)
 }
 
@@ -178,6 +193,91 @@ func (s *Scanner) removeSource(source *config.LogSource) {
 			break
 		}
 	}
+	if reader, exists := s.archiveReaders[source]; exists {
+		go reader.Stop()
+		delete(s.archiveReaders, source)
+	}
+	delete(s.knownArchives, source)
+}
+
+// startArchiveReader starts reading the rotated archives of a source.
+func (s *Scanner) startArchiveReader(source *config.LogSource) {
+	reader := s.newArchiveReader(source)
+	reader.Start()
+	s.archiveReaders[source] = reader
+}
+
+// newArchiveReader returns a reader for the archives of the source that have not been seen yet,
+// they are collected and marked as known.
+func (s *Scanner) newArchiveReader(source *config.LogSource) *ArchiveReader {
+	known, exists := s.knownArchives[source]
+	if !exists {
+		known = make(map[string]bool)
+		s.knownArchives[source] = known
+	}
+	reader := NewArchiveReader(source, s.pipelineProvider.NextPipelineChanForSource(source), s.registry)
+	reader.ignored = known
+	if reader.HasArchives() {
+		for _, path := range reader.archives {
+			known[path] = true
+		}
+	}
+	return reader
+}
+
+// checkArchiveReaders launches the tailers of the sources whose rotated archives have all been read.
+func (s *Scanner) checkArchiveReaders() {
+	for source, reader := range s.archiveReaders {
+		if !reader.IsDone() {
+			continue
+		}
+		delete(s.archiveReaders, source)
+		s.launchTailers(source)
+	}
+}
+
+// checkNewArchives starts reading the rotated archives that appeared since the last scan for the sources
+// whose files are not tailed, the archives of the tailed files are left out as their content has already been sent.
+func (s *Scanner) checkNewArchives() {
+	for _, source := range s.activeSources {
+		if source.Config.RotatedArchives == "" {
+			continue
+		}
+		if _, exists := s.archiveReaders[source]; exists {
+			continue
+		}
+		reader := s.newArchiveReader(source)
+		if !reader.HasArchives() || s.isTailed(source) {
+			continue
+		}
+		reader.Start()
+		s.archiveReaders[source] = reader
+	}
+}
+
+// isTailed returns true if a file of the source is being tailed.
+func (s *Scanner) isTailed(source *config.LogSource) bool {
+	for _, tailer := range s.tailers {
+		if tailer.file.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// tailableSources returns the active sources whose files can be tailed,
+// the ones whose rotated archives are still being read are left out.
+func (s *Scanner) tailableSources() []*config.LogSource {
+	if len(s.archiveReaders) == 0 {
+		return s.activeSources
+	}
+	sources := make([]*config.LogSource, 0, len(s.activeSources))
+	for _, source := range s.activeSources {
+		if _, exists := s.archiveReaders[source]; !exists {
+			sources = append(sources, source)
+		}
+	}
+	return sources
 }
 
 // launch launches new tailers for a new source.
@@ -211,7 +311,7 @@ func (s *Scanner) launchTailers(source *config.LogSource) {
 // startNewTailer creates a new tailer, making it tail from the last committed offset, the beginning or the end of the file,
 // returns true if the operation succeeded, false otherwise
 func (s *Scanner) startNewTailer(file *File, m config.TailingMode) bool {
-	tailer := s.createTailer(file, s.pipelineProvider.NextPipelineChan())
+	tailer := s.createTailer(file, s.pipelineProvider.NextPipelineChanForSource(file.Source))
 
 	var offset int64
 	var whence int
@@ -393,3 +393,83 @@ func TestScannerScanWithTooManyFiles(t *testing.T) {
 func getScanKey(path string, source *config.LogSource) string {
 	return NewFile(path, source, false).GetScanKey()
 }
+
+func TestScannerReadsRotatedArchivesBeforeTailing(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+
+	path := fmt.Sprintf("%s/test.log", testDir)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("live\n"), 0644))
+	writeGzipArchive(t, fmt.Sprintf("%s/test.log.1.gz", testDir), "archived\n")
+
+	scanner := NewScanner(config.NewLogSources(), 3, mock.NewMockProvider(), auditor.NewRegistry(), 20*time.Millisecond)
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path, TailingMode: "beginning", RotatedArchives: path + ".*.gz"})
+	scanner.addSource(source)
+	defer scanner.cleanup()
+
+	// the file is not tailed until the archives have been read
+	assert.Equal(t, 0, len(scanner.tailers))
+	reader := scanner.archiveReaders[source]
+	msg := <-reader.outputChan
+	assert.Equal(t, "archived", string(msg.Content))
+	<-reader.done
+	scanner.scan()
+	assert.Equal(t, 0, len(scanner.archiveReaders))
+	assert.Equal(t, 1, len(scanner.tailers))
+
+	msg = <-scanner.tailers[getScanKey(path, source)].outputChan
+	assert.Equal(t, "live", string(msg.Content))
+}
+
+func TestScannerReadsNewArchivesOfUntailedSources(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+
+	path := fmt.Sprintf("%s/test.log", testDir)
+	scanner := NewScanner(config.NewLogSources(), 3, mock.NewMockProvider(), auditor.NewRegistry(), 20*time.Millisecond)
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path, TailingMode: "beginning", RotatedArchives: path + ".*.gz"})
+	scanner.addSource(source)
+	defer scanner.cleanup()
+	<-scanner.archiveReaders[source].done
+	scanner.scan()
+	assert.Equal(t, 0, len(scanner.archiveReaders))
+
+	// the file does not exist, the archive that appeared is read
+	writeGzipArchive(t, fmt.Sprintf("%s/test.log.1.gz", testDir), "archived\n")
+	scanner.scan()
+	reader := scanner.archiveReaders[source]
+	assert.NotNil(t, reader)
+	msg := <-reader.outputChan
+	assert.Equal(t, "archived", string(msg.Content))
+	<-reader.done
+
+	// the same archive is not read twice
+	scanner.scan()
+	assert.Equal(t, 0, len(scanner.archiveReaders))
+}
+
+func TestScannerIgnoresNewArchivesOfTailedSources(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+
+	path := fmt.Sprintf("%s/test.log", testDir)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("live\n"), 0644))
+	scanner := NewScanner(config.NewLogSources(), 3, mock.NewMockProvider(), auditor.NewRegistry(), 20*time.Millisecond)
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path, TailingMode: "beginning", RotatedArchives: path + ".*.gz"})
+	scanner.addSource(source)
+	defer scanner.cleanup()
+	<-scanner.archiveReaders[source].done
+	scanner.scan()
+	assert.Equal(t, 1, len(scanner.tailers))
+	msg := <-scanner.tailers[getScanKey(path, source)].outputChan
+	assert.Equal(t, "live", string(msg.Content))
+
+	// the content of the archive has been tailed before the rotation
+	writeGzipArchive(t, fmt.Sprintf("%s/test.log.1.gz", testDir), "live\n")
+	scanner.scan()
+	assert.Equal(t, 0, len(scanner.archiveReaders))
+	assert.True(t, scanner.knownArchives[source][fmt.Sprintf("%s/test.log.1.gz", testDir)])
+}
@@ -62,30 +62,6 @@ type Tailer struct {
 
 // NewTailer returns an initialized Tailer
 func NewTailer(outputChan chan *message.Message, file *File, sleepDuration time.Duration) *Tailer {
-	// TODO: remove those checks and add to source a reference to a tagProvider and a lineParser.
-	var parser lineParser.Parser
-	var matcher decoder.EndLineMatcher
-	switch file.Source.GetSourceType() {
-	case config.KubernetesSourceType:
-		parser = kubernetes.Parser
-		matcher = &decoder.NewLineMatcher{}
-	case config.DockerSourceType:
-		parser = docker.JSONParser
-		matcher = &decoder.NewLineMatcher{}
-	default:
-		switch file.Source.Config.Encoding {
-		case config.UTF16BE:
-			parser = lineParser.NewDecodingParser(lineParser.UTF16BE)
-			matcher = decoder.NewBytesSequenceMatcher(decoder.Utf16beEOL)
-		case config.UTF16LE:
-			parser = lineParser.NewDecodingParser(lineParser.UTF16LE)
-			matcher = decoder.NewBytesSequenceMatcher(decoder.Utf16leEOL)
-		default:
-			parser = lineParser.NoopParser
-			matcher = &decoder.NewLineMatcher{}
-		}
-	}
-
 	var tagProvider tag.Provider
 	if file.Source.Config.Identifier != "" {
 		tagProvider = tag.NewProvider(containers.BuildTaggerEntityName(file.Source.Config.Identifier))
@@ -99,7 +75,7 @@ func NewTailer(outputChan chan *message.Message, file *File, sleepDuration time.
 	return &Tailer{
 		file:           file,
 		outputChan:     outputChan,
-		decoder:        decoder.NewDecoderWithEndLineMatcher(file.Source, parser, matcher),
+		decoder:        newDecoder(file.Source),
 		tagProvider:    tagProvider,
 		readOffset:     0,
 		sleepDuration:  sleepDuration,
@@ -111,6 +87,34 @@ func NewTailer(outputChan chan *message.Message, file *File, sleepDuration time.
 	}
 }
 
+// newDecoder returns a decoder using the parser and the end of line matcher matching the source.
+func newDecoder(source *config.LogSource) *decoder.Decoder {
+	// TODO: remove those checks and add to source a reference to a tagProvider and a lineParser.
+	var parser lineParser.Parser
+	var matcher decoder.EndLineMatcher
+	switch source.GetSourceType() {
+	case config.KubernetesSourceType:
+		parser = kubernetes.Parser
+		matcher = &decoder.NewLineMatcher{}
+	case config.DockerSourceType:
+		parser = docker.JSONParser
+		matcher = &decoder.NewLineMatcher{}
+	default:
+		switch source.Config.Encoding {
+		case config.UTF16BE:
+			parser = lineParser.NewDecodingParser(lineParser.UTF16BE)
+			matcher = decoder.NewBytesSequenceMatcher(decoder.Utf16beEOL)
+		case config.UTF16LE:
+			parser = lineParser.NewDecodingParser(lineParser.UTF16LE)
+			matcher = decoder.NewBytesSequenceMatcher(decoder.Utf16leEOL)
+		default:
+			parser = lineParser.NoopParser
+			matcher = &decoder.NewLineMatcher{}
+		}
+	}
+	return decoder.NewDecoderWithEndLineMatcher(source, parser, matcher)
+}
+
 // Identifier returns a string that uniquely identifies a source.
 // This is the identifier used in the registry.
 // FIXME(remy): during container rotation, this Identifier() method could return
@@ -130,7 +130,7 @@ func (l *TCPListener) read(tailer *Tailer) ([]byte, error) {
 func (l *TCPListener) startTailer(conn net.Conn) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	tailer := NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChan(), l.read)
+	tailer := NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChanForSource(l.source), l.read)
 	l.tailers = append(l.tailers, tailer)
 	tailer.Start()
 }
@@ -67,7 +67,7 @@ func (l *UDPListener) startNewTailer() error {
 	if err != nil {
 		return err
 	}
-	l.tailer = NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChan(), l.read)
+	l.tailer = NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChanForSource(l.source), l.read)
 	l.tailer.Start()
 	return nil
 }
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxOctetCountDigits is the maximum number of digits of the length of an octet counted frame.
+const maxOctetCountDigits = 9
+
+// FrameReader splits a syslog stream into messages, it supports both the octet counting
+// and the non-transparent framing methods of RFC 6587. The method is detected for each frame:
+// frames starting with a digit are octet counted, the other ones are delimited by a line feed.
+type FrameReader struct {
+	reader       *bufio.Reader
+	maxFrameSize int
+}
+
+// NewFrameReader returns a new FrameReader reading frames of at most maxFrameSize bytes,
+// longer frames are truncated.
+func NewFrameReader(reader io.Reader, maxFrameSize int) *FrameReader {
+	return &FrameReader{
+		reader:       bufio.NewReader(reader),
+		maxFrameSize: maxFrameSize,
+	}
+}
+
+// Next returns the next frame of the stream, it returns io.EOF when the stream is closed.
+func (r *FrameReader) Next() ([]byte, error) {
+	for {
+		first, err := r.reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		var frame []byte
+		if first[0] >= '0' && first[0] <= '9' {
+			frame, err = r.readOctetCounted()
+		} else {
+			frame, err = r.readLine()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(frame) > 0 {
+			return frame, nil
+		}
+		// skip empty frames
+	}
+}
+
+// readOctetCounted reads a `MSG-LEN SP SYSLOG-MSG` frame.
+func (r *FrameReader) readOctetCounted() ([]byte, error) {
+	prefix, err := r.reader.ReadSlice(' ')
+	if err != nil {
+		if err == bufio.ErrBufferFull {
+			err = fmt.Errorf("invalid octet counted frame")
+		}
+		return nil, err
+	}
+	if len(prefix) > maxOctetCountDigits+1 {
+		return nil, fmt.Errorf("invalid octet counted frame length: %q", prefix)
+	}
+	length, err := strconv.Atoi(string(prefix[:len(prefix)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid octet counted frame length: %q", prefix)
+	}
+	size := length
+	if size > r.maxFrameSize {
+		size = r.maxFrameSize
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, frame); err != nil {
+		return nil, err
+	}
+	if length > size {
+		// drop the part of the frame that does not fit
+		if _, err := r.reader.Discard(length - size); err != nil {
+			return nil, err
+		}
+	}
+	return bytes.TrimRight(frame, "\r\n"), nil
+}
+
+// readLine reads a frame delimited by a line feed.
+func (r *FrameReader) readLine() ([]byte, error) {
+	var frame []byte
+	for {
+		line, err := r.reader.ReadSlice('\n')
+		if len(frame)+len(line) <= r.maxFrameSize {
+			frame = append(frame, line...)
+		} else if len(frame) < r.maxFrameSize {
+			frame = append(frame, line[:r.maxFrameSize-len(frame)]...)
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF && len(frame) > 0:
+			// the last frame of the stream may not be delimited
+			return bytes.TrimRight(frame, "\r\n"), nil
+		case err != nil:
+			return nil, err
+		}
+		return bytes.TrimRight(frame, "\r\n"), nil
+	}
+}
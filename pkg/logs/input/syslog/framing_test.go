@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAllFrames(reader *FrameReader) ([]string, error) {
+	var frames []string
+	for {
+		frame, err := reader.Next()
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return frames, err
+		}
+		frames = append(frames, string(frame))
+	}
+}
+
+func TestFrameReaderOctetCounting(t *testing.T) {
+	reader := NewFrameReader(strings.NewReader("11 <13>1 first12 <13>1 second\n"), 100)
+	frames, err := readAllFrames(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"<13>1 first", "<13>1 second"}, frames)
+}
+
+func TestFrameReaderNonTransparent(t *testing.T) {
+	reader := NewFrameReader(strings.NewReader("<13>first\r\n\n<13>second\n<13>last"), 100)
+	frames, err := readAllFrames(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"<13>first", "<13>second", "<13>last"}, frames)
+}
+
+func TestFrameReaderMixedFraming(t *testing.T) {
+	reader := NewFrameReader(strings.NewReader("<13>first\n9 <13>multi\n<13>third\n"), 100)
+	frames, err := readAllFrames(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"<13>first", "<13>multi", "<13>third"}, frames)
+}
+
+func TestFrameReaderTruncatesLongFrames(t *testing.T) {
+	reader := NewFrameReader(strings.NewReader("10 0123456789"+strings.Repeat("a", 5000)+"\n<13>next\n"), 4)
+	frames, err := readAllFrames(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"0123", "aaaa", "<13>"}, frames)
+}
+
+func TestFrameReaderInvalidOctetCount(t *testing.T) {
+	reader := NewFrameReader(strings.NewReader("12a <13>message"), 100)
+	_, err := readAllFrames(reader)
+	assert.NotNil(t, err)
+
+	reader = NewFrameReader(strings.NewReader("20 <13>truncated"), 100)
+	_, err = readAllFrames(reader)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
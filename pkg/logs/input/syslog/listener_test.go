@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+)
+
+func TestTCPListenerReceivesMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Protocol: config.TCPType})
+	listener := NewTCPListener(pp, source)
+	listener.Start()
+	defer listener.Stop()
+
+	conn, err := net.Dial("tcp", listener.listener.Addr().String())
+	require.Nil(t, err)
+	defer conn.Close()
+
+	frame := `<11>1 2020-10-17T12:00:00Z web01 nginx 42 - [req id="7"] upstream down`
+	fmt.Fprintf(conn, "%d %s", len(frame), frame)
+	fmt.Fprintf(conn, "<14>Oct 17 12:00:01 web01 cron[12]: job done\n")
+
+	msg := <-msgChan
+	assert.Equal(t, "upstream down", string(msg.Content))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "web01", msg.Origin.Hostname())
+	assert.Equal(t, "nginx", msg.Origin.Service())
+	assert.Equal(t, "syslog", msg.Origin.Source())
+	assert.Equal(t, []string{"syslog.facility:user", "syslog.appname:nginx", "syslog.procid:42", "req.id:7"}, msg.Origin.Tags())
+	assert.Equal(t, time.Date(2020, 10, 17, 12, 0, 0, 0, time.UTC), msg.Timestamp)
+
+	msg = <-msgChan
+	assert.Equal(t, "job done", string(msg.Content))
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	assert.Equal(t, "cron", msg.Origin.Service())
+}
+
+func TestTCPListenerWithTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syslog")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	certFile, keyFile := generateCertificate(t, dir)
+
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Protocol: config.TCPType, TLSCertFile: certFile, TLSKeyFile: keyFile})
+	listener := NewTCPListener(pp, source)
+	listener.Start()
+	defer listener.Stop()
+
+	conn, err := tls.Dial("tcp", listener.listener.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	require.Nil(t, err)
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "<13>1 - host app - - - over tls\n")
+	msg := <-msgChan
+	assert.Equal(t, "over tls", string(msg.Content))
+	assert.Equal(t, "host", msg.Origin.Hostname())
+}
+
+func TestTCPListenerInvalidCertificate(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Protocol: config.TCPType, TLSCertFile: "/does/not/exist", TLSKeyFile: "/does/not/exist"})
+	listener := NewTCPListener(mock.NewMockProvider(), source)
+	listener.Start()
+	assert.True(t, source.Status.IsError())
+	listener.Stop()
+}
+
+func TestUDPListenerReceivesMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType})
+	listener := NewUDPListener(pp, source)
+	listener.Start()
+	defer listener.Stop()
+
+	conn, err := net.Dial("udp", listener.conn.LocalAddr().String())
+	require.Nil(t, err)
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "<12>Oct 17 12:00:00 switch01 ifmgr: link down\n")
+	msg := <-msgChan
+	assert.Equal(t, "link down", string(msg.Content))
+	assert.Equal(t, message.StatusWarning, msg.GetStatus())
+	assert.Equal(t, "switch01", msg.Origin.Hostname())
+
+	fmt.Fprintf(conn, "not a syslog message")
+	msg = <-msgChan
+	assert.Equal(t, "not a syslog message", string(msg.Content))
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	assert.Equal(t, "", msg.Origin.Hostname())
+}
+
+// generateCertificate writes a self-signed certificate and its key in dir.
+func generateCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0600))
+	require.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600))
+	return certFile, keyFile
+}
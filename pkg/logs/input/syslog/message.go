@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// sourceName is the source of the messages when it is not defined in the configuration.
+const sourceName = "syslog"
+
+// newMessage turns a syslog frame into a message, the severity is mapped to the status,
+// the hostname and the app-name to the host and the service, and the other header fields
+// and the structured data are added as tags. Frames that can not be parsed are forwarded as is.
+func newMessage(source *config.LogSource, frame []byte, now time.Time) *message.Message {
+	origin := message.NewOrigin(source)
+	origin.SetSource(sourceName)
+
+	syslogMsg, err := Parse(frame, now)
+	if err != nil {
+		return message.NewMessage(frame, origin, message.StatusInfo, now.UnixNano())
+	}
+
+	origin.SetHostname(syslogMsg.Hostname)
+	origin.SetService(syslogMsg.AppName)
+	tags := []string{"syslog.facility:" + syslogMsg.FacilityName()}
+	if syslogMsg.AppName != "" {
+		tags = append(tags, "syslog.appname:"+syslogMsg.AppName)
+	}
+	if syslogMsg.ProcID != "" {
+		tags = append(tags, "syslog.procid:"+syslogMsg.ProcID)
+	}
+	if syslogMsg.MsgID != "" {
+		tags = append(tags, "syslog.msgid:"+syslogMsg.MsgID)
+	}
+	origin.SetTags(append(tags, syslogMsg.StructuredData...))
+
+	status, _ := message.SeverityToStatus(syslogMsg.Severity)
+	msg := message.NewMessage(syslogMsg.Content, origin, status, now.UnixNano())
+	msg.Timestamp = syslogMsg.Timestamp
+	return msg
+}
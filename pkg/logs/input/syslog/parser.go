@@ -0,0 +1,268 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// nilValue is used by RFC 5424 for the header fields that are not set.
+	nilValue = "-"
+	// rfc3164TimestampLayout is the BSD syslog timestamp, e.g. `Oct 11 22:14:15`.
+	rfc3164TimestampLayout = "Jan _2 15:04:05"
+	// maxPriority is the maximum value of the PRI part, facility 23 and severity 7.
+	maxPriority = 191
+	// maxTagLength is the maximum length of the TAG field of RFC 3164.
+	maxTagLength = 48
+)
+
+var (
+	errMissingPriority = errors.New("missing priority")
+	errInvalidPriority = errors.New("invalid priority")
+
+	// byteOrderMark may prefix the MSG part of RFC 5424 messages encoded in UTF-8.
+	byteOrderMark = []byte{0xEF, 0xBB, 0xBF}
+)
+
+// facilities contains the facility names indexed by their code.
+var facilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// Message holds the header and the content of a syslog message.
+type Message struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	// StructuredData contains the SD-PARAMs of the message formatted as `<SD-ID>.<PARAM-NAME>:<PARAM-VALUE>`.
+	StructuredData []string
+	Content        []byte
+}
+
+// FacilityName returns the name of the facility of the message.
+func (m *Message) FacilityName() string {
+	if m.Facility < 0 || m.Facility >= len(facilities) {
+		return strconv.Itoa(m.Facility)
+	}
+	return facilities[m.Facility]
+}
+
+// Parse parses a syslog message formatted according to RFC 5424 or RFC 3164,
+// now is used to complete the RFC 3164 timestamps that have no year.
+func Parse(data []byte, now time.Time) (*Message, error) {
+	priority, rest, err := parsePriority(data)
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{
+		Facility: priority / 8,
+		Severity: priority % 8,
+	}
+	if len(rest) > 1 && rest[0] >= '1' && rest[0] <= '9' && rest[1] == ' ' {
+		err = parseRFC5424(msg, rest[2:])
+	} else {
+		parseRFC3164(msg, rest, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// parsePriority parses the `<PRI>` part of the message.
+func parsePriority(data []byte) (int, []byte, error) {
+	if len(data) == 0 || data[0] != '<' {
+		return 0, nil, errMissingPriority
+	}
+	end := bytes.IndexByte(data, '>')
+	if end < 2 || end > 4 {
+		return 0, nil, errInvalidPriority
+	}
+	priority, err := strconv.Atoi(string(data[1:end]))
+	if err != nil || priority < 0 || priority > maxPriority {
+		return 0, nil, errInvalidPriority
+	}
+	return priority, data[end+1:], nil
+}
+
+// parseRFC5424 parses the part of a RFC 5424 message that follows the version:
+// `TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]`.
+func parseRFC5424(msg *Message, data []byte) error {
+	var fields [5]string
+	for i := range fields {
+		var field []byte
+		field, data = nextField(data)
+		if len(field) == 0 {
+			return fmt.Errorf("missing header field %d", i+1)
+		}
+		fields[i] = string(field)
+	}
+	if fields[0] != nilValue {
+		timestamp, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid timestamp: %v", err)
+		}
+		msg.Timestamp = timestamp.UTC()
+	}
+	msg.Hostname = nilToEmpty(fields[1])
+	msg.AppName = nilToEmpty(fields[2])
+	msg.ProcID = nilToEmpty(fields[3])
+	msg.MsgID = nilToEmpty(fields[4])
+
+	structuredData, rest, err := parseStructuredData(data)
+	if err != nil {
+		return err
+	}
+	msg.StructuredData = structuredData
+	if len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	msg.Content = bytes.TrimPrefix(rest, byteOrderMark)
+	return nil
+}
+
+// parseStructuredData parses the STRUCTURED-DATA part of a RFC 5424 message,
+// e.g. `[exampleSDID@32473 iut="3" eventSource="Application"]`.
+func parseStructuredData(data []byte) ([]string, []byte, error) {
+	if len(data) > 0 && data[0] == '-' {
+		return nil, data[1:], nil
+	}
+	var params []string
+	for len(data) > 0 && data[0] == '[' {
+		data = data[1:]
+		end := bytes.IndexAny(data, " ]")
+		if end <= 0 {
+			return nil, nil, errors.New("invalid structured data element")
+		}
+		id := sdIDName(string(data[:end]))
+		data = data[end:]
+		for len(data) > 0 && data[0] == ' ' {
+			data = data[1:]
+			name, value, rest, err := parseSDParam(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			params = append(params, id+"."+name+":"+value)
+			data = rest
+		}
+		if len(data) == 0 || data[0] != ']' {
+			return nil, nil, errors.New("unterminated structured data element")
+		}
+		data = data[1:]
+	}
+	if len(params) == 0 && len(data) > 0 && data[0] != ' ' {
+		return nil, nil, errors.New("invalid structured data")
+	}
+	return params, data, nil
+}
+
+// parseSDParam parses a `PARAM-NAME="PARAM-VALUE"` pair, unescaping the value.
+func parseSDParam(data []byte) (string, string, []byte, error) {
+	eq := bytes.IndexByte(data, '=')
+	if eq <= 0 || eq+1 >= len(data) || data[eq+1] != '"' {
+		return "", "", nil, errors.New("invalid structured data parameter")
+	}
+	name := string(data[:eq])
+	var value strings.Builder
+	for i := eq + 2; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			if i+1 < len(data) && (data[i+1] == '"' || data[i+1] == '\\' || data[i+1] == ']') {
+				i++
+			}
+			value.WriteByte(data[i])
+		case '"':
+			return name, value.String(), data[i+1:], nil
+		default:
+			value.WriteByte(data[i])
+		}
+	}
+	return "", "", nil, errors.New("unterminated structured data parameter value")
+}
+
+// sdIDName strips the private enterprise number from a SD-ID, e.g. `exampleSDID@32473`.
+func sdIDName(id string) string {
+	if i := strings.IndexByte(id, '@'); i > 0 {
+		return id[:i]
+	}
+	return id
+}
+
+// parseRFC3164 parses the part of a BSD syslog message that follows the priority:
+// `TIMESTAMP HOSTNAME TAG[PID]: MSG`. As many devices don't follow the RFC strictly,
+// the timestamp, the hostname and the tag are all optional and RFC 3339 timestamps are accepted.
+func parseRFC3164(msg *Message, data []byte, now time.Time) {
+	if len(data) >= len(rfc3164TimestampLayout) {
+		if timestamp, err := time.ParseInLocation(rfc3164TimestampLayout, string(data[:len(rfc3164TimestampLayout)]), now.Location()); err == nil {
+			timestamp = timestamp.AddDate(now.Year(), 0, 0)
+			if timestamp.After(now.AddDate(0, 0, 1)) {
+				// the message was sent last year, e.g. on December 31st and received on January 1st
+				timestamp = timestamp.AddDate(-1, 0, 0)
+			}
+			msg.Timestamp = timestamp.UTC()
+			data = bytes.TrimLeft(data[len(rfc3164TimestampLayout):], " ")
+		}
+	}
+	if msg.Timestamp.IsZero() {
+		if field, rest := nextField(data); len(field) > 0 {
+			if timestamp, err := time.Parse(time.RFC3339Nano, string(field)); err == nil {
+				msg.Timestamp = timestamp.UTC()
+				data = rest
+			}
+		}
+	}
+
+	// the hostname is followed by the tag, which always ends with ':'
+	if field, rest := nextField(data); len(field) > 0 && !isTag(field) {
+		if tag, _ := nextField(rest); isTag(tag) {
+			msg.Hostname = string(field)
+			data = rest
+		}
+	}
+	if field, rest := nextField(data); isTag(field) {
+		tag := field[:len(field)-1]
+		if start := bytes.IndexByte(tag, '['); start > 0 && tag[len(tag)-1] == ']' {
+			msg.ProcID = string(tag[start+1 : len(tag)-1])
+			tag = tag[:start]
+		}
+		msg.AppName = string(tag)
+		data = rest
+	}
+	msg.Content = data
+}
+
+// isTag returns true if the field looks like a RFC 3164 TAG, e.g. `sshd[1234]:`.
+func isTag(field []byte) bool {
+	return len(field) > 1 && len(field) <= maxTagLength && field[len(field)-1] == ':'
+}
+
+// nextField returns the content up to the next space and the content that follows this space.
+func nextField(data []byte) ([]byte, []byte) {
+	end := bytes.IndexByte(data, ' ')
+	if end < 0 {
+		return data, nil
+	}
+	return data[:end], data[end+1:]
+}
+
+func nilToEmpty(field string) string {
+	if field == nilValue {
+		return ""
+	}
+	return field
+}
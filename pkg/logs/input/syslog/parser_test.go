@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2020, 10, 17, 12, 0, 0, 0, time.UTC)
+
+func TestParseRFC5424(t *testing.T) {
+	msg, err := Parse([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"] An application event`), now)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, msg.Facility)
+	assert.Equal(t, "local4", msg.FacilityName())
+	assert.Equal(t, 5, msg.Severity)
+	assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), msg.Timestamp)
+	assert.Equal(t, "mymachine.example.com", msg.Hostname)
+	assert.Equal(t, "evntslog", msg.AppName)
+	assert.Equal(t, "1234", msg.ProcID)
+	assert.Equal(t, "ID47", msg.MsgID)
+	assert.Equal(t, []string{"exampleSDID.iut:3", "exampleSDID.eventSource:Application", "exampleSDID.eventID:1011", "examplePriority.class:high"}, msg.StructuredData)
+	assert.Equal(t, "An application event", string(msg.Content))
+}
+
+func TestParseRFC5424WithNilValues(t *testing.T) {
+	msg, err := Parse([]byte("<34>1 - - su - - - \xEF\xBB\xBF'su root' failed"), now)
+	assert.Nil(t, err)
+	assert.True(t, msg.Timestamp.IsZero())
+	assert.Equal(t, "", msg.Hostname)
+	assert.Equal(t, "su", msg.AppName)
+	assert.Equal(t, "", msg.ProcID)
+	assert.Nil(t, msg.StructuredData)
+	assert.Equal(t, "'su root' failed", string(msg.Content))
+}
+
+func TestParseRFC5424EscapedStructuredData(t *testing.T) {
+	msg, err := Parse([]byte(`<14>1 2003-10-11T22:14:15Z host app - - [meta sequence="a \"quoted\\ value\]"]`), now)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`meta.sequence:a "quoted\ value]`}, msg.StructuredData)
+	assert.Empty(t, msg.Content)
+}
+
+func TestParseRFC5424InvalidMessages(t *testing.T) {
+	for _, data := range []string{
+		`<14>1 2003-10-11T22:14:15Z host app`,
+		`<14>1 yesterday host app - - - message`,
+		`<14>1 2003-10-11T22:14:15Z host app - - [meta sequence="1" message`,
+		`<14>1 2003-10-11T22:14:15Z host app - - [meta sequence=1] message`,
+	} {
+		_, err := Parse([]byte(data), now)
+		assert.NotNil(t, err, data)
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	msg, err := Parse([]byte(`<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8`), now)
+	assert.Nil(t, err)
+	assert.Equal(t, "auth", msg.FacilityName())
+	assert.Equal(t, 2, msg.Severity)
+	assert.Equal(t, time.Date(2020, 10, 11, 22, 14, 15, 0, time.UTC), msg.Timestamp)
+	assert.Equal(t, "mymachine", msg.Hostname)
+	assert.Equal(t, "su", msg.AppName)
+	assert.Equal(t, "230", msg.ProcID)
+	assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", string(msg.Content))
+}
+
+func TestParseRFC3164FromLastYear(t *testing.T) {
+	msg, err := Parse([]byte(`<13>Dec 31 23:59:59 host app: message`), time.Date(2021, 1, 1, 0, 0, 1, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC), msg.Timestamp)
+}
+
+func TestParseRFC3164Variants(t *testing.T) {
+	msg, err := Parse([]byte(`<13>Oct  1 08:00:00 sshd: no hostname`), now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 10, 1, 8, 0, 0, 0, time.UTC), msg.Timestamp)
+	assert.Equal(t, "", msg.Hostname)
+	assert.Equal(t, "sshd", msg.AppName)
+	assert.Equal(t, "no hostname", string(msg.Content))
+
+	msg, err = Parse([]byte(`<13>2020-10-17T10:00:00+02:00 firewall kernel: dropped packet`), now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 10, 17, 8, 0, 0, 0, time.UTC), msg.Timestamp)
+	assert.Equal(t, "firewall", msg.Hostname)
+	assert.Equal(t, "kernel", msg.AppName)
+	assert.Equal(t, "dropped packet", string(msg.Content))
+
+	msg, err = Parse([]byte(`<13>just a message`), now)
+	assert.Nil(t, err)
+	assert.True(t, msg.Timestamp.IsZero())
+	assert.Equal(t, "", msg.Hostname)
+	assert.Equal(t, "", msg.AppName)
+	assert.Equal(t, "just a message", string(msg.Content))
+}
+
+func TestParseInvalidPriority(t *testing.T) {
+	for _, data := range []string{"", "no priority", "<>1 message", "<192>1 message", "<1a>message", "<1234>message"} {
+		_, err := Parse([]byte(data), now)
+		assert.NotNil(t, err, data)
+	}
+}
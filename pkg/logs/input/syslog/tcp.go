@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+)
+
+// maxTCPFrameSize is the maximum size of a message received over TCP, longer messages are truncated.
+const maxTCPFrameSize = 256 * 1000
+
+// A TCPListener accepts syslog connections, optionally over TLS, and forwards the messages to the pipeline.
+type TCPListener struct {
+	pipelineProvider pipeline.Provider
+	source           *config.LogSource
+	listener         net.Listener
+	mu               sync.Mutex
+	conns            map[net.Conn]struct{}
+	stopped          bool
+	wg               sync.WaitGroup
+}
+
+// NewTCPListener returns an initialized TCPListener
+func NewTCPListener(pipelineProvider pipeline.Provider, source *config.LogSource) *TCPListener {
+	return &TCPListener{
+		pipelineProvider: pipelineProvider,
+		source:           source,
+		conns:            make(map[net.Conn]struct{}),
+	}
+}
+
+// Start starts the listener to accept new incoming connections.
+func (l *TCPListener) Start() {
+	log.Infof("Starting syslog TCP listener on port %d, with TLS: %t", l.source.Config.Port, l.source.Config.TLSCertFile != "")
+	listener, err := l.listen()
+	if err != nil {
+		log.Errorf("Can't start syslog TCP listener on port %d: %v", l.source.Config.Port, err)
+		l.source.Status.Error(err)
+		return
+	}
+	l.listener = listener
+	l.source.Status.Success()
+	l.wg.Add(1)
+	go l.run()
+}
+
+// Stop stops the listener and closes all the active connections.
+func (l *TCPListener) Stop() {
+	log.Infof("Stopping syslog TCP listener on port %d", l.source.Config.Port)
+	if l.listener == nil {
+		return
+	}
+	l.listener.Close()
+	l.mu.Lock()
+	l.stopped = true
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+}
+
+// listen opens the socket, TLS is used when a certificate is configured.
+func (l *TCPListener) listen() (net.Listener, error) {
+	address := fmt.Sprintf(":%d", l.source.Config.Port)
+	if l.source.Config.TLSCertFile == "" {
+		return net.Listen("tcp", address)
+	}
+	certificate, err := tls.LoadX509KeyPair(l.source.Config.TLSCertFile, l.source.Config.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return tls.Listen("tcp", address, &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	})
+}
+
+// run accepts new connections and handles each of them in a dedicated goroutine.
+func (l *TCPListener) run() {
+	defer l.wg.Done()
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if isClosedConnError(err) {
+				return
+			}
+			log.Warnf("Can't accept syslog connection on port %d: %v", l.source.Config.Port, err)
+			l.source.Status.Error(err)
+			time.Sleep(time.Second)
+			continue
+		}
+		l.mu.Lock()
+		if l.stopped {
+			l.mu.Unlock()
+			conn.Close()
+			return
+		}
+		l.conns[conn] = struct{}{}
+		l.wg.Add(1)
+		l.mu.Unlock()
+		go l.handleConnection(conn)
+	}
+}
+
+// handleConnection reads the messages of a connection until it is closed.
+func (l *TCPListener) handleConnection(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		l.wg.Done()
+	}()
+	outputChan := l.pipelineProvider.NextPipelineChanForSource(l.source)
+	reader := NewFrameReader(conn, maxTCPFrameSize)
+	for {
+		frame, err := reader.Next()
+		if err != nil {
+			if err != io.EOF && !isClosedConnError(err) {
+				log.Warnf("Couldn't read syslog message from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		l.source.BytesRead.Add(int64(len(frame)))
+		outputChan <- newMessage(l.source, frame, time.Now())
+	}
+}
+
+// isClosedConnError returns true if the error is related to a closed connection,
+// for more details, see: https://golang.org/src/internal/poll/fd.go#L18.
+func isClosedConnError(err error) bool {
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
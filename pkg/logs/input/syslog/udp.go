@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+)
+
+// maxUDPFrameSize is the maximum size of a UDP datagram.
+const maxUDPFrameSize = 65535
+
+// A UDPListener reads syslog datagrams, one message per datagram, and forwards them to the pipeline.
+type UDPListener struct {
+	pipelineProvider pipeline.Provider
+	source           *config.LogSource
+	conn             net.PacketConn
+	done             chan struct{}
+}
+
+// NewUDPListener returns an initialized UDPListener
+func NewUDPListener(pipelineProvider pipeline.Provider, source *config.LogSource) *UDPListener {
+	return &UDPListener{
+		pipelineProvider: pipelineProvider,
+		source:           source,
+		done:             make(chan struct{}),
+	}
+}
+
+// Start opens the socket and starts reading datagrams.
+func (l *UDPListener) Start() {
+	log.Infof("Starting syslog UDP listener on port %d", l.source.Config.Port)
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", l.source.Config.Port))
+	if err != nil {
+		log.Errorf("Can't start syslog UDP listener on port %d: %v", l.source.Config.Port, err)
+		l.source.Status.Error(err)
+		return
+	}
+	l.conn = conn
+	l.source.Status.Success()
+	go l.run()
+}
+
+// Stop closes the socket and waits for the last message to be forwarded.
+func (l *UDPListener) Stop() {
+	log.Infof("Stopping syslog UDP listener on port %d", l.source.Config.Port)
+	if l.conn == nil {
+		return
+	}
+	l.conn.Close()
+	<-l.done
+}
+
+// run reads datagrams until the socket is closed.
+func (l *UDPListener) run() {
+	defer close(l.done)
+	outputChan := l.pipelineProvider.NextPipelineChanForSource(l.source)
+	buffer := make([]byte, maxUDPFrameSize)
+	for {
+		n, _, err := l.conn.ReadFrom(buffer)
+		if err != nil {
+			if isClosedConnError(err) {
+				return
+			}
+			log.Warnf("Couldn't read syslog datagram on port %d: %v", l.source.Config.Port, err)
+			continue
+		}
+		frame := bytes.TrimRight(buffer[:n], "\r\n\x00")
+		if len(frame) == 0 {
+			continue
+		}
+		l.source.BytesRead.Add(int64(n))
+		// copy the frame as the buffer is reused for the next datagram
+		outputChan <- newMessage(l.source, append([]byte(nil), frame...), time.Now())
+	}
+}
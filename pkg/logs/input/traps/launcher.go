@@ -34,7 +34,7 @@ func (l *Launcher) Start() {
 }
 
 func (l *Launcher) startNewTailer(source *config.LogSource, inputChan chan *traps.SnmpPacket) {
-	outputChan := l.pipelineProvider.NextPipelineChan()
+	outputChan := l.pipelineProvider.NextPipelineChanForSource(source)
 	l.tailer = NewTailer(source, inputChan, outputChan)
 	l.tailer.Start()
 }
@@ -88,7 +88,7 @@ func (l *Launcher) sanitizedConfig(sourceConfig *config.LogsConfig) *Config {
 func (l *Launcher) setupTailer(source *config.LogSource) (*Tailer, error) {
 	sanitizedConfig := l.sanitizedConfig(source.Config)
 	config := &Config{sanitizedConfig.ChannelPath, sanitizedConfig.Query}
-	tailer := NewTailer(source, config, l.pipelineProvider.NextPipelineChan())
+	tailer := NewTailer(source, config, l.pipelineProvider.NextPipelineChanForSource(source))
 	tailer.Start()
 	return tailer, nil
 }
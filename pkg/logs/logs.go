@@ -30,6 +30,8 @@ import (
 const (
 	// key used to display a warning message on the agent status
 	invalidProcessingRules = "invalid_global_processing_rules"
+	invalidForwarders      = "invalid_forwarders"
+	invalidPipelines       = "invalid_dedicated_pipelines"
 	invalidEndpoints       = "invalid_endpoints"
 )
 
@@ -94,11 +96,27 @@ func start(getAC func() *autodiscovery.AutoConfig, serverless bool, logsChan cha
 		return errors.New(message)
 	}
 
+	// setup the forwarders
+	forwarders, err := config.Forwarders()
+	if err != nil {
+		message := fmt.Sprintf("Invalid forwarders: %v", err)
+		status.AddGlobalError(invalidForwarders, message)
+		return errors.New(message)
+	}
+
+	// setup the dedicated pipelines
+	dedicatedPipelines, err := config.DedicatedPipelines()
+	if err != nil {
+		message := fmt.Sprintf("Invalid dedicated pipelines: %v", err)
+		status.AddGlobalError(invalidPipelines, message)
+		return errors.New(message)
+	}
+
 	// setup and start the logs agent
 	if !serverless {
 		// regular logs agent
 		log.Info("Starting logs-agent...")
-		agent = NewAgent(sources, services, processingRules, endpoints)
+		agent = NewAgent(sources, services, processingRules, endpoints, forwarders, dedicatedPipelines)
 	} else {
 		// serverless logs agent
 		log.Info("Starting a serverless logs-agent...")
@@ -16,6 +16,7 @@ type Origin struct {
 	Identifier string
 	LogSource  *config.LogSource
 	Offset     string
+	hostname   string
 	service    string
 	source     string
 	tags       []string
@@ -88,6 +89,13 @@ func (o *Origin) SetTags(tags []string) {
 	o.tags = tags
 }
 
+// AddTags appends tags to the ones already set on the origin.
+func (o *Origin) AddTags(tags ...string) {
+	newTags := make([]string, 0, len(o.tags)+len(tags))
+	newTags = append(newTags, o.tags...)
+	o.tags = append(newTags, tags...)
+}
+
 // SetSource sets the source of the origin.
 func (o *Origin) SetSource(source string) {
 	o.source = source
@@ -115,3 +123,14 @@ func (o *Origin) Service() string {
 	}
 	return o.service
 }
+
+// SetHostname sets the name of the host that emitted the message.
+func (o *Origin) SetHostname(hostname string) {
+	o.hostname = hostname
+}
+
+// Hostname returns the name of the host that emitted the message,
+// returns an empty string when the message was emitted by the host of the agent.
+func (o *Origin) Hostname() string {
+	return o.hostname
+}
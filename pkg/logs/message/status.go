@@ -48,3 +48,24 @@ func StatusToSeverity(status string) []byte {
 	}
 	return SevInfo
 }
+
+// severityStatusMapping maps the syslog severity numbers to statuses.
+var severityStatusMapping = []string{
+	StatusEmergency,
+	StatusAlert,
+	StatusCritical,
+	StatusError,
+	StatusWarning,
+	StatusNotice,
+	StatusInfo,
+	StatusDebug,
+}
+
+// SeverityToStatus transforms a syslog severity number into a status,
+// returns false if the severity is out of range.
+func SeverityToStatus(severity int) (string, bool) {
+	if severity < 0 || severity >= len(severityStatusMapping) {
+		return "", false
+	}
+	return severityStatusMapping[severity], true
+}
@@ -33,3 +33,16 @@ func TestStatusToSeverity(t *testing.T) {
 	// default value should be "info"
 	assert.Equal(t, 0, bytes.Compare(SevInfo, StatusToSeverity("foo")))
 }
+
+func TestSeverityToStatus(t *testing.T) {
+	for severity, expected := range []string{StatusEmergency, StatusAlert, StatusCritical, StatusError, StatusWarning, StatusNotice, StatusInfo, StatusDebug} {
+		status, ok := SeverityToStatus(severity)
+		assert.True(t, ok)
+		assert.Equal(t, expected, status)
+	}
+
+	_, ok := SeverityToStatus(-1)
+	assert.False(t, ok)
+	_, ok = SeverityToStatus(8)
+	assert.False(t, ok)
+}
@@ -24,6 +24,11 @@ var (
 	// TlmLogsProcessed is the total number of processed logs.
 	TlmLogsProcessed = telemetry.NewCounter("logs", "processed",
 		nil, "Total number of processed logs")
+	// LogsSampledOut is the total number of logs dropped by sampling rules.
+	LogsSampledOut = expvar.Int{}
+	// TlmLogsSampledOut is the total number of logs dropped per sampling rule.
+	TlmLogsSampledOut = telemetry.NewCounter("logs", "sampled_out",
+		[]string{"rule"}, "Total number of logs dropped per sampling rule")
 
 	// LogsSent is the total number of sent logs.
 	LogsSent = expvar.Int{}
@@ -51,6 +56,13 @@ var (
 	// TlmEncodedBytesSent is the total number of sent bytes after encoding if any
 	TlmEncodedBytesSent = telemetry.NewCounter("logs", "encoded_bytes_sent",
 		nil, "Total number of sent bytes after encoding if any")
+	// DiskBufferBytes is the number of bytes of the payloads buffered on disk
+	DiskBufferBytes = expvar.Int{}
+	// DiskBufferDroppedPayloads is the total number of payloads removed from the disk buffer to make room for new ones
+	DiskBufferDroppedPayloads = expvar.Int{}
+	// TlmDiskBufferDroppedPayloads is the total number of payloads removed from the disk buffer to make room for new ones
+	TlmDiskBufferDroppedPayloads = telemetry.NewCounter("logs", "disk_buffer_dropped_payloads",
+		nil, "Total number of payloads removed from the disk buffer to make room for new ones")
 	// TODO: Add LogsCollected for the total number of collected logs.
 
 )
@@ -59,9 +71,12 @@ func init() {
 	LogsExpvars = expvar.NewMap("logs-agent")
 	LogsExpvars.Set("LogsDecoded", &LogsDecoded)
 	LogsExpvars.Set("LogsProcessed", &LogsProcessed)
+	LogsExpvars.Set("LogsSampledOut", &LogsSampledOut)
 	LogsExpvars.Set("LogsSent", &LogsSent)
 	LogsExpvars.Set("DestinationErrors", &DestinationErrors)
 	LogsExpvars.Set("DestinationLogsDropped", &DestinationLogsDropped)
 	LogsExpvars.Set("BytesSent", &BytesSent)
 	LogsExpvars.Set("EncodedBytesSent", &EncodedBytesSent)
+	LogsExpvars.Set("DiskBufferBytes", &DiskBufferBytes)
+	LogsExpvars.Set("DiskBufferDroppedPayloads", &DiskBufferDroppedPayloads)
 }
@@ -8,6 +8,7 @@ package mock
 import (
 	"context"
 
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 )
@@ -37,3 +38,8 @@ func (p *mockProvider) Flush(ctx context.Context) {}
 func (p *mockProvider) NextPipelineChan() chan *message.Message {
 	return p.msgChan
 }
+
+// NextPipelineChanForSource returns the next pipeline
+func (p *mockProvider) NextPipelineChanForSource(source *config.LogSource) chan *message.Message {
+	return p.msgChan
+}
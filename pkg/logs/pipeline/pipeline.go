@@ -7,6 +7,7 @@ package pipeline
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
@@ -23,10 +24,20 @@ type Pipeline struct {
 	InputChan chan *message.Message
 	processor *processor.Processor
 	sender    *sender.Sender
+
+	// scheduler is set when the pipelines have different priorities, the messages are then relayed
+	// from InputChan to processorChan once the pipelines with a higher priority have no message pending.
+	scheduler     *scheduler
+	priority      int
+	processorChan chan *message.Message
+	relayDone     chan struct{}
+	// relaying is set while a message is being handed to the processor.
+	relaying int32
 }
 
-// NewPipeline returns a new Pipeline
-func NewPipeline(outputChan chan *message.Message, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, diagnosticMessageReceiver diagnostic.MessageReceiver, serverless bool) *Pipeline {
+// NewPipeline returns a new Pipeline, the payloads that can not be sent are buffered
+// in diskBuffer and a copy of the processed messages is handed to forwarder when they are not nil.
+func NewPipeline(outputChan chan *message.Message, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, diagnosticMessageReceiver diagnostic.MessageReceiver, serverless bool, diskBuffer *sender.DiskBuffer, forwarder processor.Forwarder) *Pipeline {
 	var destinations *client.Destinations
 	if endpoints.UseHTTP {
 		main := http.NewDestination(endpoints.Main, http.JSONContentType, destinationsContext)
@@ -36,7 +47,13 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 		}
 		destinations = client.NewDestinations(main, additionals)
 	} else {
-		main := tcp.NewDestination(endpoints.Main, endpoints.UseProto, destinationsContext)
+		var main *tcp.Destination
+		if diskBuffer != nil {
+			// the payloads are buffered on disk while the intake is unreachable
+			main = tcp.NewFailFastDestination(endpoints.Main, endpoints.UseProto, destinationsContext)
+		} else {
+			main = tcp.NewDestination(endpoints.Main, endpoints.UseProto, destinationsContext)
+		}
 		additionals := []client.Destination{}
 		for _, endpoint := range endpoints.Additionals {
 			additionals = append(additionals, tcp.NewDestination(endpoint, endpoints.UseProto, destinationsContext))
@@ -48,11 +65,20 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 
 	var strategy sender.Strategy
 	if endpoints.UseHTTP || serverless {
-		strategy = sender.NewBatchStrategy(sender.ArraySerializer, endpoints.BatchWait)
+		if endpoints.BatchMaxSize > 0 {
+			strategy = sender.NewBatchStrategyWithSize(sender.ArraySerializer, endpoints.BatchWait, endpoints.BatchMaxSize)
+		} else {
+			strategy = sender.NewBatchStrategy(sender.ArraySerializer, endpoints.BatchWait)
+		}
 	} else {
 		strategy = sender.StreamStrategy
 	}
-	sender := sender.NewSender(senderChan, outputChan, destinations, strategy)
+	var logsSender *sender.Sender
+	if diskBuffer != nil {
+		logsSender = sender.NewSenderWithDiskBuffer(senderChan, outputChan, destinations, strategy, diskBuffer)
+	} else {
+		logsSender = sender.NewSender(senderChan, outputChan, destinations, strategy)
+	}
 
 	var encoder processor.Encoder
 	if serverless {
@@ -66,12 +92,17 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 	}
 
 	inputChan := make(chan *message.Message, config.ChanSize)
-	processor := processor.New(inputChan, senderChan, processingRules, encoder, diagnosticMessageReceiver)
+	var logsProcessor *processor.Processor
+	if forwarder != nil {
+		logsProcessor = processor.NewWithForwarder(inputChan, senderChan, processingRules, encoder, diagnosticMessageReceiver, forwarder)
+	} else {
+		logsProcessor = processor.New(inputChan, senderChan, processingRules, encoder, diagnosticMessageReceiver)
+	}
 
 	return &Pipeline{
 		InputChan: inputChan,
-		processor: processor,
-		sender:    sender,
+		processor: logsProcessor,
+		sender:    logsSender,
 	}
 }
 
@@ -79,14 +110,48 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 func (p *Pipeline) Start() {
 	p.sender.Start()
 	p.processor.Start()
+	if p.scheduler != nil {
+		p.relayDone = make(chan struct{})
+		go p.relay()
+	}
 }
 
 // Stop stops the pipeline
 func (p *Pipeline) Stop() {
+	if p.scheduler != nil {
+		close(p.InputChan)
+		<-p.relayDone
+	}
 	p.processor.Stop()
 	p.sender.Stop()
 }
 
+// schedule makes the pipeline wait for the pipelines of the scheduler with a higher priority
+// before processing a message and notify the scheduler of its progress, it must be called before Start.
+func (p *Pipeline) schedule(scheduler *scheduler, priority int) {
+	p.scheduler = scheduler
+	p.priority = priority
+	p.processorChan = p.InputChan
+	p.InputChan = make(chan *message.Message, config.ChanSize)
+}
+
+// relay forwards the messages to the processor when the scheduler allows it.
+func (p *Pipeline) relay() {
+	defer close(p.relayDone)
+	for msg := range p.InputChan {
+		p.scheduler.wait(p.priority)
+		atomic.StoreInt32(&p.relaying, 1)
+		p.processorChan <- msg
+		atomic.StoreInt32(&p.relaying, 0)
+		p.scheduler.relayed()
+	}
+}
+
+// pendingMessages returns the number of messages waiting to be handed to the processor.
+func (p *Pipeline) pendingMessages() int {
+	return len(p.InputChan) + int(atomic.LoadInt32(&p.relaying))
+}
+
 // Flush flushes synchronously the processor and sender managed by this pipeline.
 func (p *Pipeline) Flush(ctx context.Context) {
 	p.processor.Flush(ctx) // flush messages in the processor into the sender
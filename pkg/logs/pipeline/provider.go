@@ -7,6 +7,8 @@ package pipeline
 
 import (
 	"context"
+	"path/filepath"
+	"strconv"
 	"sync/atomic"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
@@ -15,7 +17,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/processor"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
+	"github.com/DataDog/datadog-agent/pkg/logs/sender"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 // Provider provides message channels
@@ -23,6 +28,9 @@ type Provider interface {
 	Start()
 	Stop()
 	NextPipelineChan() chan *message.Message
+	// NextPipelineChanForSource returns the input channel of the pipeline dedicated to the source,
+	// or the next shared pipeline input channel when no pipeline is dedicated to it
+	NextPipelineChanForSource(source *config.LogSource) chan *message.Message
 	// Flush flushes all pipeline contained in this Provider
 	Flush(ctx context.Context)
 }
@@ -41,11 +49,48 @@ type provider struct {
 	destinationsContext  *client.DestinationsContext
 
 	serverless bool
+
+	// diskBufferPath is the directory where the pipelines buffer the payloads that can not be sent,
+	// buffering is disabled when empty.
+	diskBufferPath    string
+	diskBufferMaxSize int64
+
+	// forwarder receives a copy of the processed messages when not nil.
+	forwarder processor.Forwarder
+
+	// dedicatedPipelineConfigs describe the pipelines reserved to the sources they match.
+	dedicatedPipelineConfigs []*config.DedicatedPipelineConfig
+	dedicatedPipelines       []*dedicatedPipeline
+}
+
+// dedicatedPipeline is a pipeline reserved to the sources matched by its config.
+type dedicatedPipeline struct {
+	config   *config.DedicatedPipelineConfig
+	pipeline *Pipeline
+}
+
+// ProviderOptions holds the optional features of the pipelines of a Provider.
+type ProviderOptions struct {
+	// DiskBufferPath is the directory where the pipelines buffer the payloads that can not be sent,
+	// buffering is disabled when empty. DiskBufferMaxSize is shared between all the pipelines.
+	DiskBufferPath    string
+	DiskBufferMaxSize int64
+	// Forwarder receives a copy of the processed messages when not nil.
+	Forwarder processor.Forwarder
+	// DedicatedPipelineConfigs describe the pipelines created in addition to the shared pipelines,
+	// the sources matched by a dedicated pipeline are sent to it and the pipelines with a higher
+	// priority take precedence over the others.
+	DedicatedPipelineConfigs []*config.DedicatedPipelineConfig
 }
 
 // NewProvider returns a new Provider
-func NewProvider(numberOfPipelines int, auditor auditor.Auditor, diagnosticMessageReceiver diagnostic.MessageReceiver, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext) Provider {
-	return newProvider(numberOfPipelines, auditor, diagnosticMessageReceiver, processingRules, endpoints, destinationsContext, false)
+func NewProvider(numberOfPipelines int, auditor auditor.Auditor, diagnosticMessageReceiver diagnostic.MessageReceiver, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, options ProviderOptions) Provider {
+	p := newProvider(numberOfPipelines, auditor, diagnosticMessageReceiver, processingRules, endpoints, destinationsContext, false).(*provider)
+	p.diskBufferPath = options.DiskBufferPath
+	p.diskBufferMaxSize = options.DiskBufferMaxSize
+	p.forwarder = options.Forwarder
+	p.dedicatedPipelineConfigs = options.DedicatedPipelineConfigs
+	return p
 }
 
 // NewServerlessProvider returns a new Provider in serverless mode
@@ -72,21 +117,66 @@ func (p *provider) Start() {
 	p.outputChan = p.auditor.Channel()
 
 	for i := 0; i < p.numberOfPipelines; i++ {
-		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext, p.diagnosticMessageReceiver, p.serverless)
-		pipeline.Start()
+		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext, p.diagnosticMessageReceiver, p.serverless, p.newDiskBuffer(strconv.Itoa(i)), p.forwarder)
 		p.pipelines = append(p.pipelines, pipeline)
 	}
+	for _, dedicatedPipelineConfig := range p.dedicatedPipelineConfigs {
+		endpoints := dedicatedPipelineConfig.BuildEndpoints(p.endpoints)
+		diskBuffer := p.newDiskBuffer(filepath.Join("dedicated", dedicatedPipelineConfig.Name))
+		p.dedicatedPipelines = append(p.dedicatedPipelines, &dedicatedPipeline{
+			config:   dedicatedPipelineConfig,
+			pipeline: NewPipeline(p.outputChan, p.processingRules, endpoints, p.destinationsContext, p.diagnosticMessageReceiver, p.serverless, diskBuffer, p.forwarder),
+		})
+	}
+
+	scheduler := newScheduler()
+	for _, pipeline := range p.pipelines {
+		scheduler.add(pipeline, config.NormalPriority)
+	}
+	for _, dedicated := range p.dedicatedPipelines {
+		scheduler.add(dedicated.pipeline, dedicated.config.GetPriority())
+	}
+	scheduler.schedule()
+
+	for _, pipeline := range p.allPipelines() {
+		pipeline.Start()
+	}
+}
+
+// allPipelines returns the shared and the dedicated pipelines.
+func (p *provider) allPipelines() []*Pipeline {
+	pipelines := append([]*Pipeline{}, p.pipelines...)
+	for _, dedicated := range p.dedicatedPipelines {
+		pipelines = append(pipelines, dedicated.pipeline)
+	}
+	return pipelines
+}
+
+// newDiskBuffer returns the disk buffer of the pipeline stored in name, or nil when buffering is disabled or fails to initialize.
+func (p *provider) newDiskBuffer(name string) *sender.DiskBuffer {
+	if p.diskBufferPath == "" {
+		return nil
+	}
+	path := filepath.Join(p.diskBufferPath, name)
+	numberOfPipelines := p.numberOfPipelines + len(p.dedicatedPipelineConfigs)
+	diskBuffer, err := sender.NewDiskBuffer(path, p.diskBufferMaxSize/int64(numberOfPipelines))
+	if err != nil {
+		log.Warnf("Could not initialize the disk buffer in %s, payloads will not be buffered: %v", path, err)
+		return nil
+	}
+	return diskBuffer
 }
 
 // Stop stops all pipelines in parallel,
 // this call blocks until all pipelines are stopped
 func (p *provider) Stop() {
 	stopper := restart.NewParallelStopper()
-	for _, pipeline := range p.pipelines {
+	for _, pipeline := range p.allPipelines() {
 		stopper.Add(pipeline)
 	}
 	stopper.Stop()
 	p.pipelines = p.pipelines[:0]
+	p.dedicatedPipelines = p.dedicatedPipelines[:0]
 	p.outputChan = nil
 }
 
@@ -102,9 +192,22 @@ func (p *provider) NextPipelineChan() chan *message.Message {
 	return nextPipeline.InputChan
 }
 
+// NextPipelineChanForSource returns the input channel of the first dedicated pipeline matching the source,
+// or the next shared pipeline input channel
+func (p *provider) NextPipelineChanForSource(source *config.LogSource) chan *message.Message {
+	if source != nil {
+		for _, dedicated := range p.dedicatedPipelines {
+			if dedicated.config.Matches(source) {
+				return dedicated.pipeline.InputChan
+			}
+		}
+	}
+	return p.NextPipelineChan()
+}
+
 // Flush flushes synchronously all the contained pipeline of this provider.
 func (p *provider) Flush(ctx context.Context) {
-	for _, p := range p.pipelines {
+	for _, p := range p.allPipelines() {
 		select {
 		case <-ctx.Done():
 			return
@@ -56,6 +56,50 @@ func (suite *ProviderTestSuite) TestProvider() {
 	suite.Nil(suite.p.NextPipelineChan())
 }
 
+func (suite *ProviderTestSuite) TestProviderWithDedicatedPipelines() {
+	suite.p.dedicatedPipelineConfigs = []*config.DedicatedPipelineConfig{
+		{Name: "critical", Priority: config.HighPriority, Services: []string{"payments"}},
+		{Name: "audit", Tags: []string{"team:security"}},
+	}
+	suite.a.Start()
+	suite.p.Start()
+	suite.Equal(3, len(suite.p.pipelines))
+	suite.Equal(2, len(suite.p.dedicatedPipelines))
+
+	critical := suite.p.dedicatedPipelines[0].pipeline
+	audit := suite.p.dedicatedPipelines[1].pipeline
+	suite.Equal(critical.InputChan, suite.p.NextPipelineChanForSource(config.NewLogSource("", &config.LogsConfig{Service: "payments"})))
+	suite.Equal(audit.InputChan, suite.p.NextPipelineChanForSource(config.NewLogSource("", &config.LogsConfig{Tags: []string{"team:security"}})))
+
+	// the other sources are sent to the shared pipelines
+	suite.Equal(suite.p.pipelines[1].InputChan, suite.p.NextPipelineChanForSource(config.NewLogSource("", &config.LogsConfig{Service: "web"})))
+	suite.Equal(suite.p.pipelines[2].InputChan, suite.p.NextPipelineChanForSource(nil))
+
+	// the pipelines are scheduled so that the ones with a lower priority wait for the critical one
+	suite.NotNil(critical.scheduler)
+	suite.NotNil(audit.scheduler)
+	for _, pipeline := range suite.p.pipelines {
+		suite.NotNil(pipeline.scheduler)
+	}
+
+	suite.p.Stop()
+	suite.a.Stop()
+	suite.Equal(0, len(suite.p.dedicatedPipelines))
+}
+
+func (suite *ProviderTestSuite) TestNewProviderWithOptions() {
+	dedicatedPipelineConfigs := []*config.DedicatedPipelineConfig{{Name: "critical", Priority: config.HighPriority}}
+	p := NewProvider(3, suite.a, nil, nil, suite.p.endpoints, nil, ProviderOptions{
+		DiskBufferPath:           "/tmp/buffer",
+		DiskBufferMaxSize:        1024,
+		DedicatedPipelineConfigs: dedicatedPipelineConfigs,
+	}).(*provider)
+	suite.Equal("/tmp/buffer", p.diskBufferPath)
+	suite.Equal(int64(1024), p.diskBufferMaxSize)
+	suite.Nil(p.forwarder)
+	suite.Equal(dedicatedPipelineConfigs, p.dedicatedPipelineConfigs)
+}
+
 func TestProviderTestSuite(t *testing.T) {
 	suite.Run(t, new(ProviderTestSuite))
 }
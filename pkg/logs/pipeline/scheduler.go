@@ -0,0 +1,131 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+const (
+	// maxSchedulingDelay is the maximum time a pipeline waits for the pipelines with a higher priority
+	// before processing a message, so that it is never starved.
+	maxSchedulingDelay = 10 * time.Millisecond
+)
+
+// priorities orders the priorities of the pipelines.
+var priorities = map[string]int{
+	config.LowPriority:    0,
+	config.NormalPriority: 1,
+	config.HighPriority:   2,
+}
+
+// scheduledPipeline is a pipeline with its priority.
+type scheduledPipeline struct {
+	pipeline *Pipeline
+	priority int
+}
+
+// scheduler gives precedence to the pipelines with a higher priority: a pipeline only processes
+// a message when the pipelines with a higher priority have no message pending, or after maxSchedulingDelay.
+// Once a pipeline has waited for maxSchedulingDelay, it does not wait again until the pipelines with a higher
+// priority make progress, so that a stalled pipeline does not slow down the others.
+type scheduler struct {
+	pipelines []scheduledPipeline
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// progress is incremented each time a message is handed to a processor.
+	progress uint64
+	// stalledAt is the progress at which the pipelines of a priority last gave up waiting.
+	stalledAt map[int]uint64
+}
+
+// newScheduler returns a new scheduler.
+func newScheduler() *scheduler {
+	s := &scheduler{
+		stalledAt: make(map[int]uint64),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// add adds a pipeline with the given priority to the scheduler.
+func (s *scheduler) add(pipeline *Pipeline, priority string) {
+	s.pipelines = append(s.pipelines, scheduledPipeline{
+		pipeline: pipeline,
+		priority: priorities[priority],
+	})
+}
+
+// schedule makes the pipelines wait for the ones with a higher priority,
+// it does nothing when all the pipelines have the same priority.
+func (s *scheduler) schedule() {
+	if len(s.pipelines) == 0 {
+		return
+	}
+	samePriority := true
+	for _, p := range s.pipelines {
+		if p.priority != s.pipelines[0].priority {
+			samePriority = false
+		}
+	}
+	if samePriority {
+		return
+	}
+	for _, p := range s.pipelines {
+		p.pipeline.schedule(s, p.priority)
+	}
+}
+
+// wait blocks while a pipeline with a higher priority than priority has messages pending,
+// at most for maxSchedulingDelay.
+func (s *scheduler) wait(priority int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.hasPendingMessages(priority) {
+		return
+	}
+	if stalledAt, exists := s.stalledAt[priority]; exists && stalledAt == s.progress {
+		// the pipelines with a higher priority did not make progress since the last timeout
+		return
+	}
+
+	timedOut := false
+	timer := time.AfterFunc(maxSchedulingDelay, func() {
+		s.mu.Lock()
+		timedOut = true
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	})
+	defer timer.Stop()
+	for s.hasPendingMessages(priority) && !timedOut {
+		s.cond.Wait()
+	}
+	if timedOut {
+		s.stalledAt[priority] = s.progress
+	}
+}
+
+// relayed wakes up the pipelines waiting for a message to be handed to a processor.
+func (s *scheduler) relayed() {
+	s.mu.Lock()
+	s.progress++
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// hasPendingMessages returns true if a pipeline with a higher priority than priority has messages pending.
+func (s *scheduler) hasPendingMessages(priority int) bool {
+	for _, p := range s.pipelines {
+		if p.priority > priority && p.pipeline.pendingMessages() > 0 {
+			return true
+		}
+	}
+	return false
+}
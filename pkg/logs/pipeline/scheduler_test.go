@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newTestPipeline() *Pipeline {
+	return &Pipeline{InputChan: make(chan *message.Message, 10)}
+}
+
+func TestSchedulerDoesNothingWithTheSamePriorities(t *testing.T) {
+	first, second := newTestPipeline(), newTestPipeline()
+	scheduler := newScheduler()
+	scheduler.add(first, config.NormalPriority)
+	scheduler.add(second, config.NormalPriority)
+	scheduler.schedule()
+
+	assert.Nil(t, first.scheduler)
+	assert.Nil(t, second.scheduler)
+}
+
+func TestSchedulerGivesPrecedenceToHigherPriorities(t *testing.T) {
+	high, normal, low := newTestPipeline(), newTestPipeline(), newTestPipeline()
+	scheduler := newScheduler()
+	scheduler.add(high, config.HighPriority)
+	scheduler.add(normal, config.NormalPriority)
+	scheduler.add(low, config.LowPriority)
+	scheduler.schedule()
+
+	assert.NotNil(t, high.scheduler)
+	assert.NotNil(t, normal.scheduler)
+	assert.NotNil(t, low.scheduler)
+	assert.False(t, scheduler.hasPendingMessages(priorities[config.LowPriority]))
+
+	// the messages waiting to be relayed to the processor are pending
+	normal.InputChan <- message.NewMessage([]byte("a"), nil, "", 0)
+	assert.True(t, scheduler.hasPendingMessages(priorities[config.LowPriority]))
+	assert.False(t, scheduler.hasPendingMessages(priorities[config.NormalPriority]))
+	<-normal.InputChan
+
+	high.InputChan <- message.NewMessage([]byte("b"), nil, "", 0)
+	assert.True(t, scheduler.hasPendingMessages(priorities[config.LowPriority]))
+	assert.True(t, scheduler.hasPendingMessages(priorities[config.NormalPriority]))
+	assert.False(t, scheduler.hasPendingMessages(priorities[config.HighPriority]))
+
+	// the pipelines with a lower priority are never blocked for longer than maxSchedulingDelay
+	start := time.Now()
+	scheduler.wait(priorities[config.LowPriority])
+	assert.True(t, time.Since(start) >= maxSchedulingDelay)
+
+	// they do not wait again until the stalled pipeline makes progress
+	start = time.Now()
+	scheduler.wait(priorities[config.LowPriority])
+	assert.True(t, time.Since(start) < maxSchedulingDelay)
+	scheduler.relayed()
+	start = time.Now()
+	scheduler.wait(priorities[config.LowPriority])
+	assert.True(t, time.Since(start) >= maxSchedulingDelay)
+}
+
+func TestSchedulerWakesUpWhenHigherPrioritiesAreRelayed(t *testing.T) {
+	high, low := newTestPipeline(), newTestPipeline()
+	scheduler := newScheduler()
+	scheduler.add(high, config.HighPriority)
+	scheduler.add(low, config.LowPriority)
+	scheduler.schedule()
+
+	high.InputChan <- message.NewMessage([]byte("a"), nil, "", 0)
+	done := make(chan struct{})
+	go func() {
+		scheduler.wait(priorities[config.LowPriority])
+		close(done)
+	}()
+
+	// the scheduler is notified once the message has been handed to the processor
+	time.Sleep(time.Millisecond)
+	<-high.InputChan
+	scheduler.relayed()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the pipeline with a lower priority has not been woken up")
+	}
+}
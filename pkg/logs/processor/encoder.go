@@ -17,6 +17,14 @@ type Encoder interface {
 	Encode(msg *message.Message, redactedMsg []byte) ([]byte, error)
 }
 
+// getMessageHostname returns the host that emitted the message if known, the host of the agent otherwise.
+func getMessageHostname(msg *message.Message) string {
+	if hostname := msg.Origin.Hostname(); hostname != "" {
+		return hostname
+	}
+	return getHostname()
+}
+
 // toValidUtf8 ensures all characters are UTF-8.
 func toValidUtf8(msg []byte) string {
 	if utf8.Valid(msg) {
@@ -221,9 +221,72 @@ func TestJsonEncoder(t *testing.T) {
 	assert.NotEmpty(t, log.Timestamp)
 }
 
+func TestEncodersUseMessageHostname(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{})
+	msg := newMessage([]byte("message"), source, message.StatusInfo)
+	msg.Origin.SetHostname("remote-host")
+
+	jsonMessage, err := JSONEncoder.Encode(msg, msg.Content)
+	assert.Nil(t, err)
+	log := &jsonPayload{}
+	assert.Nil(t, json.Unmarshal(jsonMessage, log))
+	assert.Equal(t, "remote-host", log.Hostname)
+
+	protoMessage, err := ProtoEncoder.Encode(msg, msg.Content)
+	assert.Nil(t, err)
+	protoLog := &pb.Log{}
+	assert.Nil(t, protoLog.Unmarshal(protoMessage))
+	assert.Equal(t, "remote-host", protoLog.Hostname)
+
+	rawMessage, err := RawEncoder.Encode(msg, msg.Content)
+	assert.Nil(t, err)
+	assert.Contains(t, string(rawMessage), " remote-host ")
+}
+
 func TestEncoderToValidUTF8(t *testing.T) {
 	assert.Equal(t, "a�z", toValidUtf8([]byte("a\xfez")))
 	assert.Equal(t, "a��z", toValidUtf8([]byte("a\xc0\xafz")))
 	assert.Equal(t, "a���z", toValidUtf8([]byte("a\xed\xa0\x80z")))
 	assert.Equal(t, "a����z", toValidUtf8([]byte("a\xf0\x8f\xbf\xbfz")))
 }
+
+func TestSyslogEncoder(t *testing.T) {
+	logsConfig := &config.LogsConfig{
+		Service:        "Service",
+		Source:         "Source",
+		SourceCategory: "SourceCategory",
+		Tags:           []string{"foo:bar", "baz"},
+	}
+	source := config.NewLogSource("", logsConfig)
+	msg := newMessage([]byte("message"), source, message.StatusError)
+	msg.Origin.SetTags([]string{"a", "b:c"})
+	msg.Origin.SetHostname("remote-host")
+	msg.Timestamp = time.Date(2021, 3, 4, 5, 6, 7, 123456789, time.UTC)
+
+	syslogMessage, err := SyslogEncoder.Encode(msg, []byte("redacted"))
+	assert.Nil(t, err)
+	assert.Equal(t, `<43>1 2021-03-04T05:06:07.123456Z remote-host Service - - [dd ddsource="Source" ddtags="a,b:c,sourcecategory:SourceCategory,foo:bar,baz"] redacted`, string(syslogMessage))
+}
+
+func TestSyslogEncoderDefaults(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{})
+	msg := newMessage([]byte("message"), source, "")
+	msg.Origin.SetHostname("remote host")
+
+	syslogMessage, err := SyslogEncoder.Encode(msg, []byte(""))
+	assert.Nil(t, err)
+	parts := strings.Split(string(syslogMessage), " ")
+	assert.Equal(t, 7, len(parts))
+	assert.Equal(t, string(message.SevInfo)+"1", parts[0])
+	assert.Equal(t, "remote_host", parts[2])
+	assert.Equal(t, []string{"-", "-", "-", "-"}, parts[3:])
+}
+
+func TestSyslogEncoderEscapesStructuredData(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{Source: `a"b\c]d`})
+	msg := newMessage([]byte("message"), source, message.StatusInfo)
+
+	syslogMessage, err := SyslogEncoder.Encode(msg, msg.Content)
+	assert.Nil(t, err)
+	assert.Contains(t, string(syslogMessage), `[dd ddsource="a\"b\\c\]d"] message`)
+}
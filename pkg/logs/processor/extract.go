@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// applyExtractRule runs the rule regular expression on the content and turns the
+// non-empty named captures into message tags or structured attributes.
+// When extracting to attributes, the content is turned into a JSON object holding
+// the original content under the `message` key, unless it already is a JSON object.
+func applyExtractRule(rule *config.ProcessingRule, msg *message.Message, content []byte) []byte {
+	submatches := rule.Regex.FindSubmatch(content)
+	if submatches == nil {
+		return content
+	}
+	names := rule.Regex.SubexpNames()
+
+	if rule.ExtractTo != config.ExtractToAttributes {
+		var tags []string
+		for i, name := range names {
+			if name != "" && len(submatches[i]) > 0 {
+				tags = append(tags, name+":"+string(submatches[i]))
+			}
+		}
+		if len(tags) > 0 {
+			msg.Origin.AddTags(tags...)
+		}
+		return content
+	}
+
+	attributes, ok := parseJSONObject(content)
+	if !ok {
+		attributes = map[string]interface{}{"message": toValidUtf8(content)}
+	}
+	extracted := false
+	for i, name := range names {
+		if name != "" && len(submatches[i]) > 0 {
+			attributes[name] = string(submatches[i])
+			extracted = true
+		}
+	}
+	if !extracted {
+		return content
+	}
+	structured, err := json.Marshal(attributes)
+	if err != nil {
+		return content
+	}
+	return structured
+}
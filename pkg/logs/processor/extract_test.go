@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func newExtractSource(t *testing.T, pattern, extractTo string) *config.LogSource {
+	rules := []*config.ProcessingRule{{Type: config.Extract, Name: "extract", Pattern: pattern, ExtractTo: extractTo}}
+	assert.Nil(t, config.ValidateProcessingRules(rules))
+	assert.Nil(t, config.CompileProcessingRules(rules))
+	return config.NewLogSource("", &config.LogsConfig{ProcessingRules: rules})
+}
+
+func TestExtractToTags(t *testing.T) {
+	p := &Processor{}
+	source := newExtractSource(t, `"%{HTTPMETHOD} %{NOTSPACE}[^"]*" %{HTTPSTATUS:status_code}(?: request_id=(?P<request_id>\w+))?`, "")
+
+	content := []byte(`10.0.0.1 - - [17/Oct/2020:13:55:36 -0700] "GET /health HTTP/1.1" 503 request_id=abc42`)
+	msg := newMessage(content, source, "")
+	shouldProcess, redactedMessage := p.applyRedactingRules(msg)
+	assert.True(t, shouldProcess)
+	assert.Equal(t, content, redactedMessage)
+	assert.Equal(t, []string{"status_code:503", "request_id:abc42"}, msg.Origin.Tags())
+
+	// empty captures are not turned into tags
+	msg = newMessage([]byte(`"POST /login HTTP/1.1" 200`), source, "")
+	p.applyRedactingRules(msg)
+	assert.Equal(t, []string{"status_code:200"}, msg.Origin.Tags())
+
+	// lines that don't match are left untouched
+	msg = newMessage([]byte("starting server"), source, "")
+	_, redactedMessage = p.applyRedactingRules(msg)
+	assert.Equal(t, []byte("starting server"), redactedMessage)
+	assert.Empty(t, msg.Origin.Tags())
+}
+
+func TestExtractToAttributes(t *testing.T) {
+	p := &Processor{}
+	source := newExtractSource(t, `status=%{INT:status_code}`, config.ExtractToAttributes)
+
+	msg := newMessage([]byte("request done status=404"), source, "")
+	_, redactedMessage := p.applyRedactingRules(msg)
+	var attributes map[string]interface{}
+	assert.Nil(t, json.Unmarshal(redactedMessage, &attributes))
+	assert.Equal(t, map[string]interface{}{"message": "request done status=404", "status_code": "404"}, attributes)
+	assert.Empty(t, msg.Origin.Tags())
+
+	// JSON objects are enriched in place
+	msg = newMessage([]byte(`{"msg":"request done status=404","duration":12345678901234567}`), source, "")
+	_, redactedMessage = p.applyRedactingRules(msg)
+	assert.JSONEq(t, `{"msg":"request done status=404","duration":12345678901234567,"status_code":"404"}`, string(redactedMessage))
+}
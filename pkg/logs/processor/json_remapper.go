@@ -0,0 +1,239 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// attributePathSeparator separates the keys of nested JSON attributes, e.g. `http.status_code`.
+const attributePathSeparator = "."
+
+// statusAliases maps the most common level names to message statuses.
+var statusAliases = map[string]string{
+	"emerg":         message.StatusEmergency,
+	"emergency":     message.StatusEmergency,
+	"panic":         message.StatusEmergency,
+	"alert":         message.StatusAlert,
+	"crit":          message.StatusCritical,
+	"critical":      message.StatusCritical,
+	"fatal":         message.StatusCritical,
+	"err":           message.StatusError,
+	"error":         message.StatusError,
+	"severe":        message.StatusError,
+	"warn":          message.StatusWarning,
+	"warning":       message.StatusWarning,
+	"notice":        message.StatusNotice,
+	"info":          message.StatusInfo,
+	"informational": message.StatusInfo,
+	"debug":         message.StatusDebug,
+	"trace":         message.StatusDebug,
+}
+
+// applyJSONRule parses the content as a JSON object, promotes the configured attributes
+// to the message status, service, timestamp and tags, then renames and drops attributes.
+// The content is returned untouched when it is not a JSON object.
+func applyJSONRule(rule *config.ProcessingRule, msg *message.Message, content []byte) []byte {
+	attributes, ok := parseJSONObject(content)
+	if !ok {
+		return content
+	}
+
+	if value, found := getAttribute(attributes, rule.StatusAttribute); found {
+		if status, ok := toStatus(value); ok {
+			msg.SetStatus(status)
+		}
+	}
+	if value, found := getAttribute(attributes, rule.ServiceAttribute); found {
+		if service, ok := toTagValue(value); ok && service != "" {
+			msg.Origin.SetService(service)
+		}
+	}
+	if value, found := getAttribute(attributes, rule.TimestampAttribute); found {
+		if timestamp, ok := toTimestamp(value); ok {
+			msg.Timestamp = timestamp
+		}
+	}
+	var tags []string
+	for _, path := range rule.TagAttributes {
+		if value, found := getAttribute(attributes, path); found {
+			if tagValue, ok := toTagValue(value); ok {
+				tags = append(tags, path+":"+tagValue)
+			}
+		}
+	}
+	if len(tags) > 0 {
+		msg.Origin.AddTags(tags...)
+	}
+
+	if len(rule.RenameAttributes) == 0 && len(rule.DropAttributes) == 0 {
+		return content
+	}
+	modified := false
+	for from, to := range rule.RenameAttributes {
+		if value, found := deleteAttribute(attributes, from); found {
+			setAttribute(attributes, to, value)
+			modified = true
+		}
+	}
+	for _, path := range rule.DropAttributes {
+		if _, found := deleteAttribute(attributes, path); found {
+			modified = true
+		}
+	}
+	if !modified {
+		return content
+	}
+	remapped, err := json.Marshal(attributes)
+	if err != nil {
+		return content
+	}
+	return remapped
+}
+
+// parseJSONObject decodes content made of a single JSON object, numbers are kept as json.Number
+// so that they are not altered when the object is encoded again.
+func parseJSONObject(content []byte) (map[string]interface{}, bool) {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	var attributes map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	if err := decoder.Decode(&attributes); err != nil || decoder.More() {
+		return nil, false
+	}
+	return attributes, true
+}
+
+// getAttribute returns the value of the attribute at the given path.
+func getAttribute(attributes map[string]interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	if value, found := attributes[path]; found {
+		return value, true
+	}
+	keys := strings.Split(path, attributePathSeparator)
+	current := attributes
+	for i, key := range keys {
+		value, found := current[key]
+		if !found {
+			return nil, false
+		}
+		if i == len(keys)-1 {
+			return value, true
+		}
+		if current, found = value.(map[string]interface{}); !found {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// deleteAttribute removes the attribute at the given path and returns its value.
+func deleteAttribute(attributes map[string]interface{}, path string) (interface{}, bool) {
+	if value, found := attributes[path]; found {
+		delete(attributes, path)
+		return value, true
+	}
+	keys := strings.Split(path, attributePathSeparator)
+	current := attributes
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	last := keys[len(keys)-1]
+	value, found := current[last]
+	if found {
+		delete(current, last)
+	}
+	return value, found
+}
+
+// setAttribute sets the attribute at the given path, creating the intermediate objects if needed.
+func setAttribute(attributes map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, attributePathSeparator)
+	current := attributes
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+}
+
+// toStatus converts a level name or a syslog severity number into a message status.
+func toStatus(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		status, found := statusAliases[strings.ToLower(strings.TrimSpace(v))]
+		return status, found
+	case json.Number:
+		severity, err := v.Int64()
+		if err != nil {
+			return "", false
+		}
+		return message.SeverityToStatus(int(severity))
+	}
+	return "", false
+}
+
+// toTimestamp converts a RFC3339 date or a unix epoch in seconds, milliseconds,
+// microseconds or nanoseconds into an UTC time.
+func toTimestamp(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t.UTC(), true
+	case json.Number:
+		epoch, err := v.Float64()
+		if err != nil || epoch <= 0 {
+			return time.Time{}, false
+		}
+		switch {
+		case epoch < 1e11:
+			return time.Unix(0, int64(epoch*float64(time.Second))).UTC(), true
+		case epoch < 1e14:
+			return time.Unix(0, int64(epoch*float64(time.Millisecond))).UTC(), true
+		case epoch < 1e17:
+			return time.Unix(0, int64(epoch*float64(time.Microsecond))).UTC(), true
+		default:
+			return time.Unix(0, int64(epoch)).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// toTagValue converts a scalar JSON value into a tag value.
+func toTagValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return fmt.Sprintf("%t", v), true
+	}
+	return "", false
+}
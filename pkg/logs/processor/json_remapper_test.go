@@ -0,0 +1,131 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newJSONSource(rule *config.ProcessingRule) *config.LogSource {
+	rule.Type = config.RemapJSON
+	rule.Name = "json"
+	return config.NewLogSource("", &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{rule}})
+}
+
+func TestJSONRulePromotesAttributes(t *testing.T) {
+	p := &Processor{}
+	source := newJSONSource(&config.ProcessingRule{
+		StatusAttribute:    "level",
+		ServiceAttribute:   "service",
+		TimestampAttribute: "timestamp",
+		TagAttributes:      []string{"trace_id", "http.status_code", "missing"},
+	})
+
+	content := []byte(`{"level":"WARNING","service":"billing","timestamp":"2020-10-17T12:00:00.5Z","trace_id":"abc123","http":{"status_code":503},"message":"upstream timeout"}`)
+	msg := newMessage(content, source, "")
+	shouldProcess, redactedMessage := p.applyRedactingRules(msg)
+
+	assert.True(t, shouldProcess)
+	assert.Equal(t, content, redactedMessage)
+	assert.Equal(t, message.StatusWarning, msg.GetStatus())
+	assert.Equal(t, "billing", msg.Origin.Service())
+	assert.Equal(t, time.Date(2020, 10, 17, 12, 0, 0, 500000000, time.UTC), msg.Timestamp)
+	assert.Equal(t, []string{"trace_id:abc123", "http.status_code:503"}, msg.Origin.Tags())
+}
+
+func TestJSONRuleRenamesAndDropsAttributes(t *testing.T) {
+	p := &Processor{}
+	source := newJSONSource(&config.ProcessingRule{
+		RenameAttributes: map[string]string{"msg": "message", "ctx.user": "usr.id"},
+		DropAttributes:   []string{"password", "ctx.session"},
+	})
+
+	msg := newMessage([]byte(`{"msg":"logged in","password":"hunter2","ctx":{"user":"bob","session":"s3cr3t"}}`), source, "")
+	_, redactedMessage := p.applyRedactingRules(msg)
+
+	var attributes map[string]interface{}
+	assert.Nil(t, json.Unmarshal(redactedMessage, &attributes))
+	assert.Equal(t, map[string]interface{}{
+		"message": "logged in",
+		"ctx":     map[string]interface{}{},
+		"usr":     map[string]interface{}{"id": "bob"},
+	}, attributes)
+}
+
+func TestJSONRuleIgnoresNonJSONLines(t *testing.T) {
+	p := &Processor{}
+	source := newJSONSource(&config.ProcessingRule{
+		StatusAttribute: "level",
+		DropAttributes:  []string{"password"},
+	})
+
+	for _, content := range []string{"plain text line", `{"level":"error"`, `["level","error"]`, `{"level":"error"} trailing`} {
+		msg := newMessage([]byte(content), source, message.StatusInfo)
+		shouldProcess, redactedMessage := p.applyRedactingRules(msg)
+		assert.True(t, shouldProcess)
+		assert.Equal(t, []byte(content), redactedMessage)
+		assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	}
+}
+
+func TestJSONRuleWithPattern(t *testing.T) {
+	p := &Processor{}
+	rule := &config.ProcessingRule{StatusAttribute: "level", Pattern: "billing", Regex: regexp.MustCompile("billing")}
+	source := newJSONSource(rule)
+
+	msg := newMessage([]byte(`{"level":"error","service":"shipping"}`), source, "")
+	p.applyRedactingRules(msg)
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+
+	msg = newMessage([]byte(`{"level":"error","service":"billing"}`), source, "")
+	p.applyRedactingRules(msg)
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+}
+
+func TestToStatus(t *testing.T) {
+	for value, expected := range map[interface{}]string{
+		"fatal":          message.StatusCritical,
+		" Err ":          message.StatusError,
+		"informational":  message.StatusInfo,
+		json.Number("3"): message.StatusError,
+		json.Number("7"): message.StatusDebug,
+	} {
+		status, ok := toStatus(value)
+		assert.True(t, ok)
+		assert.Equal(t, expected, status)
+	}
+	for _, value := range []interface{}{"verbose", json.Number("8"), json.Number("-1"), json.Number("2.5"), true} {
+		_, ok := toStatus(value)
+		assert.False(t, ok)
+	}
+}
+
+func TestToTimestamp(t *testing.T) {
+	expected := time.Date(2020, 10, 17, 12, 0, 0, 0, time.UTC)
+	for _, value := range []interface{}{
+		"2020-10-17T14:00:00+02:00",
+		json.Number("1602936000"),
+		json.Number("1602936000000"),
+		json.Number("1602936000000000"),
+		json.Number("1602936000000000000"),
+	} {
+		timestamp, ok := toTimestamp(value)
+		assert.True(t, ok)
+		assert.True(t, expected.Equal(timestamp), value)
+	}
+	for _, value := range []interface{}{"yesterday", json.Number("-1"), false} {
+		_, ok := toTimestamp(value)
+		assert.False(t, ok)
+	}
+}
@@ -8,6 +8,7 @@ package processor
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -17,6 +18,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 )
 
+// Forwarder receives the processed messages to send a copy of them to other destinations.
+type Forwarder interface {
+	Forward(msg *message.Message, redactedMsg []byte)
+}
+
 // A Processor updates messages from an inputChan and pushes
 // in an outputChan.
 type Processor struct {
@@ -26,6 +32,7 @@ type Processor struct {
 	encoder                   Encoder
 	done                      chan struct{}
 	diagnosticMessageReceiver diagnostic.MessageReceiver
+	forwarder                 Forwarder
 	mu                        sync.Mutex
 }
 
@@ -41,6 +48,13 @@ func New(inputChan, outputChan chan *message.Message, processingRules []*config.
 	}
 }
 
+// NewWithForwarder returns an initialized Processor handing a copy of the processed messages to forwarder.
+func NewWithForwarder(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, encoder Encoder, diagnosticMessageReceiver diagnostic.MessageReceiver, forwarder Forwarder) *Processor {
+	processor := New(inputChan, outputChan, processingRules, encoder, diagnosticMessageReceiver)
+	processor.forwarder = forwarder
+	return processor
+}
+
 // Start starts the Processor.
 func (p *Processor) Start() {
 	go p.run()
@@ -91,6 +105,9 @@ func (p *Processor) processMessage(msg *message.Message) {
 		metrics.TlmLogsProcessed.Inc()
 
 		p.diagnosticMessageReceiver.HandleMessage(*msg, redactedMsg)
+		if p.forwarder != nil {
+			p.forwarder.Forward(msg, redactedMsg)
+		}
 
 		// Encode the message to its final format
 		content, err := p.encoder.Encode(msg, redactedMsg)
@@ -108,7 +125,7 @@ func (p *Processor) processMessage(msg *message.Message) {
 func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 	content := msg.Content
 	rules := append(p.processingRules, msg.Origin.LogSource.Config.ProcessingRules...)
-	for _, rule := range rules {
+	for i, rule := range rules {
 		switch rule.Type {
 		case config.ExcludeAtMatch:
 			if rule.Regex.Match(content) {
@@ -120,6 +137,22 @@ func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 			}
 		case config.MaskSequences:
 			content = rule.Regex.ReplaceAll(content, rule.Placeholder)
+		case config.RemapJSON:
+			if rule.Regex == nil || rule.Regex.Match(content) {
+				content = applyJSONRule(rule, msg, content)
+			}
+		case config.Extract:
+			content = applyExtractRule(rule, msg, content)
+		case config.Sample:
+			if rule.Regex == nil || rule.Regex.Match(content) {
+				origin, index := "global", i
+				if i >= len(p.processingRules) {
+					origin, index = "source", i-len(p.processingRules)
+				}
+				if !getSampler(msg.Origin.LogSource, rule, origin, index).keep(time.Now()) {
+					return false, nil
+				}
+			}
 		}
 	}
 	return true, content
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/stretchr/testify/assert"
 )
@@ -149,6 +150,30 @@ func newProcessingRule(ruleType, replacePlaceholder, pattern string) *config.Pro
 	}
 }
 
+type forwarderMock struct {
+	messages [][]byte
+}
+
+func (f *forwarderMock) Forward(msg *message.Message, redactedMsg []byte) {
+	f.messages = append(f.messages, redactedMsg)
+}
+
+func TestProcessorForwardsProcessedMessages(t *testing.T) {
+	forwarder := &forwarderMock{}
+	outputChan := make(chan *message.Message, 2)
+	p := NewWithForwarder(nil, outputChan, []*config.ProcessingRule{
+		newProcessingRule(config.ExcludeAtMatch, "", "exclude"),
+		newProcessingRule(config.MaskSequences, "[masked]", "secret"),
+	}, RawEncoder, &diagnostic.NoopMessageReceiver{}, forwarder)
+
+	source := config.NewLogSource("", &config.LogsConfig{})
+	p.processMessage(newMessage([]byte("exclude me"), source, ""))
+	p.processMessage(newMessage([]byte("my secret"), source, ""))
+
+	assert.Equal(t, [][]byte{[]byte("my [masked]")}, forwarder.messages)
+	assert.Len(t, outputChan, 1)
+}
+
 func newSource(ruleType, replacePlaceholder, pattern string) config.LogSource {
 	return config.LogSource{Config: &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{newProcessingRule(ruleType, replacePlaceholder, pattern)}}}
 }
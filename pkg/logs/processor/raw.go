@@ -35,10 +35,14 @@ func (r *rawEncoder) Encode(msg *message.Message, redactedMsg []byte) ([]byte, e
 		extraContent = append(extraContent, ' ')
 
 		// Timestamp
-		extraContent = time.Now().UTC().AppendFormat(extraContent, config.DateFormat)
+		ts := time.Now().UTC()
+		if !msg.Timestamp.IsZero() {
+			ts = msg.Timestamp
+		}
+		extraContent = ts.AppendFormat(extraContent, config.DateFormat)
 		extraContent = append(extraContent, ' ')
 
-		extraContent = append(extraContent, []byte(getHostname())...)
+		extraContent = append(extraContent, []byte(getMessageHostname(msg))...)
 		extraContent = append(extraContent, ' ')
 
 		// Service
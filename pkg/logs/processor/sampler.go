@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+// sampler keeps one line out of sampleRate and enforces a rate limit with a token bucket.
+// A sampler is shared by all the processors handling lines of a same source, it is
+// registered on the source so that the matched and dropped counts show up on the status page.
+type sampler struct {
+	// Put counters first because they are modified with sync/atomic, so they need to
+	// be 64-bit aligned on 32-bit systems. See https://golang.org/pkg/sync/atomic/#pkg-note-BUG
+	matched int64
+	dropped int64
+
+	ruleName   string
+	infoKey    string
+	sampleRate int64
+	rateLimit  float64
+	burst      float64
+
+	mu         sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+}
+
+// newSampler returns a sampler configured with the given rule,
+// the burst defaults to one second worth of lines.
+func newSampler(rule *config.ProcessingRule, infoKey string) *sampler {
+	burst := float64(rule.Burst)
+	if burst == 0 {
+		burst = math.Max(1, math.Ceil(rule.RateLimit))
+	}
+	return &sampler{
+		ruleName:   rule.Name,
+		infoKey:    infoKey,
+		sampleRate: int64(rule.SampleRate),
+		rateLimit:  rule.RateLimit,
+		burst:      burst,
+		tokens:     burst,
+	}
+}
+
+// samplerInfoKey returns the key of the sampler of a rule on the status page, the rule is
+// identified by its origin, global or source, and its position so that rules sharing
+// a name get their own sampler.
+func samplerInfoKey(ruleName string, origin string, index int) string {
+	return fmt.Sprintf("Sampling rule %s (%s rule #%d)", ruleName, origin, index+1)
+}
+
+// getSampler returns the sampler of the rule at the given position of the global or source rules for the given source.
+func getSampler(source *config.LogSource, rule *config.ProcessingRule, origin string, index int) *sampler {
+	key := samplerInfoKey(rule.Name, origin, index)
+	return source.GetOrRegisterInfo(key, func() config.InfoProvider {
+		return newSampler(rule, key)
+	}).(*sampler)
+}
+
+// keep returns true if the line should be kept, dropped lines are counted.
+func (s *sampler) keep(now time.Time) bool {
+	matched := atomic.AddInt64(&s.matched, 1)
+	if s.sampleRate > 1 && (matched-1)%s.sampleRate != 0 {
+		s.drop()
+		return false
+	}
+	if s.rateLimit > 0 && !s.takeToken(now) {
+		s.drop()
+		return false
+	}
+	return true
+}
+
+// takeToken refills the bucket with the tokens accumulated since the last call
+// and consumes one token if there is any left.
+func (s *sampler) takeToken(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.lastRefill.IsZero() {
+		s.tokens = math.Min(s.burst, s.tokens+now.Sub(s.lastRefill).Seconds()*s.rateLimit)
+	}
+	s.lastRefill = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+func (s *sampler) drop() {
+	atomic.AddInt64(&s.dropped, 1)
+	metrics.LogsSampledOut.Add(1)
+	metrics.TlmLogsSampledOut.Inc(s.ruleName)
+}
+
+// InfoKey returns the key
+func (s *sampler) InfoKey() string {
+	return s.infoKey
+}
+
+// Info returns the info
+func (s *sampler) Info() []string {
+	return []string{fmt.Sprintf("%d lines matched, %d dropped", atomic.LoadInt64(&s.matched), atomic.LoadInt64(&s.dropped))}
+}
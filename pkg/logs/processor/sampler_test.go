@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+func TestSamplerSampleRate(t *testing.T) {
+	s := newSampler(&config.ProcessingRule{Name: "one_in_three", SampleRate: 3}, "")
+	now := time.Now()
+
+	var kept []int
+	for i := 0; i < 9; i++ {
+		if s.keep(now) {
+			kept = append(kept, i)
+		}
+	}
+	assert.Equal(t, []int{0, 3, 6}, kept)
+	assert.Equal(t, []string{"9 lines matched, 6 dropped"}, s.Info())
+}
+
+func TestSamplerRateLimit(t *testing.T) {
+	s := newSampler(&config.ProcessingRule{Name: "two_per_second", RateLimit: 2}, "")
+	now := time.Now()
+
+	// the bucket starts full
+	assert.True(t, s.keep(now))
+	assert.True(t, s.keep(now))
+	assert.False(t, s.keep(now))
+
+	// tokens are refilled over time
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, s.keep(now))
+	assert.False(t, s.keep(now))
+
+	// the bucket never holds more than the burst
+	now = now.Add(time.Minute)
+	assert.True(t, s.keep(now))
+	assert.True(t, s.keep(now))
+	assert.False(t, s.keep(now))
+	assert.Equal(t, []string{"8 lines matched, 3 dropped"}, s.Info())
+}
+
+func TestSamplerRateLimitWithBurst(t *testing.T) {
+	s := newSampler(&config.ProcessingRule{Name: "burst", RateLimit: 0.5, Burst: 3}, "")
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, s.keep(now))
+	}
+	assert.False(t, s.keep(now))
+	now = now.Add(time.Second)
+	assert.False(t, s.keep(now))
+	now = now.Add(time.Second)
+	assert.True(t, s.keep(now))
+}
+
+func TestSampleRule(t *testing.T) {
+	p := &Processor{}
+	rule := &config.ProcessingRule{Type: config.Sample, Name: "healthchecks", Pattern: "healthz", Regex: regexp.MustCompile("healthz"), SampleRate: 2}
+	source := config.NewLogSource("", &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{rule}})
+	sampledOut := metrics.LogsSampledOut.Value()
+
+	var kept int
+	for i := 0; i < 10; i++ {
+		if shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("GET /healthz 200"), source, "")); shouldProcess {
+			kept++
+		}
+	}
+	assert.Equal(t, 5, kept)
+
+	// lines that don't match the pattern are not sampled
+	for i := 0; i < 10; i++ {
+		shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("GET /users 200"), source, ""))
+		assert.True(t, shouldProcess)
+	}
+
+	assert.Equal(t, sampledOut+5, metrics.LogsSampledOut.Value())
+	assert.Equal(t, []string{"10 lines matched, 5 dropped"}, source.GetInfoStatus()["Sampling rule healthchecks (source rule #1)"])
+}
+
+func TestSampleRuleIsPerSource(t *testing.T) {
+	rule := &config.ProcessingRule{Type: config.Sample, Name: "global", RateLimit: 1}
+	p := &Processor{processingRules: []*config.ProcessingRule{rule}}
+	source1 := config.NewLogSource("", &config.LogsConfig{})
+	source2 := config.NewLogSource("", &config.LogsConfig{})
+
+	shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("hello"), source1, ""))
+	assert.True(t, shouldProcess)
+	shouldProcess, _ = p.applyRedactingRules(newMessage([]byte("hello"), source2, ""))
+	assert.True(t, shouldProcess)
+	shouldProcess, _ = p.applyRedactingRules(newMessage([]byte("hello"), source1, ""))
+	assert.False(t, shouldProcess)
+}
+
+func TestSampleRulesWithTheSameName(t *testing.T) {
+	globalRule := &config.ProcessingRule{Type: config.Sample, Name: "noisy", SampleRate: 2}
+	sourceRule := &config.ProcessingRule{Type: config.Sample, Name: "noisy", SampleRate: 3}
+	p := &Processor{processingRules: []*config.ProcessingRule{globalRule}}
+	source := config.NewLogSource("", &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{sourceRule}})
+
+	var kept int
+	for i := 0; i < 12; i++ {
+		if shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("hello"), source, "")); shouldProcess {
+			kept++
+		}
+	}
+	// each rule has its own sampler: 6 lines out of 12 go through the global rule, 2 of them through the source rule
+	assert.Equal(t, 2, kept)
+	assert.Equal(t, []string{"12 lines matched, 6 dropped"}, source.GetInfoStatus()["Sampling rule noisy (global rule #1)"])
+	assert.Equal(t, []string{"6 lines matched, 4 dropped"}, source.GetInfoStatus()["Sampling rule noisy (source rule #1)"])
+}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const (
+	// syslogTimestampFormat is the RFC 5424 timestamp format, it allows at most 6 digits for the fraction of second.
+	syslogTimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+	// syslogNilValue is used for the header fields that are not set.
+	syslogNilValue = "-"
+	// maxSyslogAppNameLength is the maximum length of the APP-NAME field.
+	maxSyslogAppNameLength = 48
+	// maxSyslogHostnameLength is the maximum length of the HOSTNAME field.
+	maxSyslogHostnameLength = 255
+)
+
+// SyslogEncoder is a shared encoder formatting the messages according to RFC 5424.
+var SyslogEncoder Encoder = &syslogEncoder{}
+
+// syslogEncoder differs from the raw encoder which targets the Datadog intake: the output
+// strictly follows RFC 5424 so that it can be ingested by any syslog collector.
+type syslogEncoder struct{}
+
+// Encode formats the message as `<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG`,
+// the source and the tags are sent as the parameters of the `dd` structured data element.
+func (s *syslogEncoder) Encode(msg *message.Message, redactedMsg []byte) ([]byte, error) {
+	ts := time.Now().UTC()
+	if !msg.Timestamp.IsZero() {
+		ts = msg.Timestamp.UTC()
+	}
+
+	var b strings.Builder
+	b.Write(message.StatusToSeverity(msg.GetStatus()))
+	b.WriteString("1 ")
+	b.WriteString(ts.Format(syslogTimestampFormat))
+	b.WriteByte(' ')
+	b.WriteString(toSyslogHeaderField(getMessageHostname(msg), maxSyslogHostnameLength))
+	b.WriteByte(' ')
+	b.WriteString(toSyslogHeaderField(msg.Origin.Service(), maxSyslogAppNameLength))
+	// PROCID and MSGID
+	b.WriteString(" - - ")
+	b.WriteString(buildSyslogStructuredData(msg.Origin))
+	if len(redactedMsg) > 0 {
+		b.WriteByte(' ')
+		b.WriteString(toValidUtf8(redactedMsg))
+	}
+	return []byte(b.String()), nil
+}
+
+// buildSyslogStructuredData returns the structured data element holding the source and the tags.
+func buildSyslogStructuredData(origin *message.Origin) string {
+	var params []string
+	if source := origin.Source(); source != "" {
+		params = append(params, "ddsource=\""+escapeSyslogParamValue(source)+"\"")
+	}
+	if tags := origin.TagsToString(); tags != "" {
+		params = append(params, "ddtags=\""+escapeSyslogParamValue(tags)+"\"")
+	}
+	if len(params) == 0 {
+		return syslogNilValue
+	}
+	return "[dd " + strings.Join(params, " ") + "]"
+}
+
+// escapeSyslogParamValue escapes the characters that are not allowed in a PARAM-VALUE.
+func escapeSyslogParamValue(value string) string {
+	var b strings.Builder
+	for _, r := range toValidUtf8([]byte(value)) {
+		if r == '"' || r == '\\' || r == ']' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// toSyslogHeaderField returns the value of a header field that only accepts printable US-ASCII characters
+// without spaces, the other characters are replaced by an underscore.
+func toSyslogHeaderField(value string, maxLength int) string {
+	if value == "" {
+		return syslogNilValue
+	}
+	field := []byte(value)
+	if len(field) > maxLength {
+		field = field[:maxLength]
+	}
+	for i, c := range field {
+		if c < 33 || c > 126 {
+			field[i] = '_'
+		}
+	}
+	return string(field)
+}
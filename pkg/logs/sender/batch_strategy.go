@@ -30,8 +30,13 @@ type batchStrategy struct {
 
 // NewBatchStrategy returns a new batchStrategy.
 func NewBatchStrategy(serializer Serializer, batchWait time.Duration) Strategy {
+	return NewBatchStrategyWithSize(serializer, batchWait, maxBatchSize)
+}
+
+// NewBatchStrategyWithSize returns a new batchStrategy sending at most batchSize messages per batch.
+func NewBatchStrategyWithSize(serializer Serializer, batchWait time.Duration, batchSize int) Strategy {
 	return &batchStrategy{
-		buffer:     NewMessageBuffer(maxBatchSize, maxContentSize),
+		buffer:     NewMessageBuffer(batchSize, maxContentSize),
 		serializer: serializer,
 		batchWait:  batchWait,
 	}
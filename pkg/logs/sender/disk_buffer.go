@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+const (
+	diskBufferExtension     = ".buffer"
+	diskBufferTempExtension = ".tmp"
+)
+
+// DiskBuffer is a bounded FIFO queue of payloads stored on disk, it is used to keep the payloads
+// that could not be sent while the main destination is unreachable.
+// Each payload is written to its own file which is renamed only once fully written and synced,
+// so that a crash never leaves a partial payload in the queue. When the maximum size is reached,
+// the oldest payloads are removed to make room for the new ones.
+type DiskBuffer struct {
+	path               string
+	maxSizeInBytes     int64
+	mu                 sync.Mutex
+	filenames          []string
+	currentSizeInBytes int64
+	sequence           uint64
+}
+
+// NewDiskBuffer returns a new DiskBuffer storing payloads in path,
+// the payloads left by a previous run are reloaded.
+func NewDiskBuffer(path string, maxSizeInBytes int64) (*DiskBuffer, error) {
+	if maxSizeInBytes <= 0 {
+		return nil, fmt.Errorf("invalid disk buffer maximum size: %d", maxSizeInBytes)
+	}
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	b := &DiskBuffer{
+		path:           path,
+		maxSizeInBytes: maxSizeInBytes,
+	}
+	if err := b.reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Store appends a payload to the queue.
+func (b *DiskBuffer) Store(payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	size := int64(len(payload))
+	if size > b.maxSizeInBytes {
+		return fmt.Errorf("payload is too big to be buffered on disk. Current:%v Maximum:%v", size, b.maxSizeInBytes)
+	}
+	for len(b.filenames) > 0 && b.currentSizeInBytes+size > b.maxSizeInBytes {
+		log.Warnf("Maximum disk space for logs buffering is reached. Removing %s", b.filenames[0])
+		if err := b.removeOldest(); err != nil {
+			return err
+		}
+		metrics.DiskBufferDroppedPayloads.Add(1)
+		metrics.TlmDiskBufferDroppedPayloads.Inc()
+	}
+
+	file, err := ioutil.TempFile(b.path, "*"+diskBufferTempExtension)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(payload); err == nil {
+		err = file.Sync()
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	filename := filepath.Join(b.path, b.nextFilename())
+	if err == nil {
+		err = os.Rename(file.Name(), filename)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	// sync the directory so that the rename survives a crash
+	if err := syncDir(b.path); err != nil {
+		_ = os.Remove(filename)
+		return err
+	}
+
+	b.filenames = append(b.filenames, filename)
+	b.currentSizeInBytes += size
+	metrics.DiskBufferBytes.Add(size)
+	return nil
+}
+
+// Peek returns the oldest payload of the queue without removing it,
+// it returns nil when the queue is empty.
+func (b *DiskBuffer) Peek() ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.filenames) == 0 {
+		return nil, nil
+	}
+	payload, err := ioutil.ReadFile(b.filenames[0])
+	if err != nil {
+		// the file is not readable, remove it to not block the queue
+		_ = b.removeOldest()
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Pop removes the oldest payload of the queue.
+func (b *DiskBuffer) Pop() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.filenames) == 0 {
+		return nil
+	}
+	return b.removeOldest()
+}
+
+// IsEmpty returns true if there is no payload in the queue.
+func (b *DiskBuffer) IsEmpty() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.filenames) == 0
+}
+
+// Len returns the number of payloads in the queue.
+func (b *DiskBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.filenames)
+}
+
+// nextFilename returns a file name that sorts after all the ones already generated.
+func (b *DiskBuffer) nextFilename() string {
+	b.sequence++
+	return fmt.Sprintf("%020d_%010d%s", time.Now().UnixNano(), b.sequence, diskBufferExtension)
+}
+
+func (b *DiskBuffer) removeOldest() error {
+	filename := b.filenames[0]
+
+	// Remove the file from b.filenames also in case of error to not
+	// fail on the next call.
+	b.filenames = b.filenames[1:]
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil {
+		return err
+	}
+	b.currentSizeInBytes -= info.Size()
+	metrics.DiskBufferBytes.Add(-info.Size())
+	return nil
+}
+
+// reload loads the payloads stored by a previous run, in order, and removes
+// the files that were not fully written.
+func (b *DiskBuffer) reload() error {
+	entries, err := ioutil.ReadDir(b.path)
+	if err != nil {
+		return err
+	}
+	var filenames []string
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+		switch filepath.Ext(entry.Name()) {
+		case diskBufferExtension:
+			filenames = append(filenames, filepath.Join(b.path, entry.Name()))
+			b.currentSizeInBytes += entry.Size()
+		case diskBufferTempExtension:
+			_ = os.Remove(filepath.Join(b.path, entry.Name()))
+		}
+	}
+	sort.Strings(filenames)
+	b.filenames = filenames
+	if len(filenames) > 0 {
+		log.Infof("Reloaded %d logs payloads buffered on disk in %s", len(filenames), b.path)
+	}
+	metrics.DiskBufferBytes.Add(b.currentSizeInBytes)
+	return nil
+}
@@ -0,0 +1,14 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build windows
+
+package sender
+
+// syncDir is a no-op on Windows where directories can not be synced,
+// the renames are persisted with the metadata of the file system.
+func syncDir(path string) error {
+	return nil
+}
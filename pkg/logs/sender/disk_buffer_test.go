@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskBufferPath(t *testing.T) string {
+	path, err := ioutil.TempDir("", "disk_buffer")
+	require.NoError(t, err)
+	return path
+}
+
+func TestDiskBufferIsFIFO(t *testing.T) {
+	path := newTestDiskBufferPath(t)
+	defer os.RemoveAll(path)
+
+	b, err := NewDiskBuffer(path, 1024)
+	require.NoError(t, err)
+	assert.True(t, b.IsEmpty())
+
+	payload, err := b.Peek()
+	assert.NoError(t, err)
+	assert.Nil(t, payload)
+
+	for _, p := range []string{"a", "b", "c"} {
+		assert.NoError(t, b.Store([]byte(p)))
+	}
+	assert.Equal(t, 3, b.Len())
+
+	for _, expected := range []string{"a", "b", "c"} {
+		payload, err := b.Peek()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(payload))
+		assert.NoError(t, b.Pop())
+	}
+	assert.True(t, b.IsEmpty())
+}
+
+func TestDiskBufferRemovesOldestPayloadsWhenFull(t *testing.T) {
+	path := newTestDiskBufferPath(t)
+	defer os.RemoveAll(path)
+
+	b, err := NewDiskBuffer(path, 10)
+	require.NoError(t, err)
+
+	assert.NoError(t, b.Store([]byte("1234")))
+	assert.NoError(t, b.Store([]byte("5678")))
+	assert.NoError(t, b.Store([]byte("9012")))
+	assert.Equal(t, 2, b.Len())
+	assert.Equal(t, int64(8), b.currentSizeInBytes)
+
+	payload, err := b.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, "5678", string(payload))
+
+	assert.Error(t, b.Store([]byte("this payload is too big")))
+	assert.Equal(t, 2, b.Len())
+}
+
+func TestDiskBufferReloadsPayloads(t *testing.T) {
+	path := newTestDiskBufferPath(t)
+	defer os.RemoveAll(path)
+
+	b, err := NewDiskBuffer(path, 1024)
+	require.NoError(t, err)
+	assert.NoError(t, b.Store([]byte("first")))
+	assert.NoError(t, b.Store([]byte("second")))
+
+	// a payload that was not fully written before a crash
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(path, "partial"+diskBufferTempExtension), []byte("sec"), 0600))
+
+	b, err = NewDiskBuffer(path, 1024)
+	require.NoError(t, err)
+	assert.Equal(t, 2, b.Len())
+	assert.Equal(t, int64(11), b.currentSizeInBytes)
+	payload, err := b.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, "first", string(payload))
+
+	_, err = os.Stat(filepath.Join(path, "partial"+diskBufferTempExtension))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDiskBufferInvalidSize(t *testing.T) {
+	path := newTestDiskBufferPath(t)
+	defer os.RemoveAll(path)
+
+	_, err := NewDiskBuffer(path, 0)
+	assert.Error(t, err)
+}
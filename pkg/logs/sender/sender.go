@@ -8,6 +8,9 @@ package sender
 import (
 	"context"
 	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
@@ -21,6 +24,9 @@ type Strategy interface {
 	Flush(ctx context.Context, inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error, mu *sync.Mutex)
 }
 
+// diskBufferReplayPeriod is the period at which the payloads buffered on disk are replayed.
+var diskBufferReplayPeriod = time.Second
+
 // Sender sends logs to different destinations.
 type Sender struct {
 	inputChan    chan *message.Message
@@ -29,6 +35,14 @@ type Sender struct {
 	strategy     Strategy
 	done         chan struct{}
 	mu           sync.Mutex
+
+	// diskBuffer, when set, stores the payloads that could not be sent to the main destination,
+	// they are replayed in the background once the destination recovers.
+	diskBuffer *DiskBuffer
+	// sendMu serializes the calls to the main destination between the sender and the replay.
+	sendMu     sync.Mutex
+	stopReplay chan struct{}
+	replayDone chan struct{}
 }
 
 // NewSender returns a new sender.
@@ -42,8 +56,21 @@ func NewSender(inputChan chan *message.Message, outputChan chan *message.Message
 	}
 }
 
+// NewSenderWithDiskBuffer returns a new sender buffering on disk the payloads
+// that can not be sent while the main destination is unreachable.
+func NewSenderWithDiskBuffer(inputChan chan *message.Message, outputChan chan *message.Message, destinations *client.Destinations, strategy Strategy, diskBuffer *DiskBuffer) *Sender {
+	sender := NewSender(inputChan, outputChan, destinations, strategy)
+	sender.diskBuffer = diskBuffer
+	sender.stopReplay = make(chan struct{})
+	sender.replayDone = make(chan struct{})
+	return sender
+}
+
 // Start starts the sender.
 func (s *Sender) Start() {
+	if s.diskBuffer != nil {
+		go s.replay()
+	}
 	go s.run()
 }
 
@@ -52,6 +79,10 @@ func (s *Sender) Start() {
 func (s *Sender) Stop() {
 	close(s.inputChan)
 	<-s.done
+	if s.diskBuffer != nil {
+		close(s.stopReplay)
+		<-s.replayDone
+	}
 }
 
 // Flush sends synchronously the messages that this sender has to send.
@@ -69,9 +100,23 @@ func (s *Sender) run() {
 // send sends a payload to multiple destinations,
 // it will forever retry for the main destination unless the error is not retryable
 // and only try once for additionnal destinations.
+// When a disk buffer is set, the payloads are stored on disk instead of being retried.
 func (s *Sender) send(payload []byte) error {
+	if s.diskBuffer != nil {
+		if handled, err := s.sendOrBuffer(payload); handled {
+			if err != nil {
+				return err
+			}
+			s.sendToAdditionals(payload)
+			return nil
+		}
+		// the payload could not be stored on disk, fallback to retrying
+	}
+
 	for {
+		s.sendMu.Lock()
 		err := s.destinations.Main.Send(payload)
+		s.sendMu.Unlock()
 		if err != nil {
 			metrics.DestinationErrors.Add(1)
 			metrics.TlmDestinationErrors.Inc()
@@ -85,13 +130,95 @@ func (s *Sender) send(payload []byte) error {
 		break
 	}
 
+	s.sendToAdditionals(payload)
+	return nil
+}
+
+// sendToAdditionals sends a payload once to all the additional destinations.
+func (s *Sender) sendToAdditionals(payload []byte) {
 	for _, destination := range s.destinations.Additionals {
 		// send in the background so that the agent does not fall behind
 		// for the main destination
 		destination.SendAsync(payload)
 	}
+}
 
-	return nil
+// sendOrBuffer sends a payload to the main destination or stores it on disk when the destination
+// is unreachable, payloads are stored right away when older ones are waiting to be replayed to preserve
+// their order. It returns false when the payload could neither be sent nor stored, and the error
+// of the main destination when it is not retryable.
+func (s *Sender) sendOrBuffer(payload []byte) (bool, error) {
+	if s.diskBuffer.IsEmpty() {
+		s.sendMu.Lock()
+		err := s.destinations.Main.Send(payload)
+		s.sendMu.Unlock()
+		if err == nil {
+			return true, nil
+		}
+		metrics.DestinationErrors.Add(1)
+		metrics.TlmDestinationErrors.Inc()
+		if _, ok := err.(*client.RetryableError); !ok {
+			return true, err
+		}
+	}
+	if err := s.diskBuffer.Store(payload); err != nil {
+		log.Warnf("Could not buffer payload on disk: %v", err)
+		return false, nil
+	}
+	return true, nil
+}
+
+// replay periodically sends the payloads buffered on disk to the main destination,
+// in order, until the buffer is empty or the destination fails again.
+func (s *Sender) replay() {
+	defer close(s.replayDone)
+	ticker := time.NewTicker(diskBufferReplayPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopReplay:
+			return
+		case <-ticker.C:
+		}
+		for !s.diskBuffer.IsEmpty() {
+			select {
+			case <-s.stopReplay:
+				return
+			default:
+			}
+			if !s.replayOldest() {
+				break
+			}
+		}
+	}
+}
+
+// replayOldest sends the oldest payload buffered on disk and removes it from the buffer,
+// it returns false when the destination is still unreachable.
+func (s *Sender) replayOldest() bool {
+	payload, err := s.diskBuffer.Peek()
+	if err != nil {
+		log.Warnf("Could not read payload buffered on disk: %v", err)
+		return true
+	}
+	if payload == nil {
+		return true
+	}
+	s.sendMu.Lock()
+	err = s.destinations.Main.Send(payload)
+	s.sendMu.Unlock()
+	if err != nil {
+		metrics.DestinationErrors.Add(1)
+		metrics.TlmDestinationErrors.Inc()
+		if _, ok := err.(*client.RetryableError); ok || shouldStopSending(err) {
+			return false
+		}
+		log.Warnf("Could not send payload buffered on disk, dropping it: %v", err)
+	}
+	if err := s.diskBuffer.Pop(); err != nil {
+		log.Warnf("Could not remove payload buffered on disk: %v", err)
+	}
+	return true
 }
 
 // shouldStopSending returns true if a component should stop sending logs.
@@ -6,9 +6,15 @@
 package sender
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/mock"
@@ -87,3 +93,70 @@ func TestSenderNotBlockedByAdditional(t *testing.T) {
 	sender.Stop()
 	destinationsCtx.Stop()
 }
+
+// unreliableDestination fails with a retryable error until it is made available.
+type unreliableDestination struct {
+	mu        sync.Mutex
+	available bool
+	payloads  []string
+}
+
+func (d *unreliableDestination) Send(payload []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.available {
+		return client.NewRetryableError(errors.New("destination is down"))
+	}
+	d.payloads = append(d.payloads, string(payload))
+	return nil
+}
+
+func (d *unreliableDestination) SendAsync(payload []byte) {}
+
+func (d *unreliableDestination) setAvailable(available bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.available = available
+}
+
+func (d *unreliableDestination) received() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.payloads...)
+}
+
+func TestSenderWithDiskBuffer(t *testing.T) {
+	defer func(period time.Duration) { diskBufferReplayPeriod = period }(diskBufferReplayPeriod)
+	diskBufferReplayPeriod = 10 * time.Millisecond
+
+	path, err := ioutil.TempDir("", "disk_buffer")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+	diskBuffer, err := NewDiskBuffer(path, 1024)
+	require.NoError(t, err)
+
+	source := config.NewLogSource("", &config.LogsConfig{})
+	input := make(chan *message.Message, 1)
+	output := make(chan *message.Message, 1)
+
+	destination := &unreliableDestination{}
+	sender := NewSenderWithDiskBuffer(input, output, client.NewDestinations(destination, nil), StreamStrategy, diskBuffer)
+	sender.Start()
+
+	// the messages are forwarded to the next stage even though the destination is down
+	for _, content := range []string{"line 1", "line 2"} {
+		input <- newMessage([]byte(content), source, "")
+		<-output
+	}
+	assert.Equal(t, 2, diskBuffer.Len())
+	assert.Empty(t, destination.received())
+
+	destination.setAvailable(true)
+	assert.Eventually(t, diskBuffer.IsEmpty, time.Second, 10*time.Millisecond)
+
+	input <- newMessage([]byte("line 3"), source, "")
+	<-output
+	assert.Equal(t, []string{"line 1", "line 2", "line 3"}, destination.received())
+
+	sender.Stop()
+}
@@ -5,7 +5,12 @@
 
 package metrics
 
-import "github.com/DataDog/datadog-agent/pkg/util"
+import (
+	"math"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
 
 // HistogramBucket represents a prometheus/openmetrics histogram bucket
 type HistogramBucket struct {
@@ -38,3 +43,18 @@ func (m *HistogramBucket) GetTags(tb *util.TagsBuilder) {
 	// tags.
 	tb.Append(m.Tags...)
 }
+
+// BucketBoundTags returns a copy of the tags of a histogram with the lower_bound and upper_bound
+// tags of one of its buckets, so that each bucket gets its own context in the aggregator.
+func BucketBoundTags(tags []string, lowerBound, upperBound float64) []string {
+	bucketTags := make([]string, 0, len(tags)+2)
+	bucketTags = append(bucketTags, tags...)
+	return append(bucketTags, "lower_bound:"+formatBound(lowerBound), "upper_bound:"+formatBound(upperBound))
+}
+
+func formatBound(bound float64) string {
+	if math.IsInf(bound, 1) {
+		return "inf"
+	}
+	return strconv.FormatFloat(bound, 'f', -1, 64)
+}
@@ -0,0 +1,267 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	typeGauge   = "gauge"
+	typeSummary = "summary"
+
+	quantileLabel = "quantile"
+)
+
+// family is a set of samples sharing the same metric name and type.
+type family struct {
+	name    string
+	typ     string
+	samples []sample
+}
+
+// sample is a series or a sketch rendered as one or several lines.
+type sample struct {
+	// labels is the rendered label set, without the braces
+	labels string
+	lines  []string
+}
+
+// renderer renders the series and the sketches of a flush in the OpenMetrics text format.
+// The series are rendered as gauges since the counts and the rates are computed over a flush
+// interval, the sketches as summaries made of the configured percentiles, the sum and the count.
+type renderer struct {
+	percentiles []float64
+	families    map[string]*family
+}
+
+func newRenderer(percentiles []float64) *renderer {
+	return &renderer{
+		percentiles: percentiles,
+		families:    make(map[string]*family),
+	}
+}
+
+// render writes the series and the sketches of a snapshot, snapshot can be nil.
+func (r *renderer) render(w io.Writer, snapshot *aggregator.FlushSnapshot) error {
+	if snapshot != nil {
+		for _, serie := range snapshot.Series {
+			r.addSerie(serie)
+		}
+		for _, sketch := range snapshot.Sketches {
+			r.addSketch(sketch)
+		}
+	}
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		f := r.families[name]
+		sort.SliceStable(f.samples, func(i, j int) bool { return f.samples[i].labels < f.samples[j].labels })
+		bw.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+		for i, s := range f.samples {
+			if i > 0 && s.labels == f.samples[i-1].labels {
+				// several series can have the same name and labels once sanitized
+				continue
+			}
+			for _, line := range s.lines {
+				bw.WriteString(line)
+				bw.WriteByte('\n')
+			}
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// getFamily returns the family of a metric, nil if a family of another type has the same name.
+func (r *renderer) getFamily(name, typ string) *family {
+	f, found := r.families[name]
+	if !found {
+		f = &family{name: name, typ: typ}
+		r.families[name] = f
+	}
+	if f.typ != typ {
+		log.Debugf("Not rendering the %s %s: a %s has the same name", typ, name, f.typ)
+		return nil
+	}
+	return f
+}
+
+func (r *renderer) addSerie(serie *metrics.Serie) {
+	if len(serie.Points) == 0 {
+		return
+	}
+	f := r.getFamily(sanitizeMetricName(serie.Name), typeGauge)
+	if f == nil {
+		return
+	}
+	tags := serie.Tags
+	if serie.Device != "" {
+		tags = append(append([]string{}, tags...), "device:"+serie.Device)
+	}
+	labels := renderLabels(serie.Host, tags, "")
+	point := serie.Points[len(serie.Points)-1]
+	f.samples = append(f.samples, sample{
+		labels: labels,
+		lines:  []string{sampleLine(f.name, labels, point.Value, point.Ts)},
+	})
+}
+
+func (r *renderer) addSketch(sketch metrics.SketchSeries) {
+	if len(sketch.Points) == 0 || sketch.Points[len(sketch.Points)-1].Sketch == nil {
+		return
+	}
+	f := r.getFamily(sanitizeMetricName(sketch.Name), typeSummary)
+	if f == nil {
+		return
+	}
+	point := sketch.Points[len(sketch.Points)-1]
+	ts := float64(point.Ts)
+	labels := renderLabels(sketch.Host, sketch.Tags, quantileLabel)
+
+	lines := make([]string, 0, len(r.percentiles)+2)
+	for _, p := range r.percentiles {
+		quantileLabels := quantileLabel + `="` + formatFloat(p) + `"`
+		if labels != "" {
+			quantileLabels = labels + "," + quantileLabels
+		}
+		lines = append(lines, sampleLine(f.name, quantileLabels, point.Sketch.Quantile(quantile.Default(), p), ts))
+	}
+	lines = append(lines,
+		sampleLine(f.name+"_sum", labels, point.Sketch.Basic.Sum, ts),
+		sampleLine(f.name+"_count", labels, float64(point.Sketch.Basic.Cnt), ts),
+	)
+	f.samples = append(f.samples, sample{labels: labels, lines: lines})
+}
+
+func sampleLine(name, labels string, value, ts float64) string {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	return name + " " + formatFloat(value) + " " + strconv.FormatFloat(ts, 'f', -1, 64)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// renderLabels converts the host and the tags of a series to labels sorted by name. The tags
+// without value are converted to labels set to "true" and the values of the tags having the
+// same key are joined with commas. The reserved label is renamed to exported_<label>.
+func renderLabels(host string, tags []string, reserved string) string {
+	values := make(map[string][]string, len(tags)+1)
+	if host != "" {
+		values["host"] = []string{host}
+	}
+	for _, tag := range tags {
+		key, value := tag, "true"
+		if i := strings.IndexByte(tag, ':'); i >= 0 {
+			key, value = tag[:i], tag[i+1:]
+		}
+		key = sanitizeLabelName(key)
+		if key == reserved {
+			key = "exported_" + key
+		}
+		values[key] = append(values[key], value)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		v := values[key]
+		sort.Strings(v)
+		b.WriteString(key)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(strings.Join(dedupSorted(v), ",")))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+func dedupSorted(values []string) []string {
+	result := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// sanitizeMetricName replaces the characters not allowed in the OpenMetrics names with underscores.
+func sanitizeMetricName(name string) string {
+	return sanitizeName(name, true)
+}
+
+// sanitizeLabelName replaces the characters not allowed in the label names with underscores,
+// the names starting with two underscores are reserved.
+func sanitizeLabelName(name string) string {
+	name = sanitizeName(name, false)
+	if strings.HasPrefix(name, "__") {
+		name = "tag" + name
+	}
+	return name
+}
+
+func sanitizeName(name string, allowColon bool) string {
+	if name == "" {
+		return "_"
+	}
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':' && allowColon:
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+)
+
+func testSnapshot() *aggregator.FlushSnapshot {
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 2, 2, 2, 2)
+
+	return &aggregator.FlushSnapshot{
+		Series: metrics.Series{
+			{
+				Name:   "system.load.1",
+				Points: []metrics.Point{{Value: 0.5, Ts: 1600000000}},
+				Tags:   []string{"env:prod", "role:db", "role:api", "role:api", "standalone"},
+				Host:   "web-1",
+				MType:  metrics.APIGaugeType,
+			},
+			{
+				Name:   "system.load.1",
+				Points: []metrics.Point{{Value: 1, Ts: 1600000000}},
+				Host:   "web-0",
+				MType:  metrics.APIGaugeType,
+			},
+			{
+				Name:   "2xx.requests",
+				Points: []metrics.Point{{Value: 3, Ts: 1599999990}, {Value: 12, Ts: 1600000000}},
+				Tags:   []string{"path:/a\"b\\c", "__name__:x", "dash-key:1"},
+				MType:  metrics.APICountType,
+				Device: "sda",
+			},
+			{
+				Name:  "no.points",
+				MType: metrics.APIGaugeType,
+			},
+		},
+		Sketches: metrics.SketchSeriesList{
+			{
+				Name:   "request.latency",
+				Tags:   []string{"quantile:x"},
+				Host:   "web-1",
+				Points: []metrics.SketchPoint{{Sketch: sketch, Ts: 1600000000}},
+			},
+			{
+				// a summary cannot have the name of a gauge
+				Name:   "system.load.1",
+				Points: []metrics.SketchPoint{{Sketch: sketch, Ts: 1600000000}},
+			},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, newRenderer([]float64{0.5, 0.99}).render(&buf, testSnapshot()))
+
+	sketch := testSnapshot().Sketches[0].Points[0].Sketch
+	p50 := formatFloat(sketch.Quantile(quantile.Default(), 0.5))
+	p99 := formatFloat(sketch.Quantile(quantile.Default(), 0.99))
+	expected := `# TYPE _2xx_requests gauge
+_2xx_requests{dash_key="1",device="sda",path="/a\"b\\c",tag__name__="x"} 12 1600000000
+# TYPE request_latency summary
+request_latency{exported_quantile="x",host="web-1",quantile="0.5"} ` + p50 + ` 1600000000
+request_latency{exported_quantile="x",host="web-1",quantile="0.99"} ` + p99 + ` 1600000000
+request_latency_sum{exported_quantile="x",host="web-1"} 8 1600000000
+request_latency_count{exported_quantile="x",host="web-1"} 4 1600000000
+# TYPE system_load_1 gauge
+system_load_1{env="prod",host="web-1",role="api,db",standalone="true"} 0.5 1600000000
+system_load_1{host="web-0"} 1 1600000000
+# EOF
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestRenderEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, newRenderer(nil).render(&buf, nil))
+	assert.Equal(t, "# EOF\n", buf.String())
+}
+
+func TestSanitizeNames(t *testing.T) {
+	assert.Equal(t, "datadog_agent_running", sanitizeMetricName("datadog.agent.running"))
+	assert.Equal(t, "ns:metric_1", sanitizeMetricName("ns:metric-1"))
+	assert.Equal(t, "_1m", sanitizeMetricName("1m"))
+	assert.Equal(t, "_", sanitizeMetricName(""))
+	assert.Equal(t, "kube_namespace", sanitizeLabelName("kube_namespace"))
+	assert.Equal(t, "a_b", sanitizeLabelName("a:b"))
+	assert.Equal(t, "tag__meta", sanitizeLabelName("__meta"))
+	assert.Equal(t, "tag__", sanitizeLabelName("éà"))
+}
+
+func TestParsePercentiles(t *testing.T) {
+	assert.Equal(t, []float64{0.5, 0.95}, parsePercentiles([]string{"0.95", "invalid", "1.5", "0.5"}))
+}
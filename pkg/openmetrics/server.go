@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package openmetrics exposes the metrics flushed by the aggregator in the OpenMetrics
+// text format, so they can be scraped by a local Prometheus server.
+package openmetrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	metricsPath = "/metrics"
+
+	contentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+	stopTimeout = 5 * time.Second
+)
+
+var serverInstance *Server
+
+// snapshotSource returns the series and the sketches of the last flush.
+type snapshotSource interface {
+	GetLastFlush() *aggregator.FlushSnapshot
+}
+
+// Server renders the last series and sketches flushed by the aggregator on the /metrics endpoint.
+type Server struct {
+	server      *http.Server
+	listener    net.Listener
+	source      snapshotSource
+	percentiles []float64
+}
+
+// IsEnabled returns whether the OpenMetrics endpoint is enabled in the configuration.
+func IsEnabled() bool {
+	return config.Datadog.GetBool("openmetrics_endpoint.enabled")
+}
+
+// StartServer starts the global OpenMetrics endpoint, rendering the flushes of agg.
+func StartServer(agg *aggregator.BufferedAggregator) error {
+	server, err := NewServer(agg)
+	if err != nil {
+		return err
+	}
+	serverInstance = server
+	return nil
+}
+
+// StopServer stops the global OpenMetrics endpoint, if it is running.
+func StopServer() {
+	if serverInstance != nil {
+		serverInstance.Stop()
+		serverInstance = nil
+	}
+}
+
+// NewServer returns a running OpenMetrics endpoint listening on the configured address.
+func NewServer(source snapshotSource) (*Server, error) {
+	addr := net.JoinHostPort(config.Datadog.GetString("openmetrics_endpoint.bind_host"), strconv.Itoa(config.Datadog.GetInt("openmetrics_endpoint.port")))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %s: %v", addr, err)
+	}
+
+	s := &Server{
+		listener:    listener,
+		source:      source,
+		percentiles: parsePercentiles(config.Datadog.GetStringSlice("openmetrics_endpoint.percentiles")),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(metricsPath, s.handleMetrics)
+	s.server = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Errorf("OpenMetrics endpoint stopped: %v", err)
+		}
+	}()
+	log.Infof("Exposing the aggregated metrics on http://%s%s", listener.Addr(), metricsPath)
+	return s, nil
+}
+
+// parsePercentiles returns the sorted percentiles, the invalid ones are skipped.
+func parsePercentiles(values []string) []float64 {
+	percentiles := make([]float64, 0, len(values))
+	for _, value := range values {
+		p, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			log.Errorf("Could not parse '%s' from 'openmetrics_endpoint.percentiles' (skipping): %s", value, err)
+			continue
+		}
+		if p < 0 || p > 1 {
+			log.Errorf("openmetrics_endpoint.percentiles must be between 0 and 1: skipping %f", p)
+			continue
+		}
+		percentiles = append(percentiles, p)
+	}
+	sort.Float64s(percentiles)
+	return percentiles
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Stop stops the server.
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Errorf("Could not stop the OpenMetrics endpoint: %v", err)
+	}
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if err := newRenderer(s.percentiles).render(w, s.source.GetLastFlush()); err != nil {
+		log.Debugf("Could not write the OpenMetrics response: %v", err)
+	}
+}
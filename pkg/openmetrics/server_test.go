@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+type staticSource struct {
+	snapshot *aggregator.FlushSnapshot
+}
+
+func (s *staticSource) GetLastFlush() *aggregator.FlushSnapshot {
+	return s.snapshot
+}
+
+func TestServer(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("openmetrics_endpoint.port", 0)
+
+	source := &staticSource{}
+	server, err := NewServer(source)
+	require.NoError(t, err)
+	defer server.Stop()
+	url := fmt.Sprintf("http://%s%s", server.Addr(), metricsPath)
+
+	// nothing was flushed yet
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, contentType, resp.Header.Get("Content-Type"))
+	assert.Equal(t, "# EOF\n", string(body))
+
+	source.snapshot = &aggregator.FlushSnapshot{
+		Series: metrics.Series{{
+			Name:   "dogstatsd.gauge",
+			Points: []metrics.Point{{Value: 21, Ts: 1600000000}},
+			Tags:   []string{"env:prod"},
+		}},
+	}
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	body, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "# TYPE dogstatsd_gauge gauge\ndogstatsd_gauge{env=\"prod\"} 21 1600000000\n# EOF\n", string(body))
+
+	resp, err = http.Post(url, "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
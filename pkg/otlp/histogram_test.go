@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build test
+
+package otlp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/otlp/model"
+)
+
+// bucketCounts returns the number of values of the sketches of each bucket, by upper bound.
+func bucketCounts(sketches metrics.SketchSeriesList) map[string]int64 {
+	counts := make(map[string]int64)
+	for _, sketch := range sketches {
+		for _, tag := range sketch.Tags {
+			if !strings.HasPrefix(tag, "upper_bound:") {
+				continue
+			}
+			for _, point := range sketch.Points {
+				counts[tag] += point.Sketch.Basic.Cnt
+			}
+		}
+	}
+	return counts
+}
+
+func cumulativeHistogram(bucketCounts ...uint64) *model.MetricsRequest {
+	return metricsRequest(model.Metric{
+		Name:                   "latency",
+		DataType:               model.MetricDataTypeHistogram,
+		AggregationTemporality: model.AggregationTemporalityCumulative,
+		HistogramDataPoints: []model.HistogramDataPoint{{
+			BucketCounts:   bucketCounts,
+			ExplicitBounds: []float64{0.1, 0.5},
+		}},
+	})
+}
+
+func TestConvertCumulativeHistogramWithCheckSampler(t *testing.T) {
+	sampler := aggregator.NewTestCheckSampler(100)
+	c := newMetricsConverter(sampler.Sender)
+
+	c.convert(cumulativeHistogram(4, 2, 1))
+	assert.Equal(t, map[string]int64{"upper_bound:0.1": 4, "upper_bound:0.5": 2, "upper_bound:inf": 1}, bucketCounts(sampler.Flush()))
+
+	// each bucket only reports its own increase
+	c.convert(cumulativeHistogram(6, 2, 3))
+	assert.Equal(t, map[string]int64{"upper_bound:0.1": 2, "upper_bound:inf": 2}, bucketCounts(sampler.Flush()))
+}
@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/otlp/model"
+)
+
+// resourceTags maps the resource attributes of the OpenTelemetry semantic conventions to the Datadog tags.
+var resourceTags = map[string]string{
+	"service.name":           "service",
+	"deployment.environment": "env",
+	"service.version":        "version",
+}
+
+// metricsConverter submits the data points of the OTLP metrics to the aggregator: the gauges
+// and the non monotonic sums are submitted as gauges, the monotonic sums as counts and the
+// histograms as distributions.
+type metricsConverter struct {
+	sender aggregator.Sender
+}
+
+func newMetricsConverter(sender aggregator.Sender) *metricsConverter {
+	return &metricsConverter{
+		sender: sender,
+	}
+}
+
+// convert submits the data points of a request.
+func (c *metricsConverter) convert(request *model.MetricsRequest) {
+	for _, rm := range request.ResourceMetrics {
+		host, tags := hostAndTags(rm.Resource)
+		for _, ilm := range rm.InstrumentationLibraryMetrics {
+			for _, m := range ilm.Metrics {
+				c.convertMetric(m, host, tags)
+			}
+		}
+	}
+	c.sender.Commit()
+}
+
+// hostAndTags returns the host and the tags of a resource.
+func hostAndTags(resource model.Resource) (string, []string) {
+	var host string
+	var tags []string
+	for _, kv := range resource.Attributes {
+		if kv.Key == "host.name" {
+			host = kv.Value.AsString()
+			continue
+		}
+		if tag, found := resourceTags[kv.Key]; found {
+			if value := kv.Value.AsString(); value != "" {
+				tags = append(tags, tag+":"+value)
+			}
+		}
+	}
+	return host, tags
+}
+
+// dataPointTags returns the tags of a resource followed by the attributes of a data point.
+func dataPointTags(resourceTags []string, attributes []model.KeyValue) []string {
+	tags := make([]string, 0, len(resourceTags)+len(attributes))
+	tags = append(tags, resourceTags...)
+	for _, kv := range attributes {
+		if value := kv.Value.AsString(); value != "" {
+			tags = append(tags, kv.Key+":"+value)
+		}
+	}
+	return tags
+}
+
+func (c *metricsConverter) convertMetric(m model.Metric, host string, resourceTags []string) {
+	switch m.DataType {
+	case model.MetricDataTypeGauge:
+		for _, dp := range m.NumberDataPoints {
+			if !math.IsNaN(dp.Value) {
+				c.sender.Gauge(m.Name, dp.Value, host, dataPointTags(resourceTags, dp.Attributes))
+			}
+		}
+	case model.MetricDataTypeSum:
+		for _, dp := range m.NumberDataPoints {
+			if math.IsNaN(dp.Value) {
+				continue
+			}
+			tags := dataPointTags(resourceTags, dp.Attributes)
+			switch {
+			case !m.IsMonotonic:
+				c.sender.Gauge(m.Name, dp.Value, host, tags)
+			case m.AggregationTemporality == model.AggregationTemporalityDelta:
+				c.sender.Count(m.Name, dp.Value, host, tags)
+			default:
+				c.sender.MonotonicCount(m.Name, dp.Value, host, tags)
+			}
+		}
+	case model.MetricDataTypeHistogram:
+		cumulative := m.AggregationTemporality != model.AggregationTemporalityDelta
+		for _, dp := range m.HistogramDataPoints {
+			tags := dataPointTags(resourceTags, dp.Attributes)
+			c.submitCount(m.Name+".count", float64(dp.Count), cumulative, host, tags)
+			c.submitCount(m.Name+".sum", dp.Sum, cumulative, host, tags)
+			c.submitHistogram(m.Name, dp, cumulative, host, tags)
+		}
+	case model.MetricDataTypeSummary:
+		for _, dp := range m.SummaryDataPoints {
+			tags := dataPointTags(resourceTags, dp.Attributes)
+			c.submitCount(m.Name+".count", float64(dp.Count), true, host, tags)
+			c.submitCount(m.Name+".sum", dp.Sum, true, host, tags)
+			for _, q := range dp.QuantileValues {
+				if math.IsNaN(q.Value) {
+					continue
+				}
+				quantileTags := append(append([]string{}, tags...), "quantile:"+strconv.FormatFloat(q.Quantile, 'g', -1, 64))
+				c.sender.Gauge(m.Name+".quantile", q.Value, host, quantileTags)
+			}
+		}
+	}
+}
+
+func (c *metricsConverter) submitCount(name string, value float64, cumulative bool, host string, tags []string) {
+	if math.IsNaN(value) {
+		return
+	}
+	if cumulative {
+		c.sender.MonotonicCount(name, value, host, tags)
+	} else {
+		c.sender.Count(name, value, host, tags)
+	}
+}
+
+// submitHistogram submits the count of each bucket of a histogram data point, tagged with its bounds
+// so that each bucket gets its own context. The counts of the cumulative histograms are submitted
+// as monotonic so that the aggregator only keeps the increase of each bucket.
+func (c *metricsConverter) submitHistogram(name string, dp model.HistogramDataPoint, cumulative bool, host string, tags []string) {
+	if len(dp.BucketCounts) != len(dp.ExplicitBounds)+1 {
+		// the data point has no bucket or is invalid
+		return
+	}
+
+	for i, count := range dp.BucketCounts {
+		if count == 0 && !cumulative {
+			continue
+		}
+		lowerBound, upperBound := math.Inf(-1), math.Inf(1)
+		if i > 0 {
+			lowerBound = dp.ExplicitBounds[i-1]
+		}
+		if i < len(dp.ExplicitBounds) {
+			upperBound = dp.ExplicitBounds[i]
+		}
+		if math.IsInf(lowerBound, -1) {
+			// the values of the first bucket are assumed to be positive when its upper bound is
+			lowerBound = math.Min(0, upperBound)
+		}
+		c.sender.HistogramBucket(name, int64(count), lowerBound, upperBound, cumulative, host, metrics.BucketBoundTags(tags, lowerBound, upperBound))
+	}
+}
@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/otlp/model"
+)
+
+func stringAttribute(key, value string) model.KeyValue {
+	return model.KeyValue{Key: key, Value: model.AnyValue{Type: model.ValueTypeString, StringValue: value}}
+}
+
+func metricsRequest(metrics ...model.Metric) *model.MetricsRequest {
+	return &model.MetricsRequest{
+		ResourceMetrics: []model.ResourceMetrics{{
+			Resource: model.Resource{Attributes: []model.KeyValue{
+				stringAttribute("host.name", "web-1"),
+				stringAttribute("service.name", "checkout"),
+				stringAttribute("deployment.environment", "prod"),
+				stringAttribute("service.version", "1.2.3"),
+				stringAttribute("telemetry.sdk.language", "go"),
+			}},
+			InstrumentationLibraryMetrics: []model.InstrumentationLibraryMetrics{{Metrics: metrics}},
+		}},
+	}
+}
+
+var resourceTagList = []string{"service:checkout", "env:prod", "version:1.2.3"}
+
+func withResourceTags(tags ...string) []string {
+	return append(append([]string{}, resourceTagList...), tags...)
+}
+
+func TestConvertGaugesAndSums(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	c := newMetricsConverter(sender)
+
+	c.convert(metricsRequest(
+		model.Metric{
+			Name:     "memory.used",
+			DataType: model.MetricDataTypeGauge,
+			NumberDataPoints: []model.NumberDataPoint{
+				{Value: 1024, Attributes: []model.KeyValue{stringAttribute("pool", "heap")}},
+				{Value: math.NaN()},
+			},
+		},
+		model.Metric{
+			Name:                   "requests",
+			DataType:               model.MetricDataTypeSum,
+			AggregationTemporality: model.AggregationTemporalityCumulative,
+			IsMonotonic:            true,
+			NumberDataPoints:       []model.NumberDataPoint{{Value: 12, Attributes: []model.KeyValue{stringAttribute("code", "200")}}},
+		},
+		model.Metric{
+			Name:                   "errors",
+			DataType:               model.MetricDataTypeSum,
+			AggregationTemporality: model.AggregationTemporalityDelta,
+			IsMonotonic:            true,
+			NumberDataPoints:       []model.NumberDataPoint{{Value: 3}},
+		},
+		model.Metric{
+			Name:                   "queue.size",
+			DataType:               model.MetricDataTypeSum,
+			AggregationTemporality: model.AggregationTemporalityCumulative,
+			NumberDataPoints:       []model.NumberDataPoint{{Value: 7}},
+		},
+	))
+
+	sender.AssertMetric(t, "Gauge", "memory.used", 1024, "web-1", withResourceTags("pool:heap"))
+	sender.AssertMetric(t, "MonotonicCount", "requests", 12, "web-1", withResourceTags("code:200"))
+	sender.AssertMetric(t, "Count", "errors", 3, "web-1", resourceTagList)
+	sender.AssertMetric(t, "Gauge", "queue.size", 7, "web-1", resourceTagList)
+	sender.AssertNumberOfCalls(t, "Gauge", 2)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestConvertDeltaHistogram(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	c := newMetricsConverter(sender)
+
+	c.convert(metricsRequest(model.Metric{
+		Name:                   "latency",
+		DataType:               model.MetricDataTypeHistogram,
+		AggregationTemporality: model.AggregationTemporalityDelta,
+		HistogramDataPoints: []model.HistogramDataPoint{{
+			Count:          6,
+			Sum:            3.5,
+			BucketCounts:   []uint64{1, 0, 5},
+			ExplicitBounds: []float64{0.1, 1},
+		}},
+	}))
+
+	sender.AssertMetric(t, "Count", "latency.count", 6, "web-1", resourceTagList)
+	sender.AssertMetric(t, "Count", "latency.sum", 3.5, "web-1", resourceTagList)
+	sender.AssertHistogramBucket(t, "HistogramBucket", "latency", 1, 0, 0.1, false, "web-1", withResourceTags("lower_bound:0", "upper_bound:0.1"))
+	sender.AssertHistogramBucket(t, "HistogramBucket", "latency", 5, 1, math.Inf(1), false, "web-1", withResourceTags("lower_bound:1", "upper_bound:inf"))
+	// the empty buckets are not submitted
+	sender.AssertNumberOfCalls(t, "HistogramBucket", 2)
+}
+
+func TestConvertCumulativeHistogram(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	c := newMetricsConverter(sender)
+
+	c.convert(metricsRequest(model.Metric{
+		Name:                   "latency",
+		DataType:               model.MetricDataTypeHistogram,
+		AggregationTemporality: model.AggregationTemporalityCumulative,
+		HistogramDataPoints: []model.HistogramDataPoint{{
+			Count:          2,
+			BucketCounts:   []uint64{2, 0},
+			ExplicitBounds: []float64{0.1},
+		}},
+	}))
+
+	// the aggregator keeps the increase of the monotonic buckets, including the empty ones
+	sender.AssertMetric(t, "MonotonicCount", "latency.count", 2, "web-1", resourceTagList)
+	sender.AssertHistogramBucket(t, "HistogramBucket", "latency", 2, 0, 0.1, true, "web-1", withResourceTags("lower_bound:0", "upper_bound:0.1"))
+	sender.AssertHistogramBucket(t, "HistogramBucket", "latency", 0, 0.1, math.Inf(1), true, "web-1", withResourceTags("lower_bound:0.1", "upper_bound:inf"))
+	sender.AssertNumberOfCalls(t, "HistogramBucket", 2)
+}
+
+func TestConvertSummary(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	c := newMetricsConverter(sender)
+
+	c.convert(metricsRequest(model.Metric{
+		Name:     "gc.pause",
+		DataType: model.MetricDataTypeSummary,
+		SummaryDataPoints: []model.SummaryDataPoint{{
+			Count:          3,
+			Sum:            0.03,
+			QuantileValues: []model.ValueAtQuantile{{Quantile: 0.5, Value: 0.01}, {Quantile: 0.99, Value: math.NaN()}},
+		}},
+	}))
+
+	sender.AssertMetric(t, "MonotonicCount", "gc.pause.count", 3, "web-1", resourceTagList)
+	sender.AssertMetric(t, "MonotonicCount", "gc.pause.sum", 0.03, "web-1", resourceTagList)
+	sender.AssertMetric(t, "Gauge", "gc.pause.quantile", 0.01, "web-1", withResourceTags("quantile:0.5"))
+	sender.AssertNumberOfCalls(t, "Gauge", 1)
+}
@@ -0,0 +1,341 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package model decodes the messages of the OpenTelemetry protocol (OTLP).
+//
+// The types mirror the messages of opentelemetry/proto/common/v1, resource/v1,
+// trace/v1, metrics/v1 and of the collector services. Only the fields used by
+// the agent are decoded, the other ones are skipped. The package has no
+// dependency on the agent so it can be used by both the core and the trace agent.
+package model
+
+import (
+	"encoding/base64"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/protowire"
+)
+
+// ValueType is the type of an attribute value.
+type ValueType int
+
+// Types of the attribute values.
+const (
+	ValueTypeEmpty ValueType = iota
+	ValueTypeString
+	ValueTypeBool
+	ValueTypeInt
+	ValueTypeDouble
+	ValueTypeArray
+	ValueTypeKvlist
+	ValueTypeBytes
+)
+
+// AnyValue is the value of an attribute.
+type AnyValue struct {
+	Type        ValueType
+	StringValue string
+	BoolValue   bool
+	IntValue    int64
+	DoubleValue float64
+	ArrayValue  []AnyValue
+	KvlistValue []KeyValue
+	BytesValue  []byte
+}
+
+// KeyValue is an attribute.
+type KeyValue struct {
+	Key   string
+	Value AnyValue
+}
+
+// Resource describes the entity producing the telemetry, e.g. a service or a host.
+type Resource struct {
+	Attributes []KeyValue
+}
+
+// InstrumentationLibrary describes the library that produced the telemetry.
+type InstrumentationLibrary struct {
+	Name    string
+	Version string
+}
+
+// AsString returns the string representation of a value, arrays and key/value
+// lists are rendered as JSON and bytes are base64 encoded.
+func (v AnyValue) AsString() string {
+	switch v.Type {
+	case ValueTypeString:
+		return v.StringValue
+	case ValueTypeBool:
+		return strconv.FormatBool(v.BoolValue)
+	case ValueTypeInt:
+		return strconv.FormatInt(v.IntValue, 10)
+	case ValueTypeDouble:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	case ValueTypeBytes:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	case ValueTypeArray, ValueTypeKvlist:
+		var b strings.Builder
+		v.writeJSON(&b)
+		return b.String()
+	}
+	return ""
+}
+
+func (v AnyValue) writeJSON(b *strings.Builder) {
+	switch v.Type {
+	case ValueTypeString, ValueTypeBytes:
+		b.WriteString(strconv.Quote(v.AsString()))
+	case ValueTypeBool, ValueTypeInt:
+		b.WriteString(v.AsString())
+	case ValueTypeDouble:
+		if math.IsNaN(v.DoubleValue) || math.IsInf(v.DoubleValue, 0) {
+			b.WriteString(strconv.Quote(v.AsString()))
+		} else {
+			b.WriteString(v.AsString())
+		}
+	case ValueTypeArray:
+		b.WriteByte('[')
+		for i, elem := range v.ArrayValue {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			elem.writeJSON(b)
+		}
+		b.WriteByte(']')
+	case ValueTypeKvlist:
+		b.WriteByte('{')
+		for i, kv := range v.KvlistValue {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Quote(kv.Key))
+			b.WriteByte(':')
+			kv.Value.writeJSON(b)
+		}
+		b.WriteByte('}')
+	default:
+		b.WriteString("null")
+	}
+}
+
+// GetAttribute returns the value of the attribute with the given key.
+func GetAttribute(attributes []KeyValue, key string) (AnyValue, bool) {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return AnyValue{}, false
+}
+
+// fieldFunc reads or skips the value of a field of a message.
+type fieldFunc func(d *protowire.Decoder, field, wireType int) error
+
+// decodeMessage calls fn for each field of a message.
+func decodeMessage(data []byte, fn fieldFunc) error {
+	d := protowire.NewDecoder(data)
+	for !d.Done() {
+		field, wireType, err := d.Key()
+		if err != nil {
+			return err
+		}
+		if err := fn(d, field, wireType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readEmbedded(d *protowire.Decoder, field, wireType int, unmarshal func([]byte) error) error {
+	if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+		return err
+	}
+	msg, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	return unmarshal(msg)
+}
+
+func readString(d *protowire.Decoder, field, wireType int, v *string) error {
+	if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+		return err
+	}
+	var err error
+	*v, err = d.String()
+	return err
+}
+
+func readBytes(d *protowire.Decoder, field, wireType int, v *[]byte) error {
+	if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+		return err
+	}
+	var err error
+	*v, err = d.Bytes()
+	return err
+}
+
+func readVarint(d *protowire.Decoder, field, wireType int) (uint64, error) {
+	if err := protowire.Expect(field, wireType, protowire.WireVarint); err != nil {
+		return 0, err
+	}
+	return d.Varint()
+}
+
+func readFixed64(d *protowire.Decoder, field, wireType int, v *uint64) error {
+	if err := protowire.Expect(field, wireType, protowire.WireFixed64); err != nil {
+		return err
+	}
+	var err error
+	*v, err = d.Fixed64()
+	return err
+}
+
+func readDouble(d *protowire.Decoder, field, wireType int, v *float64) error {
+	if err := protowire.Expect(field, wireType, protowire.WireFixed64); err != nil {
+		return err
+	}
+	var err error
+	*v, err = d.Double()
+	return err
+}
+
+// readRepeatedFixed64 reads the values of a repeated fixed64 or double field, packed or not.
+func readRepeatedFixed64(d *protowire.Decoder, field, wireType int, v *[]uint64) error {
+	if wireType == protowire.WireFixed64 {
+		value, err := d.Fixed64()
+		*v = append(*v, value)
+		return err
+	}
+	if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+		return err
+	}
+	packed, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	if len(packed)%8 != 0 {
+		return protowire.ErrTruncated
+	}
+	pd := protowire.NewDecoder(packed)
+	for !pd.Done() {
+		value, err := pd.Fixed64()
+		if err != nil {
+			return err
+		}
+		*v = append(*v, value)
+	}
+	return nil
+}
+
+func (v *AnyValue) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			v.Type = ValueTypeString
+			return readString(d, field, wireType, &v.StringValue)
+		case 2:
+			value, err := readVarint(d, field, wireType)
+			v.Type, v.BoolValue = ValueTypeBool, value != 0
+			return err
+		case 3:
+			value, err := readVarint(d, field, wireType)
+			v.Type, v.IntValue = ValueTypeInt, int64(value)
+			return err
+		case 4:
+			v.Type = ValueTypeDouble
+			return readDouble(d, field, wireType, &v.DoubleValue)
+		case 5:
+			v.Type = ValueTypeArray
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				return decodeMessage(msg, func(d *protowire.Decoder, field, wireType int) error {
+					if field != 1 {
+						return d.Skip(wireType)
+					}
+					return readEmbedded(d, field, wireType, func(msg []byte) error {
+						var elem AnyValue
+						err := elem.unmarshal(msg)
+						v.ArrayValue = append(v.ArrayValue, elem)
+						return err
+					})
+				})
+			})
+		case 6:
+			v.Type = ValueTypeKvlist
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				return decodeMessage(msg, func(d *protowire.Decoder, field, wireType int) error {
+					if field != 1 {
+						return d.Skip(wireType)
+					}
+					return readKeyValue(d, field, wireType, &v.KvlistValue)
+				})
+			})
+		case 7:
+			v.Type = ValueTypeBytes
+			return readBytes(d, field, wireType, &v.BytesValue)
+		}
+		return d.Skip(wireType)
+	})
+}
+
+// readKeyValue reads a KeyValue message and appends it to attributes.
+func readKeyValue(d *protowire.Decoder, field, wireType int, attributes *[]KeyValue) error {
+	return readEmbedded(d, field, wireType, func(msg []byte) error {
+		var kv KeyValue
+		err := decodeMessage(msg, func(d *protowire.Decoder, field, wireType int) error {
+			switch field {
+			case 1:
+				return readString(d, field, wireType, &kv.Key)
+			case 2:
+				return readEmbedded(d, field, wireType, kv.Value.unmarshal)
+			}
+			return d.Skip(wireType)
+		})
+		*attributes = append(*attributes, kv)
+		return err
+	})
+}
+
+// readStringKeyValue reads a deprecated StringKeyValue message and appends it to attributes.
+func readStringKeyValue(d *protowire.Decoder, field, wireType int, attributes *[]KeyValue) error {
+	return readEmbedded(d, field, wireType, func(msg []byte) error {
+		kv := KeyValue{Value: AnyValue{Type: ValueTypeString}}
+		err := decodeMessage(msg, func(d *protowire.Decoder, field, wireType int) error {
+			switch field {
+			case 1:
+				return readString(d, field, wireType, &kv.Key)
+			case 2:
+				return readString(d, field, wireType, &kv.Value.StringValue)
+			}
+			return d.Skip(wireType)
+		})
+		*attributes = append(*attributes, kv)
+		return err
+	})
+}
+
+func (r *Resource) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		if field == 1 {
+			return readKeyValue(d, field, wireType, &r.Attributes)
+		}
+		return d.Skip(wireType)
+	})
+}
+
+func (l *InstrumentationLibrary) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readString(d, field, wireType, &l.Name)
+		case 2:
+			return readString(d, field, wireType, &l.Version)
+		}
+		return d.Skip(wireType)
+	})
+}
@@ -0,0 +1,213 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package model
+
+import (
+	"math"
+
+	"github.com/DataDog/datadog-agent/pkg/util/protowire"
+)
+
+// Marshal encodes an ExportTraceServiceRequest.
+func (r *TracesRequest) Marshal() []byte {
+	e := &protowire.Encoder{}
+	for _, rs := range r.ResourceSpans {
+		rse := &protowire.Encoder{}
+		rse.Message(1, encodeResource(rs.Resource))
+		for _, ils := range rs.InstrumentationLibrarySpans {
+			ilse := &protowire.Encoder{}
+			ilse.Message(1, encodeLibrary(ils.InstrumentationLibrary))
+			for _, span := range ils.Spans {
+				ilse.Message(2, encodeSpan(span))
+			}
+			rse.Message(2, ilse)
+		}
+		e.Message(1, rse)
+	}
+	return e.Data()
+}
+
+// Marshal encodes an ExportMetricsServiceRequest.
+func (r *MetricsRequest) Marshal() []byte {
+	e := &protowire.Encoder{}
+	for _, rm := range r.ResourceMetrics {
+		rme := &protowire.Encoder{}
+		rme.Message(1, encodeResource(rm.Resource))
+		for _, ilm := range rm.InstrumentationLibraryMetrics {
+			ilme := &protowire.Encoder{}
+			ilme.Message(1, encodeLibrary(ilm.InstrumentationLibrary))
+			for _, m := range ilm.Metrics {
+				ilme.Message(2, encodeMetric(m))
+			}
+			rme.Message(2, ilme)
+		}
+		e.Message(1, rme)
+	}
+	return e.Data()
+}
+
+func encodeValue(v AnyValue) *protowire.Encoder {
+	e := &protowire.Encoder{}
+	switch v.Type {
+	case ValueTypeString:
+		e.String(1, v.StringValue)
+	case ValueTypeBool:
+		e.Key(2, protowire.WireVarint)
+		if v.BoolValue {
+			e.Varint(1)
+		} else {
+			e.Varint(0)
+		}
+	case ValueTypeInt:
+		e.Key(3, protowire.WireVarint)
+		e.Varint(uint64(v.IntValue))
+	case ValueTypeDouble:
+		e.Key(4, protowire.WireFixed64)
+		e.Double(v.DoubleValue)
+	case ValueTypeArray:
+		array := &protowire.Encoder{}
+		for _, elem := range v.ArrayValue {
+			array.Message(1, encodeValue(elem))
+		}
+		e.Message(5, array)
+	case ValueTypeKvlist:
+		kvlist := &protowire.Encoder{}
+		encodeAttributes(kvlist, 1, v.KvlistValue)
+		e.Message(6, kvlist)
+	case ValueTypeBytes:
+		e.Bytes(7, v.BytesValue)
+	}
+	return e
+}
+
+func encodeAttributes(e *protowire.Encoder, field int, attributes []KeyValue) {
+	for _, kv := range attributes {
+		kve := &protowire.Encoder{}
+		kve.String(1, kv.Key)
+		kve.Message(2, encodeValue(kv.Value))
+		e.Message(field, kve)
+	}
+}
+
+func encodeResource(r Resource) *protowire.Encoder {
+	e := &protowire.Encoder{}
+	encodeAttributes(e, 1, r.Attributes)
+	return e
+}
+
+func encodeLibrary(l InstrumentationLibrary) *protowire.Encoder {
+	e := &protowire.Encoder{}
+	e.String(1, l.Name)
+	e.String(2, l.Version)
+	return e
+}
+
+func encodeFixed64(e *protowire.Encoder, field int, v uint64) {
+	e.Key(field, protowire.WireFixed64)
+	e.Fixed64(v)
+}
+
+func encodeDouble(e *protowire.Encoder, field int, v float64) {
+	encodeFixed64(e, field, math.Float64bits(v))
+}
+
+func encodeSpan(s Span) *protowire.Encoder {
+	e := &protowire.Encoder{}
+	e.Bytes(1, s.TraceID)
+	e.Bytes(2, s.SpanID)
+	if len(s.ParentSpanID) > 0 {
+		e.Bytes(4, s.ParentSpanID)
+	}
+	e.String(5, s.Name)
+	e.Key(6, protowire.WireVarint)
+	e.Varint(uint64(s.Kind))
+	encodeFixed64(e, 7, s.StartTimeUnixNano)
+	encodeFixed64(e, 8, s.EndTimeUnixNano)
+	encodeAttributes(e, 9, s.Attributes)
+	for _, event := range s.Events {
+		ee := &protowire.Encoder{}
+		encodeFixed64(ee, 1, event.TimeUnixNano)
+		ee.String(2, event.Name)
+		encodeAttributes(ee, 3, event.Attributes)
+		e.Message(11, ee)
+	}
+	status := &protowire.Encoder{}
+	status.String(2, s.Status.Message)
+	status.Key(3, protowire.WireVarint)
+	status.Varint(uint64(s.Status.Code))
+	e.Message(15, status)
+	return e
+}
+
+func encodeMetric(m Metric) *protowire.Encoder {
+	data := &protowire.Encoder{}
+	for _, dp := range m.NumberDataPoints {
+		dpe := &protowire.Encoder{}
+		encodeFixed64(dpe, 2, dp.StartTimeUnixNano)
+		encodeFixed64(dpe, 3, dp.TimeUnixNano)
+		encodeDouble(dpe, 4, dp.Value)
+		encodeAttributes(dpe, 7, dp.Attributes)
+		data.Message(1, dpe)
+	}
+	for _, dp := range m.HistogramDataPoints {
+		dpe := &protowire.Encoder{}
+		encodeFixed64(dpe, 2, dp.StartTimeUnixNano)
+		encodeFixed64(dpe, 3, dp.TimeUnixNano)
+		encodeFixed64(dpe, 4, dp.Count)
+		encodeDouble(dpe, 5, dp.Sum)
+		counts := &protowire.Encoder{}
+		for _, count := range dp.BucketCounts {
+			counts.Fixed64(count)
+		}
+		dpe.Message(6, counts)
+		bounds := &protowire.Encoder{}
+		for _, bound := range dp.ExplicitBounds {
+			bounds.Double(bound)
+		}
+		dpe.Message(7, bounds)
+		encodeAttributes(dpe, 9, dp.Attributes)
+		data.Message(1, dpe)
+	}
+	for _, dp := range m.SummaryDataPoints {
+		dpe := &protowire.Encoder{}
+		encodeFixed64(dpe, 2, dp.StartTimeUnixNano)
+		encodeFixed64(dpe, 3, dp.TimeUnixNano)
+		encodeFixed64(dpe, 4, dp.Count)
+		encodeDouble(dpe, 5, dp.Sum)
+		for _, q := range dp.QuantileValues {
+			qe := &protowire.Encoder{}
+			encodeDouble(qe, 1, q.Quantile)
+			encodeDouble(qe, 2, q.Value)
+			dpe.Message(6, qe)
+		}
+		encodeAttributes(dpe, 7, dp.Attributes)
+		data.Message(1, dpe)
+	}
+	if m.DataType == MetricDataTypeSum || m.DataType == MetricDataTypeHistogram {
+		data.Key(2, protowire.WireVarint)
+		data.Varint(uint64(m.AggregationTemporality))
+	}
+	if m.DataType == MetricDataTypeSum && m.IsMonotonic {
+		data.Key(3, protowire.WireVarint)
+		data.Varint(1)
+	}
+
+	e := &protowire.Encoder{}
+	e.String(1, m.Name)
+	e.String(2, m.Description)
+	e.String(3, m.Unit)
+	switch m.DataType {
+	case MetricDataTypeGauge:
+		e.Message(5, data)
+	case MetricDataTypeSum:
+		e.Message(7, data)
+	case MetricDataTypeHistogram:
+		e.Message(9, data)
+	case MetricDataTypeSummary:
+		e.Message(11, data)
+	}
+	return e
+}
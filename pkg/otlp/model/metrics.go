@@ -0,0 +1,344 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package model
+
+import (
+	"math"
+
+	"github.com/DataDog/datadog-agent/pkg/util/protowire"
+)
+
+// MetricDataType is the type of the data points of a metric.
+type MetricDataType int
+
+// Types of the metrics, the deprecated integer types are decoded as their double equivalent.
+const (
+	MetricDataTypeNone MetricDataType = iota
+	MetricDataTypeGauge
+	MetricDataTypeSum
+	MetricDataTypeHistogram
+	MetricDataTypeSummary
+)
+
+// AggregationTemporality tells whether the value of a data point is relative to
+// the previous data point or to the start of the measurement.
+type AggregationTemporality int
+
+// Aggregation temporalities of the sums and histograms.
+const (
+	AggregationTemporalityUnspecified AggregationTemporality = 0
+	AggregationTemporalityDelta       AggregationTemporality = 1
+	AggregationTemporalityCumulative  AggregationTemporality = 2
+)
+
+// MetricsRequest is the payload of an ExportMetricsServiceRequest.
+type MetricsRequest struct {
+	ResourceMetrics []ResourceMetrics
+}
+
+// ResourceMetrics holds the metrics of a resource.
+type ResourceMetrics struct {
+	Resource                      Resource
+	InstrumentationLibraryMetrics []InstrumentationLibraryMetrics
+}
+
+// InstrumentationLibraryMetrics holds the metrics produced by an instrumentation library.
+type InstrumentationLibraryMetrics struct {
+	InstrumentationLibrary InstrumentationLibrary
+	Metrics                []Metric
+}
+
+// Metric is a named set of data points, only the data points of its type are set.
+type Metric struct {
+	Name        string
+	Description string
+	Unit        string
+	DataType    MetricDataType
+
+	// AggregationTemporality is set for the sums and the histograms
+	AggregationTemporality AggregationTemporality
+	// IsMonotonic is set for the sums
+	IsMonotonic bool
+
+	NumberDataPoints    []NumberDataPoint
+	HistogramDataPoints []HistogramDataPoint
+	SummaryDataPoints   []SummaryDataPoint
+}
+
+// NumberDataPoint is a data point of a gauge or a sum.
+type NumberDataPoint struct {
+	Attributes        []KeyValue
+	StartTimeUnixNano uint64
+	TimeUnixNano      uint64
+	Value             float64
+}
+
+// HistogramDataPoint is a data point of a histogram. The bucket i counts the values
+// in (ExplicitBounds[i-1], ExplicitBounds[i]], the last bucket has no upper bound.
+type HistogramDataPoint struct {
+	Attributes        []KeyValue
+	StartTimeUnixNano uint64
+	TimeUnixNano      uint64
+	Count             uint64
+	Sum               float64
+	BucketCounts      []uint64
+	ExplicitBounds    []float64
+}
+
+// SummaryDataPoint is a data point of a summary.
+type SummaryDataPoint struct {
+	Attributes        []KeyValue
+	StartTimeUnixNano uint64
+	TimeUnixNano      uint64
+	Count             uint64
+	Sum               float64
+	QuantileValues    []ValueAtQuantile
+}
+
+// ValueAtQuantile is the value of a quantile of a summary.
+type ValueAtQuantile struct {
+	Quantile float64
+	Value    float64
+}
+
+// Unmarshal decodes an ExportMetricsServiceRequest.
+func (r *MetricsRequest) Unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		if field != 1 {
+			return d.Skip(wireType)
+		}
+		return readEmbedded(d, field, wireType, func(msg []byte) error {
+			var rm ResourceMetrics
+			err := rm.unmarshal(msg)
+			r.ResourceMetrics = append(r.ResourceMetrics, rm)
+			return err
+		})
+	})
+}
+
+// DataPointCount returns the number of data points of the request.
+func (r *MetricsRequest) DataPointCount() int {
+	count := 0
+	for _, rm := range r.ResourceMetrics {
+		for _, ilm := range rm.InstrumentationLibraryMetrics {
+			for _, m := range ilm.Metrics {
+				count += len(m.NumberDataPoints) + len(m.HistogramDataPoints) + len(m.SummaryDataPoints)
+			}
+		}
+	}
+	return count
+}
+
+func (rm *ResourceMetrics) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readEmbedded(d, field, wireType, rm.Resource.unmarshal)
+		case 2:
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				var ilm InstrumentationLibraryMetrics
+				err := ilm.unmarshal(msg)
+				rm.InstrumentationLibraryMetrics = append(rm.InstrumentationLibraryMetrics, ilm)
+				return err
+			})
+		}
+		return d.Skip(wireType)
+	})
+}
+
+func (ilm *InstrumentationLibraryMetrics) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readEmbedded(d, field, wireType, ilm.InstrumentationLibrary.unmarshal)
+		case 2:
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				var m Metric
+				err := m.unmarshal(msg)
+				ilm.Metrics = append(ilm.Metrics, m)
+				return err
+			})
+		}
+		return d.Skip(wireType)
+	})
+}
+
+func (m *Metric) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readString(d, field, wireType, &m.Name)
+		case 2:
+			return readString(d, field, wireType, &m.Description)
+		case 3:
+			return readString(d, field, wireType, &m.Unit)
+		case 4, 5:
+			// IntGauge and Gauge
+			m.DataType = MetricDataTypeGauge
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				return m.unmarshalData(msg, field == 4)
+			})
+		case 6, 7:
+			// IntSum and Sum
+			m.DataType = MetricDataTypeSum
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				return m.unmarshalData(msg, field == 6)
+			})
+		case 8, 9:
+			// IntHistogram and Histogram
+			m.DataType = MetricDataTypeHistogram
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				return m.unmarshalData(msg, field == 8)
+			})
+		case 11:
+			m.DataType = MetricDataTypeSummary
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				return m.unmarshalData(msg, false)
+			})
+		}
+		return d.Skip(wireType)
+	})
+}
+
+// unmarshalData decodes the Gauge, Sum, Histogram or Summary message of a metric,
+// isInt is set for the deprecated integer messages.
+func (m *Metric) unmarshalData(data []byte, isInt bool) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				switch m.DataType {
+				case MetricDataTypeHistogram:
+					var dp HistogramDataPoint
+					err := dp.unmarshal(msg, isInt)
+					m.HistogramDataPoints = append(m.HistogramDataPoints, dp)
+					return err
+				case MetricDataTypeSummary:
+					var dp SummaryDataPoint
+					err := dp.unmarshal(msg)
+					m.SummaryDataPoints = append(m.SummaryDataPoints, dp)
+					return err
+				default:
+					var dp NumberDataPoint
+					err := dp.unmarshal(msg, isInt)
+					m.NumberDataPoints = append(m.NumberDataPoints, dp)
+					return err
+				}
+			})
+		case 2:
+			if m.DataType == MetricDataTypeSum || m.DataType == MetricDataTypeHistogram {
+				temporality, err := readVarint(d, field, wireType)
+				m.AggregationTemporality = AggregationTemporality(temporality)
+				return err
+			}
+		case 3:
+			if m.DataType == MetricDataTypeSum {
+				monotonic, err := readVarint(d, field, wireType)
+				m.IsMonotonic = monotonic != 0
+				return err
+			}
+		}
+		return d.Skip(wireType)
+	})
+}
+
+// readSfixed64 reads a sfixed64 field as a float.
+func readSfixed64(d *protowire.Decoder, field, wireType int, v *float64) error {
+	var value uint64
+	err := readFixed64(d, field, wireType, &value)
+	*v = float64(int64(value))
+	return err
+}
+
+func (dp *NumberDataPoint) unmarshal(data []byte, isInt bool) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readStringKeyValue(d, field, wireType, &dp.Attributes)
+		case 2:
+			return readFixed64(d, field, wireType, &dp.StartTimeUnixNano)
+		case 3:
+			return readFixed64(d, field, wireType, &dp.TimeUnixNano)
+		case 4:
+			if isInt {
+				return readSfixed64(d, field, wireType, &dp.Value)
+			}
+			return readDouble(d, field, wireType, &dp.Value)
+		case 6:
+			return readSfixed64(d, field, wireType, &dp.Value)
+		case 7:
+			return readKeyValue(d, field, wireType, &dp.Attributes)
+		}
+		return d.Skip(wireType)
+	})
+}
+
+func (dp *HistogramDataPoint) unmarshal(data []byte, isInt bool) error {
+	var bounds []uint64
+	err := decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readStringKeyValue(d, field, wireType, &dp.Attributes)
+		case 2:
+			return readFixed64(d, field, wireType, &dp.StartTimeUnixNano)
+		case 3:
+			return readFixed64(d, field, wireType, &dp.TimeUnixNano)
+		case 4:
+			return readFixed64(d, field, wireType, &dp.Count)
+		case 5:
+			if isInt {
+				return readSfixed64(d, field, wireType, &dp.Sum)
+			}
+			return readDouble(d, field, wireType, &dp.Sum)
+		case 6:
+			return readRepeatedFixed64(d, field, wireType, &dp.BucketCounts)
+		case 7:
+			return readRepeatedFixed64(d, field, wireType, &bounds)
+		case 9:
+			return readKeyValue(d, field, wireType, &dp.Attributes)
+		}
+		return d.Skip(wireType)
+	})
+	for _, bound := range bounds {
+		dp.ExplicitBounds = append(dp.ExplicitBounds, math.Float64frombits(bound))
+	}
+	return err
+}
+
+func (dp *SummaryDataPoint) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readStringKeyValue(d, field, wireType, &dp.Attributes)
+		case 2:
+			return readFixed64(d, field, wireType, &dp.StartTimeUnixNano)
+		case 3:
+			return readFixed64(d, field, wireType, &dp.TimeUnixNano)
+		case 4:
+			return readFixed64(d, field, wireType, &dp.Count)
+		case 5:
+			return readDouble(d, field, wireType, &dp.Sum)
+		case 6:
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				var q ValueAtQuantile
+				err := decodeMessage(msg, func(d *protowire.Decoder, field, wireType int) error {
+					switch field {
+					case 1:
+						return readDouble(d, field, wireType, &q.Quantile)
+					case 2:
+						return readDouble(d, field, wireType, &q.Value)
+					}
+					return d.Skip(wireType)
+				})
+				dp.QuantileValues = append(dp.QuantileValues, q)
+				return err
+			})
+		case 7:
+			return readKeyValue(d, field, wireType, &dp.Attributes)
+		}
+		return d.Skip(wireType)
+	})
+}
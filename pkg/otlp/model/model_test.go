@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package model
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/protowire"
+)
+
+func stringValue(v string) AnyValue {
+	return AnyValue{Type: ValueTypeString, StringValue: v}
+}
+
+func TestTracesRequestRoundTrip(t *testing.T) {
+	expected := &TracesRequest{
+		ResourceSpans: []ResourceSpans{{
+			Resource: Resource{Attributes: []KeyValue{
+				{Key: "service.name", Value: stringValue("checkout")},
+				{Key: "process.pid", Value: AnyValue{Type: ValueTypeInt, IntValue: -42}},
+			}},
+			InstrumentationLibrarySpans: []InstrumentationLibrarySpans{{
+				InstrumentationLibrary: InstrumentationLibrary{Name: "otelhttp", Version: "0.16.0"},
+				Spans: []Span{{
+					TraceID:           []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+					SpanID:            []byte{1, 2, 3, 4, 5, 6, 7, 8},
+					ParentSpanID:      []byte{8, 7, 6, 5, 4, 3, 2, 1},
+					Name:              "GET /cart",
+					Kind:              SpanKindServer,
+					StartTimeUnixNano: 1612345678000000000,
+					EndTimeUnixNano:   1612345678500000000,
+					Attributes: []KeyValue{
+						{Key: "http.status_code", Value: AnyValue{Type: ValueTypeInt, IntValue: 500}},
+						{Key: "sampled", Value: AnyValue{Type: ValueTypeBool, BoolValue: true}},
+						{Key: "ratio", Value: AnyValue{Type: ValueTypeDouble, DoubleValue: 0.25}},
+						{Key: "ids", Value: AnyValue{Type: ValueTypeArray, ArrayValue: []AnyValue{stringValue("a"), stringValue("b")}}},
+						{Key: "nested", Value: AnyValue{Type: ValueTypeKvlist, KvlistValue: []KeyValue{{Key: "k", Value: stringValue("v")}}}},
+						{Key: "raw", Value: AnyValue{Type: ValueTypeBytes, BytesValue: []byte("raw")}},
+					},
+					Events: []Event{{
+						TimeUnixNano: 1612345678100000000,
+						Name:         "exception",
+						Attributes:   []KeyValue{{Key: "exception.message", Value: stringValue("boom")}},
+					}},
+					Status: Status{Code: StatusCodeError, Message: "internal error"},
+				}},
+			}},
+		}},
+	}
+
+	request := &TracesRequest{}
+	require.NoError(t, request.Unmarshal(expected.Marshal()))
+	assert.Equal(t, expected, request)
+	assert.Equal(t, 1, request.SpanCount())
+}
+
+func TestMetricsRequestRoundTrip(t *testing.T) {
+	expected := &MetricsRequest{
+		ResourceMetrics: []ResourceMetrics{{
+			Resource: Resource{Attributes: []KeyValue{{Key: "host.name", Value: stringValue("web-1")}}},
+			InstrumentationLibraryMetrics: []InstrumentationLibraryMetrics{{
+				InstrumentationLibrary: InstrumentationLibrary{Name: "runtime"},
+				Metrics: []Metric{
+					{
+						Name:             "memory.used",
+						Unit:             "By",
+						DataType:         MetricDataTypeGauge,
+						NumberDataPoints: []NumberDataPoint{{TimeUnixNano: 10, Value: 1024}},
+					},
+					{
+						Name:                   "requests",
+						DataType:               MetricDataTypeSum,
+						AggregationTemporality: AggregationTemporalityCumulative,
+						IsMonotonic:            true,
+						NumberDataPoints: []NumberDataPoint{{
+							Attributes:        []KeyValue{{Key: "code", Value: stringValue("200")}},
+							StartTimeUnixNano: 5,
+							TimeUnixNano:      10,
+							Value:             12,
+						}},
+					},
+					{
+						Name:                   "latency",
+						DataType:               MetricDataTypeHistogram,
+						AggregationTemporality: AggregationTemporalityDelta,
+						HistogramDataPoints: []HistogramDataPoint{{
+							TimeUnixNano:   10,
+							Count:          6,
+							Sum:            3.5,
+							BucketCounts:   []uint64{1, 2, 3},
+							ExplicitBounds: []float64{0.1, 1},
+						}},
+					},
+					{
+						Name:     "gc.pause",
+						DataType: MetricDataTypeSummary,
+						SummaryDataPoints: []SummaryDataPoint{{
+							TimeUnixNano:   10,
+							Count:          3,
+							Sum:            0.03,
+							QuantileValues: []ValueAtQuantile{{Quantile: 0.5, Value: 0.01}, {Quantile: 0.99, Value: 0.015}},
+						}},
+					},
+				},
+			}},
+		}},
+	}
+
+	request := &MetricsRequest{}
+	require.NoError(t, request.Unmarshal(expected.Marshal()))
+	assert.Equal(t, expected, request)
+	assert.Equal(t, 4, request.DataPointCount())
+}
+
+func TestUnmarshalDeprecatedIntMetrics(t *testing.T) {
+	label := &protowire.Encoder{}
+	label.String(1, "code")
+	label.String(2, "200")
+
+	dp := &protowire.Encoder{}
+	dp.Message(1, label)
+	dp.Key(4, protowire.WireFixed64)
+	minusThree := int64(-3)
+	dp.Fixed64(uint64(minusThree))
+	intSum := &protowire.Encoder{}
+	intSum.Message(1, dp)
+	intSum.Key(2, protowire.WireVarint)
+	intSum.Varint(uint64(AggregationTemporalityDelta))
+	intSum.Key(3, protowire.WireVarint)
+	intSum.Varint(1)
+
+	hdp := &protowire.Encoder{}
+	hdp.Key(4, protowire.WireFixed64)
+	hdp.Fixed64(2)
+	hdp.Key(5, protowire.WireFixed64)
+	hdp.Fixed64(7)
+	// unpacked repeated fields
+	for _, count := range []uint64{1, 1} {
+		hdp.Key(6, protowire.WireFixed64)
+		hdp.Fixed64(count)
+	}
+	hdp.Key(7, protowire.WireFixed64)
+	hdp.Double(5)
+	intHistogram := &protowire.Encoder{}
+	intHistogram.Message(1, hdp)
+
+	sum := &protowire.Encoder{}
+	sum.String(1, "errors")
+	sum.Message(6, intSum)
+	histogram := &protowire.Encoder{}
+	histogram.String(1, "size")
+	histogram.Message(8, intHistogram)
+	ilm := &protowire.Encoder{}
+	ilm.Message(2, sum)
+	ilm.Message(2, histogram)
+	rm := &protowire.Encoder{}
+	rm.Message(2, ilm)
+	e := &protowire.Encoder{}
+	e.Message(1, rm)
+
+	request := &MetricsRequest{}
+	require.NoError(t, request.Unmarshal(e.Data()))
+	metrics := request.ResourceMetrics[0].InstrumentationLibraryMetrics[0].Metrics
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, Metric{
+		Name:                   "errors",
+		DataType:               MetricDataTypeSum,
+		AggregationTemporality: AggregationTemporalityDelta,
+		IsMonotonic:            true,
+		NumberDataPoints:       []NumberDataPoint{{Attributes: []KeyValue{{Key: "code", Value: stringValue("200")}}, Value: -3}},
+	}, metrics[0])
+	assert.Equal(t, Metric{
+		Name:                "size",
+		DataType:            MetricDataTypeHistogram,
+		HistogramDataPoints: []HistogramDataPoint{{Count: 2, Sum: 7, BucketCounts: []uint64{1, 1}, ExplicitBounds: []float64{5}}},
+	}, metrics[1])
+}
+
+func TestUnmarshalDeprecatedStatusCode(t *testing.T) {
+	status := &protowire.Encoder{}
+	status.Key(1, protowire.WireVarint)
+	status.Varint(2)
+	span := &protowire.Encoder{}
+	span.Message(15, status)
+
+	var s Span
+	require.NoError(t, s.unmarshal(span.Data()))
+	assert.Equal(t, StatusCodeError, s.Status.Code)
+}
+
+func TestUnmarshalInvalidRequest(t *testing.T) {
+	data := (&TracesRequest{ResourceSpans: []ResourceSpans{{}}}).Marshal()
+	assert.Error(t, (&TracesRequest{}).Unmarshal(data[:len(data)-1]))
+
+	e := &protowire.Encoder{}
+	e.Key(1, protowire.WireVarint)
+	e.Varint(1)
+	assert.Error(t, (&MetricsRequest{}).Unmarshal(e.Data()))
+}
+
+func TestAnyValueAsString(t *testing.T) {
+	for _, tt := range []struct {
+		value    AnyValue
+		expected string
+	}{
+		{AnyValue{}, ""},
+		{stringValue("value"), "value"},
+		{AnyValue{Type: ValueTypeBool, BoolValue: true}, "true"},
+		{AnyValue{Type: ValueTypeInt, IntValue: -12}, "-12"},
+		{AnyValue{Type: ValueTypeDouble, DoubleValue: 1.5}, "1.5"},
+		{AnyValue{Type: ValueTypeBytes, BytesValue: []byte("raw")}, "cmF3"},
+		{
+			AnyValue{Type: ValueTypeArray, ArrayValue: []AnyValue{stringValue("a"), {Type: ValueTypeInt, IntValue: 1}, {Type: ValueTypeDouble, DoubleValue: math.NaN()}}},
+			`["a",1,"NaN"]`,
+		},
+		{
+			AnyValue{Type: ValueTypeKvlist, KvlistValue: []KeyValue{{Key: "k", Value: stringValue("v")}, {Key: "empty"}}},
+			`{"k":"v","empty":null}`,
+		},
+	} {
+		assert.Equal(t, tt.expected, tt.value.AsString())
+	}
+
+	value, found := GetAttribute([]KeyValue{{Key: "k", Value: stringValue("v")}}, "k")
+	assert.True(t, found)
+	assert.Equal(t, "v", value.AsString())
+	_, found = GetAttribute(nil, "k")
+	assert.False(t, found)
+}
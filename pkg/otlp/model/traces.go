@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package model
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/protowire"
+)
+
+// SpanKind is the role of a span in a trace.
+type SpanKind int
+
+// Kinds of the spans.
+const (
+	SpanKindUnspecified SpanKind = 0
+	SpanKindInternal    SpanKind = 1
+	SpanKindServer      SpanKind = 2
+	SpanKindClient      SpanKind = 3
+	SpanKindProducer    SpanKind = 4
+	SpanKindConsumer    SpanKind = 5
+)
+
+// String returns the lower case name of the kind.
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindInternal:
+		return "internal"
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	case SpanKindProducer:
+		return "producer"
+	case SpanKindConsumer:
+		return "consumer"
+	}
+	return "unspecified"
+}
+
+// StatusCode is the status of a span.
+type StatusCode int
+
+// Status codes of the spans.
+const (
+	StatusCodeUnset StatusCode = 0
+	StatusCodeOk    StatusCode = 1
+	StatusCodeError StatusCode = 2
+)
+
+// TracesRequest is the payload of an ExportTraceServiceRequest.
+type TracesRequest struct {
+	ResourceSpans []ResourceSpans
+}
+
+// ResourceSpans holds the spans of a resource.
+type ResourceSpans struct {
+	Resource                    Resource
+	InstrumentationLibrarySpans []InstrumentationLibrarySpans
+}
+
+// InstrumentationLibrarySpans holds the spans produced by an instrumentation library.
+type InstrumentationLibrarySpans struct {
+	InstrumentationLibrary InstrumentationLibrary
+	Spans                  []Span
+}
+
+// Span is a single operation of a trace.
+type Span struct {
+	// TraceID is 16 bytes long, SpanID and ParentSpanID are 8 bytes long
+	TraceID           []byte
+	SpanID            []byte
+	ParentSpanID      []byte
+	Name              string
+	Kind              SpanKind
+	StartTimeUnixNano uint64
+	EndTimeUnixNano   uint64
+	Attributes        []KeyValue
+	Events            []Event
+	Status            Status
+}
+
+// Event is a timestamped annotation of a span.
+type Event struct {
+	TimeUnixNano uint64
+	Name         string
+	Attributes   []KeyValue
+}
+
+// Status is the result of the operation of a span.
+type Status struct {
+	Code    StatusCode
+	Message string
+}
+
+// Unmarshal decodes an ExportTraceServiceRequest.
+func (r *TracesRequest) Unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		if field != 1 {
+			return d.Skip(wireType)
+		}
+		return readEmbedded(d, field, wireType, func(msg []byte) error {
+			var rs ResourceSpans
+			err := rs.unmarshal(msg)
+			r.ResourceSpans = append(r.ResourceSpans, rs)
+			return err
+		})
+	})
+}
+
+// SpanCount returns the number of spans of the request.
+func (r *TracesRequest) SpanCount() int {
+	count := 0
+	for _, rs := range r.ResourceSpans {
+		for _, ils := range rs.InstrumentationLibrarySpans {
+			count += len(ils.Spans)
+		}
+	}
+	return count
+}
+
+func (rs *ResourceSpans) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readEmbedded(d, field, wireType, rs.Resource.unmarshal)
+		case 2:
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				var ils InstrumentationLibrarySpans
+				err := ils.unmarshal(msg)
+				rs.InstrumentationLibrarySpans = append(rs.InstrumentationLibrarySpans, ils)
+				return err
+			})
+		}
+		return d.Skip(wireType)
+	})
+}
+
+func (ils *InstrumentationLibrarySpans) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readEmbedded(d, field, wireType, ils.InstrumentationLibrary.unmarshal)
+		case 2:
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				var span Span
+				err := span.unmarshal(msg)
+				ils.Spans = append(ils.Spans, span)
+				return err
+			})
+		}
+		return d.Skip(wireType)
+	})
+}
+
+func (s *Span) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readBytes(d, field, wireType, &s.TraceID)
+		case 2:
+			return readBytes(d, field, wireType, &s.SpanID)
+		case 4:
+			return readBytes(d, field, wireType, &s.ParentSpanID)
+		case 5:
+			return readString(d, field, wireType, &s.Name)
+		case 6:
+			kind, err := readVarint(d, field, wireType)
+			s.Kind = SpanKind(kind)
+			return err
+		case 7:
+			return readFixed64(d, field, wireType, &s.StartTimeUnixNano)
+		case 8:
+			return readFixed64(d, field, wireType, &s.EndTimeUnixNano)
+		case 9:
+			return readKeyValue(d, field, wireType, &s.Attributes)
+		case 11:
+			return readEmbedded(d, field, wireType, func(msg []byte) error {
+				var event Event
+				err := event.unmarshal(msg)
+				s.Events = append(s.Events, event)
+				return err
+			})
+		case 15:
+			return readEmbedded(d, field, wireType, s.Status.unmarshal)
+		}
+		return d.Skip(wireType)
+	})
+}
+
+func (e *Event) unmarshal(data []byte) error {
+	return decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			return readFixed64(d, field, wireType, &e.TimeUnixNano)
+		case 2:
+			return readString(d, field, wireType, &e.Name)
+		case 3:
+			return readKeyValue(d, field, wireType, &e.Attributes)
+		}
+		return d.Skip(wireType)
+	})
+}
+
+func (s *Status) unmarshal(data []byte) error {
+	var deprecatedCode uint64
+	err := decodeMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			var err error
+			deprecatedCode, err = readVarint(d, field, wireType)
+			return err
+		case 2:
+			return readString(d, field, wireType, &s.Message)
+		case 3:
+			code, err := readVarint(d, field, wireType)
+			s.Code = StatusCode(code)
+			return err
+		}
+		return d.Skip(wireType)
+	})
+	// the senders implementing older versions of the protocol only set the
+	// deprecated code, where any value but 0 (ok) is an error
+	if s.Code == StatusCodeUnset && deprecatedCode != 0 {
+		s.Code = StatusCodeError
+	}
+	return err
+}
@@ -0,0 +1,336 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/otlp/model"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// senderID identifies the sender of the OTLP metrics in the aggregator
+	senderID check.ID = "otlp"
+
+	tracesPath  = "/v1/traces"
+	metricsPath = "/v1/metrics"
+
+	// traceAgentPath is the endpoint of the trace-agent receiving the OTLP traces
+	traceAgentPath = "/v0.1/otlp/traces"
+	// defaultTraceAgentPort is the port of the trace-agent when apm_config.receiver_port is not set
+	defaultTraceAgentPort = 8126
+
+	protobufContentType = "application/x-protobuf"
+
+	// maxRequestSize is the maximum size of a request, compressed or not
+	maxRequestSize = 32 * 1024 * 1024
+
+	stopTimeout = 5 * time.Second
+)
+
+var (
+	otlpExpvars = expvar.NewMap("otlp")
+
+	receiverInstance *Receiver
+)
+
+// invalidRequestError is returned when a request cannot be decoded.
+type invalidRequestError struct {
+	err error
+}
+
+func (e *invalidRequestError) Error() string {
+	return e.err.Error()
+}
+
+// Receiver receives the metrics and the traces sent with the OpenTelemetry protocol (OTLP) over
+// gRPC and HTTP. The metrics are submitted to the aggregator and the traces are forwarded to the
+// trace-agent, which converts them to Datadog spans.
+type Receiver struct {
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
+	httpServer   *http.Server
+	httpListener net.Listener
+
+	// traceAgentURL is empty when the traces are not accepted
+	traceAgentURL string
+	client        *http.Client
+
+	// the metric requests are converted one at a time since the converter keeps the count of the
+	// histogram buckets, it is nil when the metrics are not accepted
+	mu      sync.Mutex
+	metrics *metricsConverter
+}
+
+// IsEnabled returns whether the OTLP receiver is enabled in the configuration.
+func IsEnabled() bool {
+	return config.Datadog.GetBool("otlp_config.enabled")
+}
+
+// StartReceiver starts the global OTLP receiver.
+func StartReceiver() error {
+	receiver, err := NewReceiver()
+	if err != nil {
+		return err
+	}
+	receiverInstance = receiver
+	return nil
+}
+
+// StopReceiver stops the global OTLP receiver, if it is running.
+func StopReceiver() {
+	if receiverInstance != nil {
+		receiverInstance.Stop()
+		receiverInstance = nil
+	}
+}
+
+// NewReceiver returns a running OTLP receiver listening on the configured addresses. A port
+// set to 0 in the configuration disables the corresponding protocol.
+func NewReceiver() (*Receiver, error) {
+	r := &Receiver{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if config.Datadog.GetBool("otlp_config.metrics_enabled") {
+		sender, err := aggregator.GetSender(senderID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get a sender: %v", err)
+		}
+		r.metrics = newMetricsConverter(sender)
+	}
+	if config.Datadog.GetBool("otlp_config.traces_enabled") {
+		port := defaultTraceAgentPort
+		if config.Datadog.IsSet("apm_config.receiver_port") {
+			port = config.Datadog.GetInt("apm_config.receiver_port")
+		}
+		r.traceAgentURL = fmt.Sprintf("http://%s%s", net.JoinHostPort("localhost", strconv.Itoa(port)), traceAgentPath)
+	}
+
+	bindHost := config.Datadog.GetString("otlp_config.bind_host")
+	if port := config.Datadog.GetInt("otlp_config.grpc_port"); port > 0 {
+		addr := net.JoinHostPort(bindHost, strconv.Itoa(port))
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			r.Stop()
+			return nil, fmt.Errorf("could not listen on %s: %v", addr, err)
+		}
+		r.grpcListener = listener
+		r.grpcServer = grpc.NewServer(grpc.CustomCodec(rawCodec{}), grpc.MaxRecvMsgSize(maxRequestSize))
+		r.grpcServer.RegisterService(&traceServiceDesc, r)
+		r.grpcServer.RegisterService(&metricsServiceDesc, r)
+		go func() {
+			if err := r.grpcServer.Serve(listener); err != nil {
+				log.Errorf("OTLP gRPC receiver stopped: %v", err)
+			}
+		}()
+		log.Infof("Listening for OTLP gRPC requests on %s", listener.Addr())
+	}
+	if port := config.Datadog.GetInt("otlp_config.http_port"); port > 0 {
+		addr := net.JoinHostPort(bindHost, strconv.Itoa(port))
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			r.Stop()
+			return nil, fmt.Errorf("could not listen on %s: %v", addr, err)
+		}
+		r.httpListener = listener
+		mux := http.NewServeMux()
+		mux.HandleFunc(tracesPath, r.httpHandler((*Receiver).consumeTraces))
+		mux.HandleFunc(metricsPath, r.httpHandler((*Receiver).consumeMetrics))
+		r.httpServer = &http.Server{
+			Handler:      mux,
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 30 * time.Second,
+		}
+		go func() {
+			if err := r.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				log.Errorf("OTLP HTTP receiver stopped: %v", err)
+			}
+		}()
+		log.Infof("Listening for OTLP HTTP requests on %s", listener.Addr())
+	}
+	return r, nil
+}
+
+// GRPCAddr returns the address the gRPC server listens on, nil if it is disabled.
+func (r *Receiver) GRPCAddr() net.Addr {
+	if r.grpcListener == nil {
+		return nil
+	}
+	return r.grpcListener.Addr()
+}
+
+// HTTPAddr returns the address the HTTP server listens on, nil if it is disabled.
+func (r *Receiver) HTTPAddr() net.Addr {
+	if r.httpListener == nil {
+		return nil
+	}
+	return r.httpListener.Addr()
+}
+
+// Stop stops the servers and releases the sender.
+func (r *Receiver) Stop() {
+	if r.grpcServer != nil {
+		r.grpcServer.Stop()
+	} else if r.grpcListener != nil {
+		r.grpcListener.Close()
+	}
+	if r.httpServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		if err := r.httpServer.Shutdown(ctx); err != nil {
+			log.Errorf("Could not stop the OTLP HTTP receiver: %v", err)
+		}
+	} else if r.httpListener != nil {
+		r.httpListener.Close()
+	}
+	if r.metrics != nil {
+		aggregator.DestroySender(senderID)
+	}
+}
+
+// consumeMetrics decodes a metrics request and submits its data points.
+func (r *Receiver) consumeMetrics(data []byte) error {
+	if r.metrics == nil {
+		return &invalidRequestError{errors.New("the OTLP metrics are disabled")}
+	}
+	otlpExpvars.Add("MetricsRequests", 1)
+
+	request := &model.MetricsRequest{}
+	if err := request.Unmarshal(data); err != nil {
+		otlpExpvars.Add("MetricsRequestErrors", 1)
+		return &invalidRequestError{fmt.Errorf("invalid metrics request: %v", err)}
+	}
+
+	r.mu.Lock()
+	r.metrics.convert(request)
+	r.mu.Unlock()
+
+	otlpExpvars.Add("DataPoints", int64(request.DataPointCount()))
+	return nil
+}
+
+// consumeTraces checks a traces request and forwards it to the trace-agent.
+func (r *Receiver) consumeTraces(data []byte) error {
+	if r.traceAgentURL == "" {
+		return &invalidRequestError{errors.New("the OTLP traces are disabled")}
+	}
+	otlpExpvars.Add("TracesRequests", 1)
+
+	request := &model.TracesRequest{}
+	if err := request.Unmarshal(data); err != nil {
+		otlpExpvars.Add("TracesRequestErrors", 1)
+		return &invalidRequestError{fmt.Errorf("invalid traces request: %v", err)}
+	}
+	spans := request.SpanCount()
+	if spans == 0 {
+		return nil
+	}
+
+	if err := r.forwardTraces(data); err != nil {
+		otlpExpvars.Add("TracesForwardErrors", 1)
+		log.Debugf("Could not forward the OTLP traces to the trace-agent: %v", err)
+		return fmt.Errorf("could not forward the traces to the trace-agent: %v", err)
+	}
+	otlpExpvars.Add("Spans", int64(spans))
+	return nil
+}
+
+func (r *Receiver) forwardTraces(data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, r.traceAgentURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", protobufContentType)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body) //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// httpHandler returns the handler of an OTLP/HTTP endpoint, only the protobuf encoding is supported.
+func (r *Receiver) httpHandler(consume func(*Receiver, []byte) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != protobufContentType {
+			http.Error(w, fmt.Sprintf("unsupported content type, only %s is supported", protobufContentType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		data, err := readBody(w, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := consume(r, data); err != nil {
+			var invalid *invalidRequestError
+			if errors.As(err, &invalid) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			}
+			return
+		}
+
+		// the response is an empty Export*ServiceResponse message
+		w.Header().Set("Content-Type", protobufContentType)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// readBody reads the body of a request, decompressing it if needed.
+func readBody(w http.ResponseWriter, req *http.Request) ([]byte, error) {
+	var body io.Reader = http.MaxBytesReader(w, req.Body, maxRequestSize)
+	switch req.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip payload: %v", err)
+		}
+		defer gz.Close()
+		body = io.LimitReader(gz, maxRequestSize+1)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", req.Header.Get("Content-Encoding"))
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the request: %v", err)
+	}
+	if len(data) > maxRequestSize {
+		return nil, fmt.Errorf("the request exceeds %d bytes once decompressed", maxRequestSize)
+	}
+	return data, nil
+}
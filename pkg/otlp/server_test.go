@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/otlp/model"
+)
+
+func gaugeRequest() *model.MetricsRequest {
+	return metricsRequest(model.Metric{
+		Name:             "memory.used",
+		DataType:         model.MetricDataTypeGauge,
+		NumberDataPoints: []model.NumberDataPoint{{Value: 1024}},
+	})
+}
+
+func tracesRequest() *model.TracesRequest {
+	return &model.TracesRequest{
+		ResourceSpans: []model.ResourceSpans{{
+			InstrumentationLibrarySpans: []model.InstrumentationLibrarySpans{{
+				Spans: []model.Span{{
+					TraceID: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+					SpanID:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+					Name:    "span",
+				}},
+			}},
+		}},
+	}
+}
+
+// newTestReceiver starts a receiver listening on random ports, forwarding the traces to traceAgent.
+func newTestReceiver(t *testing.T, traceAgent *httptest.Server) *Receiver {
+	mockConfig := config.Mock()
+	// the ports are picked by the tests since 0 disables the servers
+	mockConfig.Set("otlp_config.grpc_port", freePort(t))
+	mockConfig.Set("otlp_config.http_port", freePort(t))
+	if traceAgent != nil {
+		_, port, err := net.SplitHostPort(traceAgent.Listener.Addr().String())
+		require.NoError(t, err)
+		mockConfig.Set("apm_config.receiver_port", port)
+	}
+	r, err := NewReceiver()
+	require.NoError(t, err)
+	return r
+}
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestReceiverDisabledServers(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("otlp_config.grpc_port", 0)
+	mockConfig.Set("otlp_config.http_port", 0)
+	r, err := NewReceiver()
+	require.NoError(t, err)
+	defer r.Stop()
+	assert.Nil(t, r.GRPCAddr())
+	assert.Nil(t, r.HTTPAddr())
+}
+
+func TestReceiverHTTPMetrics(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	r := newTestReceiver(t, nil)
+	defer r.Stop()
+	url := fmt.Sprintf("http://%s%s", r.HTTPAddr(), metricsPath)
+
+	resp, err := http.Post(url, protobufContentType, bytes.NewReader(gaugeRequest().Marshal()))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, protobufContentType, resp.Header.Get("Content-Type"))
+	sender.AssertMetric(t, "Gauge", "memory.used", 1024, "web-1", resourceTagList)
+
+	// gzip compressed payload
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(gaugeRequest().Marshal())
+	gz.Close()
+	req, err := http.NewRequest(http.MethodPost, url, &compressed)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", protobufContentType)
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	sender.AssertNumberOfCalls(t, "Gauge", 2)
+
+	resp, err = http.Post(url, "application/json", bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	resp, err = http.Post(url, protobufContentType, bytes.NewReader([]byte{0x0a, 0x05}))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestReceiverForwardsTraces(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	forwarded := make(chan []byte, 2)
+	traceAgent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, traceAgentPath, req.URL.Path)
+		assert.Equal(t, protobufContentType, req.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(req.Body)
+		forwarded <- body
+	}))
+	defer traceAgent.Close()
+	r := newTestReceiver(t, traceAgent)
+	defer r.Stop()
+
+	payload := tracesRequest().Marshal()
+	resp, err := http.Post(fmt.Sprintf("http://%s%s", r.HTTPAddr(), tracesPath), protobufContentType, bytes.NewReader(payload))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, payload, <-forwarded)
+
+	// the trace-agent is not reachable
+	traceAgent.Close()
+	resp, err = http.Post(fmt.Sprintf("http://%s%s", r.HTTPAddr(), tracesPath), protobufContentType, bytes.NewReader(payload))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestReceiverGRPC(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	forwarded := make(chan []byte, 1)
+	traceAgent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		forwarded <- body
+	}))
+	defer traceAgent.Close()
+	r := newTestReceiver(t, traceAgent)
+	defer r.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, r.GRPCAddr().String(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithDefaultCallOptions(grpc.CallCustomCodec(rawCodec{})))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	request := gaugeRequest().Marshal()
+	var response []byte
+	require.NoError(t, conn.Invoke(ctx, "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export", &request, &response))
+	assert.Empty(t, response)
+	sender.AssertMetric(t, "Gauge", "memory.used", 1024, "web-1", resourceTagList)
+
+	request = tracesRequest().Marshal()
+	require.NoError(t, conn.Invoke(ctx, "/opentelemetry.proto.collector.trace.v1.TraceService/Export", &request, &response))
+	assert.Equal(t, request, <-forwarded)
+
+	request = []byte{0x0a, 0x05}
+	err = conn.Invoke(ctx, "/opentelemetry.proto.collector.trace.v1.TraceService/Export", &request, &response)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestReceiverDisabledSignals(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("otlp_config.metrics_enabled", false)
+	mockConfig.Set("otlp_config.traces_enabled", false)
+	mockConfig.Set("otlp_config.grpc_port", 0)
+	mockConfig.Set("otlp_config.http_port", freePort(t))
+	r, err := NewReceiver()
+	require.NoError(t, err)
+	defer r.Stop()
+
+	for _, path := range []string{metricsPath, tracesPath} {
+		resp, err := http.Post(fmt.Sprintf("http://%s%s", r.HTTPAddr(), path), protobufContentType, bytes.NewReader(nil))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+}
@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	metricNameLabel = "__name__"
+	bucketLabel     = "le"
+)
+
+// metricKind is how a series is submitted to the aggregator.
+type metricKind int
+
+const (
+	kindGauge metricKind = iota
+	kindCounter
+	kindBucket
+)
+
+// podTagsFunc returns the tags of a pod, identified by its namespace and name.
+type podTagsFunc func(namespace, name string) []string
+
+// converter submits the samples of the remote-write requests to the aggregator: the counters
+// are submitted as monotonic counts, the histograms as distributions and the other series as gauges.
+type converter struct {
+	sender    aggregator.Sender
+	namespace string
+
+	podNameLabel      string
+	podNamespaceLabel string
+	// podTags is nil when the series are not enriched with the tags of their pod
+	podTags podTagsFunc
+
+	// metadata holds the type of the metric families, Prometheus sends them periodically
+	metadata map[string]MetricType
+}
+
+// histogramSeries gathers the buckets of a histogram received in a request.
+type histogramSeries struct {
+	name    string
+	tags    []string
+	buckets []histogramBucket
+}
+
+type histogramBucket struct {
+	upperBound float64
+	// count is cumulative, as sent by Prometheus
+	count float64
+}
+
+func newConverter(sender aggregator.Sender, namespace, podNameLabel, podNamespaceLabel string, podTags podTagsFunc) *converter {
+	return &converter{
+		sender:            sender,
+		namespace:         namespace,
+		podNameLabel:      podNameLabel,
+		podNamespaceLabel: podNamespaceLabel,
+		podTags:           podTags,
+		metadata:          make(map[string]MetricType),
+	}
+}
+
+// convert submits the samples of a request and returns the number of series submitted.
+func (c *converter) convert(request *WriteRequest) int {
+	for _, metadata := range request.Metadata {
+		if metadata.MetricFamilyName != "" {
+			c.metadata[metadata.MetricFamilyName] = metadata.Type
+		}
+	}
+
+	submitted := 0
+	histograms := make(map[string]*histogramSeries)
+	podTags := make(map[string][]string)
+	for _, series := range request.Timeseries {
+		if len(series.Samples) == 0 {
+			continue
+		}
+		// several samples are sent when Prometheus lags behind, only the latest one is kept
+		sample := series.Samples[0]
+		for _, s := range series.Samples[1:] {
+			if s.Timestamp > sample.Timestamp {
+				sample = s
+			}
+		}
+		// NaN values are staleness markers
+		if math.IsNaN(sample.Value) {
+			continue
+		}
+
+		name, tags, upperBound := c.parseLabels(series.Labels, podTags)
+		if name == "" {
+			remoteWriteExpvars.Add("InvalidSeries", 1)
+			continue
+		}
+
+		switch c.kind(name, upperBound != "") {
+		case kindCounter:
+			c.sender.MonotonicCount(c.metricName(name), sample.Value, "", tags)
+		case kindBucket:
+			bound, err := strconv.ParseFloat(upperBound, 64)
+			if err != nil {
+				remoteWriteExpvars.Add("InvalidSeries", 1)
+				continue
+			}
+			sort.Strings(tags)
+			family := strings.TrimSuffix(name, "_bucket")
+			key := family + "|" + strings.Join(tags, ",")
+			histogram, found := histograms[key]
+			if !found {
+				histogram = &histogramSeries{name: family, tags: tags}
+				histograms[key] = histogram
+			}
+			histogram.buckets = append(histogram.buckets, histogramBucket{upperBound: bound, count: sample.Value})
+		default:
+			c.sender.Gauge(c.metricName(name), sample.Value, "", tags)
+		}
+		submitted++
+	}
+
+	for _, histogram := range histograms {
+		c.submitHistogram(histogram)
+	}
+	c.sender.Commit()
+	return submitted
+}
+
+// parseLabels returns the metric name, the tags and the upper bound of the bucket of a series.
+func (c *converter) parseLabels(labels []Label, podTags map[string][]string) (string, []string, string) {
+	var name, upperBound, podName, podNamespace string
+	tags := make([]string, 0, len(labels))
+	for _, label := range labels {
+		switch label.Name {
+		case metricNameLabel:
+			name = label.Value
+			continue
+		case bucketLabel:
+			upperBound = label.Value
+			continue
+		case c.podNameLabel:
+			podName = label.Value
+		case c.podNamespaceLabel:
+			podNamespace = label.Value
+		}
+		if label.Value != "" {
+			tags = append(tags, label.Name+":"+label.Value)
+		}
+	}
+
+	if c.podTags != nil && podName != "" && podNamespace != "" {
+		// the tags of a pod are looked up once per request
+		key := podNamespace + "/" + podName
+		t, found := podTags[key]
+		if !found {
+			t = c.podTags(podNamespace, podName)
+			podTags[key] = t
+		}
+		tags = append(tags, t...)
+	}
+	return name, tags, upperBound
+}
+
+// kind returns how a series is submitted, from the type of its metric family when Prometheus
+// sent it, or from the naming conventions of the Prometheus metrics otherwise.
+func (c *converter) kind(name string, isBucket bool) metricKind {
+	if metricType, found := c.metadata[name]; found {
+		if metricType == MetricTypeCounter {
+			return kindCounter
+		}
+		return kindGauge
+	}
+	for _, suffix := range []string{"_total", "_bucket", "_sum", "_count"} {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		metricType, found := c.metadata[strings.TrimSuffix(name, suffix)]
+		if !found {
+			break
+		}
+		switch {
+		case metricType == MetricTypeCounter && suffix == "_total":
+			return kindCounter
+		case metricType == MetricTypeHistogram && suffix == "_bucket" && isBucket:
+			return kindBucket
+		case (metricType == MetricTypeHistogram || metricType == MetricTypeSummary) && (suffix == "_sum" || suffix == "_count"):
+			return kindCounter
+		}
+		return kindGauge
+	}
+
+	switch {
+	case strings.HasSuffix(name, "_bucket") && isBucket:
+		return kindBucket
+	case strings.HasSuffix(name, "_total"), strings.HasSuffix(name, "_sum"), strings.HasSuffix(name, "_count"):
+		return kindCounter
+	}
+	return kindGauge
+}
+
+func (c *converter) metricName(name string) string {
+	if c.namespace == "" {
+		return name
+	}
+	return c.namespace + "." + name
+}
+
+// submitHistogram submits the count of each bucket of a histogram, tagged with its bounds so that
+// each bucket gets its own context, as monotonic so that the aggregator only keeps the increase
+// of each bucket since the previous request.
+func (c *converter) submitHistogram(histogram *histogramSeries) {
+	sort.Slice(histogram.buckets, func(i, j int) bool {
+		return histogram.buckets[i].upperBound < histogram.buckets[j].upperBound
+	})
+
+	name := c.metricName(histogram.name)
+	var lowerBound, previousCumulative float64
+	for i, bucket := range histogram.buckets {
+		if i == 0 && bucket.upperBound <= 0 {
+			lowerBound = bucket.upperBound
+		}
+		count := bucket.count - previousCumulative
+		previousCumulative = bucket.count
+
+		c.sender.HistogramBucket(name, int64(count), lowerBound, bucket.upperBound, true, "", metrics.BucketBoundTags(histogram.tags, lowerBound, bucket.upperBound))
+		lowerBound = bucket.upperBound
+	}
+}
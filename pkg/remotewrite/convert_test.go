@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+func series(name string, value float64, labels ...string) TimeSeries {
+	ts := TimeSeries{
+		Labels:  []Label{{Name: metricNameLabel, Value: name}},
+		Samples: []Sample{{Value: value, Timestamp: 1000}},
+	}
+	for i := 0; i+1 < len(labels); i += 2 {
+		ts.Labels = append(ts.Labels, Label{Name: labels[i], Value: labels[i+1]})
+	}
+	return ts
+}
+
+func TestConvertGaugesAndCounters(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	c := newConverter(sender, "prom", "pod", "namespace", nil)
+
+	submitted := c.convert(&WriteRequest{
+		Timeseries: []TimeSeries{
+			series("temperature", 21.5, "room", "kitchen"),
+			series("http_requests_total", 12, "code", "200"),
+			series("rpc_duration_seconds_count", 4),
+			// a NaN value marks a stale series
+			series("stale", math.NaN()),
+			// the metric name is required
+			{Labels: []Label{{Name: "code", Value: "200"}}, Samples: []Sample{{Value: 1}}},
+		},
+	})
+
+	assert.Equal(t, 3, submitted)
+	sender.AssertMetric(t, "Gauge", "prom.temperature", 21.5, "", []string{"room:kitchen"})
+	sender.AssertMetric(t, "MonotonicCount", "prom.http_requests_total", 12, "", []string{"code:200"})
+	sender.AssertMetric(t, "MonotonicCount", "prom.rpc_duration_seconds_count", 4, "", nil)
+	sender.AssertNumberOfCalls(t, "Gauge", 1)
+	sender.AssertNumberOfCalls(t, "MonotonicCount", 2)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestConvertWithMetadata(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	c := newConverter(sender, "", "pod", "namespace", nil)
+
+	c.convert(&WriteRequest{
+		Metadata: []MetricMetadata{
+			{Type: MetricTypeGauge, MetricFamilyName: "queue_total"},
+			{Type: MetricTypeCounter, MetricFamilyName: "processed"},
+			{Type: MetricTypeSummary, MetricFamilyName: "rpc_duration_seconds"},
+		},
+	})
+	c.convert(&WriteRequest{
+		Timeseries: []TimeSeries{
+			series("queue_total", 3),
+			series("processed_total", 42),
+			series("rpc_duration_seconds", 0.2, "quantile", "0.99"),
+			series("rpc_duration_seconds_sum", 8),
+		},
+	})
+
+	sender.AssertMetric(t, "Gauge", "queue_total", 3, "", nil)
+	sender.AssertMetric(t, "MonotonicCount", "processed_total", 42, "", nil)
+	sender.AssertMetric(t, "Gauge", "rpc_duration_seconds", 0.2, "", []string{"quantile:0.99"})
+	sender.AssertMetric(t, "MonotonicCount", "rpc_duration_seconds_sum", 8, "", nil)
+}
+
+func TestConvertLatestSample(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	c := newConverter(sender, "", "pod", "namespace", nil)
+
+	c.convert(&WriteRequest{
+		Timeseries: []TimeSeries{{
+			Labels:  []Label{{Name: metricNameLabel, Value: "temperature"}},
+			Samples: []Sample{{Value: 2, Timestamp: 2000}, {Value: 3, Timestamp: 3000}, {Value: 1, Timestamp: 1000}},
+		}},
+	})
+
+	sender.AssertMetric(t, "Gauge", "temperature", 3, "", nil)
+	sender.AssertNumberOfCalls(t, "Gauge", 1)
+}
+
+func TestConvertHistogram(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	c := newConverter(sender, "", "pod", "namespace", nil)
+
+	c.convert(&WriteRequest{
+		Timeseries: []TimeSeries{
+			series("latency_bucket", 7, "le", "+Inf", "path", "/"),
+			series("latency_bucket", 4, "le", "0.1", "path", "/"),
+			series("latency_bucket", 6, "le", "0.5", "path", "/"),
+		},
+	})
+
+	// the cumulative counts of the buckets are split, the aggregator keeps their increase
+	sender.AssertHistogramBucket(t, "HistogramBucket", "latency", 4, 0, 0.1, true, "", []string{"path:/", "lower_bound:0", "upper_bound:0.1"})
+	sender.AssertHistogramBucket(t, "HistogramBucket", "latency", 2, 0.1, 0.5, true, "", []string{"path:/", "lower_bound:0.1", "upper_bound:0.5"})
+	sender.AssertHistogramBucket(t, "HistogramBucket", "latency", 1, 0.5, math.Inf(1), true, "", []string{"path:/", "lower_bound:0.5", "upper_bound:inf"})
+	sender.AssertNumberOfCalls(t, "HistogramBucket", 3)
+}
+
+func TestConvertPodTags(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+	lookups := 0
+	c := newConverter(sender, "", "kubernetes_pod_name", "kubernetes_namespace", func(namespace, name string) []string {
+		lookups++
+		return []string{"kube_deployment:" + name[:3], "kube_namespace:" + namespace}
+	})
+
+	c.convert(&WriteRequest{
+		Timeseries: []TimeSeries{
+			series("temperature", 1, "kubernetes_pod_name", "web-1234", "kubernetes_namespace", "default"),
+			series("pressure", 1, "kubernetes_pod_name", "web-1234", "kubernetes_namespace", "default"),
+			series("humidity", 1, "kubernetes_pod_name", "web-1234"),
+		},
+	})
+
+	expected := []string{"kubernetes_pod_name:web-1234", "kubernetes_namespace:default", "kube_deployment:web", "kube_namespace:default"}
+	sender.AssertMetric(t, "Gauge", "temperature", 1, "", expected)
+	sender.AssertMetric(t, "Gauge", "pressure", 1, "", expected)
+	sender.AssertMetricNotTaggedWith(t, "Gauge", "humidity", []string{"kube_deployment:web"})
+	// the tags of a pod are looked up once per request
+	assert.Equal(t, 1, lookups)
+}
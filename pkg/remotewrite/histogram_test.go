@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build test
+
+package remotewrite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// bucketCounts returns the number of values of the sketches of each bucket, by upper bound.
+func bucketCounts(sketches metrics.SketchSeriesList) map[string]int64 {
+	counts := make(map[string]int64)
+	for _, sketch := range sketches {
+		for _, tag := range sketch.Tags {
+			if !strings.HasPrefix(tag, "upper_bound:") {
+				continue
+			}
+			for _, point := range sketch.Points {
+				counts[tag] += point.Sketch.Basic.Cnt
+			}
+		}
+	}
+	return counts
+}
+
+func histogramRequest(le01, le05, leInf float64) *WriteRequest {
+	return &WriteRequest{
+		Timeseries: []TimeSeries{
+			series("latency_bucket", le01, "le", "0.1"),
+			series("latency_bucket", le05, "le", "0.5"),
+			series("latency_bucket", leInf, "le", "+Inf"),
+		},
+	}
+}
+
+func TestConvertHistogramWithCheckSampler(t *testing.T) {
+	sampler := aggregator.NewTestCheckSampler(100)
+	c := newConverter(sampler.Sender, "", "pod", "namespace", nil)
+
+	c.convert(histogramRequest(4, 6, 7))
+	assert.Equal(t, map[string]int64{"upper_bound:0.1": 4, "upper_bound:0.5": 2, "upper_bound:inf": 1}, bucketCounts(sampler.Flush()))
+
+	// each bucket only reports its own increase
+	c.convert(histogramRequest(6, 8, 11))
+	assert.Equal(t, map[string]int64{"upper_bound:0.1": 2, "upper_bound:inf": 2}, bucketCounts(sampler.Flush()))
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubelet
+
+package remotewrite
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// newPodTagger returns a function returning the tagger tags of the pods running on the node.
+func newPodTagger(cardinality collectors.TagCardinality) podTagsFunc {
+	return func(namespace, name string) []string {
+		ku, err := kubelet.GetKubeUtil()
+		if err != nil {
+			log.Debugf("Could not get the tags of the pod %s/%s: %v", namespace, name, err)
+			return nil
+		}
+		pods, err := ku.GetLocalPodList()
+		if err != nil {
+			log.Debugf("Could not get the tags of the pod %s/%s: %v", namespace, name, err)
+			return nil
+		}
+		for _, pod := range pods {
+			if pod.Metadata.Name != name || pod.Metadata.Namespace != namespace {
+				continue
+			}
+			tags, err := tagger.Tag(kubelet.PodUIDToTaggerEntityName(pod.Metadata.UID), cardinality)
+			if err != nil {
+				log.Debugf("Could not get the tags of the pod %s/%s: %v", namespace, name, err)
+			}
+			return tags
+		}
+		return nil
+	}
+}
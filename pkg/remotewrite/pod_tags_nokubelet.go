@@ -0,0 +1,19 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !kubelet
+
+package remotewrite
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// newPodTagger returns nil, the pods can only be looked up when the kubelet is supported.
+func newPodTagger(cardinality collectors.TagCardinality) podTagsFunc {
+	log.Info("The Prometheus remote-write series are not tagged with the tags of their pod, the kubelet is not supported by this build")
+	return nil
+}
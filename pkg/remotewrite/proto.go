@@ -0,0 +1,257 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/util/protowire"
+)
+
+// The types below mirror the messages of the Prometheus remote-write protocol
+// (prometheus/prompb/remote.proto and types.proto). Only the fields used by the
+// receiver are decoded, the other ones are skipped.
+
+// MetricType is the type of a metric family.
+type MetricType int32
+
+// Metric types sent in the metadata of the remote-write requests.
+const (
+	MetricTypeUnknown        MetricType = 0
+	MetricTypeCounter        MetricType = 1
+	MetricTypeGauge          MetricType = 2
+	MetricTypeHistogram      MetricType = 3
+	MetricTypeGaugeHistogram MetricType = 4
+	MetricTypeSummary        MetricType = 5
+	MetricTypeInfo           MetricType = 6
+	MetricTypeStateset       MetricType = 7
+)
+
+// WriteRequest is the payload of a remote-write request.
+type WriteRequest struct {
+	Timeseries []TimeSeries
+	Metadata   []MetricMetadata
+}
+
+// TimeSeries is a series identified by its labels, and its samples.
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// Label is a name/value pair, the metric name is held by the `__name__` label.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a value and its timestamp in milliseconds.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// MetricMetadata describes a metric family.
+type MetricMetadata struct {
+	Type             MetricType
+	MetricFamilyName string
+	Help             string
+	Unit             string
+}
+
+// Unmarshal decodes a remote-write request.
+func (r *WriteRequest) Unmarshal(data []byte) error {
+	d := protowire.NewDecoder(data)
+	for !d.Done() {
+		field, wireType, err := d.Key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+				return err
+			}
+			msg, err := d.Bytes()
+			if err != nil {
+				return err
+			}
+			var ts TimeSeries
+			if err := ts.unmarshal(msg); err != nil {
+				return fmt.Errorf("invalid time series: %v", err)
+			}
+			r.Timeseries = append(r.Timeseries, ts)
+		case 3:
+			if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+				return err
+			}
+			msg, err := d.Bytes()
+			if err != nil {
+				return err
+			}
+			var md MetricMetadata
+			if err := md.unmarshal(msg); err != nil {
+				return fmt.Errorf("invalid metadata: %v", err)
+			}
+			r.Metadata = append(r.Metadata, md)
+		default:
+			if err := d.Skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (ts *TimeSeries) unmarshal(data []byte) error {
+	d := protowire.NewDecoder(data)
+	for !d.Done() {
+		field, wireType, err := d.Key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+				return err
+			}
+			msg, err := d.Bytes()
+			if err != nil {
+				return err
+			}
+			var label Label
+			if err := label.unmarshal(msg); err != nil {
+				return err
+			}
+			ts.Labels = append(ts.Labels, label)
+		case 2:
+			if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+				return err
+			}
+			msg, err := d.Bytes()
+			if err != nil {
+				return err
+			}
+			var sample Sample
+			if err := sample.unmarshal(msg); err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, sample)
+		default:
+			if err := d.Skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *Label) unmarshal(data []byte) error {
+	d := protowire.NewDecoder(data)
+	for !d.Done() {
+		field, wireType, err := d.Key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1, 2:
+			if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+				return err
+			}
+			value, err := d.Bytes()
+			if err != nil {
+				return err
+			}
+			if field == 1 {
+				l.Name = string(value)
+			} else {
+				l.Value = string(value)
+			}
+		default:
+			if err := d.Skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Sample) unmarshal(data []byte) error {
+	d := protowire.NewDecoder(data)
+	for !d.Done() {
+		field, wireType, err := d.Key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			if err := protowire.Expect(field, wireType, protowire.WireFixed64); err != nil {
+				return err
+			}
+			value, err := d.Double()
+			if err != nil {
+				return err
+			}
+			s.Value = value
+		case 2:
+			if err := protowire.Expect(field, wireType, protowire.WireVarint); err != nil {
+				return err
+			}
+			value, err := d.Varint()
+			if err != nil {
+				return err
+			}
+			s.Timestamp = int64(value)
+		default:
+			if err := d.Skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MetricMetadata) unmarshal(data []byte) error {
+	d := protowire.NewDecoder(data)
+	for !d.Done() {
+		field, wireType, err := d.Key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			if err := protowire.Expect(field, wireType, protowire.WireVarint); err != nil {
+				return err
+			}
+			value, err := d.Varint()
+			if err != nil {
+				return err
+			}
+			m.Type = MetricType(value)
+		case 2, 4, 5:
+			if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+				return err
+			}
+			value, err := d.Bytes()
+			if err != nil {
+				return err
+			}
+			switch field {
+			case 2:
+				m.MetricFamilyName = string(value)
+			case 4:
+				m.Help = string(value)
+			case 5:
+				m.Unit = string(value)
+			}
+		default:
+			if err := d.Skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
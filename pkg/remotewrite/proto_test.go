@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/protowire"
+)
+
+func marshalWriteRequest(r *WriteRequest) []byte {
+	e := &protowire.Encoder{}
+	for _, ts := range r.Timeseries {
+		tsEncoder := &protowire.Encoder{}
+		for _, label := range ts.Labels {
+			labelEncoder := &protowire.Encoder{}
+			labelEncoder.String(1, label.Name)
+			labelEncoder.String(2, label.Value)
+			tsEncoder.Message(1, labelEncoder)
+		}
+		for _, sample := range ts.Samples {
+			sampleEncoder := &protowire.Encoder{}
+			sampleEncoder.Key(1, protowire.WireFixed64)
+			sampleEncoder.Double(sample.Value)
+			sampleEncoder.Key(2, protowire.WireVarint)
+			sampleEncoder.Varint(uint64(sample.Timestamp))
+			tsEncoder.Message(2, sampleEncoder)
+		}
+		e.Message(1, tsEncoder)
+	}
+	for _, md := range r.Metadata {
+		mdEncoder := &protowire.Encoder{}
+		mdEncoder.Key(1, protowire.WireVarint)
+		mdEncoder.Varint(uint64(md.Type))
+		mdEncoder.String(2, md.MetricFamilyName)
+		mdEncoder.String(4, md.Help)
+		mdEncoder.String(5, md.Unit)
+		e.Message(3, mdEncoder)
+	}
+	return e.Data()
+}
+
+func TestUnmarshalWriteRequest(t *testing.T) {
+	expected := &WriteRequest{
+		Timeseries: []TimeSeries{
+			{
+				Labels:  []Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "code", Value: "200"}},
+				Samples: []Sample{{Value: 1027, Timestamp: 1612345678000}, {Value: 1030, Timestamp: 1612345693000}},
+			},
+			{
+				Labels:  []Label{{Name: "__name__", Value: "temperature"}},
+				Samples: []Sample{{Value: -3.5, Timestamp: 1612345678000}},
+			},
+		},
+		Metadata: []MetricMetadata{
+			{Type: MetricTypeCounter, MetricFamilyName: "http_requests_total", Help: "The number of requests", Unit: "requests"},
+		},
+	}
+
+	// unknown fields are skipped
+	e := &protowire.Encoder{}
+	e.Key(15, protowire.WireVarint)
+	e.Varint(42)
+	e.String(16, "unknown")
+	data := append(marshalWriteRequest(expected), e.Data()...)
+
+	request := &WriteRequest{}
+	require.NoError(t, request.Unmarshal(data))
+	assert.Equal(t, expected, request)
+}
+
+func TestUnmarshalInvalidWriteRequest(t *testing.T) {
+	data := marshalWriteRequest(&WriteRequest{
+		Timeseries: []TimeSeries{{Labels: []Label{{Name: "__name__", Value: "metric"}}}},
+	})
+
+	request := &WriteRequest{}
+	assert.Error(t, request.Unmarshal(data[:len(data)-1]))
+
+	// the time series are length delimited
+	e := &protowire.Encoder{}
+	e.Key(1, protowire.WireVarint)
+	e.Varint(1)
+	assert.Error(t, request.Unmarshal(e.Data()))
+}
@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// senderID identifies the sender of the remote-write samples in the aggregator
+	senderID check.ID = "prometheus_remote_write"
+
+	writePath = "/api/v1/write"
+
+	// maxRequestSize is the maximum size of a request, compressed or not
+	maxRequestSize = 32 * 1024 * 1024
+
+	stopTimeout = 5 * time.Second
+)
+
+var (
+	remoteWriteExpvars = expvar.NewMap("prometheus_remote_write")
+
+	serverInstance *Server
+)
+
+// Server receives the samples sent with the Prometheus remote-write protocol and submits them to the aggregator.
+type Server struct {
+	server   *http.Server
+	listener net.Listener
+
+	// the requests are converted one at a time since the converter keeps the count of the histogram buckets
+	mu        sync.Mutex
+	converter *converter
+}
+
+// IsEnabled returns whether the remote-write receiver is enabled in the configuration.
+func IsEnabled() bool {
+	return config.Datadog.GetBool("prometheus_remote_write.enabled")
+}
+
+// StartServer starts the global remote-write receiver.
+func StartServer() error {
+	server, err := NewServer()
+	if err != nil {
+		return err
+	}
+	serverInstance = server
+	return nil
+}
+
+// StopServer stops the global remote-write receiver, if it is running.
+func StopServer() {
+	if serverInstance != nil {
+		serverInstance.Stop()
+		serverInstance = nil
+	}
+}
+
+// NewServer returns a running remote-write receiver listening on the configured address.
+func NewServer() (*Server, error) {
+	sender, err := aggregator.GetSender(senderID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get a sender: %v", err)
+	}
+
+	var podTags podTagsFunc
+	if config.Datadog.GetBool("prometheus_remote_write.pod_tags_enabled") {
+		cardinality, err := collectors.StringToTagCardinality(config.Datadog.GetString("prometheus_remote_write.tag_cardinality"))
+		if err != nil {
+			log.Warnf("Invalid prometheus_remote_write.tag_cardinality, using the low cardinality: %v", err)
+			cardinality = collectors.LowCardinality
+		}
+		podTags = newPodTagger(cardinality)
+	}
+
+	addr := net.JoinHostPort(config.Datadog.GetString("prometheus_remote_write.bind_host"), strconv.Itoa(config.Datadog.GetInt("prometheus_remote_write.port")))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		aggregator.DestroySender(senderID)
+		return nil, fmt.Errorf("could not listen on %s: %v", addr, err)
+	}
+
+	s := &Server{
+		listener: listener,
+		converter: newConverter(
+			sender,
+			config.Datadog.GetString("prometheus_remote_write.namespace"),
+			config.Datadog.GetString("prometheus_remote_write.pod_name_label"),
+			config.Datadog.GetString("prometheus_remote_write.pod_namespace_label"),
+			podTags,
+		),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(writePath, s.handleWrite)
+	s.server = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Prometheus remote-write receiver stopped: %v", err)
+		}
+	}()
+	log.Infof("Listening for Prometheus remote-write requests on %s", listener.Addr())
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Stop stops the server and releases its sender.
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Errorf("Could not stop the Prometheus remote-write receiver: %v", err)
+	}
+	aggregator.DestroySender(senderID)
+}
+
+func (s *Server) handleWrite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	remoteWriteExpvars.Add("Requests", 1)
+
+	request, err := decodeRequest(w, r)
+	if err != nil {
+		remoteWriteExpvars.Add("RequestErrors", 1)
+		log.Debugf("Invalid Prometheus remote-write request: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	submitted := s.converter.convert(request)
+	s.mu.Unlock()
+
+	remoteWriteExpvars.Add("Series", int64(submitted))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeRequest reads the snappy compressed protobuf payload of a request.
+func decodeRequest(w http.ResponseWriter, r *http.Request) (*WriteRequest, error) {
+	compressed, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		return nil, fmt.Errorf("could not read the request: %v", err)
+	}
+	length, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy payload: %v", err)
+	}
+	if length > maxRequestSize {
+		return nil, fmt.Errorf("the request exceeds %d bytes once decompressed", maxRequestSize)
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy payload: %v", err)
+	}
+
+	request := &WriteRequest{}
+	if err := request.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("invalid protobuf payload: %v", err)
+	}
+	return request, nil
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestServer(t *testing.T) {
+	sender := mocksender.NewMockSender(senderID)
+	sender.SetupAcceptAll()
+
+	mockConfig := config.Mock()
+	mockConfig.Set("prometheus_remote_write.port", 0)
+	mockConfig.Set("prometheus_remote_write.namespace", "prom")
+
+	server, err := NewServer()
+	require.NoError(t, err)
+	defer server.Stop()
+	url := fmt.Sprintf("http://%s%s", server.Addr(), writePath)
+
+	payload := snappy.Encode(nil, marshalWriteRequest(&WriteRequest{
+		Timeseries: []TimeSeries{series("temperature", 21.5, "room", "kitchen")},
+	}))
+	resp, err := http.Post(url, "application/x-protobuf", bytes.NewReader(payload))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	sender.AssertMetric(t, "Gauge", "prom.temperature", 21.5, "", []string{"room:kitchen"})
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+
+	// the payload must be compressed
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(marshalWriteRequest(&WriteRequest{})))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
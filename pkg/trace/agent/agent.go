@@ -37,6 +37,7 @@ type Agent struct {
 	Receiver          *api.HTTPReceiver
 	Concentrator      *stats.Concentrator
 	Blacklister       *filters.Blacklister
+	TagFilter         *filters.TagFilter
 	Replacer          *filters.Replacer
 	PrioritySampler   *sampler.PrioritySampler
 	ErrorsSampler     *sampler.ErrorsSampler
@@ -70,6 +71,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 	agnt := &Agent{
 		Concentrator:      stats.NewConcentrator(conf, statsChan, time.Now()),
 		Blacklister:       filters.NewBlacklister(conf.Ignore["resource"]),
+		TagFilter:         filters.NewTagFilter(conf),
 		Replacer:          filters.NewReplacer(conf.ReplaceTags),
 		PrioritySampler:   sampler.NewPrioritySampler(conf, dynConf),
 		ErrorsSampler:     sampler.NewErrorsSampler(conf),
@@ -142,8 +144,12 @@ func (a *Agent) work() {
 }
 
 func (a *Agent) loop() {
+	t := time.NewTicker(10 * time.Second)
+	defer t.Stop()
 	for {
 		select {
+		case <-t.C:
+			a.TagFilter.Stats().Publish()
 		case <-a.ctx.Done():
 			log.Info("Exiting...")
 			if err := a.Receiver.Stop(); err != nil {
@@ -200,7 +206,7 @@ func (a *Agent) Process(p *api.Payload) {
 			continue
 		}
 
-		if filteredByTags(root, a.conf.RequireTags, a.conf.RejectTags) {
+		if !a.TagFilter.Allows(root) {
 			log.Debugf("Trace rejected as it fails to meet tag requirements. root: %v", root)
 			atomic.AddInt64(&ts.TracesFiltered, 1)
 			atomic.AddInt64(&ts.SpansFiltered, tracen)
@@ -377,21 +383,6 @@ func traceContainsError(trace pb.Trace) bool {
 	return false
 }
 
-func filteredByTags(root *pb.Span, require, reject []*config.Tag) bool {
-	for _, tag := range reject {
-		if v, ok := root.Meta[tag.K]; ok && (tag.V == "" || v == tag.V) {
-			return true
-		}
-	}
-	for _, tag := range require {
-		v, ok := root.Meta[tag.K]
-		if !ok || (tag.V != "" && v != tag.V) {
-			return true
-		}
-	}
-	return false
-}
-
 func newEventProcessor(conf *config.AgentConfig) *event.Processor {
 	extractors := []event.Extractor{
 		event.NewMetricBasedExtractor(),
@@ -0,0 +1,392 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// jaegerEndpointVersion identifies the Jaeger spans in the receiver stats
+const jaegerEndpointVersion = "jaeger"
+
+// jaegerTagType is the type of the value of a Jaeger tag.
+type jaegerTagType int32
+
+// Jaeger tag types
+const (
+	jaegerTagString jaegerTagType = iota
+	jaegerTagDouble
+	jaegerTagBool
+	jaegerTagLong
+	jaegerTagBinary
+)
+
+// jaegerRefChildOf is the type of the references to the parent of a span.
+const jaegerRefChildOf = 0
+
+// jaegerBatch is a batch of spans of a process, as defined by the jaeger.thrift IDL.
+type jaegerBatch struct {
+	Process jaegerProcess
+	Spans   []jaegerSpan
+}
+
+// jaegerProcess describes the traced process.
+type jaegerProcess struct {
+	ServiceName string
+	Tags        []jaegerTag
+}
+
+// jaegerTag is a typed key-value pair.
+type jaegerTag struct {
+	Key     string
+	Type    jaegerTagType
+	VStr    string
+	VDouble float64
+	VBool   bool
+	VLong   int64
+	VBinary []byte
+}
+
+// jaegerSpan is a Jaeger span, its timestamps are in microseconds.
+type jaegerSpan struct {
+	TraceIDLow    int64
+	TraceIDHigh   int64
+	SpanID        int64
+	ParentSpanID  int64
+	OperationName string
+	References    []jaegerSpanRef
+	Flags         int32
+	StartTime     int64
+	Duration      int64
+	Tags          []jaegerTag
+	Logs          []jaegerLog
+}
+
+// jaegerSpanRef is a reference of a span to another span.
+type jaegerSpanRef struct {
+	RefType     int32
+	TraceIDLow  int64
+	TraceIDHigh int64
+	SpanID      int64
+}
+
+// jaegerLog is a timed event of a span.
+type jaegerLog struct {
+	Timestamp int64
+	Fields    []jaegerTag
+}
+
+// handleJaegerTraces handles the spans sent to the HTTP API of the Jaeger collector,
+// the body is a Batch encoded with the Thrift binary protocol.
+func (r *HTTPReceiver) handleJaegerTraces(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch mediaType(req) {
+	case "application/x-thrift", "application/vnd.apache.thrift.binary":
+	default:
+		http.Error(w, "unsupported media type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	batch := &jaegerBatch{}
+	body, err := readSpansBody(req, r.conf.MaxRequestBytes)
+	if err == nil {
+		err = batch.read(&thriftReader{data: body})
+	}
+	if err != nil {
+		httpDecodingError(err, []string{"handler:jaeger_traces"}, w)
+		log.Errorf("Cannot decode Jaeger batch payload: %v", err)
+		return
+	}
+	spans := make([]*pb.Span, 0, len(batch.Spans))
+	for _, span := range batch.Spans {
+		spans = append(spans, convertJaegerSpan(span, batch.Process))
+	}
+	r.submitSpans(w, jaegerTags(batch.Process), spans, len(body))
+}
+
+// jaegerTags returns the stats tags of the spans of a process, from the version of its Jaeger client,
+// e.g. "Go-2.25.0".
+func jaegerTags(process jaegerProcess) info.Tags {
+	tags := info.Tags{EndpointVersion: jaegerEndpointVersion}
+	for _, tag := range process.Tags {
+		if tag.Key != "jaeger.version" {
+			continue
+		}
+		v := tagValue(tag)
+		if i := strings.IndexByte(v, '-'); i >= 0 {
+			tags.Lang = strings.ToLower(v[:i])
+			v = v[i+1:]
+		}
+		tags.TracerVersion = "jaeger-" + v
+	}
+	return tags
+}
+
+// convertJaegerSpan converts a Jaeger span to a Datadog span. The string tags of the process and
+// of the span are added to its meta and the numeric tags to its metrics.
+func convertJaegerSpan(span jaegerSpan, process jaegerProcess) *pb.Span {
+	s := &pb.Span{
+		TraceID:  uint64(span.TraceIDLow),
+		SpanID:   uint64(span.SpanID),
+		ParentID: uint64(span.ParentSpanID),
+		Start:    span.StartTime * 1000,
+		Duration: span.Duration * 1000,
+		Service:  process.ServiceName,
+		Meta:     make(map[string]string, len(process.Tags)+len(span.Tags)+4),
+		Metrics:  make(map[string]float64),
+	}
+	if s.Service == "" {
+		s.Service = defaultService
+	}
+	if s.ParentID == 0 {
+		// the recent clients only set the parent in the references
+		for _, ref := range span.References {
+			if ref.RefType == jaegerRefChildOf && ref.TraceIDLow == span.TraceIDLow {
+				s.ParentID = uint64(ref.SpanID)
+				break
+			}
+		}
+	}
+	for _, tags := range [][]jaegerTag{process.Tags, span.Tags} {
+		for _, tag := range tags {
+			switch tag.Type {
+			case jaegerTagLong:
+				s.Metrics[tag.Key] = float64(tag.VLong)
+			case jaegerTagDouble:
+				s.Metrics[tag.Key] = tag.VDouble
+			default:
+				s.Meta[tag.Key] = tagValue(tag)
+			}
+		}
+	}
+	if span.TraceIDHigh != 0 {
+		// the 64 higher bits of the trace ID are lost in the conversion
+		s.Meta["jaeger.trace_id"] = fmt.Sprintf("%016x%016x", uint64(span.TraceIDHigh), uint64(span.TraceIDLow))
+	}
+
+	kind := s.Meta["span.kind"]
+	if kind == "" {
+		kind = "internal"
+		s.Meta["span.kind"] = kind
+	}
+	component := s.Meta["component"]
+	if component == "" {
+		component = "jaeger"
+	}
+	s.Name = spanName(component, kind)
+	s.Resource = spanResource(span.OperationName, kind, s.Meta)
+	if s.Resource == "" {
+		s.Resource = s.Name
+	}
+	s.Type = spanType(kind, s.Meta)
+
+	if s.Meta["error"] == "true" {
+		s.Error = 1
+		for _, l := range span.Logs {
+			setJaegerErrorLog(s, l)
+		}
+	}
+	return s
+}
+
+// setJaegerErrorLog sets the error message, type and stack of a span from a log of its error,
+// following the OpenTracing conventions.
+func setJaegerErrorLog(s *pb.Span, l jaegerLog) {
+	var isError bool
+	for _, field := range l.Fields {
+		if field.Key == "event" && tagValue(field) == "error" {
+			isError = true
+		}
+	}
+	if !isError {
+		return
+	}
+	for _, field := range l.Fields {
+		switch field.Key {
+		case "message", "error.object":
+			s.Meta["error.msg"] = tagValue(field)
+		case "error.kind":
+			s.Meta["error.type"] = tagValue(field)
+		case "stack":
+			s.Meta["error.stack"] = tagValue(field)
+		}
+	}
+}
+
+// tagValue returns the value of a tag as a string.
+func tagValue(tag jaegerTag) string {
+	switch tag.Type {
+	case jaegerTagDouble:
+		return strconv.FormatFloat(tag.VDouble, 'f', -1, 64)
+	case jaegerTagBool:
+		return strconv.FormatBool(tag.VBool)
+	case jaegerTagLong:
+		return strconv.FormatInt(tag.VLong, 10)
+	case jaegerTagBinary:
+		return base64.StdEncoding.EncodeToString(tag.VBinary)
+	default:
+		return tag.VStr
+	}
+}
+
+func (b *jaegerBatch) read(r *thriftReader) error {
+	return r.readStruct(func(typ byte, id int16) error {
+		switch id {
+		case 1:
+			if typ != thriftTypeStruct {
+				return thriftTypeError(typ, thriftTypeStruct)
+			}
+			return b.Process.read(r)
+		case 2:
+			return r.readList(typ, thriftTypeStruct, func() error {
+				var span jaegerSpan
+				if err := span.read(r); err != nil {
+					return fmt.Errorf("invalid span: %v", err)
+				}
+				b.Spans = append(b.Spans, span)
+				return nil
+			})
+		default:
+			return r.skip(typ)
+		}
+	})
+}
+
+func (p *jaegerProcess) read(r *thriftReader) error {
+	return r.readStruct(func(typ byte, id int16) error {
+		switch id {
+		case 1:
+			return r.string(typ, &p.ServiceName)
+		case 2:
+			return readJaegerTags(r, typ, &p.Tags)
+		default:
+			return r.skip(typ)
+		}
+	})
+}
+
+func (s *jaegerSpan) read(r *thriftReader) error {
+	return r.readStruct(func(typ byte, id int16) error {
+		switch id {
+		case 1:
+			return r.i64(typ, &s.TraceIDLow)
+		case 2:
+			return r.i64(typ, &s.TraceIDHigh)
+		case 3:
+			return r.i64(typ, &s.SpanID)
+		case 4:
+			return r.i64(typ, &s.ParentSpanID)
+		case 5:
+			return r.string(typ, &s.OperationName)
+		case 6:
+			return r.readList(typ, thriftTypeStruct, func() error {
+				var ref jaegerSpanRef
+				if err := ref.read(r); err != nil {
+					return err
+				}
+				s.References = append(s.References, ref)
+				return nil
+			})
+		case 7:
+			return r.i32(typ, &s.Flags)
+		case 8:
+			return r.i64(typ, &s.StartTime)
+		case 9:
+			return r.i64(typ, &s.Duration)
+		case 10:
+			return readJaegerTags(r, typ, &s.Tags)
+		case 11:
+			return r.readList(typ, thriftTypeStruct, func() error {
+				var l jaegerLog
+				if err := l.read(r); err != nil {
+					return err
+				}
+				s.Logs = append(s.Logs, l)
+				return nil
+			})
+		default:
+			return r.skip(typ)
+		}
+	})
+}
+
+func (ref *jaegerSpanRef) read(r *thriftReader) error {
+	return r.readStruct(func(typ byte, id int16) error {
+		switch id {
+		case 1:
+			return r.i32(typ, &ref.RefType)
+		case 2:
+			return r.i64(typ, &ref.TraceIDLow)
+		case 3:
+			return r.i64(typ, &ref.TraceIDHigh)
+		case 4:
+			return r.i64(typ, &ref.SpanID)
+		default:
+			return r.skip(typ)
+		}
+	})
+}
+
+func (l *jaegerLog) read(r *thriftReader) error {
+	return r.readStruct(func(typ byte, id int16) error {
+		switch id {
+		case 1:
+			return r.i64(typ, &l.Timestamp)
+		case 2:
+			return readJaegerTags(r, typ, &l.Fields)
+		default:
+			return r.skip(typ)
+		}
+	})
+}
+
+func (t *jaegerTag) read(r *thriftReader) error {
+	return r.readStruct(func(typ byte, id int16) error {
+		switch id {
+		case 1:
+			return r.string(typ, &t.Key)
+		case 2:
+			var v int32
+			err := r.i32(typ, &v)
+			t.Type = jaegerTagType(v)
+			return err
+		case 3:
+			return r.string(typ, &t.VStr)
+		case 4:
+			return r.double(typ, &t.VDouble)
+		case 5:
+			return r.bool(typ, &t.VBool)
+		case 6:
+			return r.i64(typ, &t.VLong)
+		case 7:
+			return r.binary(typ, &t.VBinary)
+		default:
+			return r.skip(typ)
+		}
+	})
+}
+
+func readJaegerTags(r *thriftReader, typ byte, tags *[]jaegerTag) error {
+	return r.readList(typ, thriftTypeStruct, func() error {
+		var tag jaegerTag
+		if err := tag.read(r); err != nil {
+			return err
+		}
+		*tags = append(*tags, tag)
+		return nil
+	})
+}
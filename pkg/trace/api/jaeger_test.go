@@ -0,0 +1,228 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func jaegerString(key, value string) jaegerTag {
+	return jaegerTag{Key: key, Type: jaegerTagString, VStr: value}
+}
+
+func testJaegerBatch() *jaegerBatch {
+	return &jaegerBatch{
+		Process: jaegerProcess{
+			ServiceName: "checkout",
+			Tags: []jaegerTag{
+				jaegerString("jaeger.version", "Go-2.25.0"),
+				jaegerString("hostname", "web-1"),
+			},
+		},
+		Spans: []jaegerSpan{
+			{
+				TraceIDLow:    2,
+				TraceIDHigh:   1,
+				SpanID:        3,
+				OperationName: "HTTP GET /cart",
+				StartTime:     1612345678000000,
+				Duration:      2000,
+				Tags: []jaegerTag{
+					jaegerString("span.kind", "server"),
+					jaegerString("component", "net/http"),
+					jaegerString("http.method", "GET"),
+					{Key: "http.status_code", Type: jaegerTagLong, VLong: 500},
+					{Key: "error", Type: jaegerTagBool, VBool: true},
+				},
+				Logs: []jaegerLog{
+					{Timestamp: 1612345678000100, Fields: []jaegerTag{jaegerString("event", "retry")}},
+					{Timestamp: 1612345678000200, Fields: []jaegerTag{
+						jaegerString("event", "error"),
+						jaegerString("error.kind", "TimeoutError"),
+						jaegerString("message", "timed out"),
+						jaegerString("stack", "main.go:12"),
+					}},
+				},
+			},
+			{
+				TraceIDLow:    2,
+				TraceIDHigh:   1,
+				SpanID:        4,
+				OperationName: "query",
+				References:    []jaegerSpanRef{{RefType: jaegerRefChildOf, TraceIDLow: 2, TraceIDHigh: 1, SpanID: 3}},
+				StartTime:     1612345678000500,
+				Duration:      1000,
+				Tags: []jaegerTag{
+					jaegerString("span.kind", "client"),
+					jaegerString("db.type", "redis"),
+					{Key: "db.ratio", Type: jaegerTagDouble, VDouble: 0.5},
+					{Key: "db.key", Type: jaegerTagBinary, VBinary: []byte("cart")},
+				},
+			},
+		},
+	}
+}
+
+func writeJaegerTags(w *thriftWriter, id int16, tags []jaegerTag) {
+	w.list(id, len(tags), func(i int) {
+		tag := tags[i]
+		w.string(1, tag.Key)
+		w.i32(2, int32(tag.Type))
+		switch tag.Type {
+		case jaegerTagString:
+			w.string(3, tag.VStr)
+		case jaegerTagDouble:
+			w.double(4, tag.VDouble)
+		case jaegerTagBool:
+			w.bool(5, tag.VBool)
+		case jaegerTagLong:
+			w.i64(6, tag.VLong)
+		case jaegerTagBinary:
+			w.string(7, string(tag.VBinary))
+		}
+		w.stop()
+	})
+}
+
+func marshalJaegerBatch(b *jaegerBatch) []byte {
+	w := &thriftWriter{}
+	w.field(thriftTypeStruct, 1)
+	w.string(1, b.Process.ServiceName)
+	writeJaegerTags(w, 2, b.Process.Tags)
+	w.stop()
+	w.list(2, len(b.Spans), func(i int) {
+		s := b.Spans[i]
+		w.i64(1, s.TraceIDLow)
+		w.i64(2, s.TraceIDHigh)
+		w.i64(3, s.SpanID)
+		w.i64(4, s.ParentSpanID)
+		w.string(5, s.OperationName)
+		if len(s.References) > 0 {
+			w.list(6, len(s.References), func(i int) {
+				ref := s.References[i]
+				w.i32(1, ref.RefType)
+				w.i64(2, ref.TraceIDLow)
+				w.i64(3, ref.TraceIDHigh)
+				w.i64(4, ref.SpanID)
+				w.stop()
+			})
+		}
+		w.i32(7, s.Flags)
+		w.i64(8, s.StartTime)
+		w.i64(9, s.Duration)
+		writeJaegerTags(w, 10, s.Tags)
+		if len(s.Logs) > 0 {
+			w.list(11, len(s.Logs), func(i int) {
+				w.i64(1, s.Logs[i].Timestamp)
+				writeJaegerTags(w, 2, s.Logs[i].Fields)
+				w.stop()
+			})
+		}
+		w.stop()
+	})
+	// unknown fields are skipped
+	w.i64(3, 42)
+	w.stop()
+	return w.data
+}
+
+func TestReadJaegerBatch(t *testing.T) {
+	expected := testJaegerBatch()
+	batch := &jaegerBatch{}
+	r := &thriftReader{data: marshalJaegerBatch(expected)}
+	require.NoError(t, batch.read(r))
+	assert.Equal(t, expected, batch)
+
+	data := marshalJaegerBatch(expected)
+	assert.Error(t, (&jaegerBatch{}).read(&thriftReader{data: data[:len(data)-1]}))
+}
+
+func TestConvertJaegerSpan(t *testing.T) {
+	batch := testJaegerBatch()
+	s := convertJaegerSpan(batch.Spans[0], batch.Process)
+	assert.Equal(t, &pb.Span{
+		Service:  "checkout",
+		Name:     "net/http.server",
+		Resource: "GET",
+		TraceID:  2,
+		SpanID:   3,
+		Start:    1612345678000000000,
+		Duration: 2000000,
+		Error:    1,
+		Type:     "web",
+		Meta: map[string]string{
+			"jaeger.version":  "Go-2.25.0",
+			"hostname":        "web-1",
+			"span.kind":       "server",
+			"component":       "net/http",
+			"http.method":     "GET",
+			"error":           "true",
+			"error.type":      "TimeoutError",
+			"error.msg":       "timed out",
+			"error.stack":     "main.go:12",
+			"jaeger.trace_id": "00000000000000010000000000000002",
+		},
+		Metrics: map[string]float64{"http.status_code": 500},
+	}, s)
+
+	s = convertJaegerSpan(batch.Spans[1], batch.Process)
+	assert.Equal(t, uint64(3), s.ParentID)
+	assert.Equal(t, "jaeger.client", s.Name)
+	assert.Equal(t, "query", s.Resource)
+	assert.Equal(t, "cache", s.Type)
+	assert.Equal(t, 0.5, s.Metrics["db.ratio"])
+	assert.Equal(t, "Y2FydA==", s.Meta["db.key"])
+	assert.Equal(t, int32(0), s.Error)
+
+	s = convertJaegerSpan(jaegerSpan{TraceIDLow: 5, SpanID: 6}, jaegerProcess{})
+	assert.Equal(t, defaultService, s.Service)
+	assert.Equal(t, "jaeger.internal", s.Name)
+	assert.Equal(t, "jaeger.internal", s.Resource)
+	assert.Equal(t, "custom", s.Type)
+	assert.NotContains(t, s.Meta, "jaeger.trace_id")
+}
+
+func TestHandleJaegerTraces(t *testing.T) {
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader(marshalJaegerBatch(testJaegerBatch())))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-thrift")
+	r.handleJaegerTraces(rr, req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+
+	require.Len(t, r.out, 1)
+	payload := <-r.out
+	require.Len(t, payload.Traces, 1)
+	assert.Len(t, payload.Traces[0], 2)
+	assert.Equal(t, "go", payload.Source.Lang)
+	assert.Equal(t, "jaeger-2.25.0", payload.Source.TracerVersion)
+	assert.Equal(t, int64(1), payload.Source.TracesReceived)
+
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader([]byte{thriftTypeStruct, 0}))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-thrift")
+	r.handleJaegerTraces(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader(nil))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	r.handleJaegerTraces(rr, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	assert.Len(t, r.out, 0)
+}
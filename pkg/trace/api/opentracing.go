@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// The helpers of this file are shared by the Zipkin and Jaeger endpoints, whose span tags
+// follow the OpenTracing semantic conventions.
+
+// readSpansBody reads the body of a Zipkin or Jaeger request, decompressing it when needed.
+func readSpansBody(req *http.Request, limit int64) ([]byte, error) {
+	var body io.ReadCloser = NewLimitedReader(req.Body, limit)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		// the limit applies to the decompressed payload as well
+		body = NewLimitedReader(gz, limit)
+	}
+	return ioutil.ReadAll(body)
+}
+
+// mediaType returns the media type of a request, without its parameters.
+func mediaType(req *http.Request) string {
+	mt, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mt
+}
+
+// submitSpans groups the spans by trace and sends them to the agent, replying 202 Accepted
+// as the Zipkin and Jaeger collectors do.
+func (r *HTTPReceiver) submitSpans(w http.ResponseWriter, tags info.Tags, spans []*pb.Span, size int) {
+	traces := groupByTraceID(spans)
+	ts := r.Stats.GetTagStats(tags)
+	if r.rateLimited(int64(len(traces))) {
+		w.WriteHeader(r.rateLimiterResponse)
+		atomic.AddInt64(&ts.PayloadRefused, 1)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	if len(traces) == 0 {
+		return
+	}
+
+	atomic.AddInt64(&ts.TracesReceived, int64(len(traces)))
+	atomic.AddInt64(&ts.TracesBytes, int64(size))
+	atomic.AddInt64(&ts.PayloadAccepted, 1)
+	r.sendPayload(&Payload{Source: ts, Traces: traces})
+}
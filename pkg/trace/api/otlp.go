@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/otlp/model"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// otlpEndpointVersion identifies the OTLP traces in the receiver stats
+const otlpEndpointVersion = "otlp"
+
+// handleOTLPTraces handles the OTLP traces forwarded by the OTLP receiver of the core agent,
+// the body is an ExportTraceServiceRequest protobuf message.
+func (r *HTTPReceiver) handleOTLPTraces(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	request := &model.TracesRequest{}
+	body, err := ioutil.ReadAll(NewLimitedReader(req.Body, r.conf.MaxRequestBytes))
+	if err == nil {
+		err = request.Unmarshal(body)
+	}
+	if err != nil {
+		httpDecodingError(err, []string{"handler:otlp_traces"}, w)
+		log.Errorf("Cannot decode OTLP traces payload: %v", err)
+		return
+	}
+
+	for _, rs := range request.ResourceSpans {
+		traces := convertOTLPResourceSpans(rs)
+		if len(traces) == 0 {
+			continue
+		}
+		ts := r.Stats.GetTagStats(otlpTags(rs.Resource))
+		if r.rateLimited(int64(len(traces))) {
+			atomic.AddInt64(&ts.PayloadRefused, 1)
+			continue
+		}
+		atomic.AddInt64(&ts.TracesReceived, int64(len(traces)))
+		atomic.AddInt64(&ts.PayloadAccepted, 1)
+		r.sendPayload(&Payload{Source: ts, Traces: traces})
+	}
+	httpOK(w)
+}
+
+// otlpTags returns the stats tags of the spans of a resource, from the telemetry.sdk attributes.
+func otlpTags(resource model.Resource) info.Tags {
+	tags := info.Tags{EndpointVersion: otlpEndpointVersion}
+	if v, ok := model.GetAttribute(resource.Attributes, "telemetry.sdk.language"); ok {
+		tags.Lang = v.AsString()
+	}
+	if v, ok := model.GetAttribute(resource.Attributes, "telemetry.sdk.version"); ok {
+		tags.TracerVersion = "otlp-" + v.AsString()
+	}
+	return tags
+}
+
+// convertOTLPResourceSpans converts the spans of a resource to traces, grouping them by trace ID.
+func convertOTLPResourceSpans(rs model.ResourceSpans) pb.Traces {
+	resourceMeta := make(map[string]string, len(rs.Resource.Attributes))
+	for _, kv := range rs.Resource.Attributes {
+		resourceMeta[kv.Key] = kv.Value.AsString()
+	}
+
+	var spans []*pb.Span
+	for _, ils := range rs.InstrumentationLibrarySpans {
+		for _, span := range ils.Spans {
+			spans = append(spans, convertOTLPSpan(span, resourceMeta, ils.InstrumentationLibrary))
+		}
+	}
+	return groupByTraceID(spans)
+}
+
+// otlpID returns the 64 lower bits of an OTLP trace or span ID.
+func otlpID(id []byte) uint64 {
+	if len(id) < 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(id[len(id)-8:])
+}
+
+// convertOTLPSpan converts an OTLP span to a Datadog span. The resource attributes of the
+// OpenTelemetry semantic conventions are mapped to the service, env and version of the span,
+// the string attributes are added to its meta and the numeric attributes to its metrics.
+func convertOTLPSpan(span model.Span, resourceMeta map[string]string, library model.InstrumentationLibrary) *pb.Span {
+	s := &pb.Span{
+		TraceID:  otlpID(span.TraceID),
+		SpanID:   otlpID(span.SpanID),
+		ParentID: otlpID(span.ParentSpanID),
+		Start:    int64(span.StartTimeUnixNano),
+		Meta:     make(map[string]string, len(resourceMeta)+len(span.Attributes)+4),
+		Metrics:  make(map[string]float64),
+	}
+	if span.EndTimeUnixNano > span.StartTimeUnixNano {
+		s.Duration = int64(span.EndTimeUnixNano - span.StartTimeUnixNano)
+	}
+
+	for k, v := range resourceMeta {
+		s.Meta[k] = v
+	}
+	for _, kv := range span.Attributes {
+		switch kv.Value.Type {
+		case model.ValueTypeInt:
+			s.Metrics[kv.Key] = float64(kv.Value.IntValue)
+		case model.ValueTypeDouble:
+			s.Metrics[kv.Key] = kv.Value.DoubleValue
+		default:
+			s.Meta[kv.Key] = kv.Value.AsString()
+		}
+	}
+
+	s.Service = resourceMeta["service.name"]
+	if s.Service == "" {
+		s.Service = defaultService
+	}
+	if env := resourceMeta["deployment.environment"]; env != "" {
+		s.Meta["env"] = env
+	}
+	if version := resourceMeta["service.version"]; version != "" {
+		s.Meta["version"] = version
+	}
+	if library.Name != "" {
+		s.Meta["otel.library.name"] = library.Name
+	}
+	if library.Version != "" {
+		s.Meta["otel.library.version"] = library.Version
+	}
+	if len(span.TraceID) == 16 {
+		// the 64 higher bits of the trace ID are lost in the conversion
+		s.Meta["otel.trace_id"] = hex.EncodeToString(span.TraceID)
+	}
+	kind := span.Kind.String()
+	s.Meta["span.kind"] = kind
+
+	component := library.Name
+	if component == "" {
+		component = "opentelemetry"
+	}
+	s.Name = spanName(component, kind)
+	operation := span.Name
+	if operation == "" {
+		operation = spanName("opentelemetry", kind)
+	}
+	s.Resource = spanResource(operation, kind, s.Meta)
+	s.Type = spanType(kind, s.Meta)
+
+	switch span.Status.Code {
+	case model.StatusCodeOk:
+		s.Meta["otel.status_code"] = "Ok"
+	case model.StatusCodeError:
+		s.Meta["otel.status_code"] = "Error"
+		s.Error = 1
+		if span.Status.Message != "" {
+			s.Meta["error.msg"] = span.Status.Message
+		}
+		for _, event := range span.Events {
+			if event.Name != "exception" {
+				continue
+			}
+			for _, kv := range event.Attributes {
+				switch kv.Key {
+				case "exception.type":
+					s.Meta["error.type"] = kv.Value.AsString()
+				case "exception.message":
+					s.Meta["error.msg"] = kv.Value.AsString()
+				case "exception.stacktrace":
+					s.Meta["error.stack"] = kv.Value.AsString()
+				}
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/otlp/model"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func otlpString(key, value string) model.KeyValue {
+	return model.KeyValue{Key: key, Value: model.AnyValue{Type: model.ValueTypeString, StringValue: value}}
+}
+
+var otlpTraceID = []byte{0x80, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2}
+
+func testOTLPResourceSpans() model.ResourceSpans {
+	return model.ResourceSpans{
+		Resource: model.Resource{Attributes: []model.KeyValue{
+			otlpString("service.name", "checkout"),
+			otlpString("deployment.environment", "prod"),
+			otlpString("service.version", "1.2.3"),
+			otlpString("telemetry.sdk.language", "go"),
+			otlpString("telemetry.sdk.version", "0.16.0"),
+		}},
+		InstrumentationLibrarySpans: []model.InstrumentationLibrarySpans{{
+			InstrumentationLibrary: model.InstrumentationLibrary{Name: "otelhttp", Version: "0.16.0"},
+			Spans: []model.Span{
+				{
+					TraceID:           otlpTraceID,
+					SpanID:            []byte{0, 0, 0, 0, 0, 0, 0, 3},
+					Name:              "HTTP GET",
+					Kind:              model.SpanKindServer,
+					StartTimeUnixNano: 1000,
+					EndTimeUnixNano:   3000,
+					Attributes: []model.KeyValue{
+						otlpString("http.method", "GET"),
+						otlpString("http.route", "/cart/:id"),
+						{Key: "http.status_code", Value: model.AnyValue{Type: model.ValueTypeInt, IntValue: 500}},
+						{Key: "http.sampled", Value: model.AnyValue{Type: model.ValueTypeBool, BoolValue: true}},
+					},
+					Events: []model.Event{{
+						Name: "exception",
+						Attributes: []model.KeyValue{
+							otlpString("exception.type", "TimeoutError"),
+							otlpString("exception.message", "timed out"),
+							otlpString("exception.stacktrace", "main.go:12"),
+						},
+					}},
+					Status: model.Status{Code: model.StatusCodeError, Message: "failed"},
+				},
+				{
+					TraceID:           otlpTraceID,
+					SpanID:            []byte{0, 0, 0, 0, 0, 0, 0, 4},
+					ParentSpanID:      []byte{0, 0, 0, 0, 0, 0, 0, 3},
+					Name:              "SELECT",
+					Kind:              model.SpanKindClient,
+					StartTimeUnixNano: 1500,
+					EndTimeUnixNano:   2500,
+					Attributes: []model.KeyValue{
+						otlpString("db.system", "postgresql"),
+						otlpString("db.statement", "SELECT * FROM carts"),
+					},
+					Status: model.Status{Code: model.StatusCodeOk},
+				},
+			},
+		}},
+	}
+}
+
+func TestConvertOTLPResourceSpans(t *testing.T) {
+	traces := convertOTLPResourceSpans(testOTLPResourceSpans())
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0], 2)
+
+	assert.Equal(t, &pb.Span{
+		Service:  "checkout",
+		Name:     "otelhttp.server",
+		Resource: "GET /cart/:id",
+		TraceID:  2,
+		SpanID:   3,
+		Start:    1000,
+		Duration: 2000,
+		Error:    1,
+		Type:     "web",
+		Meta: map[string]string{
+			"service.name":           "checkout",
+			"deployment.environment": "prod",
+			"service.version":        "1.2.3",
+			"telemetry.sdk.language": "go",
+			"telemetry.sdk.version":  "0.16.0",
+			"env":                    "prod",
+			"version":                "1.2.3",
+			"http.method":            "GET",
+			"http.route":             "/cart/:id",
+			"http.sampled":           "true",
+			"otel.library.name":      "otelhttp",
+			"otel.library.version":   "0.16.0",
+			"otel.trace_id":          "80000000000000010000000000000002",
+			"otel.status_code":       "Error",
+			"span.kind":              "server",
+			"error.type":             "TimeoutError",
+			"error.msg":              "timed out",
+			"error.stack":            "main.go:12",
+		},
+		Metrics: map[string]float64{"http.status_code": 500},
+	}, traces[0][0])
+
+	db := traces[0][1]
+	assert.Equal(t, uint64(3), db.ParentID)
+	assert.Equal(t, "otelhttp.client", db.Name)
+	assert.Equal(t, "SELECT * FROM carts", db.Resource)
+	assert.Equal(t, "db", db.Type)
+	assert.Equal(t, int32(0), db.Error)
+	assert.Equal(t, "Ok", db.Meta["otel.status_code"])
+}
+
+func TestConvertOTLPSpanDefaults(t *testing.T) {
+	s := convertOTLPSpan(model.Span{
+		TraceID:           []byte{0, 0, 0, 0, 0, 0, 0, 5},
+		SpanID:            []byte{0, 0, 0, 0, 0, 0, 0, 6},
+		StartTimeUnixNano: 2000,
+		EndTimeUnixNano:   1000,
+	}, map[string]string{}, model.InstrumentationLibrary{})
+
+	assert.Equal(t, uint64(5), s.TraceID)
+	assert.Equal(t, defaultService, s.Service)
+	assert.Equal(t, "opentelemetry.unspecified", s.Name)
+	assert.Equal(t, "opentelemetry.unspecified", s.Resource)
+	assert.Equal(t, "custom", s.Type)
+	assert.Equal(t, int64(0), s.Duration)
+	assert.NotContains(t, s.Meta, "otel.trace_id")
+
+	s = convertOTLPSpan(model.Span{
+		Kind:       model.SpanKindClient,
+		Attributes: []model.KeyValue{otlpString("db.system", "redis")},
+	}, map[string]string{}, model.InstrumentationLibrary{})
+	assert.Equal(t, "cache", s.Type)
+
+	s = convertOTLPSpan(model.Span{
+		Kind:       model.SpanKindClient,
+		Name:       "HTTP POST",
+		Attributes: []model.KeyValue{otlpString("http.method", "POST")},
+	}, map[string]string{}, model.InstrumentationLibrary{})
+	assert.Equal(t, "http", s.Type)
+	assert.Equal(t, "HTTP POST", s.Resource)
+}
+
+func TestHandleOTLPTraces(t *testing.T) {
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+	request := &model.TracesRequest{ResourceSpans: []model.ResourceSpans{testOTLPResourceSpans()}}
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/v0.1/otlp/traces", bytes.NewReader(request.Marshal()))
+	require.NoError(t, err)
+	r.handleOTLPTraces(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	require.Len(t, r.out, 1)
+	payload := <-r.out
+	assert.Len(t, payload.Traces, 1)
+	assert.Equal(t, "go", payload.Source.Lang)
+	assert.Equal(t, "otlp-0.16.0", payload.Source.TracerVersion)
+	assert.Equal(t, int64(1), payload.Source.TracesReceived)
+
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodPost, "/v0.1/otlp/traces", bytes.NewReader([]byte{0x0a, 0x05}))
+	require.NoError(t, err)
+	r.handleOTLPTraces(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Len(t, r.out, 0)
+}
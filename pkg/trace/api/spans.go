@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// The helpers of this file are shared by the OTLP, Zipkin and Jaeger endpoints, which convert
+// spans tagged with the OpenTelemetry or OpenTracing semantic conventions to Datadog spans.
+
+// defaultService is the service of the spans sent without a service name.
+const defaultService = "unknown_service"
+
+// groupByTraceID groups spans by trace ID, keeping the order in which the traces are first seen.
+func groupByTraceID(spans []*pb.Span) pb.Traces {
+	var traces pb.Traces
+	byID := make(map[uint64]int)
+	for _, s := range spans {
+		i, found := byID[s.TraceID]
+		if !found {
+			i = len(traces)
+			byID[s.TraceID] = i
+			traces = append(traces, pb.Trace{})
+		}
+		traces[i] = append(traces[i], s)
+	}
+	return traces
+}
+
+// spanName returns the operation name of a span, made of its component and its kind.
+func spanName(component, kind string) string {
+	return component + "." + kind
+}
+
+// spanResource returns the resource of a span, the HTTP method and route for the HTTP spans,
+// the statement for the database spans, the operation and destination for the messaging spans
+// and the given operation name otherwise.
+func spanResource(operation, kind string, meta map[string]string) string {
+	if method := meta["http.method"]; method != "" {
+		if route := meta["http.route"]; route != "" {
+			return method + " " + route
+		}
+		if kind == "server" {
+			return method
+		}
+	}
+	if statement := meta["db.statement"]; statement != "" {
+		return statement
+	}
+	if messagingOperation := meta["messaging.operation"]; messagingOperation != "" {
+		if destination := meta["messaging.destination"]; destination != "" {
+			return messagingOperation + " " + destination
+		}
+	}
+	return operation
+}
+
+// spanType returns the type of a span from its kind and its tags.
+func spanType(kind string, meta map[string]string) string {
+	switch kind {
+	case "server":
+		if meta["http.method"] != "" {
+			return "web"
+		}
+	case "client":
+		dbType := meta["db.type"]
+		if dbType == "" {
+			dbType = meta["db.system"]
+		}
+		switch strings.ToLower(dbType) {
+		case "":
+		case "redis", "memcached":
+			return "cache"
+		default:
+			return "db"
+		}
+		if meta["http.method"] != "" {
+			return "http"
+		}
+	}
+	return "custom"
+}
@@ -0,0 +1,265 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Thrift types, as encoded by the binary protocol.
+const (
+	thriftTypeStop   byte = 0
+	thriftTypeBool   byte = 2
+	thriftTypeByte   byte = 3
+	thriftTypeDouble byte = 4
+	thriftTypeI16    byte = 6
+	thriftTypeI32    byte = 8
+	thriftTypeI64    byte = 10
+	thriftTypeString byte = 11
+	thriftTypeStruct byte = 12
+	thriftTypeMap    byte = 13
+	thriftTypeSet    byte = 14
+	thriftTypeList   byte = 15
+)
+
+// thriftMaxDepth is the maximum nesting of the skipped values.
+const thriftMaxDepth = 64
+
+var errThriftTruncated = errors.New("truncated thrift payload")
+
+// thriftReader decodes values encoded with the Thrift binary protocol, as sent by the Jaeger clients.
+type thriftReader struct {
+	data []byte
+}
+
+func (r *thriftReader) next(n int) ([]byte, error) {
+	if n < 0 || n > len(r.data) {
+		return nil, errThriftTruncated
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b, nil
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *thriftReader) readI16() (int16, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.BigEndian.Uint16(b)), nil
+}
+
+func (r *thriftReader) readI32() (int32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (r *thriftReader) readI64() (int64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	n, err := r.readI32()
+	if err != nil {
+		return nil, err
+	}
+	return r.next(int(n))
+}
+
+// readStruct reads the fields of a struct until its stop field, calling fn for each of them.
+// fn must read or skip the value of the field.
+func (r *thriftReader) readStruct(fn func(typ byte, id int16) error) error {
+	for {
+		typ, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if typ == thriftTypeStop {
+			return nil
+		}
+		id, err := r.readI16()
+		if err != nil {
+			return err
+		}
+		if err := fn(typ, id); err != nil {
+			return err
+		}
+	}
+}
+
+// readList reads a list of elements of the given type, calling fn for each of them.
+func (r *thriftReader) readList(typ, elemType byte, fn func() error) error {
+	if typ != thriftTypeList {
+		return thriftTypeError(typ, thriftTypeList)
+	}
+	t, size, err := r.readCollectionHeader()
+	if err != nil {
+		return err
+	}
+	if t != elemType {
+		return thriftTypeError(t, elemType)
+	}
+	for i := 0; i < size; i++ {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCollectionHeader reads the element type and the size of a list or a set.
+func (r *thriftReader) readCollectionHeader() (byte, int, error) {
+	typ, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	size, err := r.readI32()
+	if err != nil {
+		return 0, 0, err
+	}
+	// every element is encoded with one byte at least
+	if size < 0 || int(size) > len(r.data) {
+		return 0, 0, errThriftTruncated
+	}
+	return typ, int(size), nil
+}
+
+func (r *thriftReader) bool(typ byte, v *bool) error {
+	if typ != thriftTypeBool {
+		return thriftTypeError(typ, thriftTypeBool)
+	}
+	b, err := r.readByte()
+	*v = b != 0
+	return err
+}
+
+func (r *thriftReader) i32(typ byte, v *int32) error {
+	if typ != thriftTypeI32 {
+		return thriftTypeError(typ, thriftTypeI32)
+	}
+	var err error
+	*v, err = r.readI32()
+	return err
+}
+
+func (r *thriftReader) i64(typ byte, v *int64) error {
+	if typ != thriftTypeI64 {
+		return thriftTypeError(typ, thriftTypeI64)
+	}
+	var err error
+	*v, err = r.readI64()
+	return err
+}
+
+func (r *thriftReader) double(typ byte, v *float64) error {
+	if typ != thriftTypeDouble {
+		return thriftTypeError(typ, thriftTypeDouble)
+	}
+	i, err := r.readI64()
+	*v = math.Float64frombits(uint64(i))
+	return err
+}
+
+func (r *thriftReader) binary(typ byte, v *[]byte) error {
+	if typ != thriftTypeString {
+		return thriftTypeError(typ, thriftTypeString)
+	}
+	var err error
+	*v, err = r.readBinary()
+	return err
+}
+
+func (r *thriftReader) string(typ byte, v *string) error {
+	var b []byte
+	err := r.binary(typ, &b)
+	*v = string(b)
+	return err
+}
+
+// skip skips a value of the given type.
+func (r *thriftReader) skip(typ byte) error {
+	return r.skipDepth(typ, 0)
+}
+
+func (r *thriftReader) skipDepth(typ byte, depth int) error {
+	if depth > thriftMaxDepth {
+		return errors.New("thrift payload nested too deeply")
+	}
+	var err error
+	switch typ {
+	case thriftTypeBool, thriftTypeByte:
+		_, err = r.next(1)
+	case thriftTypeI16:
+		_, err = r.next(2)
+	case thriftTypeI32:
+		_, err = r.next(4)
+	case thriftTypeDouble, thriftTypeI64:
+		_, err = r.next(8)
+	case thriftTypeString:
+		_, err = r.readBinary()
+	case thriftTypeStruct:
+		err = r.readStruct(func(typ byte, _ int16) error {
+			return r.skipDepth(typ, depth+1)
+		})
+	case thriftTypeMap:
+		err = r.skipMap(depth)
+	case thriftTypeSet, thriftTypeList:
+		var elemType byte
+		var size int
+		elemType, size, err = r.readCollectionHeader()
+		for i := 0; err == nil && i < size; i++ {
+			err = r.skipDepth(elemType, depth+1)
+		}
+	default:
+		err = fmt.Errorf("unknown thrift type %d", typ)
+	}
+	return err
+}
+
+func (r *thriftReader) skipMap(depth int) error {
+	types, err := r.next(2)
+	if err != nil {
+		return err
+	}
+	size, err := r.readI32()
+	if err != nil {
+		return err
+	}
+	if size < 0 || int(size) > len(r.data) {
+		return errThriftTruncated
+	}
+	for i := 0; i < int(size); i++ {
+		if err := r.skipDepth(types[0], depth+1); err != nil {
+			return err
+		}
+		if err := r.skipDepth(types[1], depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func thriftTypeError(typ, expected byte) error {
+	return fmt.Errorf("invalid thrift type %d, expected %d", typ, expected)
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// thriftWriter encodes values with the Thrift binary protocol.
+type thriftWriter struct {
+	data []byte
+}
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.data = append(w.data, typ)
+	w.uint16(uint16(id))
+}
+
+func (w *thriftWriter) uint16(v uint16) {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, v)
+	w.data = append(w.data, b...)
+}
+
+func (w *thriftWriter) uint32(v uint32) {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	w.data = append(w.data, b...)
+}
+
+func (w *thriftWriter) uint64(v uint64) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	w.data = append(w.data, b...)
+}
+
+func (w *thriftWriter) stop() {
+	w.data = append(w.data, thriftTypeStop)
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftTypeI32, id)
+	w.uint32(uint32(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftTypeI64, id)
+	w.uint64(uint64(v))
+}
+
+func (w *thriftWriter) double(id int16, v float64) {
+	w.field(thriftTypeDouble, id)
+	w.uint64(math.Float64bits(v))
+}
+
+func (w *thriftWriter) bool(id int16, v bool) {
+	w.field(thriftTypeBool, id)
+	if v {
+		w.data = append(w.data, 1)
+	} else {
+		w.data = append(w.data, 0)
+	}
+}
+
+func (w *thriftWriter) string(id int16, v string) {
+	w.field(thriftTypeString, id)
+	w.uint32(uint32(len(v)))
+	w.data = append(w.data, v...)
+}
+
+// list writes the header of a list of structs, fn must write each of them.
+func (w *thriftWriter) list(id int16, size int, fn func(i int)) {
+	w.field(thriftTypeList, id)
+	w.data = append(w.data, thriftTypeStruct)
+	w.uint32(uint32(size))
+	for i := 0; i < size; i++ {
+		fn(i)
+	}
+}
+
+func TestThriftReaderSkip(t *testing.T) {
+	w := &thriftWriter{}
+	w.i64(1, 42)
+	w.double(2, 0.5)
+	w.bool(3, true)
+	w.string(4, "skipped")
+	w.list(5, 2, func(i int) {
+		w.i32(1, int32(i))
+		w.stop()
+	})
+	// map<string, i32> with one entry
+	w.field(thriftTypeMap, 6)
+	w.data = append(w.data, thriftTypeString, thriftTypeI32, 0, 0, 0, 1, 0, 0, 0, 1, 'k', 0, 0, 0, 7)
+	w.string(7, "kept")
+	w.stop()
+
+	var kept string
+	r := &thriftReader{data: w.data}
+	err := r.readStruct(func(typ byte, id int16) error {
+		if id == 7 {
+			return r.string(typ, &kept)
+		}
+		return r.skip(typ)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kept", kept)
+	assert.Empty(t, r.data)
+}
+
+func TestThriftReaderInvalid(t *testing.T) {
+	w := &thriftWriter{}
+	w.string(1, "value")
+	w.stop()
+
+	var v string
+	r := &thriftReader{data: w.data[:len(w.data)-3]}
+	err := r.readStruct(func(typ byte, id int16) error { return r.string(typ, &v) })
+	assert.Equal(t, errThriftTruncated, err)
+
+	// the field types are checked
+	var i int64
+	r = &thriftReader{data: w.data}
+	assert.Error(t, r.readStruct(func(typ byte, id int16) error { return r.i64(typ, &i) }))
+
+	// the list sizes can not exceed the payload size
+	r = &thriftReader{data: []byte{thriftTypeList, 0, 1, thriftTypeI32, 0x7f, 0xff, 0xff, 0xff}}
+	assert.Equal(t, errThriftTruncated, r.readStruct(func(typ byte, id int16) error { return r.skip(typ) }))
+
+	r = &thriftReader{data: []byte{42, 0, 1}}
+	assert.Error(t, r.readStruct(func(typ byte, id int16) error { return r.skip(typ) }))
+}
@@ -0,0 +1,347 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/protowire"
+)
+
+// zipkinEndpointVersion identifies the Zipkin spans in the receiver stats
+const zipkinEndpointVersion = "zipkin-v2"
+
+// zipkinSpan is a span of the Zipkin v2 API.
+type zipkinSpan struct {
+	TraceID        string            `json:"traceId"`
+	ParentID       string            `json:"parentId"`
+	ID             string            `json:"id"`
+	Kind           string            `json:"kind"`
+	Name           string            `json:"name"`
+	Timestamp      uint64            `json:"timestamp"`
+	Duration       uint64            `json:"duration"`
+	LocalEndpoint  *zipkinEndpoint   `json:"localEndpoint"`
+	RemoteEndpoint *zipkinEndpoint   `json:"remoteEndpoint"`
+	Tags           map[string]string `json:"tags"`
+}
+
+// zipkinEndpoint is the network context of a node in the service graph.
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int    `json:"port"`
+}
+
+// handleZipkinSpans handles the spans sent to the Zipkin v2 API, encoded as a JSON list
+// or as a ListOfSpans protobuf message.
+func (r *HTTPReceiver) handleZipkinSpans(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	mt := mediaType(req)
+	if mt != "" && mt != "application/json" && mt != "application/x-protobuf" {
+		http.Error(w, "unsupported media type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var spans []zipkinSpan
+	body, err := readSpansBody(req, r.conf.MaxRequestBytes)
+	if err == nil {
+		if mt == "application/x-protobuf" {
+			spans, err = unmarshalZipkinSpans(body)
+		} else {
+			err = json.Unmarshal(body, &spans)
+		}
+	}
+	var converted []*pb.Span
+	if err == nil {
+		converted, err = convertZipkinSpans(spans)
+	}
+	if err != nil {
+		httpDecodingError(err, []string{"handler:zipkin_spans"}, w)
+		log.Errorf("Cannot decode Zipkin spans payload: %v", err)
+		return
+	}
+	r.submitSpans(w, info.Tags{EndpointVersion: zipkinEndpointVersion}, converted, len(body))
+}
+
+// convertZipkinSpans converts Zipkin spans to Datadog spans.
+func convertZipkinSpans(spans []zipkinSpan) ([]*pb.Span, error) {
+	converted := make([]*pb.Span, 0, len(spans))
+	for _, span := range spans {
+		s, err := convertZipkinSpan(span)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, s)
+	}
+	return converted, nil
+}
+
+// convertZipkinSpan converts a Zipkin span to a Datadog span. The tags of the span are added to
+// its meta, its timestamps are converted from microseconds to nanoseconds.
+func convertZipkinSpan(span zipkinSpan) (*pb.Span, error) {
+	traceID, err := parseZipkinID(span.TraceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace ID %q: %v", span.TraceID, err)
+	}
+	spanID, err := parseZipkinID(span.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span ID %q: %v", span.ID, err)
+	}
+	s := &pb.Span{
+		TraceID:  traceID,
+		SpanID:   spanID,
+		Start:    int64(span.Timestamp) * 1000,
+		Duration: int64(span.Duration) * 1000,
+		Service:  defaultService,
+		Meta:     make(map[string]string, len(span.Tags)+4),
+		Metrics:  make(map[string]float64),
+	}
+	if span.ParentID != "" {
+		if s.ParentID, err = parseZipkinID(span.ParentID); err != nil {
+			return nil, fmt.Errorf("invalid parent ID %q: %v", span.ParentID, err)
+		}
+	}
+
+	for k, v := range span.Tags {
+		s.Meta[k] = v
+	}
+	if len(span.TraceID) > 16 {
+		// the 64 higher bits of the trace ID are lost in the conversion
+		s.Meta["zipkin.trace_id"] = strings.ToLower(span.TraceID)
+	}
+	if e := span.LocalEndpoint; e != nil && e.ServiceName != "" {
+		s.Service = e.ServiceName
+	}
+	if e := span.RemoteEndpoint; e != nil {
+		if e.ServiceName != "" {
+			s.Meta["peer.service"] = e.ServiceName
+		}
+		if e.IPv4 != "" {
+			s.Meta["peer.ipv4"] = e.IPv4
+		}
+		if e.IPv6 != "" {
+			s.Meta["peer.ipv6"] = e.IPv6
+		}
+		if e.Port != 0 {
+			s.Metrics["peer.port"] = float64(e.Port)
+		}
+	}
+
+	kind := strings.ToLower(span.Kind)
+	if kind == "" {
+		kind = "internal"
+	}
+	s.Meta["span.kind"] = kind
+	component := span.Tags["component"]
+	if component == "" {
+		component = "zipkin"
+	}
+	s.Name = spanName(component, kind)
+	s.Resource = spanResource(span.Name, kind, s.Meta)
+	if s.Resource == "" {
+		s.Resource = s.Name
+	}
+	s.Type = spanType(kind, s.Meta)
+
+	// the error tag is set on failed spans, its value being the error message when known
+	if msg, ok := span.Tags["error"]; ok {
+		s.Error = 1
+		if msg != "" && msg != "true" {
+			s.Meta["error.msg"] = msg
+		}
+	}
+	return s, nil
+}
+
+// parseZipkinID parses a hex encoded Zipkin ID, returning the 64 lower bits of the 128-bit trace IDs.
+func parseZipkinID(id string) (uint64, error) {
+	if id == "" || len(id) > 32 {
+		return 0, fmt.Errorf("invalid length %d", len(id))
+	}
+	if len(id) > 16 {
+		id = id[len(id)-16:]
+	}
+	return strconv.ParseUint(id, 16, 64)
+}
+
+// zipkinKinds are the span kinds of the Zipkin protobuf messages, indexed by value.
+var zipkinKinds = []string{"", "CLIENT", "SERVER", "PRODUCER", "CONSUMER"}
+
+// unmarshalZipkinSpans decodes a ListOfSpans protobuf message of the Zipkin v2 API.
+func unmarshalZipkinSpans(data []byte) ([]zipkinSpan, error) {
+	var spans []zipkinSpan
+	err := decodeZipkinMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		if field != 1 {
+			return d.Skip(wireType)
+		}
+		msg, err := zipkinBytes(d, field, wireType)
+		if err != nil {
+			return err
+		}
+		var span zipkinSpan
+		if err := span.unmarshal(msg); err != nil {
+			return fmt.Errorf("invalid span: %v", err)
+		}
+		spans = append(spans, span)
+		return nil
+	})
+	return spans, err
+}
+
+func (s *zipkinSpan) unmarshal(data []byte) error {
+	return decodeZipkinMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1, 2, 3:
+			id, err := zipkinBytes(d, field, wireType)
+			if err != nil {
+				return err
+			}
+			ids := []*string{&s.TraceID, &s.ParentID, &s.ID}
+			*ids[field-1] = hex.EncodeToString(id)
+		case 4:
+			kind, err := zipkinVarint(d, field, wireType)
+			if err != nil {
+				return err
+			}
+			if kind < uint64(len(zipkinKinds)) {
+				s.Kind = zipkinKinds[kind]
+			}
+		case 5:
+			name, err := zipkinBytes(d, field, wireType)
+			if err != nil {
+				return err
+			}
+			s.Name = string(name)
+		case 6:
+			if err := protowire.Expect(field, wireType, protowire.WireFixed64); err != nil {
+				return err
+			}
+			v, err := d.Fixed64()
+			if err != nil {
+				return err
+			}
+			s.Timestamp = v
+		case 7:
+			v, err := zipkinVarint(d, field, wireType)
+			if err != nil {
+				return err
+			}
+			s.Duration = v
+		case 8, 9:
+			msg, err := zipkinBytes(d, field, wireType)
+			if err != nil {
+				return err
+			}
+			e := &zipkinEndpoint{}
+			if err := e.unmarshal(msg); err != nil {
+				return fmt.Errorf("invalid endpoint: %v", err)
+			}
+			if field == 8 {
+				s.LocalEndpoint = e
+			} else {
+				s.RemoteEndpoint = e
+			}
+		case 11:
+			msg, err := zipkinBytes(d, field, wireType)
+			if err != nil {
+				return err
+			}
+			var kv [2][]byte
+			err = decodeZipkinMessage(msg, func(d *protowire.Decoder, field, wireType int) error {
+				if field != 1 && field != 2 {
+					return d.Skip(wireType)
+				}
+				var err error
+				kv[field-1], err = zipkinBytes(d, field, wireType)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("invalid tag: %v", err)
+			}
+			if s.Tags == nil {
+				s.Tags = make(map[string]string)
+			}
+			s.Tags[string(kv[0])] = string(kv[1])
+		default:
+			// the annotations, debug and shared fields are not used
+			return d.Skip(wireType)
+		}
+		return nil
+	})
+}
+
+func (e *zipkinEndpoint) unmarshal(data []byte) error {
+	return decodeZipkinMessage(data, func(d *protowire.Decoder, field, wireType int) error {
+		switch field {
+		case 1:
+			name, err := zipkinBytes(d, field, wireType)
+			if err != nil {
+				return err
+			}
+			e.ServiceName = string(name)
+		case 2, 3:
+			ip, err := zipkinBytes(d, field, wireType)
+			if err != nil {
+				return err
+			}
+			if field == 2 {
+				e.IPv4 = net.IP(ip).String()
+			} else {
+				e.IPv6 = net.IP(ip).String()
+			}
+		case 4:
+			port, err := zipkinVarint(d, field, wireType)
+			if err != nil {
+				return err
+			}
+			e.Port = int(port)
+		default:
+			return d.Skip(wireType)
+		}
+		return nil
+	})
+}
+
+func decodeZipkinMessage(data []byte, fn func(d *protowire.Decoder, field, wireType int) error) error {
+	d := protowire.NewDecoder(data)
+	for !d.Done() {
+		field, wireType, err := d.Key()
+		if err != nil {
+			return err
+		}
+		if err := fn(d, field, wireType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func zipkinBytes(d *protowire.Decoder, field, wireType int) ([]byte, error) {
+	if err := protowire.Expect(field, wireType, protowire.WireBytes); err != nil {
+		return nil, err
+	}
+	return d.Bytes()
+}
+
+func zipkinVarint(d *protowire.Decoder, field, wireType int) (uint64, error) {
+	if err := protowire.Expect(field, wireType, protowire.WireVarint); err != nil {
+		return 0, err
+	}
+	return d.Varint()
+}
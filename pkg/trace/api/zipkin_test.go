@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/util/protowire"
+)
+
+const zipkinJSONSpans = `[
+  {
+    "traceId": "80000000000000010000000000000002",
+    "id": "0000000000000003",
+    "kind": "SERVER",
+    "name": "get /cart",
+    "timestamp": 1612345678000000,
+    "duration": 2000,
+    "localEndpoint": {"serviceName": "checkout", "ipv4": "10.0.0.1"},
+    "tags": {"http.method": "GET", "http.route": "/cart/{id}", "http.status_code": "500", "error": "timed out"}
+  },
+  {
+    "traceId": "80000000000000010000000000000002",
+    "parentId": "0000000000000003",
+    "id": "0000000000000004",
+    "kind": "CLIENT",
+    "name": "query",
+    "timestamp": 1612345678000500,
+    "duration": 1000,
+    "localEndpoint": {"serviceName": "checkout"},
+    "remoteEndpoint": {"serviceName": "postgres", "ipv4": "10.0.0.2", "port": 5432},
+    "tags": {"db.type": "sql", "db.statement": "SELECT * FROM carts"}
+  },
+  {
+    "traceId": "5",
+    "id": "6",
+    "name": "cleanup"
+  }
+]`
+
+func TestConvertZipkinSpans(t *testing.T) {
+	var spans []zipkinSpan
+	require.NoError(t, json.Unmarshal([]byte(zipkinJSONSpans), &spans))
+	converted, err := convertZipkinSpans(spans)
+	require.NoError(t, err)
+	require.Len(t, converted, 3)
+
+	assert.Equal(t, &pb.Span{
+		Service:  "checkout",
+		Name:     "zipkin.server",
+		Resource: "GET /cart/{id}",
+		TraceID:  2,
+		SpanID:   3,
+		Start:    1612345678000000000,
+		Duration: 2000000,
+		Error:    1,
+		Type:     "web",
+		Meta: map[string]string{
+			"http.method":      "GET",
+			"http.route":       "/cart/{id}",
+			"http.status_code": "500",
+			"error":            "timed out",
+			"error.msg":        "timed out",
+			"span.kind":        "server",
+			"zipkin.trace_id":  "80000000000000010000000000000002",
+		},
+		Metrics: map[string]float64{},
+	}, converted[0])
+
+	db := converted[1]
+	assert.Equal(t, uint64(3), db.ParentID)
+	assert.Equal(t, "zipkin.client", db.Name)
+	assert.Equal(t, "SELECT * FROM carts", db.Resource)
+	assert.Equal(t, "db", db.Type)
+	assert.Equal(t, "postgres", db.Meta["peer.service"])
+	assert.Equal(t, "10.0.0.2", db.Meta["peer.ipv4"])
+	assert.Equal(t, float64(5432), db.Metrics["peer.port"])
+	assert.Equal(t, int32(0), db.Error)
+
+	local := converted[2]
+	assert.Equal(t, uint64(5), local.TraceID)
+	assert.Equal(t, defaultService, local.Service)
+	assert.Equal(t, "zipkin.internal", local.Name)
+	assert.Equal(t, "cleanup", local.Resource)
+	assert.Equal(t, "custom", local.Type)
+	assert.NotContains(t, local.Meta, "zipkin.trace_id")
+
+	for _, id := range []string{"", "xyz", "800000000000000100000000000000020"} {
+		_, err := convertZipkinSpans([]zipkinSpan{{TraceID: id, ID: "1"}})
+		assert.Error(t, err, id)
+	}
+}
+
+func marshalZipkinSpan(span zipkinSpan) *protowire.Encoder {
+	e := &protowire.Encoder{}
+	e.Bytes(1, []byte{0, 0, 0, 0, 0, 0, 0, 7})
+	e.Bytes(3, []byte{0, 0, 0, 0, 0, 0, 0, 8})
+	e.Key(4, protowire.WireVarint)
+	e.Varint(2)
+	e.String(5, span.Name)
+	e.Key(6, protowire.WireFixed64)
+	e.Fixed64(span.Timestamp)
+	e.Key(7, protowire.WireVarint)
+	e.Varint(span.Duration)
+	local := &protowire.Encoder{}
+	local.String(1, span.LocalEndpoint.ServiceName)
+	local.Bytes(2, []byte{10, 0, 0, 1})
+	e.Message(8, local)
+	for k, v := range span.Tags {
+		tag := &protowire.Encoder{}
+		tag.String(1, k)
+		tag.String(2, v)
+		e.Message(11, tag)
+	}
+	// the annotations are skipped
+	annotation := &protowire.Encoder{}
+	annotation.String(2, "ws")
+	e.Message(10, annotation)
+	return e
+}
+
+func TestUnmarshalZipkinSpans(t *testing.T) {
+	e := &protowire.Encoder{}
+	e.Message(1, marshalZipkinSpan(zipkinSpan{
+		Name:          "get",
+		Timestamp:     1000,
+		Duration:      20,
+		LocalEndpoint: &zipkinEndpoint{ServiceName: "web"},
+		Tags:          map[string]string{"http.method": "GET"},
+	}))
+
+	spans, err := unmarshalZipkinSpans(e.Data())
+	require.NoError(t, err)
+	assert.Equal(t, []zipkinSpan{{
+		TraceID:       "0000000000000007",
+		ID:            "0000000000000008",
+		Kind:          "SERVER",
+		Name:          "get",
+		Timestamp:     1000,
+		Duration:      20,
+		LocalEndpoint: &zipkinEndpoint{ServiceName: "web", IPv4: "10.0.0.1"},
+		Tags:          map[string]string{"http.method": "GET"},
+	}}, spans)
+
+	_, err = unmarshalZipkinSpans(e.Data()[:len(e.Data())-1])
+	assert.Error(t, err)
+}
+
+func TestHandleZipkinSpans(t *testing.T) {
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader([]byte(zipkinJSONSpans)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	r.handleZipkinSpans(rr, req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+
+	require.Len(t, r.out, 1)
+	payload := <-r.out
+	assert.Len(t, payload.Traces, 2)
+	assert.Equal(t, zipkinEndpointVersion, payload.Source.EndpointVersion)
+	assert.Equal(t, int64(2), payload.Source.TracesReceived)
+
+	// the payloads can be compressed
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(zipkinJSONSpans))
+	gz.Close()
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodPost, "/api/v2/spans", &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+	r.handleZipkinSpans(rr, req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	require.Len(t, r.out, 1)
+	<-r.out
+
+	e := &protowire.Encoder{}
+	e.Message(1, marshalZipkinSpan(zipkinSpan{Name: "get", LocalEndpoint: &zipkinEndpoint{ServiceName: "web"}}))
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader(e.Data()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	r.handleZipkinSpans(rr, req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	require.Len(t, r.out, 1)
+	payload = <-r.out
+	assert.Equal(t, "web", payload.Traces[0][0].Service)
+
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader([]byte(`[{"traceId": "z"}]`)))
+	require.NoError(t, err)
+	r.handleZipkinSpans(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader(nil))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-thrift")
+	r.handleZipkinSpans(rr, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	assert.Len(t, r.out, 0)
+}
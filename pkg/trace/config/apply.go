@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -53,6 +54,50 @@ type ObfuscationConfig struct {
 	// Memcached holds the configuration for obfuscating the "memcached.command" tag
 	// for spans of type "memcached".
 	Memcached Enablable `mapstructure:"memcached"`
+
+	// SensitiveData holds the configuration for scanning the span tags for sensitive data.
+	SensitiveData SensitiveDataConfig `mapstructure:"sensitive_data"`
+}
+
+// SensitiveDataConfig holds the configuration of the sensitive data scanner, which redacts or
+// hashes the values matched by its detectors in the span tags of any type.
+type SensitiveDataConfig struct {
+	// Enabled specifies whether the span tags are scanned.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Detectors lists the built-in detectors to run, out of SensitiveDataDetectors.
+	// All of them run when empty.
+	Detectors []string `mapstructure:"detectors"`
+
+	// Rules holds the user-defined detectors.
+	Rules []SensitiveDataRule `mapstructure:"rules"`
+
+	// Keys lists the span tags to scan. All the tags are scanned when empty.
+	Keys []string `mapstructure:"keys"`
+
+	// Resource specifies whether the span resources are scanned too.
+	Resource bool `mapstructure:"resource"`
+
+	// Action specifies how the matches are obfuscated: "redact" (default) replaces them
+	// with "?" and "hash" with the first 16 hex digits of their HMAC-SHA256 keyed by HashKey.
+	Action string `mapstructure:"action"`
+
+	// HashKey is the secret key of the HMAC computed by the "hash" action. It is required
+	// by the "hash" action, so that the hashes of low-entropy values can not be reversed.
+	HashKey string `mapstructure:"hash_key"`
+}
+
+// SensitiveDataRule is a user-defined sensitive data detector.
+type SensitiveDataRule struct {
+	// Name identifies the rule in the hit counters.
+	Name string `mapstructure:"name"`
+
+	// Pattern is the regexp matching the sensitive data. When it has capture groups,
+	// only the captured text is obfuscated. It must compile.
+	Pattern string `mapstructure:"pattern"`
+
+	// Re holds the compiled Pattern and is only used internally.
+	Re *regexp.Regexp `mapstructure:"-"`
 }
 
 // HTTPObfuscationConfig holds the configuration settings for HTTP obfuscation.
@@ -262,6 +307,10 @@ func (c *AgentConfig) applyDatadogConfig() error {
 			if c.Obfuscation.RemoveStackTraces {
 				c.addReplaceRule("error.stack", `(?s).*`, "?")
 			}
+			if o.SensitiveData.Enabled {
+				// the invalid configurations are reported by validate
+				compileSensitiveDataConfig(&o.SensitiveData) //nolint:errcheck
+			}
 		}
 	}
 
@@ -277,6 +326,30 @@ func (c *AgentConfig) applyDatadogConfig() error {
 			c.RejectTags = append(c.RejectTags, splitTag(tag))
 		}
 	}
+	if config.Datadog.IsSet("apm_config.filter_tags_glob.require") {
+		tags := config.Datadog.GetStringSlice("apm_config.filter_tags_glob.require")
+		for _, tag := range tags {
+			c.RequireTagsGlob = append(c.RequireTagsGlob, splitTag(tag))
+		}
+	}
+	if config.Datadog.IsSet("apm_config.filter_tags_glob.reject") {
+		tags := config.Datadog.GetStringSlice("apm_config.filter_tags_glob.reject")
+		for _, tag := range tags {
+			c.RejectTagsGlob = append(c.RejectTagsGlob, splitTag(tag))
+		}
+	}
+	if config.Datadog.IsSet("apm_config.filter_tags_regex.require") {
+		// the invalid regexps are reported by validate
+		if tags, err := compileTagsRegex(config.Datadog.GetStringSlice("apm_config.filter_tags_regex.require")); err == nil {
+			c.RequireTagsRegex = tags
+		}
+	}
+	if config.Datadog.IsSet("apm_config.filter_tags_regex.reject") {
+		// the invalid regexps are reported by validate
+		if tags, err := compileTagsRegex(config.Datadog.GetStringSlice("apm_config.filter_tags_regex.reject")); err == nil {
+			c.RejectTagsRegex = tags
+		}
+	}
 
 	// undocumented
 	if config.Datadog.IsSet("apm_config.max_cpu_percent") {
@@ -301,6 +374,16 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	if config.Datadog.IsSet("apm_config.sync_flushing") {
 		c.SynchronousFlushing = config.Datadog.GetBool("apm_config.sync_flushing")
 	}
+	c.PayloadStoragePath = filepath.Join(config.Datadog.GetString("run_path"), "trace_payloads_to_retry")
+	if config.Datadog.IsSet("apm_config.payload_storage_path") {
+		c.PayloadStoragePath = config.Datadog.GetString("apm_config.payload_storage_path")
+	}
+	if config.Datadog.IsSet("apm_config.payload_storage_max_size_in_bytes") {
+		c.PayloadStorageMaxSize = config.Datadog.GetInt64("apm_config.payload_storage_max_size_in_bytes")
+	}
+	if config.Datadog.IsSet("apm_config.payload_storage_max_age_in_seconds") {
+		c.PayloadStorageMaxAge = getDuration(config.Datadog.GetInt("apm_config.payload_storage_max_age_in_seconds"))
+	}
 
 	// undocumented deprecated
 	if config.Datadog.IsSet("apm_config.analyzed_rate_by_service") {
@@ -412,6 +495,53 @@ func compileReplaceRules(rules []*ReplaceRule) error {
 	return nil
 }
 
+// SensitiveDataDetectors lists the names of the built-in detectors of the sensitive data scanner.
+var SensitiveDataDetectors = []string{"credit_card", "email", "bearer_token", "aws_key", "iban"}
+
+// compileSensitiveDataConfig checks the detectors and the action of the sensitive data scanner and
+// compiles the regular expressions of its rules. If it fails it returns the first error.
+func compileSensitiveDataConfig(cfg *SensitiveDataConfig) error {
+	for _, name := range cfg.Detectors {
+		if !isSensitiveDataDetector(name) {
+			return fmt.Errorf("unknown detector %q", name)
+		}
+	}
+	switch cfg.Action {
+	case "", "redact":
+	case "hash":
+		if cfg.HashKey == "" {
+			return errors.New(`the "hash" action requires a "hash_key"`)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", cfg.Action)
+	}
+	for i := range cfg.Rules {
+		r := &cfg.Rules[i]
+		if r.Name == "" {
+			return errors.New(`all rules must have a "name"`)
+		}
+		if r.Pattern == "" {
+			return errors.New(`all rules must have a "pattern"`)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("rule %q: %s", r.Name, err)
+		}
+		r.Re = re
+	}
+	return nil
+}
+
+// isSensitiveDataDetector returns true if name is one of the built-in detectors of the sensitive data scanner.
+func isSensitiveDataDetector(name string) bool {
+	for _, d := range SensitiveDataDetectors {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
 // getDuration returns the duration of the provided value in seconds
 func getDuration(seconds int) time.Duration {
 	return time.Duration(seconds) * time.Second
@@ -476,3 +606,22 @@ func splitTag(tag string) *Tag {
 	}
 	return kv
 }
+
+// compileTagsRegex compiles the values of key/value tags to regexps.
+// If it fails it returns the first error.
+func compileTagsRegex(tags []string) ([]*TagRegex, error) {
+	out := make([]*TagRegex, 0, len(tags))
+	for _, tag := range tags {
+		kv := splitTag(tag)
+		t := &TagRegex{K: kv.K}
+		if kv.V != "" {
+			re, err := regexp.Compile(kv.V)
+			if err != nil {
+				return nil, fmt.Errorf("tag %q: %s", tag, err)
+			}
+			t.V = re
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
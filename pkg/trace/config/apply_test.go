@@ -28,6 +28,46 @@ func TestParseRepaceRules(t *testing.T) {
 	}
 }
 
+func TestCompileSensitiveDataConfig(t *testing.T) {
+	cfg := &SensitiveDataConfig{
+		Rules: []SensitiveDataRule{{Name: "ssn", Pattern: `\b\d{3}-\d{2}-\d{4}\b`}},
+	}
+	assert.NoError(t, compileSensitiveDataConfig(cfg))
+	assert.Equal(t, cfg.Rules[0].Pattern, cfg.Rules[0].Re.String())
+
+	for _, tt := range []struct {
+		cfg SensitiveDataConfig
+		err string
+	}{
+		{
+			cfg: SensitiveDataConfig{Detectors: []string{"email", "phone"}},
+			err: `unknown detector "phone"`,
+		},
+		{
+			cfg: SensitiveDataConfig{Rules: []SensitiveDataRule{{Name: "invalid", Pattern: "["}}},
+			err: "rule \"invalid\": error parsing regexp: missing closing ]: `[`",
+		},
+		{
+			cfg: SensitiveDataConfig{Rules: []SensitiveDataRule{{Pattern: "ssn"}}},
+			err: `all rules must have a "name"`,
+		},
+		{
+			cfg: SensitiveDataConfig{Rules: []SensitiveDataRule{{Name: "ssn"}}},
+			err: `all rules must have a "pattern"`,
+		},
+		{
+			cfg: SensitiveDataConfig{Action: "hash"},
+			err: `the "hash" action requires a "hash_key"`,
+		},
+		{
+			cfg: SensitiveDataConfig{Action: "encrypt"},
+			err: `unknown action "encrypt"`,
+		},
+	} {
+		assert.EqualError(t, compileSensitiveDataConfig(&tt.cfg), tt.err)
+	}
+}
+
 func TestSplitTag(t *testing.T) {
 	for _, tt := range []struct {
 		tag string
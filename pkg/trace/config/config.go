@@ -9,11 +9,13 @@ import (
 	"bytes"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"time"
 
@@ -82,6 +84,9 @@ type AgentConfig struct {
 	StatsWriter             *WriterConfig
 	TraceWriter             *WriterConfig
 	ConnectionResetInterval time.Duration // frequency at which outgoing connections are reset. 0 means no reset is performed
+	PayloadStoragePath      string        // directory where the payloads which can not be queued are stored on disk
+	PayloadStorageMaxSize   int64         // maximum disk space used by the stored payloads, in bytes. 0 disables the storage
+	PayloadStorageMaxAge    time.Duration // maximum time a payload is stored before being dropped. 0 means no limit
 
 	// internal telemetry
 	StatsdHost string
@@ -126,6 +131,18 @@ type AgentConfig struct {
 
 	// RejectTags specifies a list of tags which must be absent on the root span in order for a trace to be accepted.
 	RejectTags []*Tag
+
+	// RequireTagsGlob specifies a list of glob patterns for tags which must be present on the root span in order for a trace to be accepted.
+	RequireTagsGlob []*Tag
+
+	// RejectTagsGlob specifies a list of glob patterns for tags which must be absent on the root span in order for a trace to be accepted.
+	RejectTagsGlob []*Tag
+
+	// RequireTagsRegex specifies a list of regexp for tags which must be present on the root span in order for a trace to be accepted.
+	RequireTagsRegex []*TagRegex
+
+	// RejectTagsRegex specifies a list of regexp for tags which must be absent on the root span in order for a trace to be accepted.
+	RejectTagsRegex []*TagRegex
 }
 
 // Tag represents a key/value pair.
@@ -133,6 +150,12 @@ type Tag struct {
 	K, V string
 }
 
+// TagRegex represents a key/value regex pattern pair.
+type TagRegex struct {
+	K string
+	V *regexp.Regexp
+}
+
 // New returns a configuration with the default values.
 func New() *AgentConfig {
 	return &AgentConfig{
@@ -153,6 +176,7 @@ func New() *AgentConfig {
 		StatsWriter:             new(WriterConfig),
 		TraceWriter:             new(WriterConfig),
 		ConnectionResetInterval: 0, // disabled
+		PayloadStorageMaxAge:    24 * time.Hour,
 
 		StatsdHost: "localhost",
 		StatsdPort: 8125,
@@ -191,6 +215,16 @@ func (c *AgentConfig) validate() error {
 	if c.DDAgentBin == "" {
 		return errors.New("agent binary path not set")
 	}
+	for _, key := range []string{"apm_config.filter_tags_regex.require", "apm_config.filter_tags_regex.reject"} {
+		if _, err := compileTagsRegex(config.Datadog.GetStringSlice(key)); err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+	}
+	if c.Obfuscation != nil && c.Obfuscation.SensitiveData.Enabled {
+		if err := compileSensitiveDataConfig(&c.Obfuscation.SensitiveData); err != nil {
+			return fmt.Errorf("apm_config.obfuscation.sensitive_data: %s", err)
+		}
+	}
 	if c.Hostname == "" {
 		if err := c.acquireHostname(); err != nil {
 			return err
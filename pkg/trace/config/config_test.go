@@ -163,6 +163,9 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.EqualValues(123.4, c.MaxMemory)
 	assert.Equal("0.0.0.0", c.ReceiverHost)
 	assert.True(c.LogThrottling)
+	assert.Equal("/tmp/trace_payloads", c.PayloadStoragePath)
+	assert.EqualValues(50000000, c.PayloadStorageMaxSize)
+	assert.Equal(time.Hour, c.PayloadStorageMaxAge)
 
 	noProxy := true
 	if _, ok := os.LookupEnv("NO_PROXY"); ok {
@@ -183,6 +186,10 @@ func TestFullYamlConfig(t *testing.T) {
 
 	assert.ElementsMatch([]*Tag{{K: "env", V: "prod"}, {K: "db", V: "mongodb"}}, c.RequireTags)
 	assert.ElementsMatch([]*Tag{{K: "outcome", V: "success"}}, c.RejectTags)
+	assert.ElementsMatch([]*Tag{{K: "version", V: "1.*"}}, c.RequireTagsGlob)
+	assert.ElementsMatch([]*Tag{{K: "http.url", V: "*healthz*"}}, c.RejectTagsGlob)
+	assert.ElementsMatch([]*TagRegex{{K: "env", V: regexp.MustCompile("^prod$")}, {K: "db"}}, c.RequireTagsRegex)
+	assert.ElementsMatch([]*TagRegex{{K: "http.url", V: regexp.MustCompile("/health(z|check)")}}, c.RejectTagsRegex)
 
 	assert.ElementsMatch([]*ReplaceRule{
 		{
@@ -218,6 +225,21 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(o.RemoveStackTraces)
 	assert.True(c.Obfuscation.Redis.Enabled)
 	assert.True(c.Obfuscation.Memcached.Enabled)
+	assert.Equal(SensitiveDataConfig{
+		Enabled:   true,
+		Detectors: []string{"credit_card", "email"},
+		Rules:     []SensitiveDataRule{{Name: "ssn", Pattern: `\b\d{3}-\d{2}-\d{4}\b`, Re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)}},
+		Keys:      []string{"user.email"},
+		Action:    "hash",
+		HashKey:   "secret",
+	}, o.SensitiveData)
+}
+
+func TestInvalidSensitiveDataConfig(t *testing.T) {
+	defer cleanConfig()()
+
+	_, err := Load("./testdata/invalid_sensitive_data.yaml")
+	assert.EqualError(t, err, `apm_config.obfuscation.sensitive_data: unknown detector "phone"`)
 }
 
 func TestUndocumentedYamlConfig(t *testing.T) {
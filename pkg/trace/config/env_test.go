@@ -8,7 +8,9 @@ package config
 import (
 	"os"
 	"reflect"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/cihub/seelog"
@@ -277,6 +279,100 @@ func TestLoadEnv(t *testing.T) {
 		assert.Equal(cfg.RejectTags, []*Tag{{K: "bad1", V: "value1"}})
 	})
 
+	env = "DD_APM_FILTER_TAGS_GLOB_REQUIRE"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `important1 important2:value?`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal(cfg.RequireTagsGlob, []*Tag{{K: "important1"}, {K: "important2", V: "value?"}})
+	})
+
+	env = "DD_APM_FILTER_TAGS_GLOB_REJECT"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `bad1:value*`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal(cfg.RejectTagsGlob, []*Tag{{K: "bad1", V: "value*"}})
+	})
+
+	env = "DD_APM_FILTER_TAGS_REGEX_REQUIRE"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `important1 important2:^value[0-9]$`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal(cfg.RequireTagsRegex, []*TagRegex{{K: "important1"}, {K: "important2", V: regexp.MustCompile("^value[0-9]$")}})
+	})
+
+	env = "DD_APM_FILTER_TAGS_REGEX_REJECT"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `bad1:value.*`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal(cfg.RejectTagsRegex, []*TagRegex{{K: "bad1", V: regexp.MustCompile("value.*")}})
+	})
+
+	t.Run("invalid "+env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `bad1:value[`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		_, err = Load("./testdata/full.yaml")
+		assert.EqualError(err, "apm_config.filter_tags_regex.reject: tag \"bad1:value[\": error parsing regexp: missing closing ]: `[`")
+	})
+
+	env = "DD_APM_PAYLOAD_STORAGE_PATH"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, "/var/spool/trace")
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal("/var/spool/trace", cfg.PayloadStoragePath)
+	})
+
+	env = "DD_APM_PAYLOAD_STORAGE_MAX_SIZE_IN_BYTES"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, "1000")
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.EqualValues(1000, cfg.PayloadStorageMaxSize)
+	})
+
+	env = "DD_APM_PAYLOAD_STORAGE_MAX_AGE_IN_SECONDS"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, "60")
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal(time.Minute, cfg.PayloadStorageMaxAge)
+	})
+
 	for _, envKey := range []string{
 		"DD_CONNECTION_LIMIT", // deprecated
 		"DD_APM_CONNECTION_LIMIT",
@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// TagFilter drops the traces whose root span does not have the required tags or
+// has one of the rejected tags. It counts the traces dropped by each of its rules.
+type TagFilter struct {
+	reject  []tagRule
+	require []tagRule
+	stats   *info.FilterStats
+}
+
+// tagRule matches the spans having a tag. When match is nil, the tag matches with any value.
+type tagRule struct {
+	key   string
+	match func(v string) bool
+	stats *info.FilterRuleStats
+}
+
+// matches reports whether the span has the tag of the rule.
+func (r *tagRule) matches(span *pb.Span) bool {
+	v, ok := span.Meta[r.key]
+	return ok && (r.match == nil || r.match(v))
+}
+
+// NewTagFilter returns a TagFilter for the rules of the given configuration. The values of the
+// filter_tags rules match exactly, the values of the filter_tags_glob rules are glob patterns
+// and the values of the filter_tags_regex rules are matched with their regexp.
+func NewTagFilter(conf *config.AgentConfig) *TagFilter {
+	f := &TagFilter{}
+	var names []string
+	for _, tag := range conf.RejectTags {
+		f.reject = append(f.reject, newTagRule(tag))
+		names = append(names, ruleName("reject", tag.K, tag.V))
+	}
+	for _, tag := range conf.RejectTagsGlob {
+		f.reject = append(f.reject, newTagGlobRule(tag))
+		names = append(names, ruleName("reject_glob", tag.K, tag.V))
+	}
+	for _, tag := range conf.RejectTagsRegex {
+		f.reject = append(f.reject, newTagRegexRule(tag))
+		names = append(names, ruleName("reject_regex", tag.K, regexString(tag.V)))
+	}
+	for _, tag := range conf.RequireTags {
+		f.require = append(f.require, newTagRule(tag))
+		names = append(names, ruleName("require", tag.K, tag.V))
+	}
+	for _, tag := range conf.RequireTagsGlob {
+		f.require = append(f.require, newTagGlobRule(tag))
+		names = append(names, ruleName("require_glob", tag.K, tag.V))
+	}
+	for _, tag := range conf.RequireTagsRegex {
+		f.require = append(f.require, newTagRegexRule(tag))
+		names = append(names, ruleName("require_regex", tag.K, regexString(tag.V)))
+	}
+
+	f.stats = info.NewFilterStats(names)
+	for i := range f.reject {
+		f.reject[i].stats = f.stats.Rules[i]
+	}
+	for i := range f.require {
+		f.require[i].stats = f.stats.Rules[len(f.reject)+i]
+	}
+	return f
+}
+
+func newTagRule(tag *config.Tag) tagRule {
+	r := tagRule{key: tag.K}
+	if v := tag.V; v != "" {
+		r.match = func(s string) bool { return s == v }
+	}
+	return r
+}
+
+func newTagGlobRule(tag *config.Tag) tagRule {
+	r := tagRule{key: tag.K}
+	if tag.V != "" {
+		r.match = globRegexp(tag.V).MatchString
+	}
+	return r
+}
+
+func newTagRegexRule(tag *config.TagRegex) tagRule {
+	r := tagRule{key: tag.K}
+	if tag.V != nil {
+		r.match = tag.V.MatchString
+	}
+	return r
+}
+
+// globRegexp compiles a glob pattern, in which '*' matches any sequence of characters
+// and '?' any single character, to an anchored regexp.
+func globRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+func ruleName(kind, k, v string) string {
+	if v == "" {
+		return kind + ":" + k
+	}
+	return kind + ":" + k + ":" + v
+}
+
+func regexString(re *regexp.Regexp) string {
+	if re == nil {
+		return ""
+	}
+	return re.String()
+}
+
+// Allows reports whether the trace of the given root span is kept by the filter. The
+// rejecting rules are evaluated first, the first rule which drops the trace is counted.
+func (f *TagFilter) Allows(root *pb.Span) bool {
+	for i := range f.reject {
+		if r := &f.reject[i]; r.matches(root) {
+			atomic.AddInt64(&r.stats.TracesDropped, 1)
+			return false
+		}
+	}
+	for i := range f.require {
+		if r := &f.require[i]; !r.matches(root) {
+			atomic.AddInt64(&r.stats.TracesDropped, 1)
+			return false
+		}
+	}
+	return true
+}
+
+// Stats returns the number of traces dropped by each rule of the filter.
+func (f *TagFilter) Stats() *info.FilterStats {
+	return f.stats
+}
@@ -0,0 +1,165 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func TestTagFilterExact(t *testing.T) {
+	for _, tt := range []struct {
+		require []*config.Tag
+		reject  []*config.Tag
+		span    pb.Span
+		drop    bool
+	}{
+		{
+			require: []*config.Tag{{K: "key", V: "val"}},
+			span:    pb.Span{Meta: map[string]string{"key": "val"}},
+			drop:    false,
+		},
+		{
+			reject: []*config.Tag{{K: "key", V: "val"}},
+			span:   pb.Span{Meta: map[string]string{"key": "val4"}},
+			drop:   false,
+		},
+		{
+			reject: []*config.Tag{{K: "something", V: "else"}},
+			span:   pb.Span{Meta: map[string]string{"key": "val"}},
+			drop:   false,
+		},
+		{
+			require: []*config.Tag{{K: "something", V: "else"}},
+			reject:  []*config.Tag{{K: "bad-key", V: "bad-value"}},
+			span:    pb.Span{Meta: map[string]string{"something": "else", "bad-key": "other-value"}},
+			drop:    false,
+		},
+		{
+			require: []*config.Tag{{K: "key", V: "value"}, {K: "key-only"}},
+			reject:  []*config.Tag{{K: "bad-key", V: "bad-value"}},
+			span:    pb.Span{Meta: map[string]string{"key": "value", "key-only": "but-also-value", "bad-key": "not-bad-value"}},
+			drop:    false,
+		},
+		{
+			require: []*config.Tag{{K: "key", V: "val"}},
+			span:    pb.Span{Meta: map[string]string{"key": "val2"}},
+			drop:    true,
+		},
+		{
+			require: []*config.Tag{{K: "something", V: "else"}},
+			span:    pb.Span{Meta: map[string]string{"key": "val"}},
+			drop:    true,
+		},
+		{
+			require: []*config.Tag{{K: "valid"}, {K: "test"}},
+			reject:  []*config.Tag{{K: "test"}},
+			span:    pb.Span{Meta: map[string]string{"test": "random", "valid": "random"}},
+			drop:    true,
+		},
+		{
+			require: []*config.Tag{{K: "valid-key", V: "valid-value"}, {K: "test"}},
+			reject:  []*config.Tag{{K: "test"}},
+			span:    pb.Span{Meta: map[string]string{"test": "random", "valid-key": "wrong-value"}},
+			drop:    true,
+		},
+		{
+			reject: []*config.Tag{{K: "key", V: "val"}},
+			span:   pb.Span{Meta: map[string]string{"key": "val"}},
+			drop:   true,
+		},
+		{
+			// the wildcards of the exact values are not patterns
+			reject: []*config.Tag{{K: "http.url", V: "/a?b=1"}},
+			span:   pb.Span{Meta: map[string]string{"http.url": "/ab=1"}},
+			drop:   false,
+		},
+		{
+			reject: []*config.Tag{{K: "http.url", V: "/a?b=1"}},
+			span:   pb.Span{Meta: map[string]string{"http.url": "/a?b=1"}},
+			drop:   true,
+		},
+		{
+			require: []*config.Tag{{K: "something", V: "else"}, {K: "key-only"}},
+			reject:  []*config.Tag{{K: "bad-key", V: "bad-value"}, {K: "bad-key-only"}},
+			span:    pb.Span{Meta: map[string]string{"something": "else", "key-only": "but-also-value", "bad-key-only": "random"}},
+			drop:    true,
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			f := NewTagFilter(&config.AgentConfig{RequireTags: tt.require, RejectTags: tt.reject})
+			assert.Equal(t, !tt.drop, f.Allows(&tt.span))
+		})
+	}
+}
+
+func TestTagFilterGlob(t *testing.T) {
+	f := NewTagFilter(&config.AgentConfig{
+		RequireTagsGlob: []*config.Tag{{K: "env", V: "prod-*"}},
+		RejectTagsGlob:  []*config.Tag{{K: "http.url", V: "*healthz*"}, {K: "http.method", V: "?ET"}},
+	})
+	for _, tt := range []struct {
+		meta  map[string]string
+		allow bool
+	}{
+		{map[string]string{"env": "prod-eu", "http.url": "/api/users"}, true},
+		{map[string]string{"env": "prod-eu", "http.url": "http://localhost/healthz?full=1"}, false},
+		{map[string]string{"env": "prod-eu", "http.method": "GET"}, false},
+		{map[string]string{"env": "prod-eu", "http.method": "POST"}, true},
+		{map[string]string{"env": "staging"}, false},
+		// the glob patterns match the whole value
+		{map[string]string{"env": "preprod-eu"}, false},
+	} {
+		assert.Equal(t, tt.allow, f.Allows(&pb.Span{Meta: tt.meta}), "%v", tt.meta)
+	}
+}
+
+func TestTagFilterRegex(t *testing.T) {
+	f := NewTagFilter(&config.AgentConfig{
+		RequireTagsRegex: []*config.TagRegex{{K: "env", V: regexp.MustCompile("^prod$|^staging$")}, {K: "service"}},
+		RejectTagsRegex:  []*config.TagRegex{{K: "http.url", V: regexp.MustCompile(`/health(z|check)`)}},
+	})
+	assert.True(t, f.Allows(&pb.Span{Meta: map[string]string{"env": "prod", "service": "web"}}))
+	assert.False(t, f.Allows(&pb.Span{Meta: map[string]string{"env": "prod"}}))
+	assert.False(t, f.Allows(&pb.Span{Meta: map[string]string{"env": "dev", "service": "web"}}))
+	assert.False(t, f.Allows(&pb.Span{Meta: map[string]string{"env": "staging", "service": "web", "http.url": "/healthcheck"}}))
+}
+
+func TestTagFilterStats(t *testing.T) {
+	f := NewTagFilter(&config.AgentConfig{
+		RequireTags:     []*config.Tag{{K: "env", V: "prod"}},
+		RejectTags:      []*config.Tag{{K: "synthetics"}},
+		RejectTagsGlob:  []*config.Tag{{K: "http.url", V: "*healthz*"}},
+		RejectTagsRegex: []*config.TagRegex{{K: "user.agent", V: regexp.MustCompile("(?i)bot")}},
+	})
+	spans := []*pb.Span{
+		{Meta: map[string]string{"env": "prod"}},
+		{Meta: map[string]string{"env": "prod", "http.url": "/healthz"}},
+		{Meta: map[string]string{"env": "prod", "http.url": "/healthz", "synthetics": "true"}},
+		{Meta: map[string]string{"env": "prod", "synthetics": "true"}},
+		{Meta: map[string]string{"env": "prod", "user.agent": "GoogleBot"}},
+		{Meta: map[string]string{"env": "dev"}},
+	}
+	for _, s := range spans {
+		f.Allows(s)
+	}
+
+	dropped := make(map[string]int64)
+	for _, rs := range f.Stats().Rules {
+		dropped[rs.Rule] = rs.TracesDropped
+	}
+	assert.Equal(t, map[string]int64{
+		"reject_glob:http.url:*healthz*":  1,
+		"reject:synthetics":               2,
+		"reject_regex:user.agent:(?i)bot": 1,
+		"require:env:prod":                1,
+	}, dropped)
+}
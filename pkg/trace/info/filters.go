@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package info
+
+import (
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+)
+
+// FilterRuleStats holds the number of traces dropped by a tag filter rule.
+// Its fields require to be accessed in an atomic way.
+type FilterRuleStats struct {
+	// Rule identifies the rule, e.g. "reject:http.url:*healthz*".
+	Rule string
+	// TracesDropped is the total number of traces dropped by the rule.
+	TracesDropped int64
+	// reported is the number of dropped traces already published as metrics.
+	reported int64
+}
+
+// FilterStats holds the stats of the tag filter rules.
+type FilterStats struct {
+	Rules []*FilterRuleStats
+}
+
+// NewFilterStats returns the stats of the given tag filter rules.
+func NewFilterStats(rules []string) *FilterStats {
+	fs := &FilterStats{Rules: make([]*FilterRuleStats, 0, len(rules))}
+	for _, rule := range rules {
+		fs.Rules = append(fs.Rules, &FilterRuleStats{Rule: rule})
+	}
+	return fs
+}
+
+// Publish sends the number of traces dropped by each rule since the last call as metrics,
+// and updates the stats exposed by the info endpoint.
+func (fs *FilterStats) Publish() {
+	snapshot := make([]FilterRuleStats, 0, len(fs.Rules))
+	for _, rs := range fs.Rules {
+		dropped := atomic.LoadInt64(&rs.TracesDropped)
+		metrics.Count("datadog.trace_agent.filters.traces_dropped", dropped-rs.reported, []string{"rule:" + rs.Rule}, 1)
+		rs.reported = dropped
+		snapshot = append(snapshot, FilterRuleStats{Rule: rs.Rule, TracesDropped: dropped})
+	}
+	updateFilterRuleStats(snapshot)
+}
+
+func updateFilterRuleStats(rs []FilterRuleStats) {
+	infoMu.Lock()
+	defer infoMu.Unlock()
+	filterRuleStats = rs
+}
+
+func publishFilterRuleStats() interface{} {
+	infoMu.RLock()
+	defer infoMu.RUnlock()
+	return filterRuleStats
+}
@@ -37,6 +37,7 @@ var (
 	watchdogInfo     watchdog.Info
 	rateByService    map[string]float64
 	rateLimiterStats RateLimiterStats
+	filterRuleStats  []FilterRuleStats
 	start            = time.Now()
 	once             sync.Once
 	infoTmpl         *template.Template
@@ -219,6 +220,7 @@ func InitInfo(conf *config.AgentConfig) error {
 		expvar.Publish("ratebyservice", expvar.Func(publishRateByService))
 		expvar.Publish("watchdog", expvar.Func(publishWatchdogInfo))
 		expvar.Publish("ratelimiter", expvar.Func(publishRateLimiterStats))
+		expvar.Publish("filter_rules", expvar.Func(publishFilterRuleStats))
 
 		// copy the config to ensure we don't expose sensitive data such as API keys
 		c := *conf
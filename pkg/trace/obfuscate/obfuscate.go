@@ -31,6 +31,8 @@ type Obfuscator struct {
 	sqlLiteralEscapes int32
 	// queryCache keeps a cache of already obfuscated queries.
 	queryCache *measuredCache
+	// sensitiveData scans the tags of all the spans for sensitive data, nil if disabled.
+	sensitiveData *sensitiveDataScanner
 }
 
 // SetSQLLiteralEscapes sets whether or not escape characters should be treated literally by the SQL obfuscator.
@@ -68,11 +70,17 @@ func NewObfuscator(cfg *config.ObfuscationConfig) *Obfuscator {
 	if cfg.SQLExecPlanNormalize.Enabled {
 		o.sqlExecPlanNormalize = newJSONObfuscator(&cfg.SQLExecPlanNormalize, &o)
 	}
+	o.sensitiveData = newSensitiveDataScanner(&cfg.SensitiveData)
 	return &o
 }
 
 // Stop cleans up after a finished Obfuscator.
-func (o *Obfuscator) Stop() { o.queryCache.Close() }
+func (o *Obfuscator) Stop() {
+	o.queryCache.Close()
+	if o.sensitiveData != nil {
+		o.sensitiveData.Close()
+	}
+}
 
 // Obfuscate may obfuscate span's properties based on its type and on the Obfuscator's
 // configuration.
@@ -96,6 +104,9 @@ func (o *Obfuscator) Obfuscate(span *pb.Span) {
 	case "elasticsearch":
 		o.obfuscateJSON(span, "elasticsearch.body", o.es)
 	}
+	if o.sensitiveData != nil {
+		o.sensitiveData.scan(span)
+	}
 }
 
 // ObfuscateStatsGroup obfuscates the given stats bucket group.
@@ -0,0 +1,272 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// sensitiveDataScanner redacts or hashes the sensitive data found in the span tags. It sends
+// the number of hits of each of its detectors every 10 seconds.
+type sensitiveDataScanner struct {
+	detectors []*detector
+	keys      map[string]bool // nil to scan all the tags
+	resource  bool
+	// hashKey is the key of the HMAC replacing the matches, nil to redact them.
+	hashKey []byte
+
+	// close allows sending shutdown notification.
+	close chan struct{}
+}
+
+// detector matches a kind of sensitive data.
+type detector struct {
+	name string
+	re   *regexp.Regexp
+	// valid reports whether a match is sensitive data, e.g. using a checksum. It is optional.
+	valid func(match string) bool
+	// hits is the number of values obfuscated since the last report.
+	hits int64
+}
+
+// builtinDetectors returns the detectors which can be enabled by name, their names are
+// listed by config.SensitiveDataDetectors.
+func builtinDetectors() []*detector {
+	return []*detector{
+		{
+			// the card numbers start with the major industry identifier of the banking industries
+			name:  "credit_card",
+			re:    regexp.MustCompile(`\b[2-6](?:[ -]?\d){12,18}\b`),
+			valid: luhnValid,
+		},
+		{
+			name: "email",
+			re:   regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`),
+		},
+		{
+			name: "bearer_token",
+			re:   regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`),
+		},
+		{
+			name: "aws_key",
+			re:   regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b|(?i:aws_?secret_?access_?key)["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`),
+		},
+		{
+			name:  "iban",
+			re:    regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+			valid: ibanValid,
+		},
+	}
+}
+
+// newSensitiveDataScanner returns a scanner for the given configuration, nil when it is disabled.
+// The detectors, the action and the rules of the configuration must have been checked and compiled
+// when loading it.
+func newSensitiveDataScanner(cfg *config.SensitiveDataConfig) *sensitiveDataScanner {
+	if !cfg.Enabled {
+		return nil
+	}
+	s := &sensitiveDataScanner{
+		resource: cfg.Resource,
+		close:    make(chan struct{}),
+	}
+	if cfg.Action == "hash" {
+		s.hashKey = []byte(cfg.HashKey)
+	}
+
+	builtins := builtinDetectors()
+	if len(cfg.Detectors) == 0 {
+		s.detectors = builtins
+	}
+	for _, name := range cfg.Detectors {
+		for _, d := range builtins {
+			if d.name == name {
+				s.detectors = append(s.detectors, d)
+				break
+			}
+		}
+	}
+	for _, rule := range cfg.Rules {
+		s.detectors = append(s.detectors, &detector{name: rule.Name, re: rule.Re})
+	}
+
+	if len(cfg.Keys) > 0 {
+		s.keys = make(map[string]bool, len(cfg.Keys))
+		for _, k := range cfg.Keys {
+			s.keys[k] = true
+		}
+	}
+	go s.statsLoop()
+	return s
+}
+
+// Close stops sending the hit counters.
+func (s *sensitiveDataScanner) Close() {
+	s.close <- struct{}{}
+	<-s.close
+}
+
+func (s *sensitiveDataScanner) statsLoop() {
+	defer func() {
+		s.close <- struct{}{}
+	}()
+	tick := time.NewTicker(10 * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			s.report()
+		case <-s.close:
+			s.report()
+			return
+		}
+	}
+}
+
+func (s *sensitiveDataScanner) report() {
+	for _, d := range s.detectors {
+		metrics.Count("datadog.trace_agent.obfuscation.sensitive_data.hits", atomic.SwapInt64(&d.hits, 0), []string{"rule:" + d.name}, 1)
+	}
+}
+
+// scan obfuscates the sensitive data found in the tags of the span, and in its resource
+// when enabled. The internal "_dd." tags are never scanned.
+func (s *sensitiveDataScanner) scan(span *pb.Span) {
+	for k, v := range span.Meta {
+		if strings.HasPrefix(k, "_dd.") || (s.keys != nil && !s.keys[k]) {
+			continue
+		}
+		if out, changed := s.obfuscate(v); changed {
+			span.Meta[k] = out
+		}
+	}
+	if s.resource {
+		if out, changed := s.obfuscate(span.Resource); changed {
+			span.Resource = out
+		}
+	}
+}
+
+// obfuscate runs all the detectors on the value, returning the obfuscated value and whether
+// anything was found.
+func (s *sensitiveDataScanner) obfuscate(v string) (string, bool) {
+	var changed bool
+	for _, d := range s.detectors {
+		out, n := s.replace(d, v)
+		if n > 0 {
+			atomic.AddInt64(&d.hits, int64(n))
+			v = out
+			changed = true
+		}
+	}
+	return v, changed
+}
+
+// replace obfuscates the matches of the detector in the value, returning the number of
+// replacements. When the regexp has capture groups, only the captured text is obfuscated.
+func (s *sensitiveDataScanner) replace(d *detector, v string) (string, int) {
+	matches := d.re.FindAllStringSubmatchIndex(v, -1)
+	if matches == nil {
+		return v, 0
+	}
+	var (
+		sb   strings.Builder
+		last int
+		n    int
+	)
+	for _, m := range matches {
+		spans := [][]int{m[:2]}
+		if len(m) > 2 {
+			spans = spans[:0]
+			for i := 2; i+1 < len(m); i += 2 {
+				if m[i] >= 0 {
+					spans = append(spans, m[i:i+2])
+				}
+			}
+		}
+		for _, sp := range spans {
+			match := v[sp[0]:sp[1]]
+			if sp[0] < last || match == "" || (d.valid != nil && !d.valid(match)) {
+				continue
+			}
+			sb.WriteString(v[last:sp[0]])
+			sb.WriteString(s.mask(match))
+			last = sp[1]
+			n++
+		}
+	}
+	if n == 0 {
+		return v, 0
+	}
+	sb.WriteString(v[last:])
+	return sb.String(), n
+}
+
+// mask returns the replacement of a match. The hashes are keyed so that the low-entropy
+// values, such as the card numbers, can not be recovered by hashing all their candidates.
+func (s *sensitiveDataScanner) mask(match string) string {
+	if s.hashKey == nil {
+		return "?"
+	}
+	mac := hmac.New(sha256.New, s.hashKey)
+	mac.Write([]byte(match)) //nolint:errcheck
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum used by the credit card numbers.
+func luhnValid(s string) bool {
+	var sum, n int
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// ibanValid reports whether s is an IBAN passing the ISO 13616 mod-97 checksum.
+func ibanValid(s string) bool {
+	s = strings.Replace(s, " ", "", -1)
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	// the country code and the check digits are moved to the end, the letters
+	// counting as two digits, A=10 to Z=35
+	var rem int
+	for _, c := range s[4:] + s[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
@@ -203,25 +203,36 @@ func (f *groupingFilter) Reset() {
 // some elements such as comments and aliases and obfuscation attempts to hide sensitive information
 // in strings and numbers by redacting them.
 func (o *Obfuscator) ObfuscateSQLString(in string) (*ObfuscatedQuery, error) {
-	if v, ok := o.queryCache.Get(in); ok {
+	return o.ObfuscateSQLStringForDialect(in, GenericDialect)
+}
+
+// ObfuscateSQLStringForDialect quantizes and obfuscates the given input SQL query string, like
+// ObfuscateSQLString, using the tokenization rules of the given SQL dialect.
+func (o *Obfuscator) ObfuscateSQLStringForDialect(in string, dialect SQLDialect) (*ObfuscatedQuery, error) {
+	key := in
+	if dialect != GenericDialect {
+		// the same query may be obfuscated differently by each dialect
+		key = dialect.String() + ":" + in
+	}
+	if v, ok := o.queryCache.Get(key); ok {
 		return v.(*ObfuscatedQuery), nil
 	}
-	oq, err := o.obfuscateSQLString(in)
+	oq, err := o.obfuscateSQLString(in, dialect)
 	if err != nil {
 		return oq, err
 	}
-	o.queryCache.Set(in, oq, oq.Cost())
+	o.queryCache.Set(key, oq, oq.Cost())
 	return oq, nil
 }
 
-func (o *Obfuscator) obfuscateSQLString(in string) (*ObfuscatedQuery, error) {
+func (o *Obfuscator) obfuscateSQLString(in string, dialect SQLDialect) (*ObfuscatedQuery, error) {
 	lesc := o.SQLLiteralEscapes()
-	tok := NewSQLTokenizer(in, lesc)
+	tok := NewSQLTokenizerForDialect(in, lesc, dialect)
 	out, err := attemptObfuscation(tok)
 	if err != nil && tok.SeenEscape() {
 		// If the tokenizer failed, but saw an escape character in the process,
 		// try again treating escapes differently
-		tok = NewSQLTokenizer(in, !lesc)
+		tok = NewSQLTokenizerForDialect(in, !lesc, dialect)
 		if out, err2 := attemptObfuscation(tok); err2 == nil {
 			// If the second attempt succeeded, change the default behavior so that
 			// on the next run we get it right in the first run.
@@ -377,10 +388,11 @@ func (o *Obfuscator) obfuscateSQL(span *pb.Span) {
 	if span.Resource == "" {
 		return
 	}
-	oq, err := o.ObfuscateSQLString(span.Resource)
+	dialect := SQLDialectFromDBType(span.Meta["db.type"])
+	oq, err := o.ObfuscateSQLStringForDialect(span.Resource, dialect)
 	if err != nil {
 		// we have an error, discard the SQL to avoid polluting user resources.
-		log.Debugf("Error parsing SQL query: %v. Resource: %q", err, span.Resource)
+		log.Debugf("Error parsing %s SQL query: %v. Resource: %q", dialect, err, span.Resource)
 		if span.Meta == nil {
 			span.Meta = make(map[string]string, 1)
 		}
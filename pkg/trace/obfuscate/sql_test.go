@@ -7,12 +7,15 @@ package obfuscate
 
 import (
 	"bytes"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/stretchr/testify/assert"
@@ -1246,6 +1249,109 @@ FROM sales, inventory
 GROUP BY sales.product_key;
 `
 
+// loadSQLDialectTests loads the golden-file tests of the given dialect from ./testdata/sql_<dialect>.xml.
+func loadSQLDialectTests(dialect SQLDialect) ([]*xmlObfuscateTest, error) {
+	f, err := os.Open(filepath.Join("testdata", fmt.Sprintf("sql_%s.xml", dialect)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var suite xmlObfuscateTests
+	if err := xml.NewDecoder(f).Decode(&suite); err != nil {
+		return nil, err
+	}
+	return suite.Tests, nil
+}
+
+func TestSQLDialects(t *testing.T) {
+	o := NewObfuscator(nil)
+	defer o.Stop()
+
+	for _, dialect := range []SQLDialect{PostgreSQLDialect, MySQLDialect, MSSQLDialect} {
+		tests, err := loadSQLDialectTests(dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tests) == 0 {
+			t.Fatalf("no %s tests", dialect)
+		}
+		for _, tt := range tests {
+			t.Run(tt.Tag, func(t *testing.T) {
+				span := SQLSpan(tt.In)
+				// the dialect is selected from the "db.type" tag
+				span.Meta["db.type"] = dialect.String()
+				o.Obfuscate(span)
+				assert.Equal(t, tt.Out, span.Resource)
+			})
+		}
+	}
+}
+
+func TestSQLDialectFromDBType(t *testing.T) {
+	for in, dialect := range map[string]SQLDialect{
+		"postgresql": PostgreSQLDialect,
+		"postgres":   PostgreSQLDialect,
+		"MySQL":      MySQLDialect,
+		"mariadb":    MySQLDialect,
+		"mssql":      MSSQLDialect,
+		"sqlserver":  MSSQLDialect,
+		"sqlite":     GenericDialect,
+		"":           GenericDialect,
+	} {
+		assert.Equal(t, dialect, SQLDialectFromDBType(in), in)
+	}
+}
+
+func TestSQLTokenizerDialect(t *testing.T) {
+	for _, tt := range []struct {
+		dialect SQLDialect
+		str     string
+		kinds   []TokenKind
+	}{
+		{GenericDialect, "a->>'k'", []TokenKind{ID, '-', '>', '>', String}},
+		{PostgreSQLDialect, "a->>'k'", []TokenKind{ID, JSONOp, String}},
+		{PostgreSQLDialect, "a#>'{k}' #- $$v$$", []TokenKind{ID, JSONOp, String, JSONOp, String}},
+		{PostgreSQLDialect, "a ?| b ? c", []TokenKind{ID, JSONOp, ID, '?', ID}},
+		{GenericDialect, "a # comment", []TokenKind{ID, Comment}},
+		{MySQLDialect, "`a b`.c = \"d\"", []TokenKind{ID, '=', String}},
+		{MSSQLDialect, "[a].[b c] = 1", []TokenKind{ID, '=', Number}},
+		{GenericDialect, "[a] = \"d\"", []TokenKind{'[', ID, ']', '=', DoubleQuotedString}},
+	} {
+		tok := NewSQLTokenizerForDialect(tt.str, false, tt.dialect)
+		var kinds []TokenKind
+		for {
+			kind, _ := tok.Scan()
+			if kind == EndChar || kind == LexError {
+				break
+			}
+			kinds = append(kinds, kind)
+		}
+		assert.NoError(t, tok.Err(), tt.str)
+		assert.Equal(t, tt.kinds, kinds, "%s: %s", tt.dialect, tt.str)
+	}
+}
+
+func TestSQLDialectCache(t *testing.T) {
+	os.Setenv("DD_APM_FEATURES", "sql_cache")
+	defer os.Unsetenv("DD_APM_FEATURES")
+	o := NewObfuscator(nil)
+	defer o.Stop()
+
+	// the double-quoted strings are identifiers in the generic dialect
+	query := `SELECT * FROM t WHERE a IN ("b", "c")`
+	generic, err := o.ObfuscateSQLString(query)
+	assert.NoError(t, err)
+	// the cache is populated asynchronously
+	assert.Eventually(t, func() bool {
+		_, ok := o.queryCache.Get(query)
+		return ok
+	}, time.Second, 10*time.Millisecond)
+	mysql, err := o.ObfuscateSQLStringForDialect(query, MySQLDialect)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a IN ( b, c )", generic.Query)
+	assert.Equal(t, "SELECT * FROM t WHERE a IN ( ? )", mysql.Query)
+}
+
 // Benchmark the Tokenizer using a SQL statement
 func BenchmarkObfuscateSQLString(b *testing.B) {
 	benchmarks := []struct {
@@ -1328,7 +1434,10 @@ func BenchmarkQueryCacheTippingPoint(b *testing.B) {
 		"xlong":       "select top ? percent IdTrebEmpresa, CodCli, NOMEMP, Baixa, CASE WHEN IdCentreTreball IS ? THEN ? ELSE CONVERT ( VARCHAR ( ? ) IdCentreTreball ) END, CASE WHEN NOMESTAB IS ? THEN ? ELSE NOMESTAB END, TIPUS, CASE WHEN IdLloc IS ? THEN ? ELSE CONVERT ( VARCHAR ( ? ) IdLloc ) END, CASE WHEN NomLlocComplert IS ? THEN ? ELSE NomLlocComplert END, CASE WHEN DesLloc IS ? THEN ? ELSE DesLloc END, IdLlocTreballUnic From ( SELECT ?, dbo.Treb_Empresa.IdTrebEmpresa, dbo.Treb_Empresa.IdTreballador, dbo.Treb_Empresa.CodCli, dbo.Clients.NOMEMP, dbo.Treb_Empresa.Baixa, dbo.Treb_Empresa.IdCentreTreball, dbo.Cli_Establiments.NOMESTAB, ?, ?, dbo.Treb_Empresa.DataInici, dbo.Treb_Empresa.DataFi, CASE WHEN dbo.Treb_Empresa.DesLloc IS ? THEN ? ELSE dbo.Treb_Empresa.DesLloc END DesLloc, dbo.Treb_Empresa.IdLlocTreballUnic FROM dbo.Clients WITH ( NOLOCK ) INNER JOIN dbo.Treb_Empresa WITH ( NOLOCK ) ON dbo.Clients.CODCLI = dbo.Treb_Empresa.CodCli LEFT OUTER JOIN dbo.Cli_Establiments WITH ( NOLOCK ) ON dbo.Cli_Establiments.Id_ESTAB_CLI = dbo.Treb_Empresa.IdCentreTreball AND dbo.Cli_Establiments.CODCLI = dbo.Treb_Empresa.CodCli WHERE dbo.Treb_Empresa.IdTreballador = ? AND Treb_Empresa.IdTecEIRLLlocTreball IS ? AND IdMedEIRLLlocTreball IS ? AND IdLlocTreballTemporal IS ? UNION ALL SELECT ?, dbo.Treb_Empresa.IdTrebEmpresa, dbo.Treb_Empresa.IdTreballador, dbo.Treb_Empresa.CodCli, dbo.Clients.NOMEMP, dbo.Treb_Empresa.Baixa, dbo.Treb_Empresa.IdCentreTreball, dbo.Cli_Establiments.NOMESTAB, dbo.Treb_Empresa.IdTecEIRLLlocTreball, dbo.fn_NomLlocComposat ( dbo.Treb_Empresa.IdTecEIRLLlocTreball ), dbo.Treb_Empresa.DataInici, dbo.Treb_Empresa.DataFi, CASE WHEN dbo.Treb_Empresa.DesLloc IS ? THEN ? ELSE dbo.Treb_Empresa.DesLloc END DesLloc, dbo.Treb_Empresa.IdLlocTreballUnic FROM dbo.Clients WITH ( NOLOCK ) INNER JOIN dbo.Treb_Empresa WITH ( NOLOCK ) ON dbo.Clients.CODCLI = dbo.Treb_Empresa.CodCli LEFT OUTER JOIN dbo.Cli_Establiments WITH ( NOLOCK ) ON dbo.Cli_Establiments.Id_ESTAB_CLI = dbo.Treb_Empresa.IdCentreTreball AND dbo.Cli_Establiments.CODCLI = dbo.Treb_Empresa.CodCli WHERE ( dbo.Treb_Empresa.IdTreballador = ? ) AND ( NOT ( dbo.Treb_Empresa.IdTecEIRLLlocTreball IS ? ) ) UNION ALL SELECT ?, dbo.Treb_Empresa.IdTrebEmpresa, dbo.Treb_Empresa.IdTreballador, dbo.Treb_Empresa.CodCli, dbo.Clients.NOMEMP, dbo.Treb_Empresa.Baixa, dbo.Treb_Empresa.IdCentreTreball, dbo.Cli_Establiments.NOMESTAB, dbo.Treb_Empresa.IdMedEIRLLlocTreball, dbo.fn_NomMedEIRLLlocComposat ( dbo.Treb_Empresa.IdMedEIRLLlocTreball ), dbo.Treb_Empresa.DataInici, dbo.Treb_Empresa.DataFi, CASE WHEN dbo.Treb_Empresa.DesLloc IS ? THEN ? ELSE dbo.Treb_Empresa.DesLloc END DesLloc, dbo.Treb_Empresa.IdLlocTreballUnic FROM dbo.Clients WITH ( NOLOCK ) INNER JOIN dbo.Treb_Empresa WITH ( NOLOCK ) ON dbo.Clients.CODCLI = dbo.Treb_Empresa.CodCli LEFT OUTER JOIN dbo.Cli_Establiments WITH ( NOLOCK ) ON dbo.Cli_Establiments.Id_ESTAB_CLI = dbo.Treb_Empresa.IdCentreTreball AND dbo.Cli_Establiments.CODCLI = dbo.Treb_Empresa.CodCli WHERE ( dbo.Treb_Empresa.IdTreballador = ? ) AND ( Treb_Empresa.IdTecEIRLLlocTreball IS ? ) AND ( NOT ( dbo.Treb_Empresa.IdMedEIRLLlocTreball IS ? ) ) UNION ALL SELECT ?, dbo.Treb_Empresa.IdTrebEmpresa, dbo.Treb_Empresa.IdTreballador, dbo.Treb_Empresa.CodCli, dbo.Clients.NOMEMP, dbo.Treb_Empresa.Baixa, dbo.Treb_Empresa.IdCentreTreball, dbo.Cli_Establiments.NOMESTAB, dbo.Treb_Empresa.IdLlocTreballTemporal, dbo.Lloc_Treball_Temporal.NomLlocTreball, dbo.Treb_Empresa.DataInici, dbo.Treb_Empresa.DataFi, CASE WHEN dbo.Treb_Empresa.DesLloc IS ? THEN ? ELSE dbo.Treb_Empresa.DesLloc END DesLloc, dbo.Treb_Empresa.IdLlocTreballUnic FROM dbo.Clients WITH ( NOLOCK ) INNER JOIN dbo.Treb_Empresa WITH ( NOLOCK ) ON dbo.Clients.CODCLI = dbo.Treb_Empresa.CodCli INNER JOIN dbo.Lloc_Treball_Temporal WITH ( NOLOCK ) ON dbo.Treb_Empresa.IdLlocTreballTemporal = dbo.Lloc_Treball_Temporal.IdLlocTreballTemporal LEFT OUTER JOIN dbo.Cli_Establiments WITH ( NOLOCK ) ON dbo.Cli_Establiments.Id_ESTAB_CLI = dbo.Treb_Empresa.IdCentreTreball AND dbo.Cli_Establiments.CODCLI = dbo.Treb_Empresa.CodCli WHERE dbo.Treb_Empresa.IdTreballador = ? AND Treb_Empresa.IdTecEIRLLlocTreball IS ? AND IdMedEIRLLlocTreball IS ? ) Where ? = %d",
 	} {
 		b.Run(fmt.Sprintf("%s-%d", name, len(queryfmt)), func(b *testing.B) {
-			b.Run("off", bench1KQueries((*Obfuscator).obfuscateSQLString, 1, queryfmt))
+			off := func(o *Obfuscator, in string) (*ObfuscatedQuery, error) {
+				return o.obfuscateSQLString(in, GenericDialect)
+			}
+			b.Run("off", bench1KQueries(off, 1, queryfmt))
 			b.Run("0%", bench1KQueries((*Obfuscator).ObfuscateSQLString, 0, queryfmt))
 			b.Run("1%", bench1KQueries((*Obfuscator).ObfuscateSQLString, 0.01, queryfmt))
 			b.Run("5%", bench1KQueries((*Obfuscator).ObfuscateSQLString, 0.05, queryfmt))
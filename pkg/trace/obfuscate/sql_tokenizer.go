@@ -8,6 +8,7 @@ package obfuscate
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -61,6 +62,9 @@ const (
 	TableName
 	ColonCast
 
+	// JSONOp specifies a JSON operator, such as '->>' in PostgreSQL and MySQL or '?|' in PostgreSQL.
+	JSONOp
+
 	// FilteredGroupable specifies that the given token has been discarded by one of the
 	// token filters and that it is groupable together with consecutive FilteredGroupable
 	// tokens.
@@ -110,6 +114,7 @@ var tokenKindStrings = map[TokenKind]string{
 	Join:                         "Join",
 	TableName:                    "TableName",
 	ColonCast:                    "ColonCast",
+	JSONOp:                       "JSONOp",
 	FilteredGroupable:            "FilteredGroupable",
 	FilteredGroupableParenthesis: "FilteredGroupableParenthesis",
 	Filtered:                     "Filtered",
@@ -126,6 +131,51 @@ func (k TokenKind) String() string {
 
 const escapeCharacter = '\\'
 
+// SQLDialect specifies the SQL dialect of a query. It enables the tokenization rules
+// which are specific to the dialect.
+type SQLDialect uint8
+
+const (
+	// GenericDialect applies the rules which are common to all the dialects.
+	GenericDialect SQLDialect = iota
+	// PostgreSQLDialect adds the dollar-quoted strings and the JSON operators.
+	PostgreSQLDialect
+	// MySQLDialect adds the backtick identifiers, the double-quoted strings and the JSON operators.
+	MySQLDialect
+	// MSSQLDialect adds the bracketed identifiers.
+	MSSQLDialect
+)
+
+var sqlDialectStrings = map[SQLDialect]string{
+	GenericDialect:    "generic",
+	PostgreSQLDialect: "postgresql",
+	MySQLDialect:      "mysql",
+	MSSQLDialect:      "mssql",
+}
+
+func (d SQLDialect) String() string {
+	str, ok := sqlDialectStrings[d]
+	if !ok {
+		return "<unknown>"
+	}
+	return str
+}
+
+// SQLDialectFromDBType returns the dialect of the queries sent to a database of the given
+// type, as found in the "db.type" span tag. The unknown types use the GenericDialect.
+func SQLDialectFromDBType(dbType string) SQLDialect {
+	switch strings.ToLower(dbType) {
+	case "postgresql", "postgres", "pg", "redshift", "cockroachdb":
+		return PostgreSQLDialect
+	case "mysql", "mariadb":
+		return MySQLDialect
+	case "mssql", "sqlserver", "sql server":
+		return MSSQLDialect
+	default:
+		return GenericDialect
+	}
+}
+
 // SQLTokenizer is the struct used to generate SQL
 // tokens for the parser.
 type SQLTokenizer struct {
@@ -137,8 +187,9 @@ type SQLTokenizer struct {
 
 	curlys uint32 // number of active open curly braces in top-level SQL escape sequences.
 
-	literalEscapes bool // indicates we should not treat backslashes as escape characters
-	seenEscape     bool // indicates whether this tokenizer has seen an escape character within a string
+	literalEscapes bool       // indicates we should not treat backslashes as escape characters
+	seenEscape     bool       // indicates whether this tokenizer has seen an escape character within a string
+	dialect        SQLDialect // dialect of the query, selecting the dialect-specific rules
 }
 
 // NewSQLTokenizer creates a new SQLTokenizer for the given SQL string. The literalEscapes argument specifies
@@ -150,6 +201,14 @@ func NewSQLTokenizer(sql string, literalEscapes bool) *SQLTokenizer {
 	}
 }
 
+// NewSQLTokenizerForDialect creates a new SQLTokenizer for the given SQL string, applying
+// the tokenization rules specific to the given dialect.
+func NewSQLTokenizerForDialect(sql string, literalEscapes bool, dialect SQLDialect) *SQLTokenizer {
+	tkn := NewSQLTokenizer(sql, literalEscapes)
+	tkn.dialect = dialect
+	return tkn
+}
+
 // Reset the underlying buffer and positions
 func (tkn *SQLTokenizer) Reset(in string) {
 	tkn.pos = 0
@@ -196,6 +255,11 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 	tkn.skipBlank()
 
 	switch ch := tkn.lastChar; {
+	case ch == '@' && tkn.dialect == PostgreSQLDialect && tkn.peek() == '>':
+		// the '@>' containment operator
+		tkn.advance()
+		tkn.advance()
+		return JSONOp, tkn.bytes()
 	case isLeadingLetter(ch):
 		return tkn.scanIdentifier()
 	case isDigit(ch):
@@ -221,7 +285,19 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 				return tkn.scanBindVar()
 			}
 			fallthrough
-		case '=', ',', ';', '(', ')', '+', '*', '&', '|', '^', '~', '[', ']', '?':
+		case '[':
+			if tkn.dialect == MSSQLDialect {
+				return tkn.scanQuotedIdentifier(nil, '[', ']')
+			}
+			return TokenKind(ch), tkn.bytes()
+		case '?':
+			if tkn.dialect == PostgreSQLDialect && (tkn.lastChar == '|' || tkn.lastChar == '&') {
+				// the '?|' and '?&' key existence operators
+				tkn.advance()
+				return JSONOp, tkn.bytes()
+			}
+			return TokenKind(ch), tkn.bytes()
+		case '=', ',', ';', '(', ')', '+', '*', '&', '|', '^', '~', ']':
 			return TokenKind(ch), tkn.bytes()
 		case '.':
 			if isDigit(tkn.lastChar) {
@@ -244,12 +320,43 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 				tkn.advance()
 				return tkn.scanCommentType1("--")
 			}
+			if tkn.lastChar == '>' && (tkn.dialect == PostgreSQLDialect || tkn.dialect == MySQLDialect) {
+				// the '->' and '->>' operators
+				tkn.advance()
+				if tkn.lastChar == '>' {
+					tkn.advance()
+				}
+				return JSONOp, tkn.bytes()
+			}
 			return TokenKind(ch), tkn.bytes()
 		case '#':
+			if tkn.dialect == PostgreSQLDialect {
+				// '#' does not start comments in PostgreSQL
+				switch tkn.lastChar {
+				case '>':
+					// the '#>' and '#>>' operators
+					tkn.advance()
+					if tkn.lastChar == '>' {
+						tkn.advance()
+					}
+					return JSONOp, tkn.bytes()
+				case '-':
+					tkn.advance()
+					return JSONOp, tkn.bytes()
+				}
+				return TokenKind(ch), tkn.bytes()
+			}
 			tkn.advance()
 			return tkn.scanCommentType1("#")
 		case '<':
 			switch tkn.lastChar {
+			case '@':
+				if tkn.dialect != PostgreSQLDialect {
+					return TokenKind(ch), tkn.bytes()
+				}
+				// the '<@' containment operator
+				tkn.advance()
+				return JSONOp, tkn.bytes()
 			case '>':
 				tkn.advance()
 				return NE, []byte("<>")
@@ -281,8 +388,15 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 		case '\'':
 			return tkn.scanString(ch, String)
 		case '"':
+			if tkn.dialect == MySQLDialect {
+				// double quotes delimit strings, unless the ANSI_QUOTES mode is enabled
+				return tkn.scanString(ch, String)
+			}
 			return tkn.scanString(ch, DoubleQuotedString)
 		case '`':
+			if tkn.dialect == MySQLDialect {
+				return tkn.scanQuotedIdentifier(nil, '`', '`')
+			}
 			return tkn.scanLiteralIdentifier('`')
 		case '%':
 			if tkn.lastChar == '(' {
@@ -295,6 +409,9 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 			// modulo operator (e.g. 'id % 8')
 			return TokenKind(ch), tkn.bytes()
 		case '$':
+			if tkn.dialect == PostgreSQLDialect && !isDigit(tkn.lastChar) {
+				return tkn.scanDollarQuotedString()
+			}
 			return tkn.scanPreparedStatement('$')
 		case '{':
 			if tkn.pos == 1 || tkn.curlys > 0 {
@@ -370,11 +487,22 @@ func toUpper(src, dst []byte) []byte {
 
 func (tkn *SQLTokenizer) scanIdentifier() (TokenKind, []byte) {
 	tkn.advance()
-	for isLetter(tkn.lastChar) || isDigit(tkn.lastChar) || tkn.lastChar == '.' || tkn.lastChar == '*' {
+	for tkn.isIdentifierChar(tkn.lastChar) {
 		tkn.advance()
 	}
 
 	t := tkn.bytes()
+	if len(t) > 0 && t[len(t)-1] == '.' {
+		// a qualified name continuing with a quoted identifier, e.g. dbo.[users]
+		switch {
+		case tkn.dialect == MySQLDialect && tkn.lastChar == '`':
+			tkn.advance()
+			return tkn.scanQuotedIdentifier(t, '`', '`')
+		case tkn.dialect == MSSQLDialect && tkn.lastChar == '[':
+			tkn.advance()
+			return tkn.scanQuotedIdentifier(t, '[', ']')
+		}
+	}
 	// Space allows us to upper-case identifiers 256 bytes long or less without allocating heap
 	// storage for them, since space is allocated on the stack. A size of 256 bytes was chosen
 	// based on the allowed length of sql identifiers in various sql implementations.
@@ -405,6 +533,119 @@ func (tkn *SQLTokenizer) scanLiteralIdentifier(quote rune) (TokenKind, []byte) {
 	return ID, t
 }
 
+// isIdentifierChar reports whether ch can be part of an unquoted identifier.
+func (tkn *SQLTokenizer) isIdentifierChar(ch rune) bool {
+	if ch == '#' && tkn.dialect == PostgreSQLDialect {
+		// '#' starts the '#>' and '#>>' operators in PostgreSQL
+		return false
+	}
+	return isLetter(ch) || isDigit(ch) || ch == '.' || ch == '*'
+}
+
+// scanQuotedIdentifier scans a MySQL backtick or an MSSQL bracketed identifier, in which the
+// closing delimiter is escaped by doubling it. The qualified names, e.g. [dbo].[users], are
+// returned as a single token, following the given prefix. The delimiters are removed, unless
+// the identifier contains characters which require them, such as spaces.
+func (tkn *SQLTokenizer) scanQuotedIdentifier(prefix []byte, open, close rune) (TokenKind, []byte) {
+	var out bytes.Buffer
+	out.Write(prefix)
+	// the opening delimiter of the first part was consumed by the caller
+	quoted := true
+	for {
+		if quoted {
+			var part bytes.Buffer
+			for {
+				ch := tkn.lastChar
+				if ch == EndChar {
+					tkn.setErr(`unexpected EOF in identifier, expected "%c"`, close)
+					return LexError, tkn.bytes()
+				}
+				tkn.advance()
+				if ch == close {
+					if tkn.lastChar != close {
+						break
+					}
+					tkn.advance()
+				}
+				part.WriteRune(ch)
+			}
+			writeQuotedIdentifier(&out, part.Bytes(), open, close)
+		} else {
+			for tkn.isIdentifierChar(tkn.lastChar) && tkn.lastChar != '.' {
+				out.WriteRune(tkn.lastChar)
+				tkn.advance()
+			}
+		}
+		if tkn.lastChar != '.' {
+			break
+		}
+		out.WriteByte('.')
+		tkn.advance()
+		quoted = tkn.lastChar == open
+		if quoted {
+			tkn.advance()
+		}
+	}
+	return ID, out.Bytes()
+}
+
+// writeQuotedIdentifier writes the identifier to out, with its delimiters when they are needed.
+func writeQuotedIdentifier(out *bytes.Buffer, ident []byte, open, close rune) {
+	plain := len(ident) > 0
+	for _, r := range string(ident) {
+		if !skipNonLiteralIdentifier(r) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		out.Write(ident)
+		return
+	}
+	out.WriteRune(open)
+	for _, r := range string(ident) {
+		if r == close {
+			out.WriteRune(r)
+		}
+		out.WriteRune(r)
+	}
+	out.WriteRune(close)
+}
+
+// scanDollarQuotedString scans a PostgreSQL dollar-quoted string, e.g. $$it's$$ or
+// $tag$it's$tag$. The opening '$' was consumed by the caller.
+func (tkn *SQLTokenizer) scanDollarQuotedString() (TokenKind, []byte) {
+	var tag bytes.Buffer
+	tag.WriteByte('$')
+	for tkn.lastChar != '$' {
+		if !isLeadingLetter(tkn.lastChar) && !isDigit(tkn.lastChar) || tkn.lastChar == '@' {
+			tkn.setErr(`unexpected character "%c" (%d) in dollar-quoted string tag`, tkn.lastChar, tkn.lastChar)
+			return LexError, tkn.bytes()
+		}
+		tag.WriteRune(tkn.lastChar)
+		tkn.advance()
+	}
+	tag.WriteByte('$')
+	tkn.advance()
+
+	if tkn.lastChar == EndChar {
+		tkn.setErr("unexpected EOF in dollar-quoted string")
+		return LexError, tkn.bytes()
+	}
+	// look for the closing tag from the offset of tkn.lastChar
+	start := tkn.off - utf8.RuneLen(tkn.lastChar)
+	n := bytes.Index(tkn.buf[start:], tag.Bytes())
+	if n == -1 {
+		tkn.setErr("unexpected EOF in dollar-quoted string")
+		return LexError, tkn.bytes()
+	}
+	end := start + n + tag.Len()
+	for tkn.lastChar != EndChar && tkn.off-utf8.RuneLen(tkn.lastChar) < end {
+		tkn.advance()
+	}
+	return String, tkn.bytes()
+}
+
 func (tkn *SQLTokenizer) scanVariableIdentifier(prefix rune) (TokenKind, []byte) {
 	for tkn.advance(); tkn.lastChar != ')' && tkn.lastChar != EndChar; tkn.advance() {
 	}
@@ -626,6 +867,12 @@ func (tkn *SQLTokenizer) advance() {
 	tkn.lastChar = ch
 }
 
+// peek returns the rune following tkn.lastChar without advancing the tokenizer.
+func (tkn *SQLTokenizer) peek() rune {
+	ch, _ := utf8.DecodeRune(tkn.buf[tkn.off:])
+	return ch
+}
+
 // bytes returns all the bytes that were advanced over since its last call.
 // This excludes tkn.lastChar, which will remain in the buffer
 func (tkn *SQLTokenizer) bytes() []byte {
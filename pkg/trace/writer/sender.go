@@ -15,7 +15,9 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -49,11 +51,45 @@ func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, q
 			url:       url,
 			apiKey:    endpoint.APIKey,
 			recorder:  r,
+			spool:     newSenderSpool(cfg, url),
 		})
 	}
 	return senders
 }
 
+// newSenderSpool returns the spool of the sender writing to url, or nil if the storage
+// of payloads on disk is disabled. The maximum storage size is split evenly between the
+// traces and the stats payloads of each endpoint.
+func newSenderSpool(cfg *config.AgentConfig, url *url.URL) *spool {
+	if cfg.PayloadStorageMaxSize <= 0 {
+		return nil
+	}
+	maxSize := cfg.PayloadStorageMaxSize / int64(2*len(cfg.Endpoints))
+	dir := filepath.Join(cfg.PayloadStoragePath, spoolDirName(url))
+	s, err := newSpool(dir, maxSize, cfg.PayloadStorageMaxAge)
+	if err != nil {
+		log.Errorf("Error initializing the payload storage in %q, payloads will not be stored on disk: %v", dir, err)
+		return nil
+	}
+	if n := s.Len(); n > 0 {
+		log.Infof("Found %d payloads stored on disk in %q", n, dir)
+	}
+	return s
+}
+
+// spoolDirName returns the name of the directory storing the payloads sent to url,
+// e.g. "trace.agent.datadoghq.com_api_v0.2_traces".
+func spoolDirName(url *url.URL) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.Trim(url.Host+url.Path, "/"))
+}
+
 // eventRecorder implementations are able to take note of events happening in
 // the sender.
 type eventRecorder interface {
@@ -73,8 +109,11 @@ const (
 	// eventTypeRejected specifies that the edge rejected this payload.
 	eventTypeRejected
 	// eventTypeDropped specifies that a payload had to be dropped to make room
-	// in the queue.
+	// in the queue or in the spool.
 	eventTypeDropped
+	// eventTypeSpooled specifies that a payload was stored on disk to make room
+	// in the queue.
+	eventTypeSpooled
 )
 
 var eventTypeStrings = map[eventType]string{
@@ -82,6 +121,7 @@ var eventTypeStrings = map[eventType]string{
 	eventTypeSent:     "eventTypeSent",
 	eventTypeRejected: "eventTypeRejected",
 	eventTypeDropped:  "eventTypeDropped",
+	eventTypeSpooled:  "eventTypeSpooled",
 }
 
 // String implements fmt.Stringer.
@@ -126,6 +166,9 @@ type senderConfig struct {
 	// recorder specifies the eventRecorder to use when reporting events occurring
 	// in the sender.
 	recorder eventRecorder
+	// spool stores on disk the payloads which do not fit in the queue. It is nil
+	// when disabled, in which case these payloads are dropped.
+	spool *spool
 }
 
 // sender is responsible for sending payloads to a given URL. It uses a size-limited
@@ -140,6 +183,9 @@ type sender struct {
 
 	mu     sync.RWMutex // guards closed
 	closed bool         // closed reports if the loop is stopped
+
+	unspoolc chan struct{} // wakes up the unspool loop, nil when the spool is disabled
+	exit     chan struct{} // stops the unspool loop
 }
 
 // newSender returns a new sender based on the given config cfg.
@@ -150,6 +196,13 @@ func newSender(cfg *senderConfig) *sender {
 		climit: make(chan struct{}, cfg.maxConns),
 	}
 	go s.loop()
+	if cfg.spool != nil {
+		s.unspoolc = make(chan struct{}, 1)
+		s.exit = make(chan struct{})
+		go s.unspoolLoop()
+		// the payloads stored by a previous run are sent without waiting for new ones
+		s.triggerUnspool()
+	}
 	return &s
 }
 
@@ -182,6 +235,9 @@ func (s *sender) Stop() {
 	s.mu.Lock()
 	s.closed = true
 	s.mu.Unlock()
+	if s.exit != nil {
+		close(s.exit)
+	}
 	close(s.queue)
 }
 
@@ -212,10 +268,10 @@ func (s *sender) Push(p *payload) {
 			atomic.AddInt32(&s.inflight, 1)
 			return
 		default:
-			// drop the oldest item in the queue to make room
+			// drop or spool the oldest item in the queue to make room
 			select {
 			case p := <-s.queue:
-				s.releasePayload(p, eventTypeDropped, &eventData{
+				s.spoolPayload(p, &eventData{
 					bytes: p.body.Len(),
 					count: 1,
 				})
@@ -249,7 +305,10 @@ func (s *sender) sendPayload(p *payload) {
 		s.mu.RLock()
 		defer s.mu.RUnlock()
 		if s.closed {
-			// sender is stopped
+			// sender is stopped; keep the payload for the next run if possible
+			if s.cfg.spool != nil {
+				s.spoolPayload(p, stats)
+			}
 			return
 		}
 		atomic.AddInt32(&s.attempt, 1)
@@ -262,8 +321,8 @@ func (s *sender) sendPayload(p *payload) {
 			s.recordEvent(eventTypeRetry, stats)
 			return
 		default:
-			// queue is full; since this is the oldest payload, we drop it
-			s.releasePayload(p, eventTypeDropped, stats)
+			// queue is full; since this is the oldest payload, we drop or spool it
+			s.spoolPayload(p, stats)
 		}
 	case nil:
 		// request was successful; the retry queue may have grown large - we should
@@ -276,12 +335,101 @@ func (s *sender) sendPayload(p *payload) {
 			}
 		}
 		s.releasePayload(p, eventTypeSent, stats)
+		s.triggerUnspool()
 	default:
 		// this is a fatal error, we have to drop this payload
 		s.releasePayload(p, eventTypeRejected, stats)
 	}
 }
 
+// spoolPayload stores the payload p on disk when the spool is enabled, or drops it otherwise.
+// The oldest payloads of the spool may be dropped to make room for it.
+func (s *sender) spoolPayload(p *payload, data *eventData) {
+	if s.cfg.spool == nil {
+		s.releasePayload(p, eventTypeDropped, data)
+		return
+	}
+	evicted, err := s.cfg.spool.Put(p)
+	for _, n := range evicted {
+		s.recordEvent(eventTypeDropped, &eventData{bytes: int(n), count: 1})
+	}
+	if err != nil {
+		log.Errorf("Error storing payload on disk: %v", err)
+		s.releasePayload(p, eventTypeDropped, data)
+		return
+	}
+	s.releasePayload(p, eventTypeSpooled, data)
+}
+
+// triggerUnspool wakes up the unspool loop, if the spool is enabled.
+func (s *sender) triggerUnspool() {
+	if s.unspoolc == nil {
+		return
+	}
+	select {
+	case s.unspoolc <- struct{}{}:
+	default:
+		// the loop is already woken up
+	}
+}
+
+// unspoolLoop drains the spool whenever it is woken up: at startup and after each successful
+// send, so that the spool gets drained as soon as the destination is reachable again.
+func (s *sender) unspoolLoop() {
+	for {
+		select {
+		case <-s.unspoolc:
+			s.unspool()
+		case <-s.exit:
+			return
+		}
+	}
+}
+
+// unspool drops the expired payloads of the spool, then moves the oldest payloads back to
+// the queue for as long as it is at most half full.
+func (s *sender) unspool() {
+	expired, err := s.cfg.spool.Expire()
+	for _, n := range expired {
+		s.recordEvent(eventTypeDropped, &eventData{bytes: int(n), count: 1})
+	}
+	if err != nil {
+		log.Errorf("Error removing expired payloads from disk: %v", err)
+	}
+	for s.cfg.spool.Len() > 0 && len(s.queue) <= cap(s.queue)/2 {
+		if !s.unspoolOne() {
+			return
+		}
+	}
+}
+
+// unspoolOne moves the oldest payload of the spool back to the queue. It returns false when
+// the spool can not be drained further for now.
+func (s *sender) unspoolOne() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false
+	}
+	p, err := s.cfg.spool.Pop()
+	if err != nil {
+		log.Errorf("Error reading payload from disk: %v", err)
+		return true
+	}
+	if p == nil {
+		return false
+	}
+	atomic.AddInt32(&s.inflight, 1)
+	select {
+	case s.queue <- p:
+		return true
+	default:
+		// the queue got filled in the meantime
+		s.spoolPayload(p, &eventData{bytes: p.body.Len(), count: 1})
+		return false
+	}
+}
+
 // waitForSenders blocks until all senders have sent their inflight payloads
 func waitForSenders(senders []*sender) {
 	var wg sync.WaitGroup
@@ -114,6 +114,90 @@ func TestSender(t *testing.T) {
 		assert.Empty(t, s.queue)
 	})
 
+	t.Run("Push/spool", func(t *testing.T) {
+		sp, err := newSpool(tempDir(t), 1000, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var recorder mockRecorder
+		s := &sender{
+			cfg:   &senderConfig{spool: sp, recorder: &recorder, url: &url.URL{Host: "localhost"}},
+			queue: make(chan *payload, 2),
+		}
+		p := func(n string) *payload {
+			return &payload{body: bytes.NewBufferString(n), headers: map[string]string{"X-Id": n}}
+		}
+		for i := 1; i <= 5; i++ {
+			s.Push(p(strconv.Itoa(i)))
+		}
+
+		// the oldest payloads are stored on disk instead of being dropped
+		assert.Empty(t, recorder.data(eventTypeDropped))
+		assert.Len(t, recorder.data(eventTypeSpooled), 3)
+		assert.Equal(t, p("4"), <-s.queue)
+		assert.Equal(t, p("5"), <-s.queue)
+		for i := 1; i <= 3; i++ {
+			spooled, err := sp.Pop()
+			assert.NoError(t, err)
+			assert.Equal(t, strconv.Itoa(i), spooled.body.String())
+			assert.Equal(t, map[string]string{"X-Id": strconv.Itoa(i)}, spooled.headers)
+		}
+		assert.Zero(t, sp.Len())
+	})
+
+	t.Run("unspool", func(t *testing.T) {
+		assert := assert.New(t)
+		server := newTestServerWithLatency(10 * time.Millisecond)
+		defer server.Close()
+		defer useBackoffDuration(0)()
+
+		sp, err := newSpool(tempDir(t), 1<<20, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var recorder mockRecorder
+		cfg := testSenderConfig(server.URL)
+		cfg.maxConns = 1
+		cfg.maxQueued = 1
+		cfg.recorder = &recorder
+		cfg.spool = sp
+		s := newSender(cfg)
+		for i := 0; i < 20; i++ {
+			s.Push(expectResponses(200))
+		}
+		// the stored payloads are sent once there is room in the queue
+		assert.Eventually(func() bool { return server.Accepted() == 20 }, 5*time.Second, 10*time.Millisecond)
+		s.Stop()
+
+		assert.NotEmpty(recorder.data(eventTypeSpooled))
+		assert.Empty(recorder.data(eventTypeDropped))
+		assert.Zero(sp.Len())
+	})
+
+	t.Run("unspool/startup", func(t *testing.T) {
+		assert := assert.New(t)
+		server := newTestServer()
+		defer server.Close()
+
+		sp, err := newSpool(tempDir(t), 1<<20, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 20; i++ {
+			if _, err := sp.Put(expectResponses(200)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cfg := testSenderConfig(server.URL)
+		cfg.maxQueued = 4
+		cfg.spool = sp
+		s := newSender(cfg)
+		// the payloads stored by a previous run are all sent, without any new payload
+		assert.Eventually(func() bool { return server.Accepted() == 20 }, 5*time.Second, 10*time.Millisecond)
+		s.Stop()
+		assert.Zero(sp.Len())
+	})
+
 	t.Run("failed", func(t *testing.T) {
 		assert := assert.New(t)
 		server := newTestServer()
@@ -293,8 +377,8 @@ func useBackoffDuration(d time.Duration) func() {
 
 // mockRecorder is a mock eventRecorder which records all calls to recordEvent.
 type mockRecorder struct {
-	mu                             sync.RWMutex
-	retry, sent, dropped, rejected []*eventData
+	mu                                      sync.RWMutex
+	retry, sent, dropped, rejected, spooled []*eventData
 }
 
 // data returns all call data for the given eventType.
@@ -310,6 +394,8 @@ func (r *mockRecorder) data(t eventType) []*eventData {
 		return r.dropped
 	case eventTypeRejected:
 		return r.rejected
+	case eventTypeSpooled:
+		return r.spooled
 	default:
 		panic("unknown event")
 	}
@@ -328,6 +414,8 @@ func (r *mockRecorder) recordEvent(t eventType, data *eventData) {
 		r.dropped = append(r.dropped, data)
 	case eventTypeRejected:
 		r.rejected = append(r.rejected, data)
+	case eventTypeSpooled:
+		r.spooled = append(r.spooled, data)
 	}
 }
 
@@ -0,0 +1,220 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	spoolFileExtension = ".payload"
+	spoolFileFormat    = "2006_01_02__15_04_05.000000000"
+)
+
+// spool stores on disk the payloads which can not be kept in the sender's queue while
+// the intake is unreachable, so that they can be sent later on, including after a restart.
+// When its maximum size is reached, the oldest payloads are removed first.
+type spool struct {
+	path    string
+	maxSize int64
+	maxAge  time.Duration // 0 to keep the payloads until they are sent
+
+	mu        sync.Mutex  // guards below
+	filenames []string    // oldest first
+	sizes     []int64     // size of each file
+	created   []time.Time // creation time of each file
+	size      int64       // total size of the files
+	seq       uint64      // orders the files created at the same time
+}
+
+// newSpool returns a new spool storing at most maxSize bytes of payloads in the directory
+// at path, for at most maxAge. The payloads stored by a previous run are loaded.
+func newSpool(path string, maxSize int64, maxAge time.Duration) (*spool, error) {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	s := &spool{path: path, maxSize: maxSize, maxAge: maxAge}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// reload loads the payload files found in the spool directory.
+func (s *spool) reload() error {
+	entries, err := ioutil.ReadDir(s.path)
+	if err != nil {
+		return err
+	}
+	var files []os.FileInfo
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() && filepath.Ext(entry.Name()) == spoolFileExtension {
+			files = append(files, entry)
+		}
+	}
+	// the file names start with their creation time
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name() < files[j].Name()
+	})
+	for _, f := range files {
+		created := f.ModTime()
+		if len(f.Name()) >= len(spoolFileFormat) {
+			if t, err := time.Parse(spoolFileFormat, f.Name()[:len(spoolFileFormat)]); err == nil {
+				created = t
+			}
+		}
+		s.filenames = append(s.filenames, filepath.Join(s.path, f.Name()))
+		s.sizes = append(s.sizes, f.Size())
+		s.created = append(s.created, created)
+		s.size += f.Size()
+	}
+	return nil
+}
+
+// Len returns the number of payloads in the spool.
+func (s *spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.filenames)
+}
+
+// Put stores the payload p on disk. It returns the sizes of the oldest payloads which
+// were removed to make room for it.
+func (s *spool) Put(p *payload) (evicted []int64, err error) {
+	b, err := marshalPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	size := int64(len(b))
+	if size > s.maxSize {
+		return nil, fmt.Errorf("payload too big: %d bytes, maximum is %d", size, s.maxSize)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.filenames) > 0 && s.size+size > s.maxSize {
+		n, err := s.removeOldest()
+		if err != nil {
+			return evicted, err
+		}
+		evicted = append(evicted, n)
+	}
+
+	s.seq++
+	now := time.Now().UTC()
+	prefix := fmt.Sprintf("%s_%010d_", now.Format(spoolFileFormat), s.seq)
+	f, err := ioutil.TempFile(s.path, prefix+"*"+spoolFileExtension)
+	if err != nil {
+		return evicted, err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return evicted, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return evicted, err
+	}
+	s.filenames = append(s.filenames, f.Name())
+	s.sizes = append(s.sizes, size)
+	s.created = append(s.created, now)
+	s.size += size
+	return evicted, nil
+}
+
+// Expire removes the payloads stored for longer than the maximum age of the spool. It returns
+// the sizes of the removed payloads.
+func (s *spool) Expire() (expired []int64, err error) {
+	if s.maxAge <= 0 {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.filenames) > 0 && time.Since(s.created[0]) > s.maxAge {
+		n, err := s.removeOldest()
+		if err != nil {
+			return expired, err
+		}
+		expired = append(expired, n)
+	}
+	return expired, nil
+}
+
+// Pop removes the oldest payload from the spool and returns it. It returns nil when
+// the spool is empty.
+func (s *spool) Pop() (*payload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.filenames) == 0 {
+		return nil, nil
+	}
+	b, err := ioutil.ReadFile(s.filenames[0])
+	// the file is removed even when it can not be read, not to fail again
+	if _, errRemove := s.removeOldest(); errRemove != nil {
+		return nil, errRemove
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPayload(b)
+}
+
+// removeOldest removes the oldest file of the spool, returning its size. The caller
+// must hold s.mu.
+func (s *spool) removeOldest() (int64, error) {
+	filename, size := s.filenames[0], s.sizes[0]
+	s.filenames = s.filenames[1:]
+	s.sizes = s.sizes[1:]
+	s.created = s.created[1:]
+	s.size -= size
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return size, err
+	}
+	return size, nil
+}
+
+// marshalPayload encodes the payload p as the length of its JSON encoded headers,
+// followed by the headers and by its body.
+func marshalPayload(p *payload) ([]byte, error) {
+	headers, err := json.Marshal(p.headers)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 4, 4+len(headers)+p.body.Len())
+	binary.BigEndian.PutUint32(b, uint32(len(headers)))
+	b = append(b, headers...)
+	return append(b, p.body.Bytes()...), nil
+}
+
+var errInvalidSpoolFile = errors.New("invalid spool file")
+
+// unmarshalPayload decodes a payload encoded with marshalPayload.
+func unmarshalPayload(b []byte) (*payload, error) {
+	if len(b) < 4 {
+		return nil, errInvalidSpoolFile
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, errInvalidSpoolFile
+	}
+	var headers map[string]string
+	if err := json.Unmarshal(b[4:4+n], &headers); err != nil {
+		return nil, err
+	}
+	p := newPayload(headers)
+	p.body.Write(b[4+n:])
+	return p, nil
+}
@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+// tempDir returns a new temporary directory, removed when the test completes.
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "trace-spool-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestSpool(t *testing.T) {
+	newTestPayload := func(body string) *payload {
+		p := newPayload(map[string]string{"Content-Encoding": "gzip"})
+		p.body.WriteString(body)
+		return p
+	}
+	// the size of a payload on disk: the headers length, the headers and the body
+	size := int64(len(`xxxx{"Content-Encoding":"gzip"}`) + 10)
+
+	t.Run("fifo", func(t *testing.T) {
+		assert := assert.New(t)
+		s, err := newSpool(tempDir(t), 10*size, 0)
+		assert.NoError(err)
+		for _, body := range []string{"0000000001", "0000000002", "0000000003"} {
+			evicted, err := s.Put(newTestPayload(body))
+			assert.NoError(err)
+			assert.Empty(evicted)
+		}
+		assert.Equal(3, s.Len())
+		assert.Equal(3*size, s.size)
+		for _, body := range []string{"0000000001", "0000000002", "0000000003"} {
+			p, err := s.Pop()
+			assert.NoError(err)
+			assert.Equal(body, p.body.String())
+			assert.Equal(map[string]string{"Content-Encoding": "gzip"}, p.headers)
+		}
+		p, err := s.Pop()
+		assert.NoError(err)
+		assert.Nil(p)
+		assert.Zero(s.size)
+	})
+
+	t.Run("evict", func(t *testing.T) {
+		assert := assert.New(t)
+		s, err := newSpool(tempDir(t), 2*size, 0)
+		assert.NoError(err)
+		for _, body := range []string{"0000000001", "0000000002", "0000000003", "0000000004"} {
+			_, err := s.Put(newTestPayload(body))
+			assert.NoError(err)
+		}
+		// the oldest payloads make room for the newest
+		assert.Equal(2, s.Len())
+		files, err := ioutil.ReadDir(s.path)
+		assert.NoError(err)
+		assert.Len(files, 2)
+		evicted, err := s.Put(newTestPayload("0000000005"))
+		assert.NoError(err)
+		assert.Equal([]int64{size}, evicted)
+		p, err := s.Pop()
+		assert.NoError(err)
+		assert.Equal("0000000004", p.body.String())
+
+		// a payload bigger than the spool is not stored
+		_, err = s.Put(newTestPayload(strings.Repeat("x", int(2*size))))
+		assert.Error(err)
+		assert.Equal(1, s.Len())
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		assert := assert.New(t)
+		dir := tempDir(t)
+		s, err := newSpool(dir, 10*size, 0)
+		assert.NoError(err)
+		for _, body := range []string{"0000000001", "0000000002"} {
+			_, err := s.Put(newTestPayload(body))
+			assert.NoError(err)
+		}
+		// a file which is not a payload is ignored
+		assert.NoError(ioutil.WriteFile(filepath.Join(dir, "other.txt"), []byte("x"), 0600))
+
+		s, err = newSpool(dir, 10*size, 0)
+		assert.NoError(err)
+		assert.Equal(2, s.Len())
+		assert.Equal(2*size, s.size)
+		for _, body := range []string{"0000000001", "0000000002"} {
+			p, err := s.Pop()
+			assert.NoError(err)
+			assert.Equal(body, p.body.String())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert := assert.New(t)
+		dir := tempDir(t)
+		assert.NoError(ioutil.WriteFile(filepath.Join(dir, "bad"+spoolFileExtension), []byte("\xff\xff\xff\xff{}"), 0600))
+		s, err := newSpool(dir, 10*size, 0)
+		assert.NoError(err)
+		_, err = s.Pop()
+		assert.Equal(errInvalidSpoolFile, err)
+		// the invalid file was removed
+		assert.Zero(s.Len())
+		files, err := ioutil.ReadDir(dir)
+		assert.NoError(err)
+		assert.Empty(files)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		assert := assert.New(t)
+		dir := tempDir(t)
+		// a payload stored by a previous run two hours ago
+		b, err := marshalPayload(newTestPayload("0000000001"))
+		assert.NoError(err)
+		old := time.Now().UTC().Add(-2*time.Hour).Format(spoolFileFormat) + "_0000000001_x" + spoolFileExtension
+		assert.NoError(ioutil.WriteFile(filepath.Join(dir, old), b, 0600))
+
+		s, err := newSpool(dir, 10*size, time.Hour)
+		assert.NoError(err)
+		_, err = s.Put(newTestPayload("0000000002"))
+		assert.NoError(err)
+		expired, err := s.Expire()
+		assert.NoError(err)
+		assert.Equal([]int64{size}, expired)
+		assert.Equal(1, s.Len())
+		p, err := s.Pop()
+		assert.NoError(err)
+		assert.Equal("0000000002", p.body.String())
+	})
+}
+
+func TestNewSenderSpool(t *testing.T) {
+	u, err := url.Parse("https://trace.agent.datadoghq.com/api/v0.2/traces")
+	assert.NoError(t, err)
+	assert.Equal(t, "trace.agent.datadoghq.com_api_v0.2_traces", spoolDirName(u))
+
+	cfg := config.New()
+	cfg.Endpoints = []*config.Endpoint{{Host: "https://trace.agent.datadoghq.com", APIKey: "123"}}
+	assert.Nil(t, newSenderSpool(cfg, u))
+
+	cfg.PayloadStoragePath = tempDir(t)
+	cfg.PayloadStorageMaxSize = 1000
+	s := newSenderSpool(cfg, u)
+	assert.NotNil(t, s)
+	// the storage is shared between the traces and the stats payloads
+	assert.EqualValues(t, 500, s.maxSize)
+	assert.Equal(t, 24*time.Hour, s.maxAge)
+	assert.Equal(t, filepath.Join(cfg.PayloadStoragePath, "trace.agent.datadoghq.com_api_v0.2_traces"), s.path)
+}
@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package protowire decodes the protobuf wire format, it is used to read the
+// messages of third party protocols without depending on their generated code.
+package protowire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Wire types of the protobuf fields.
+const (
+	WireVarint  = 0
+	WireFixed64 = 1
+	WireBytes   = 2
+	WireFixed32 = 5
+)
+
+// ErrTruncated is returned when a message ends in the middle of a field.
+var ErrTruncated = errors.New("truncated message")
+
+// Decoder reads the fields of a protobuf message.
+type Decoder struct {
+	data []byte
+}
+
+// NewDecoder returns a decoder reading the given message.
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+// Done returns true when the whole message was read.
+func (d *Decoder) Done() bool {
+	return len(d.data) == 0
+}
+
+// Key returns the number and the wire type of the next field.
+func (d *Decoder) Key() (int, int, error) {
+	key, err := d.Varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(key >> 3), int(key & 7), nil
+}
+
+// Varint reads a varint value.
+func (d *Decoder) Varint() (uint64, error) {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, ErrTruncated
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+// Fixed64 reads a 64 bits value.
+func (d *Decoder) Fixed64() (uint64, error) {
+	if len(d.data) < 8 {
+		return 0, ErrTruncated
+	}
+	v := binary.LittleEndian.Uint64(d.data)
+	d.data = d.data[8:]
+	return v, nil
+}
+
+// Fixed32 reads a 32 bits value.
+func (d *Decoder) Fixed32() (uint32, error) {
+	if len(d.data) < 4 {
+		return 0, ErrTruncated
+	}
+	v := binary.LittleEndian.Uint32(d.data)
+	d.data = d.data[4:]
+	return v, nil
+}
+
+// Double reads a double value.
+func (d *Decoder) Double() (float64, error) {
+	v, err := d.Fixed64()
+	return math.Float64frombits(v), err
+}
+
+// Bytes reads a length delimited value, the returned slice references the message.
+func (d *Decoder) Bytes() ([]byte, error) {
+	length, err := d.Varint()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(d.data)) < length {
+		return nil, ErrTruncated
+	}
+	v := d.data[:length]
+	d.data = d.data[length:]
+	return v, nil
+}
+
+// String reads a string value.
+func (d *Decoder) String() (string, error) {
+	v, err := d.Bytes()
+	return string(v), err
+}
+
+// Skip skips the value of a field of the given wire type.
+func (d *Decoder) Skip(wireType int) error {
+	var err error
+	switch wireType {
+	case WireVarint:
+		_, err = d.Varint()
+	case WireFixed64:
+		_, err = d.Fixed64()
+	case WireBytes:
+		_, err = d.Bytes()
+	case WireFixed32:
+		_, err = d.Fixed32()
+	default:
+		err = fmt.Errorf("unsupported wire type %d", wireType)
+	}
+	return err
+}
+
+// Expect returns an error if the wire type of a field is not the expected one.
+func Expect(field, wireType, expected int) error {
+	if wireType != expected {
+		return fmt.Errorf("invalid wire type %d for field %d", wireType, field)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protowire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeFields(t *testing.T) {
+	embedded := &Encoder{}
+	embedded.String(1, "embedded")
+
+	e := &Encoder{}
+	e.Key(1, WireVarint)
+	e.Varint(300)
+	e.Key(2, WireFixed64)
+	e.Double(-1.5)
+	e.String(3, "value")
+	e.Message(4, embedded)
+	e.Key(5, WireFixed32)
+	e.data = append(e.data, 1, 0, 0, 0)
+
+	d := NewDecoder(e.Data())
+	field, wireType, err := d.Key()
+	require.NoError(t, err)
+	assert.Equal(t, 1, field)
+	assert.Equal(t, WireVarint, wireType)
+	v, err := d.Varint()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(300), v)
+
+	field, wireType, err = d.Key()
+	require.NoError(t, err)
+	assert.NoError(t, Expect(field, wireType, WireFixed64))
+	assert.Error(t, Expect(field, wireType, WireBytes))
+	f, err := d.Double()
+	require.NoError(t, err)
+	assert.Equal(t, -1.5, f)
+
+	_, _, err = d.Key()
+	require.NoError(t, err)
+	s, err := d.String()
+	require.NoError(t, err)
+	assert.Equal(t, "value", s)
+
+	_, _, err = d.Key()
+	require.NoError(t, err)
+	msg, err := d.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, embedded.Data(), msg)
+
+	_, wireType, err = d.Key()
+	require.NoError(t, err)
+	require.NoError(t, d.Skip(wireType))
+	assert.True(t, d.Done())
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	e := &Encoder{}
+	e.String(1, "value")
+	data := e.Data()
+
+	d := NewDecoder(data[:len(data)-1])
+	_, _, err := d.Key()
+	require.NoError(t, err)
+	_, err = d.Bytes()
+	assert.Equal(t, ErrTruncated, err)
+
+	d = NewDecoder([]byte{0x80})
+	_, err = d.Varint()
+	assert.Equal(t, ErrTruncated, err)
+
+	d = NewDecoder([]byte{1, 2, 3})
+	_, err = d.Fixed64()
+	assert.Equal(t, ErrTruncated, err)
+
+	d = NewDecoder(nil)
+	assert.Error(t, d.Skip(3))
+}
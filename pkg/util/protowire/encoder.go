@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protowire
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Encoder writes the fields of a protobuf message.
+type Encoder struct {
+	data []byte
+}
+
+// Data returns the encoded message.
+func (e *Encoder) Data() []byte {
+	return e.data
+}
+
+// Key writes the number and the wire type of a field.
+func (e *Encoder) Key(field, wireType int) {
+	e.Varint(uint64(field<<3 | wireType))
+}
+
+// Varint writes a varint value.
+func (e *Encoder) Varint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	e.data = append(e.data, buf[:n]...)
+}
+
+// Fixed64 writes a 64 bits value.
+func (e *Encoder) Fixed64(v uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	e.data = append(e.data, buf[:]...)
+}
+
+// Double writes a double value.
+func (e *Encoder) Double(v float64) {
+	e.Fixed64(math.Float64bits(v))
+}
+
+// Bytes writes a length delimited field.
+func (e *Encoder) Bytes(field int, v []byte) {
+	e.Key(field, WireBytes)
+	e.Varint(uint64(len(v)))
+	e.data = append(e.data, v...)
+}
+
+// String writes a string field.
+func (e *Encoder) String(field int, v string) {
+	e.Bytes(field, []byte(v))
+}
+
+// Message writes an embedded message field.
+func (e *Encoder) Message(field int, msg *Encoder) {
+	e.Bytes(field, msg.data)
+}